@@ -0,0 +1,276 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// indexStride is the number of rows between two consecutive entries of [Artifacts.Index].
+const indexStride = 1024
+
+// ColumnType is the type inferred for a column's values.
+type ColumnType int
+
+// Column types, from the most specific to the most generic.
+const (
+	// ColumnTypeUnknown is the type of a column having only empty values.
+	ColumnTypeUnknown ColumnType = iota
+	// ColumnTypeBool is the type of a column having only "true"/"false" values.
+	ColumnTypeBool
+	// ColumnTypeInt is the type of a column having only integer values.
+	ColumnTypeInt
+	// ColumnTypeFloat is the type of a column having only numeric values.
+	ColumnTypeFloat
+	// ColumnTypeString is the type of any other column.
+	ColumnTypeString
+)
+
+// String returns the name of the column type.
+func (ct ColumnType) String() string {
+	switch ct {
+	case ColumnTypeBool:
+		return "bool"
+	case ColumnTypeInt:
+		return "int"
+	case ColumnTypeFloat:
+		return "float"
+	case ColumnTypeString:
+		return "string"
+	default:
+		return "unknown"
+	}
+}
+
+// Artifacts holds the information derived from a full scan of a CSV file.
+type Artifacts struct {
+	// RowsCount is the number of rows in the file (header excluded).
+	RowsCount int `json:"rowsCount"`
+	// ColumnsCount is the number of columns of the first row.
+	ColumnsCount int `json:"columnsCount"`
+	// ColumnsTypes holds the inferred type of each column.
+	ColumnsTypes []ColumnType `json:"columnsTypes"`
+	// Index holds the byte offset of every 1024th row (0th, 1024th, 2048th...).
+	Index []int64 `json:"index"`
+	// FileSize is the size of the analyzed file.
+	FileSize int64 `json:"fileSize"`
+}
+
+// BoundaryMap returns the rows' boundaries held by the artifacts' index,
+// to be set as [CsvReader.BoundaryMap].
+func (a Artifacts) BoundaryMap() *BoundaryMap {
+	return &BoundaryMap{
+		RowsCount: int64(a.RowsCount),
+		FileSize:  a.FileSize,
+		Offsets:   slices.Clone(a.Index),
+	}
+}
+
+// Analyze scans the whole CSV file and returns the derived [Artifacts].
+// If [CsvReader.Cache] is set, artifacts are looked up in the cache first,
+// and stored in it after a successful scan, keyed by the file's checksum
+// and a hash of the reader's configuration.
+func (cr *CsvReader) Analyze(ctx context.Context) (Artifacts, error) {
+	cr = cr.withContextFS(ctx)
+	var (
+		key string
+		err error
+	)
+	if cr.Cache != nil {
+		key, err = cr.cacheKey()
+		if err != nil {
+			return Artifacts{}, fmt.Errorf("bigcsvreader: could not compute cache key (%w)", err)
+		}
+		if value, ok := cr.Cache.Get(key); ok {
+			var artifacts Artifacts
+			if err := json.Unmarshal(value, &artifacts); err == nil {
+				cr.Logger.Debug("msg", "artifacts cache hit", "file", cr.fileBaseName, "key", key)
+
+				return artifacts, nil
+			}
+		}
+	}
+
+	artifacts, err := cr.analyze(ctx)
+	if err != nil {
+		return Artifacts{}, err
+	}
+
+	if cr.Cache != nil {
+		value, _ := json.Marshal(artifacts)
+		if err := cr.Cache.Set(key, value); err != nil {
+			cr.Logger.Error(
+				"msg", "could not store artifacts into cache", "err", err,
+				"file", cr.fileBaseName, "key", key,
+			)
+		}
+	}
+
+	return artifacts, nil
+}
+
+// analyze performs a sequential scan of the file computing the artifacts.
+func (cr *CsvReader) analyze(ctx context.Context) (Artifacts, error) {
+	var artifacts Artifacts
+	_, err := cr.scanRows(ctx, func(record []string, offset int) {
+		if artifacts.RowsCount%indexStride == 0 {
+			artifacts.Index = append(artifacts.Index, int64(offset))
+		}
+		if artifacts.RowsCount == 0 {
+			artifacts.ColumnsCount = len(record)
+			artifacts.ColumnsTypes = make([]ColumnType, len(record))
+		}
+		for col := 0; col < len(record) && col < len(artifacts.ColumnsTypes); col++ {
+			artifacts.ColumnsTypes[col] = widenColumnType(artifacts.ColumnsTypes[col], record[col])
+		}
+		artifacts.RowsCount++
+	})
+	if err != nil {
+		return Artifacts{}, err
+	}
+	reader, _, err := cr.withDecompressingFS()
+	if err != nil {
+		return Artifacts{}, fmt.Errorf("bigcsvreader: could not open file (%w)", err)
+	}
+	fileSize, err := reader.getFileSize()
+	if err != nil {
+		return Artifacts{}, fmt.Errorf("bigcsvreader: file size error (%w)", err)
+	}
+	artifacts.FileSize = int64(fileSize)
+
+	return artifacts, nil
+}
+
+// scanRows performs a sequential scan of the (decompressed) file, passing each row and its offset to given function.
+// Blank lines are skipped, line breaks in quoted fields are honored as [CsvReader.Read] does.
+// The record is reused between calls. It returns the header, if the file has one.
+// The buffer is sized as for reading, see [CsvReader.fitBufferSize], on a copy of the reader.
+func (cr *CsvReader) scanRows(ctx context.Context, fn func(record []string, offset int)) ([]string, error) {
+	cr, _, err := cr.withDecompressingFS()
+	if err != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not open file (%w)", err)
+	}
+	fileSize, err := cr.getFileSize()
+	if err != nil {
+		return nil, fmt.Errorf("bigcsvreader: file size error (%w)", err)
+	}
+	f, err := cr.FS.Open(cr.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not open file (%w)", err)
+	}
+	defer f.Close()
+
+	var (
+		header []string
+		offset int
+	)
+	if cr.FileHasHeader {
+		if header, offset, err = cr.readHeader(f); err != nil {
+			return nil, err
+		}
+		if _, err := f.Seek(int64(offset), io.SeekStart); err != nil {
+			return nil, fmt.Errorf("bigcsvreader: could not seek data start (%w)", err)
+		}
+	}
+	reader := cr.snapshot()
+	reader.normalizeSizes()
+	reader.fitBufferSize(nil, offset, fileSize)
+
+	r := bufio.NewReaderSize(f, reader.BufferSize)
+	parser := cr.newLineParser(cr.ColumnsCount)
+	if p, ok := parser.(*csvLineParser); ok {
+		p.csvReader.ReuseRecord = true
+	}
+	readLine := func() ([]byte, error) {
+		line, err := reader.readSlice(r)
+		if err != nil && err != io.EOF {
+			if errors.Is(err, bufio.ErrBufferFull) {
+				err = fmt.Errorf("%w: %w", ErrBufferFull, err)
+			}
+
+			return nil, fmt.Errorf("bigcsvreader: could not read line at offset %d (%w)", offset, err)
+		}
+
+		return line, nil
+	}
+	var joined []byte // lines of a record having line breaks in quoted fields.
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("bigcsvreader: received context error (%w)", err)
+		}
+		line, err := readLine()
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 {
+			break
+		}
+		if isBlankLine(line) {
+			offset += len(line)
+
+			continue
+		}
+		if (cr.MultilineQuotedFields || cr.CRLFInQuotedFields) && cr.continuesRecord(line, false) {
+			joined = append(joined[:0], line...)
+			for cr.continuesRecord(joined, false) {
+				if line, err = readLine(); err != nil {
+					return nil, err
+				}
+				if len(line) == 0 {
+					break // EOF, let the CSV parser report the unterminated field.
+				}
+				if len(joined)+len(line) > reader.maxRecordSize() {
+					return nil, fmt.Errorf("bigcsvreader: could not read row at offset %d (%w)", offset, ErrRecordTooLong)
+				}
+				joined = append(joined, line...)
+			}
+			line = joined
+		}
+
+		record, parseErr := parser.parse(line)
+		if parseErr != nil {
+			return nil, fmt.Errorf("bigcsvreader: could not parse row at offset %d (%w)", offset, parseErr)
+		}
+		fn(record, offset)
+		offset += len(line)
+	}
+
+	return header, nil
+}
+
+// widenColumnType returns the most specific type which accommodates
+// both current type and given value.
+func widenColumnType(current ColumnType, value string) ColumnType {
+	if value == "" || current == ColumnTypeString {
+		return current
+	}
+	valueType := ColumnTypeString
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		valueType = ColumnTypeInt
+	} else if _, err := strconv.ParseFloat(value, 64); err == nil {
+		valueType = ColumnTypeFloat
+	} else if value == "true" || value == "false" {
+		valueType = ColumnTypeBool
+	}
+
+	switch {
+	case current == ColumnTypeUnknown || current == valueType:
+		return valueType
+	case current == ColumnTypeBool || valueType == ColumnTypeBool:
+		return ColumnTypeString
+	case current > valueType:
+		return current
+	default:
+		return valueType
+	}
+}
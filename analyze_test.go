@@ -0,0 +1,216 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_Analyze(t *testing.T) {
+	t.Parallel()
+
+	t.Run("artifacts are computed", testCsvReaderAnalyze)
+	t.Run("artifacts are cached", testCsvReaderAnalyzeWithCache)
+	t.Run("artifacts are cached per dialect", testCsvReaderAnalyzeWithCachePerDialect)
+	t.Run("lines longer than the buffer", testCsvReaderAnalyzeLongLines)
+	t.Run("blank lines and line breaks in quoted fields", testCsvReaderAnalyzeBlankLinesAndQuotedLineBreaks)
+	t.Run("bad header", testCsvReaderAnalyzeBadHeader)
+}
+
+func testCsvReaderAnalyze(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/file_with_header.csv")
+	subject.FileHasHeader = true
+	subject.ColumnsDelimiter = ';'
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	artifacts, err := subject.Analyze(ctx)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 5, artifacts.RowsCount)
+	assertEqual(t, 3, artifacts.ColumnsCount)
+	assertEqual(
+		t,
+		[]bigcsvreader.ColumnType{bigcsvreader.ColumnTypeInt, bigcsvreader.ColumnTypeString, bigcsvreader.ColumnTypeInt},
+		artifacts.ColumnsTypes,
+	)
+	assertEqual(t, []int64{18}, artifacts.Index) // first row after header
+}
+
+func testCsvReaderAnalyzeLongLines(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	const rowsCount = 10
+	var content strings.Builder
+	for i := 1; i <= rowsCount; i++ {
+		content.WriteString(strconv.Itoa(i) + "," + strings.Repeat("x", 100) + "\n")
+	}
+	subject := bigcsvreader.New()
+	subject.SetFilePath(writeTmpFile(t, t.TempDir(), "long_lines.csv", content.String()))
+	subject.BufferSize = 16
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	artifacts, err := subject.Analyze(ctx)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, rowsCount, artifacts.RowsCount)
+	assertEqual(t, 16, subject.BufferSize) // sized on a copy of the reader.
+}
+
+func testCsvReaderAnalyzeBlankLinesAndQuotedLineBreaks(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	dir := t.TempDir()
+	subject := bigcsvreader.New()
+	subject.SetFilePath(writeTmpFile(t, dir, "multiline.csv", "1,a\n\n2,\"b\nc\"\n\r\n3,\"d\r\ne\"\n"))
+	subject.ColumnsCount = 2
+	subject.MultilineQuotedFields = true
+	crlfSubject := bigcsvreader.New()
+	crlfSubject.SetFilePath(writeTmpFile(t, dir, "crlf.csv", "1,\"a\r\nb\"\r\n\r\n2,c\r\n"))
+	crlfSubject.ColumnsCount = 2
+	crlfSubject.CRLFInQuotedFields = true
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	artifacts, err := subject.Analyze(ctx)
+	crlfArtifacts, crlfErr := crlfSubject.Analyze(ctx)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 3, artifacts.RowsCount)
+	assertNil(t, crlfErr)
+	assertEqual(t, 2, crlfArtifacts.RowsCount)
+}
+
+func testCsvReaderAnalyzeBadHeader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := bigcsvreader.New()
+	subject.SetFilePath(writeTmpFile(t, t.TempDir(), "bad_header.csv", "\"id,name\n1,John\n"))
+	subject.FileHasHeader = true
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	_, err := subject.Analyze(ctx)
+
+	// assert
+	assertTrue(t, errors.Is(err, bigcsvreader.ErrBadHeader))
+}
+
+func testCsvReaderAnalyzeWithCache(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	dirCache, err := bigcsvreader.NewDirCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("prerequisite failed: %v", err)
+	}
+	cache := &countingCache{Cache: dirCache}
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/file_without_header.csv")
+	subject.Cache = cache
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	artifacts1, err1 := subject.Analyze(ctx)
+	artifacts2, err2 := subject.Analyze(ctx)
+
+	// assert
+	assertNil(t, err1)
+	assertNil(t, err2)
+	assertEqual(t, 5, artifacts1.RowsCount)
+	assertEqual(t, artifacts1, artifacts2)
+	assertEqual(t, 2, cache.gets)
+	assertEqual(t, 1, cache.hits)
+	assertEqual(t, 1, cache.sets)
+}
+
+func testCsvReaderAnalyzeWithCachePerDialect(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	dirCache, err := bigcsvreader.NewDirCache(t.TempDir())
+	if err != nil {
+		t.Fatalf("prerequisite failed: %v", err)
+	}
+	cache := &countingCache{Cache: dirCache}
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/file_without_header.csv")
+	subject.Cache = cache
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	_, err1 := subject.Analyze(ctx)
+	subject.NullToken = `\N`
+	_, err2 := subject.Analyze(ctx)
+	subject.DefaultValues = []string{"0"}
+	_, err3 := subject.Analyze(ctx)
+	subject.TrimSpace = true
+	_, err4 := subject.Analyze(ctx)
+	subject.MultilineQuotedFields = true
+	_, err5 := subject.Analyze(ctx)
+
+	// assert
+	assertNil(t, err1)
+	assertNil(t, err2)
+	assertNil(t, err3)
+	assertNil(t, err4)
+	assertNil(t, err5)
+	assertEqual(t, 5, cache.gets)
+	assertEqual(t, 0, cache.hits)
+	assertEqual(t, 5, cache.sets)
+}
+
+// countingCache is a Cache decorator which counts its calls.
+type countingCache struct {
+	bigcsvreader.Cache
+	mu               sync.Mutex
+	gets, hits, sets int
+}
+
+func (c *countingCache) Get(key string) ([]byte, bool) {
+	value, ok := c.Cache.Get(key)
+	c.mu.Lock()
+	c.gets++
+	if ok {
+		c.hits++
+	}
+	c.mu.Unlock()
+
+	return value, ok
+}
+
+func (c *countingCache) Set(key string, value []byte) error {
+	c.mu.Lock()
+	c.sets++
+	c.mu.Unlock()
+
+	return c.Cache.Set(key, value)
+}
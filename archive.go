@@ -0,0 +1,148 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+// ArchiveAction is the action taken on the CSV file after it was read successfully, see [CsvReader.Archive].
+type ArchiveAction int
+
+const (
+	// ArchiveMove moves the file into [ArchiveOptions.Dir], renaming it with [ArchiveOptions.Suffix], if set.
+	// It can be used to rename the file in place, by setting only the suffix.
+	ArchiveMove ArchiveAction = iota + 1
+	// ArchiveDelete deletes the file.
+	ArchiveDelete
+	// ArchiveMarker writes an empty marker file next to the file, named after it,
+	// suffixed with [ArchiveOptions.Suffix] (".done" by default).
+	ArchiveMarker
+)
+
+// ArchiveOptions configures the action taken on the CSV file after it was read successfully.
+type ArchiveOptions struct {
+	// Action is the action to take.
+	Action ArchiveAction
+	// Dir is the directory the file is moved into, by [ArchiveMove].
+	// Defaults to the file's directory.
+	Dir string
+	// Suffix is appended to the name of the moved file, by [ArchiveMove],
+	// or to the name of the marker file, by [ArchiveMarker] (defaults to ".done").
+	Suffix string
+}
+
+// validateArchive checks the archive options describe an action, on the operating system's file system.
+func (cr *CsvReader) validateArchive() error {
+	if _, isOS := rawFS(cr.FS).(internal.OSFS); !isOS {
+		return errors.New("archive is supported only on the operating system's file system")
+	}
+	switch cr.Archive.Action {
+	case ArchiveMove:
+		if cr.Archive.Dir == "" && cr.Archive.Suffix == "" {
+			return errors.New("archive move has neither a directory, nor a suffix")
+		}
+	case ArchiveDelete, ArchiveMarker:
+	default:
+		return fmt.Errorf("unknown archive action %d", cr.Archive.Action)
+	}
+
+	return nil
+}
+
+// fail flags the run as failed, meaning rows were not read, or the outcome of the reading is not trustworthy,
+// even if all goroutines read their whole chunk.
+func (rn *Run) fail() {
+	atomic.StoreInt32(&rn.failed, 1)
+}
+
+// isComplete returns whether the whole file was read without fatal error.
+// Rows which could not be parsed or failed checks are not fatal errors.
+func (rn *Run) isComplete(ctx context.Context) bool {
+	return ctx.Err() == nil && !rn.isTimedOut() && !rn.isLimitReached() && !rn.errorRate.isAborted() &&
+		atomic.LoadInt32(&rn.failed) == 0
+}
+
+// archive takes the configured action on the CSV file, if the whole file was read without fatal error.
+func (cr *CsvReader) archive(ctx context.Context, rn *Run) {
+	if !rn.isComplete(ctx) {
+		cr.Logger.Debug("msg", "file not archived, as it was not read entirely", "file", cr.fileBaseName)
+
+		return
+	}
+	var err error
+	switch cr.Archive.Action {
+	case ArchiveMove:
+		dir := cr.Archive.Dir
+		if dir == "" {
+			dir = filepath.Dir(cr.filePath)
+		}
+		err = moveFile(cr.filePath, filepath.Join(dir, filepath.Base(cr.filePath)+cr.Archive.Suffix))
+	case ArchiveDelete:
+		err = os.Remove(cr.filePath)
+	case ArchiveMarker:
+		suffix := cr.Archive.Suffix
+		if suffix == "" {
+			suffix = ".done"
+		}
+		err = os.WriteFile(cr.filePath+suffix, nil, 0o644)
+	}
+	if err != nil {
+		if rn.sendErr(fmt.Errorf("bigcsvreader: could not archive file (%w)", err)) {
+			cr.Logger.Error("msg", "could not archive file", "err", err, "file", cr.fileBaseName)
+		}
+
+		return
+	}
+	cr.Logger.Debug("msg", "archived file", "file", cr.fileBaseName, "action", cr.Archive.Action)
+}
+
+// moveFile renames the file, or, if the destination is on another device, copies it and removes the source.
+func moveFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || !isCrossDevice(err) {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := out.Name()
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpName, dst)
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+
+		return err
+	}
+
+	return os.Remove(src)
+}
+
+// isCrossDevice returns whether the rename failed because source and destination are on different devices.
+func isCrossDevice(err error) bool {
+	return errors.Is(err, syscall.EXDEV)
+}
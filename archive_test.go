@@ -0,0 +1,222 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_Archive(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name string
+		// archive builds the options, given the temporary directory.
+		archive  func(dir string) *bigcsvreader.ArchiveOptions
+		content  string
+		limit    int64
+		control  string
+		expected []string // files expected in the temporary directory, besides the "processed" directory.
+		// expectedProcessed are the files expected in the "processed" directory.
+		expectedProcessed []string
+		expectedErr       bool
+	}{
+		{
+			name: "move into directory",
+			archive: func(dir string) *bigcsvreader.ArchiveOptions {
+				return &bigcsvreader.ArchiveOptions{Action: bigcsvreader.ArchiveMove, Dir: filepath.Join(dir, "processed")}
+			},
+			content:           numberedRows(600),
+			expectedProcessed: []string{"drop.csv"},
+		},
+		{
+			name: "move into directory with suffix",
+			archive: func(dir string) *bigcsvreader.ArchiveOptions {
+				return &bigcsvreader.ArchiveOptions{
+					Action: bigcsvreader.ArchiveMove,
+					Dir:    filepath.Join(dir, "processed"),
+					Suffix: ".ok",
+				}
+			},
+			content:           numberedRows(600),
+			expectedProcessed: []string{"drop.csv.ok"},
+		},
+		{
+			name: "rename in place",
+			archive: func(string) *bigcsvreader.ArchiveOptions {
+				return &bigcsvreader.ArchiveOptions{Action: bigcsvreader.ArchiveMove, Suffix: ".processed"}
+			},
+			content:  numberedRows(600),
+			expected: []string{"drop.csv.processed"},
+		},
+		{
+			name: "delete",
+			archive: func(string) *bigcsvreader.ArchiveOptions {
+				return &bigcsvreader.ArchiveOptions{Action: bigcsvreader.ArchiveDelete}
+			},
+			content: numberedRows(600),
+		},
+		{
+			name: "marker",
+			archive: func(string) *bigcsvreader.ArchiveOptions {
+				return &bigcsvreader.ArchiveOptions{Action: bigcsvreader.ArchiveMarker}
+			},
+			content:  numberedRows(600),
+			expected: []string{"drop.csv", "drop.csv.done"},
+		},
+		{
+			name: "marker with custom suffix",
+			archive: func(string) *bigcsvreader.ArchiveOptions {
+				return &bigcsvreader.ArchiveOptions{Action: bigcsvreader.ArchiveMarker, Suffix: ".ack"}
+			},
+			content:  numberedRows(600),
+			expected: []string{"drop.csv", "drop.csv.ack"},
+		},
+		{
+			name: "bad rows are not fatal",
+			archive: func(string) *bigcsvreader.ArchiveOptions {
+				return &bigcsvreader.ArchiveOptions{Action: bigcsvreader.ArchiveDelete}
+			},
+			content:     numberedRows(300) + "301,\"bad \"quote\",x\n" + numberedRows(10),
+			expectedErr: true,
+		},
+		{
+			name: "not archived if rows limit was reached",
+			archive: func(string) *bigcsvreader.ArchiveOptions {
+				return &bigcsvreader.ArchiveOptions{Action: bigcsvreader.ArchiveDelete}
+			},
+			content:  numberedRows(600),
+			limit:    10,
+			expected: []string{"drop.csv"},
+		},
+		{
+			name: "not archived if file does not match its control file",
+			archive: func(string) *bigcsvreader.ArchiveOptions {
+				return &bigcsvreader.ArchiveOptions{Action: bigcsvreader.ArchiveMarker}
+			},
+			content:     numberedRows(600),
+			control:     "rows=601",
+			expected:    []string{"drop.csv", "drop.csv.ctl"},
+			expectedErr: true,
+		},
+		{
+			name: "move failure is sent",
+			archive: func(dir string) *bigcsvreader.ArchiveOptions {
+				return &bigcsvreader.ArchiveOptions{Action: bigcsvreader.ArchiveMove, Dir: filepath.Join(dir, "missing")}
+			},
+			content:     numberedRows(600),
+			expected:    []string{"drop.csv"},
+			expectedErr: true,
+		},
+	}
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			dir := t.TempDir()
+			processedDir := filepath.Join(dir, "processed")
+			if err := os.Mkdir(processedDir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			subject := bigcsvreader.New()
+			subject.SetFilePath(writeTmpFile(t, dir, "drop.csv", test.content))
+			subject.ColumnsCount = 3
+			subject.MaxGoroutinesNo = 3
+			subject.LimitRows = test.limit
+			subject.Archive = test.archive(dir)
+			if test.control != "" {
+				writeTmpFile(t, dir, "drop.csv.ctl", test.control)
+				subject.Control = &bigcsvreader.ControlOptions{}
+			}
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			rowsChans, errsChan := subject.Read(ctx)
+			drainRows(rowsChans)
+			err := bigcsvreader.CollectErrors(errsChan)
+
+			// assert
+			assertEqual(t, test.expectedErr, err != nil)
+			assertEqual(t, test.expected, dirFiles(t, dir))
+			assertEqual(t, test.expectedProcessed, dirFiles(t, processedDir))
+		})
+	}
+
+	t.Run("not archived if context is canceled", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		dir := t.TempDir()
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, dir, "drop.csv", numberedRows(600)))
+		subject.ColumnsCount = 3
+		subject.Archive = &bigcsvreader.ArchiveOptions{Action: bigcsvreader.ArchiveDelete}
+		ctx, cancelCtx := context.WithCancel(context.Background())
+		cancelCtx()
+
+		// act
+		rowsChans, errsChan := subject.Read(ctx)
+		drainRows(rowsChans)
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertTrue(t, errors.Is(err, bigcsvreader.ErrCanceledWithPartialData))
+		assertEqual(t, []string{"drop.csv"}, dirFiles(t, dir))
+	})
+
+	t.Run("invalid archive options", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		dir := t.TempDir()
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, dir, "drop.csv", numberedRows(10)))
+		subject.ColumnsCount = 3
+		subject.Archive = &bigcsvreader.ArchiveOptions{Action: bigcsvreader.ArchiveMove}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rowsChans, errsChan := subject.Read(ctx)
+		rowsRead := drainRows(rowsChans)
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertEqual(t, 0, rowsRead)
+		if assertNotNil(t, err) {
+			assertTrue(t, strings.Contains(err.Error(), "invalid archive options"))
+		}
+		assertEqual(t, []string{"drop.csv"}, dirFiles(t, dir))
+	})
+}
+
+// dirFiles returns the names of the regular files of given directory.
+func dirFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+
+	return names
+}
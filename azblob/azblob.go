@@ -0,0 +1,396 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+// Package azblob provides an Azure Blob Storage file system for [bigcsvreader.CsvReader.FS],
+// so CSV blobs are read in parallel, with ranged reads, without being downloaded first.
+// Requests are authorized with a shared access signature (SAS) token.
+//
+// File names are "container/path/to/blob.csv". Blobs are read in blocks of [FS.BlockSize] bytes,
+// each block request being retried on transient failures (throttling, server errors, broken connections).
+// Reads are conditional on the blob's ETag at the time the file was opened, so a blob replaced meanwhile
+// makes reads fail with [ErrBlobChanged] ([bigcsvreader.CsvReader.PinFile] is not supported).
+// Requests, and waits between retries, are canceled with the context of the run reading the blob.
+//
+// Example:
+//
+//	cr := bigcsvreader.New()
+//	cr.FS = azblob.New("https://myaccount.blob.core.windows.net", sasToken)
+//	cr.SetFilePath("drops/2024/05/transactions.csv")
+package azblob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+const (
+	// apiVersion is the Blob service REST API version requests are made with.
+	apiVersion = "2021-08-06"
+	// defaultBlockSize is the default [FS.BlockSize].
+	defaultBlockSize = 4 << 20
+)
+
+// ErrBlobChanged is the error returned by reads if the blob was modified after the file was opened.
+var ErrBlobChanged = errors.New("azblob: blob changed during read")
+
+// FS is an Azure Blob Storage file system, reading blobs of a storage account.
+// It is safe for concurrent use.
+type FS struct {
+	// Client is the HTTP client requests are made with. Defaults to [http.DefaultClient].
+	Client *http.Client
+	// BlockSize is the number of bytes requested by a ranged read. Defaults to 4 MiB (also used if not positive).
+	BlockSize int
+	// MaxRetries is the number of times a failed request is retried. Defaults to 3.
+	MaxRetries int
+	// RetryDelay is the delay before the first retry, doubled for each next one,
+	// if the service does not state a Retry-After delay. Defaults to 200ms.
+	RetryDelay time.Duration
+	// MaxRetryDelay is the maximum delay before a retry, the Retry-After delay stated by the service included.
+	// 0 means no limit. Defaults to 30s.
+	MaxRetryDelay time.Duration
+
+	accountURL string
+	sasToken   string
+	// ctx is the context requests are made with, see [FS.WithContext].
+	ctx context.Context
+}
+
+// New instantiates a new file system for the storage account at given URL
+// (like "https://myaccount.blob.core.windows.net"), authorizing requests with given SAS token
+// (an empty token can be used for public blobs).
+func New(accountURL, sasToken string) *FS {
+	return &FS{
+		Client:        http.DefaultClient,
+		BlockSize:     defaultBlockSize,
+		MaxRetries:    3,
+		RetryDelay:    200 * time.Millisecond,
+		MaxRetryDelay: 30 * time.Second,
+		accountURL:    strings.TrimRight(accountURL, "/"),
+		sasToken:      strings.TrimPrefix(sasToken, "?"),
+		ctx:           context.Background(),
+	}
+}
+
+// WithContext returns a copy of the file system making requests, and waiting between retries, with given context.
+// [bigcsvreader.CsvReader] calls it with the context of each run.
+func (fsys *FS) WithContext(ctx context.Context) internal.FS {
+	withCtx := *fsys
+	withCtx.ctx = ctx
+
+	return &withCtx
+}
+
+// Open opens the named blob for reading.
+func (fsys *FS) Open(name string) (internal.File, error) {
+	info, err := fsys.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &file{fsys: fsys, info: info}, nil
+}
+
+// Stat returns the named blob's info.
+func (fsys *FS) Stat(name string) (os.FileInfo, error) {
+	info, err := fsys.stat("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// stat gets the named blob's properties.
+func (fsys *FS) stat(op, name string) (*blobInfo, error) {
+	resp, err := fsys.do(op, name, func() (*http.Request, error) {
+		return fsys.newRequest(http.MethodHead, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+
+	return &blobInfo{
+		name:    name,
+		size:    resp.ContentLength,
+		modTime: modTime,
+		etag:    resp.Header.Get("ETag"),
+	}, nil
+}
+
+// readBlock reads the blob's block of n bytes starting at given offset, if the blob still has given ETag.
+// The whole block is retried on failure, including a broken connection while reading the response body.
+func (fsys *FS) readBlock(info *blobInfo, offset int64, n int) ([]byte, error) {
+	block := make([]byte, n)
+	for attempt := 0; ; attempt++ {
+		resp, err := fsys.do("read", info.name, func() (*http.Request, error) {
+			req, err := fsys.newRequest(http.MethodGet, info.name)
+			if err == nil {
+				req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(offset+int64(n)-1, 10))
+				req.Header.Set("If-Match", info.etag)
+			}
+
+			return req, err
+		})
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.ReadFull(resp.Body, block)
+		_ = resp.Body.Close()
+		if err == nil {
+			return block, nil
+		}
+		if attempt >= fsys.MaxRetries {
+			return nil, &fs.PathError{Op: "read", Path: info.name, Err: err}
+		}
+		if err := fsys.wait(fsys.retryDelay(attempt, nil)); err != nil {
+			return nil, &fs.PathError{Op: "read", Path: info.name, Err: err}
+		}
+	}
+}
+
+// do makes the request built by newReq, retrying it on network errors and transient error statuses.
+// A response with an error status is turned into an error.
+func (fsys *FS) do(op, name string, newReq func() (*http.Request, error)) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := newReq()
+		if err != nil {
+			return nil, &fs.PathError{Op: op, Path: name, Err: err}
+		}
+		resp, err := fsys.Client.Do(req)
+		if err == nil {
+			if resp.StatusCode < http.StatusMultipleChoices {
+				return resp, nil
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+			err = statusError(resp)
+			if !isTransient(resp.StatusCode) {
+				return nil, &fs.PathError{Op: op, Path: name, Err: err}
+			}
+		}
+		if attempt >= fsys.MaxRetries {
+			return nil, &fs.PathError{Op: op, Path: name, Err: err}
+		}
+		if err := fsys.wait(fsys.retryDelay(attempt, resp)); err != nil {
+			return nil, &fs.PathError{Op: op, Path: name, Err: err}
+		}
+	}
+}
+
+// newRequest builds a request for the named blob.
+func (fsys *FS) newRequest(method, name string) (*http.Request, error) {
+	segments := strings.Split(strings.TrimPrefix(path.Clean("/"+name), "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	blobURL := fsys.accountURL + "/" + strings.Join(segments, "/")
+	if fsys.sasToken != "" {
+		blobURL += "?" + fsys.sasToken
+	}
+	req, err := http.NewRequestWithContext(fsys.context(), method, blobURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-version", apiVersion)
+
+	return req, nil
+}
+
+// context returns the context requests are made with.
+func (fsys *FS) context() context.Context {
+	if fsys.ctx == nil {
+		return context.Background()
+	}
+
+	return fsys.ctx
+}
+
+// wait waits for given delay, returning early, with the context's error, if the context is done.
+func (fsys *FS) wait(delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-fsys.context().Done():
+		return fsys.context().Err()
+	}
+}
+
+// retryDelay returns the delay to wait before retrying after given failed attempt,
+// the one stated by the service, if any, or an exponential one, capped at [FS.MaxRetryDelay].
+func (fsys *FS) retryDelay(attempt int, resp *http.Response) time.Duration {
+	delay := fsys.RetryDelay << attempt
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			delay = time.Duration(seconds) * time.Second
+		}
+	}
+	if fsys.MaxRetryDelay > 0 && (delay > fsys.MaxRetryDelay || delay < 0) {
+		delay = fsys.MaxRetryDelay
+	}
+
+	return delay
+}
+
+// isTransient returns whether a request failed with given status may succeed if retried.
+func isTransient(statusCode int) bool {
+	return statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests ||
+		statusCode >= http.StatusInternalServerError
+}
+
+// statusError returns the error corresponding to the error status of given response.
+func statusError(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return fs.ErrNotExist
+	case http.StatusForbidden:
+		return fs.ErrPermission
+	case http.StatusPreconditionFailed:
+		return ErrBlobChanged
+	}
+	if code := resp.Header.Get("x-ms-error-code"); code != "" {
+		return fmt.Errorf("azblob: unexpected status %d (%s)", resp.StatusCode, code)
+	}
+
+	return fmt.Errorf("azblob: unexpected status %d", resp.StatusCode)
+}
+
+// blobInfo describes a blob.
+type blobInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	etag    string
+}
+
+// Name returns the blob's base name.
+func (bi *blobInfo) Name() string { return path.Base(bi.name) }
+
+// Size returns the blob's size.
+func (bi *blobInfo) Size() int64 { return bi.size }
+
+// Mode returns read only file mode bits.
+func (bi *blobInfo) Mode() fs.FileMode { return 0o444 }
+
+// ModTime returns the blob's last modification time.
+func (bi *blobInfo) ModTime() time.Time { return bi.modTime }
+
+// IsDir returns false, blobs are files.
+func (bi *blobInfo) IsDir() bool { return false }
+
+// Sys returns nil.
+func (bi *blobInfo) Sys() any { return nil }
+
+// file is a blob opened for reading. It keeps the last block read,
+// so sequential reads request each block once.
+type file struct {
+	fsys       *FS
+	info       *blobInfo
+	mu         sync.Mutex
+	offset     int64
+	block      []byte
+	blockStart int64
+}
+
+// Read reads up to len(p) bytes into p, from the current offset.
+func (f *file) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.offset >= f.info.size {
+		return 0, io.EOF
+	}
+	n, err := f.readAt(p, f.offset)
+	f.offset += int64(n)
+	if n > 0 && errors.Is(err, io.EOF) {
+		err = nil
+	}
+
+	return n, err
+}
+
+// ReadAt reads len(p) bytes into p starting at given offset.
+func (f *file) ReadAt(p []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "read", Path: f.info.name, Err: fs.ErrInvalid}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.readAt(p, offset)
+}
+
+// readAt reads len(p) bytes into p starting at given offset, requesting blocks not read yet.
+func (f *file) readAt(p []byte, offset int64) (int, error) {
+	var n int
+	for n < len(p) {
+		pos := offset + int64(n)
+		if pos >= f.info.size {
+			return n, io.EOF
+		}
+		if pos < f.blockStart || pos >= f.blockStart+int64(len(f.block)) {
+			blockSize := int64(f.fsys.BlockSize)
+			if blockSize <= 0 {
+				blockSize = defaultBlockSize
+			}
+			blockStart := pos / blockSize * blockSize
+			block, err := f.fsys.readBlock(f.info, blockStart, int(min(blockSize, f.info.size-blockStart)))
+			if err != nil {
+				return n, err
+			}
+			f.block, f.blockStart = block, blockStart
+		}
+		n += copy(p[n:], f.block[pos-f.blockStart:])
+	}
+
+	return n, nil
+}
+
+// Seek sets the offset for the next Read.
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.size
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.info.name, Err: fs.ErrInvalid}
+	}
+	f.offset = offset
+
+	return offset, nil
+}
+
+// Close releases the block kept.
+func (f *file) Close() error {
+	f.mu.Lock()
+	f.block = nil
+	f.mu.Unlock()
+
+	return nil
+}
+
+// Stat returns the blob's info, as it was when the file was opened.
+func (f *file) Stat() (os.FileInfo, error) {
+	return f.info, nil
+}
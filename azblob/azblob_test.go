@@ -0,0 +1,379 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package azblob_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+	"github.com/actforgood/bigcsvreader/azblob"
+)
+
+const sasToken = "sv=2021-08-06&sr=b&sp=r&sig=secret"
+
+func TestFS(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rows are read with ranged reads", testFSRangedReads)
+	t.Run("invalid block size falls back to the default one", testFSInvalidBlockSize)
+	t.Run("transient failures are retried", testFSRetries)
+	t.Run("retries are exhausted", testFSRetriesExhausted)
+	t.Run("retry delay is capped", testFSRetryDelayCapped)
+	t.Run("retry delay is canceled with the context", testFSRetryDelayCanceled)
+	t.Run("invalid SAS token", testFSInvalidSASToken)
+	t.Run("blob not found", testFSNotFound)
+	t.Run("blob changed during read", testFSBlobChanged)
+}
+
+func testFSRangedReads(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	service := newBlobService(t, 1000)
+	subject := newCsvReader(service.url(), sasToken)
+
+	// act
+	rowsCount, err := read(t, subject)
+
+	// assert
+	assertNil(t, err)
+	if rowsCount != 1000 {
+		t.Errorf("expected 1000 rows, got %d", rowsCount)
+	}
+	if service.rangedGets() == 0 {
+		t.Error("expected ranged reads")
+	}
+}
+
+func testFSInvalidBlockSize(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	service := newBlobService(t, 100)
+	subject := newCsvReader(service.url(), sasToken)
+	subject.FS.(*azblob.FS).BlockSize = 0
+
+	// act
+	rowsCount, err := read(t, subject)
+
+	// assert
+	assertNil(t, err)
+	if rowsCount != 100 {
+		t.Errorf("expected 100 rows, got %d", rowsCount)
+	}
+}
+
+func testFSRetries(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	service := newBlobService(t, 1000)
+	service.failures = []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, 0, http.StatusInternalServerError, 0}
+	subject := newCsvReader(service.url(), sasToken)
+
+	// act
+	rowsCount, err := read(t, subject)
+
+	// assert
+	assertNil(t, err)
+	if rowsCount != 1000 {
+		t.Errorf("expected 1000 rows, got %d", rowsCount)
+	}
+	if len(service.failures) != 0 {
+		t.Errorf("expected all failures to be injected, %d left", len(service.failures))
+	}
+}
+
+func testFSRetriesExhausted(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	service := newBlobService(t, 10)
+	service.failures = []int{
+		http.StatusServiceUnavailable, http.StatusServiceUnavailable,
+		http.StatusServiceUnavailable, http.StatusServiceUnavailable,
+	}
+	subject := newCsvReader(service.url(), sasToken)
+
+	// act
+	rowsCount, err := read(t, subject)
+
+	// assert
+	if rowsCount != 0 {
+		t.Errorf("expected no rows, got %d", rowsCount)
+	}
+	if err == nil || !strings.Contains(err.Error(), "unexpected status 503 (ServerBusy)") {
+		t.Errorf("expected service unavailable error, got %v", err)
+	}
+}
+
+func testFSRetryDelayCapped(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	service := newBlobService(t, 10)
+	service.failures = []int{http.StatusServiceUnavailable}
+	service.retryAfter = "3600"
+	subject := newCsvReader(service.url(), sasToken)
+	subject.FS.(*azblob.FS).MaxRetryDelay = time.Millisecond
+
+	// act
+	rowsCount, err := read(t, subject)
+
+	// assert
+	assertNil(t, err)
+	if rowsCount != 10 {
+		t.Errorf("expected 10 rows, got %d", rowsCount)
+	}
+}
+
+func testFSRetryDelayCanceled(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	service := newBlobService(t, 10)
+	service.failures = []int{http.StatusServiceUnavailable}
+	service.retryAfter = "3600"
+	subject := newCsvReader(service.url(), sasToken)
+	subject.FS.(*azblob.FS).MaxRetryDelay = 0
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelCtx()
+	startedAt := time.Now()
+
+	// act
+	run := subject.Start(ctx)
+	for _, rowsChan := range run.RowsChans() {
+		for range rowsChan {
+		}
+	}
+	err := run.Err()
+
+	// assert
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context deadline exceeded error, got %v", err)
+	}
+	if elapsed := time.Since(startedAt); elapsed > 5*time.Second {
+		t.Errorf("expected the retry delay to be canceled, waited %v", elapsed)
+	}
+}
+
+func testFSInvalidSASToken(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	service := newBlobService(t, 10)
+	subject := newCsvReader(service.url(), "?sv=2021-08-06&sig=wrong")
+
+	// act
+	_, err := read(t, subject)
+
+	// assert
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("expected permission error, got %v", err)
+	}
+}
+
+func testFSNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	service := newBlobService(t, 10)
+	fsys := azblob.New(service.url(), sasToken)
+
+	// act
+	_, err := fsys.Stat("drops/missing.csv")
+
+	// assert
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not exist error, got %v", err)
+	}
+}
+
+func testFSBlobChanged(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	service := newBlobService(t, 10)
+	fsys := azblob.New(service.url(), sasToken)
+	f, err := fsys.Open(blobName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	service.mu.Lock()
+	service.etag = `"0x2"`
+	service.mu.Unlock()
+
+	// act
+	_, err = io.ReadAll(f)
+
+	// assert
+	if !errors.Is(err, azblob.ErrBlobChanged) {
+		t.Errorf("expected blob changed error, got %v", err)
+	}
+}
+
+const blobName = "drops/2024/daily transactions.csv"
+
+// blobService is a minimal Azure Blob Storage service, serving one blob.
+type blobService struct {
+	server  *httptest.Server
+	content string
+	mu      sync.Mutex
+	etag    string
+	gets    int
+	// failures holds the statuses the next requests fail with, 0 meaning a broken connection.
+	failures []int
+	// retryAfter is the Retry-After header of failed requests, "0" if empty.
+	retryAfter string
+}
+
+// newBlobService starts a blob service serving a CSV blob having given rows count.
+func newBlobService(t *testing.T, rowsCount int) *blobService {
+	t.Helper()
+	var content strings.Builder
+	for i := 1; i <= rowsCount; i++ {
+		fmt.Fprintf(&content, "%d,\"name %d\",%d\n", i, i, i*10)
+	}
+	service := &blobService{content: content.String(), etag: `"0x1"`}
+	service.server = httptest.NewServer(http.HandlerFunc(service.serve))
+	t.Cleanup(service.server.Close)
+
+	return service
+}
+
+func (bs *blobService) url() string {
+	return bs.server.URL + "/"
+}
+
+func (bs *blobService) rangedGets() int {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	return bs.gets
+}
+
+func (bs *blobService) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("x-ms-version") == "" || r.URL.Query().Get("sig") != "secret" {
+		w.Header().Set("x-ms-error-code", "AuthenticationFailed")
+		w.WriteHeader(http.StatusForbidden)
+
+		return
+	}
+	if r.URL.Path != "/"+blobName {
+		w.Header().Set("x-ms-error-code", "BlobNotFound")
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	bs.mu.Lock()
+	etag := bs.etag
+	failure := -1
+	if len(bs.failures) > 0 {
+		failure, bs.failures = bs.failures[0], bs.failures[1:]
+	}
+	retryAfter := bs.retryAfter
+	bs.mu.Unlock()
+	if failure > 0 {
+		if retryAfter == "" {
+			retryAfter = "0"
+		}
+		w.Header().Set("x-ms-error-code", "ServerBusy")
+		w.Header().Set("Retry-After", retryAfter)
+		w.WriteHeader(failure)
+
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat))
+	if r.Method == http.MethodHead {
+		w.Header().Set("Content-Length", strconv.Itoa(len(bs.content)))
+
+		return
+	}
+	if r.Header.Get("If-Match") != etag {
+		w.WriteHeader(http.StatusPreconditionFailed)
+
+		return
+	}
+	var start, end int
+	if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+	bs.mu.Lock()
+	bs.gets++
+	bs.mu.Unlock()
+	block := bs.content[start : end+1]
+	w.Header().Set("Content-Length", strconv.Itoa(len(block)))
+	w.WriteHeader(http.StatusPartialContent)
+	if failure == 0 { // connection breaks in the middle of the block.
+		_, _ = io.WriteString(w, block[:len(block)/2])
+
+		return
+	}
+	_, _ = io.WriteString(w, block)
+}
+
+func newCsvReader(accountURL, sasToken string) *bigcsvreader.CsvReader {
+	fsys := azblob.New(accountURL, sasToken)
+	fsys.BlockSize = 1024
+	fsys.RetryDelay = time.Millisecond
+	cr := bigcsvreader.New()
+	cr.FS = fsys
+	cr.SetFilePath(blobName)
+	cr.ColumnsCount = 3
+	cr.MaxGoroutinesNo = 4
+
+	return cr
+}
+
+func read(t *testing.T, cr *bigcsvreader.CsvReader) (int, error) {
+	t.Helper()
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+	run := cr.Start(ctx)
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		rowsCount int
+	)
+	for _, rowsChan := range run.RowsChans() {
+		wg.Add(1)
+		go func(rowsChan bigcsvreader.RowsChan) {
+			defer wg.Done()
+			for range rowsChan {
+				mu.Lock()
+				rowsCount++
+				mu.Unlock()
+			}
+		}(rowsChan)
+	}
+	wg.Wait()
+
+	return rowsCount, run.Err()
+}
+
+func assertNil(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"strconv"
+	"strings"
+)
+
+// BoolDialect maps the tokens a producer uses for boolean values, like "Y"/"N" or "1"/"0".
+type BoolDialect struct {
+	// True holds the tokens mapped to true.
+	True []string
+	// False holds the tokens mapped to false.
+	False []string
+	// CaseSensitive is a flag indicating tokens should match exactly.
+	// By default, tokens are matched case-insensitively.
+	CaseSensitive bool
+}
+
+// Predefined bool dialects.
+var (
+	// BoolDialectDefault accepts the tokens of all other predefined dialects.
+	BoolDialectDefault = BoolDialect{
+		True:  []string{"true", "t", "yes", "y", "1"},
+		False: []string{"false", "f", "no", "n", "0"},
+	}
+	// BoolDialectYN accepts "Y"/"N".
+	BoolDialectYN = BoolDialect{True: []string{"Y"}, False: []string{"N"}}
+	// BoolDialect10 accepts "1"/"0".
+	BoolDialect10 = BoolDialect{True: []string{"1"}, False: []string{"0"}}
+	// BoolDialectYesNo accepts "yes"/"no".
+	BoolDialectYesNo = BoolDialect{True: []string{"yes"}, False: []string{"no"}}
+	// BoolDialectTF accepts "T"/"F".
+	BoolDialectTF = BoolDialect{True: []string{"T"}, False: []string{"F"}}
+)
+
+// boolDialects holds the predefined bool dialects by name.
+var boolDialects = map[string]BoolDialect{
+	"default": BoolDialectDefault,
+	"yn":      BoolDialectYN,
+	"10":      BoolDialect10,
+	"yesno":   BoolDialectYesNo,
+	"tf":      BoolDialectTF,
+}
+
+// LookupBoolDialect returns a predefined bool dialect by its name,
+// which is one of "default", "yn", "10", "yesno", "tf".
+func LookupBoolDialect(name string) (BoolDialect, bool) {
+	dialect, ok := boolDialects[strings.ToLower(name)]
+
+	return dialect, ok
+}
+
+// InvalidBoolError is the error returned when a value is not one of the tokens accepted by a [BoolDialect].
+type InvalidBoolError struct {
+	// Value is the unknown token.
+	Value string
+	// Accepted holds the accepted tokens.
+	Accepted []string
+}
+
+// Error returns the error's message.
+func (e *InvalidBoolError) Error() string {
+	return "invalid bool token " + strconv.Quote(e.Value) +
+		", expected one of [" + strings.Join(e.Accepted, ", ") + "]"
+}
+
+// Parse returns the boolean value of given token.
+// It returns an [*InvalidBoolError] if the token is unknown to the dialect.
+func (d BoolDialect) Parse(value string) (bool, error) {
+	token := strings.TrimSpace(value)
+	if d.matches(d.True, token) {
+		return true, nil
+	}
+	if d.matches(d.False, token) {
+		return false, nil
+	}
+
+	accepted := make([]string, 0, len(d.True)+len(d.False))
+	accepted = append(accepted, d.True...)
+	accepted = append(accepted, d.False...)
+
+	return false, &InvalidBoolError{Value: value, Accepted: accepted}
+}
+
+// matches checks if given token is one of the tokens.
+func (d BoolDialect) matches(tokens []string, token string) bool {
+	for _, t := range tokens {
+		if t == token || (!d.CaseSensitive && strings.EqualFold(t, token)) {
+			return true
+		}
+	}
+
+	return false
+}
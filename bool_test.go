@@ -0,0 +1,75 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestBoolDialect_Parse(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name           string
+		dialect        bigcsvreader.BoolDialect
+		input          string
+		expectedResult bool
+		expectedErr    bool
+	}{
+		{name: "default true", dialect: bigcsvreader.BoolDialectDefault, input: "Yes", expectedResult: true},
+		{name: "default false", dialect: bigcsvreader.BoolDialectDefault, input: "0", expectedResult: false},
+		{name: "yn true", dialect: bigcsvreader.BoolDialectYN, input: "y", expectedResult: true},
+		{name: "yn false", dialect: bigcsvreader.BoolDialectYN, input: " N ", expectedResult: false},
+		{name: "yn unknown", dialect: bigcsvreader.BoolDialectYN, input: "yes", expectedErr: true},
+		{name: "10 true", dialect: bigcsvreader.BoolDialect10, input: "1", expectedResult: true},
+		{name: "tf false", dialect: bigcsvreader.BoolDialectTF, input: "f", expectedResult: false},
+		{
+			name:        "case sensitive",
+			dialect:     bigcsvreader.BoolDialect{True: []string{"ON"}, False: []string{"OFF"}, CaseSensitive: true},
+			input:       "on",
+			expectedErr: true,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			result, err := test.dialect.Parse(test.input)
+
+			// assert
+			assertEqual(t, test.expectedResult, result)
+			if test.expectedErr {
+				var boolErr *bigcsvreader.InvalidBoolError
+				if assertTrue(t, errors.As(err, &boolErr)) {
+					assertEqual(t, test.input, boolErr.Value)
+					assertEqual(t, len(test.dialect.True)+len(test.dialect.False), len(boolErr.Accepted))
+				}
+			} else {
+				assertNil(t, err)
+			}
+		})
+	}
+}
+
+func TestLookupBoolDialect(t *testing.T) {
+	t.Parallel()
+
+	// act
+	dialect, ok1 := bigcsvreader.LookupBoolDialect("YN")
+	_, ok2 := bigcsvreader.LookupBoolDialect("unknown")
+
+	// assert
+	assertTrue(t, ok1)
+	assertEqual(t, bigcsvreader.BoolDialectYN, dialect)
+	assertTrue(t, !ok2)
+	assertEqual(t, `invalid bool token "x", expected one of [Y, N]`, (&bigcsvreader.InvalidBoolError{Value: "x", Accepted: []string{"Y", "N"}}).Error())
+}
@@ -0,0 +1,124 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+const (
+	// defaultBufferSize is the default [CsvReader.BufferSize].
+	defaultBufferSize = 4096
+	// minBufferSize is the minimum [CsvReader.BufferSize], the one of [bufio.Reader].
+	minBufferSize = 16
+	// bufferSampleSize is the size of the data head sampled for its longest line, see [CsvReader.fitBufferSize].
+	bufferSampleSize = 64 * 1024
+)
+
+// normalizeSizes replaces the degenerate [CsvReader.MaxGoroutinesNo] and [CsvReader.BufferSize] values
+// with working ones, logging a warning for each (as an error, [CsvReader.Logger] having no warning level).
+func (cr *CsvReader) normalizeSizes() {
+	if cr.MaxGoroutinesNo < 1 {
+		cr.Logger.Error(
+			"msg", "invalid max goroutines number, reading with 1 goroutine",
+			"file", cr.fileBaseName, "maxThreads", cr.MaxGoroutinesNo,
+		)
+		cr.MaxGoroutinesNo = 1
+	}
+	switch {
+	case cr.BufferSize <= 0:
+		cr.Logger.Error(
+			"msg", "invalid buffer size, using the default one",
+			"file", cr.fileBaseName, "bufferSize", cr.BufferSize, "newBufferSize", defaultBufferSize,
+		)
+		cr.BufferSize = defaultBufferSize
+	case cr.BufferSize < minBufferSize:
+		cr.Logger.Error(
+			"msg", "too small buffer size, using the minimum one",
+			"file", cr.fileBaseName, "bufferSize", cr.BufferSize, "newBufferSize", minBufferSize,
+		)
+		cr.BufferSize = minBufferSize
+	}
+}
+
+// fitBufferSize samples the data head (its first [bufferSampleSize] bytes) for its longest line,
+// the minimum chunk size, see [CsvReader.computeChunks], and doubles [CsvReader.BufferSize] until
+// the line fits into it, logging a warning if it was grown, so plausible records do not stop goroutines
+// with an error wrapping [ErrBufferFull], leaving their chunk partially read.
+// The buffer is not grown with [CsvReader.AutoGrowBuffer] enabled.
+// It does nothing if the data can't be sampled (goroutines report the error reading it anyway).
+func (cr *CsvReader) fitBufferSize(pin *pinnedFile, dataStart, dataEnd int) {
+	if dataEnd <= dataStart {
+		return
+	}
+	var f internal.File
+	if pin != nil {
+		f = pin.f
+	} else {
+		var err error
+		if f, err = cr.FS.Open(cr.filePath); err != nil {
+			return
+		}
+		defer f.Close()
+	}
+	sample := make([]byte, min(bufferSampleSize, dataEnd-dataStart))
+	n, err := f.ReadAt(sample, int64(dataStart))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return
+	}
+	longest := longestLine(sample[:n])
+	cr.longestLine = longest
+	if cr.AutoGrowBuffer || longest <= cr.BufferSize {
+		return
+	}
+	size := cr.BufferSize
+	for size < longest {
+		size *= 2
+	}
+	cr.Logger.Error(
+		"msg", "buffer size is smaller than the data's longest line, growing it",
+		"file", cr.fileBaseName, "bufferSize", cr.BufferSize, "longestLine", longest, "newBufferSize", size,
+	)
+	cr.BufferSize = size
+}
+
+// longestLine returns the size of the longest line (end line delimiter included) of given data.
+func longestLine(data []byte) int {
+	var longest int
+	for len(data) > 0 {
+		size := bytes.IndexByte(data, '\n') + 1
+		if size == 0 {
+			size = len(data)
+		}
+		longest = max(longest, size)
+		data = data[size:]
+	}
+
+	return longest
+}
+
+// computeChunks splits data of given size into chunks, one for each goroutine, of at least
+// minBytesToReadByAGoroutine bytes and not smaller than the longest line sampled by [CsvReader.fitBufferSize],
+// so goroutines do not get chunks starting and ending within the same line, logging a warning
+// if there are fewer goroutines because of it.
+func (cr *CsvReader) computeChunks(dataSize int) [][2]int {
+	threadsInfo := internal.ComputeGoroutineOffsets(dataSize, cr.MaxGoroutinesNo, max(minBytesToReadByAGoroutine, cr.longestLine))
+	if cr.longestLine > minBytesToReadByAGoroutine && len(threadsInfo) < cr.MaxGoroutinesNo {
+		if unclamped := internal.ComputeGoroutineOffsets(dataSize, cr.MaxGoroutinesNo, minBytesToReadByAGoroutine); len(unclamped) > len(threadsInfo) {
+			cr.Logger.Error(
+				"msg", "chunks would be smaller than the data's longest line, reading with fewer goroutines",
+				"file", cr.fileBaseName, "longestLine", cr.longestLine,
+				"threads", len(unclamped), "newThreads", len(threadsInfo),
+			)
+		}
+	}
+
+	return threadsInfo
+}
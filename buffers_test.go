@@ -0,0 +1,110 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_buffersAdjustment(t *testing.T) {
+	t.Parallel()
+
+	t.Run("degenerate sizes are replaced", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		logger := &recordingLogger{}
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_without_header.csv")
+		subject.ColumnsCount = 3
+		subject.MaxGoroutinesNo = 0
+		subject.BufferSize = -1
+		subject.Logger = logger
+
+		// act
+		rowsChans, errsChan := subject.Read(context.Background())
+		records, err := gatherRecords(rowsChans, errsChan)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, 5, len(records))
+		assertEqual(t, 1, len(rowsChans))
+		messages := loggedMessages(logger)
+		assertTrue(t, messages["invalid max goroutines number, reading with 1 goroutine"])
+		assertTrue(t, messages["invalid buffer size, using the default one"])
+	})
+
+	t.Run("long lines reduce goroutines", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 4
+		var content strings.Builder
+		for i := 1; i <= rowsCount; i++ {
+			content.WriteString(strconv.Itoa(i) + "," + strings.Repeat("x", 10000) + "\n")
+		}
+		logger := &recordingLogger{}
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, t.TempDir(), "long_lines.csv", content.String()))
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 8
+		subject.Logger = logger
+
+		// act
+		rowsChans, errsChan := subject.Read(context.Background())
+		records, err := gatherRecords(rowsChans, errsChan)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, rowsCount, len(records))
+		assertTrue(t, len(rowsChans) <= rowsCount)
+		messages := loggedMessages(logger)
+		assertTrue(t, messages["buffer size is smaller than the data's longest line, growing it"])
+		assertTrue(t, messages["chunks would be smaller than the data's longest line, reading with fewer goroutines"])
+	})
+
+	t.Run("long line beyond the sampled data head", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var content strings.Builder
+		for i := 1; content.Len() < 128*1024; i++ {
+			content.WriteString(strconv.Itoa(i) + ",name " + strconv.Itoa(i) + "\n")
+		}
+		content.WriteString("0," + strings.Repeat("x", 200) + "\n")
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, t.TempDir(), "long_tail.csv", content.String()))
+		subject.ColumnsCount = 2
+		subject.BufferSize = 64
+
+		// act
+		rowsChans, errsChan := subject.Read(context.Background())
+		_, err := gatherRecords(rowsChans, errsChan)
+
+		// assert
+		assertTrue(t, errors.Is(err, bigcsvreader.ErrBufferFull))
+	})
+}
+
+// loggedMessages returns the "msg" values logged.
+func loggedMessages(logger *recordingLogger) map[string]bool {
+	messages := make(map[string]bool)
+	for _, keyValues := range logger.logs() {
+		for i := 0; i+1 < len(keyValues); i += 2 {
+			if keyValues[i] == "msg" {
+				messages[keyValues[i+1].(string)] = true
+			}
+		}
+	}
+
+	return messages
+}
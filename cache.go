@@ -0,0 +1,100 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Cache stores artifacts derived from a CSV file (see [CsvReader.Analyze]),
+// so repeated runs over the same immutable file can skip redundant full scans.
+// Keys are content addressed: they are built from the file's checksum and
+// a hash of the reader's configuration.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored for given key, if any.
+	Get(key string) (value []byte, ok bool)
+	// Set stores the value for given key.
+	Set(key string, value []byte) error
+}
+
+// DirCache is a [Cache] which stores each entry as a file in a directory.
+type DirCache struct {
+	dir string
+}
+
+// NewDirCache instantiates a new [DirCache] which stores entries in given directory.
+// The directory is created if it does not exist.
+func NewDirCache(dir string) (*DirCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &DirCache{dir: dir}, nil
+}
+
+// Get returns the value stored for given key, if any.
+func (c *DirCache) Get(key string) ([]byte, bool) {
+	value, err := os.ReadFile(filepath.Join(c.dir, key))
+	if err != nil {
+		return nil, false
+	}
+
+	return value, true
+}
+
+// Set stores the value for given key.
+// The value is written to a temporary file which is then renamed,
+// so a concurrent Get never sees a partially written entry.
+func (c *DirCache) Set(key string, value []byte) error {
+	f, err := os.CreateTemp(c.dir, key+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := f.Name()
+	_, err = f.Write(value)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpName, filepath.Join(c.dir, key))
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+	}
+
+	return err
+}
+
+// cacheKey returns the content addressed key for the CSV file:
+// the SHA-256 checksum of the file followed by a hash of the configuration
+// options which influence the derived artifacts.
+func (cr *CsvReader) cacheKey() (string, error) {
+	f, err := cr.FS.Open(cr.filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	fileHash := sha256.New()
+	if _, err := io.Copy(fileHash, f); err != nil {
+		return "", err
+	}
+
+	configHash := sha256.Sum256([]byte(fmt.Sprintf(
+		"v3|%q|%t|%d|%t|%q|%q|%q|%q|%d|%t|%t|%t",
+		cr.ColumnsDelimiter, cr.FileHasHeader, cr.ColumnsCount, cr.LazyQuotes,
+		cr.quoteChar(), cr.EscapeChar, cr.NullToken, cr.DefaultValues, cr.Encoding,
+		cr.TrimSpace, cr.MultilineQuotedFields, cr.CRLFInQuotedFields,
+	)))
+
+	return hex.EncodeToString(fileHash.Sum(nil)) + "-" + hex.EncodeToString(configHash[:8]), nil
+}
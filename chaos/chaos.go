@@ -0,0 +1,206 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+// Package chaos provides a fault-injecting file system for [bigcsvreader.CsvReader.FS],
+// so pipelines can be verified against short reads, transient read errors, and slow reads.
+//
+// Example:
+//
+//	cr := bigcsvreader.New()
+//	cr.FS = chaos.New(cr.FS,
+//		chaos.Fault{Kind: chaos.ShortRead, Offset: 1024, Times: 10},
+//		chaos.Fault{Kind: chaos.TransientError, Offset: 1 << 20},
+//	)
+package chaos
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+// ErrTransient is the default error injected by a [TransientError] fault.
+var ErrTransient = errors.New("chaos: transient read error")
+
+// Kind is the kind of fault to inject.
+type Kind int
+
+const (
+	// ShortRead makes a read covering the fault's offset return fewer bytes than requested:
+	// the bytes up to the offset, or only one byte if the read starts right at the offset.
+	ShortRead Kind = iota + 1
+	// TransientError makes a read covering the fault's offset return the bytes up to the offset,
+	// and the next read, starting at the offset, fail with the fault's error.
+	TransientError
+	// Delay makes a read covering the fault's offset sleep for the fault's delay before reading.
+	Delay
+)
+
+// Fault describes a fault to inject.
+type Fault struct {
+	// Kind is the kind of fault.
+	Kind Kind
+	// Offset is the byte offset the fault is injected at.
+	Offset int64
+	// Times is the number of times the fault is injected, by all files opened from the file system.
+	// Defaults to 1 if not positive.
+	Times int
+	// Delay is the sleeping period of a [Delay] fault.
+	Delay time.Duration
+	// Err is the error returned by a [TransientError] fault.
+	// Defaults to [ErrTransient].
+	Err error
+}
+
+// FS is a file system which injects faults into reads of files opened from an underlying file system.
+// It is safe for concurrent use.
+type FS struct {
+	base   internal.FS
+	faults []Fault
+	// fired holds the number of times each fault was injected.
+	fired []int
+	mu    sync.Mutex
+}
+
+// New instantiates a new fault-injecting file system wrapping given file system.
+// If base is nil, the operating system's file system is used.
+func New(base internal.FS, faults ...Fault) *FS {
+	if base == nil {
+		base = internal.OSFS{}
+	}
+	for i := range faults {
+		if faults[i].Times <= 0 {
+			faults[i].Times = 1
+		}
+		if faults[i].Kind == TransientError && faults[i].Err == nil {
+			faults[i].Err = ErrTransient
+		}
+	}
+
+	return &FS{
+		base:   base,
+		faults: faults,
+		fired:  make([]int, len(faults)),
+	}
+}
+
+// Open opens the named file for reading, from the underlying file system.
+func (fsys *FS) Open(name string) (internal.File, error) {
+	f, err := fsys.base.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &file{File: f, fsys: fsys}, nil
+}
+
+// Stat returns the named file's info, from the underlying file system.
+func (fsys *FS) Stat(name string) (os.FileInfo, error) {
+	return fsys.base.Stat(name)
+}
+
+// Fired returns the number of faults injected so far.
+func (fsys *FS) Fired() int {
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	var fired int
+	for _, times := range fsys.fired {
+		fired += times
+	}
+
+	return fired
+}
+
+// inject applies the first pending fault covered by a read of n bytes starting at given offset.
+// It returns the number of bytes to read, and the error to return after reading them.
+// Short reads are not injected into [io.ReaderAt] reads, as its contract forbids them.
+func (fsys *FS) inject(offset int64, n int, readAt bool) (int, error) {
+	if n == 0 {
+		return n, nil
+	}
+	end := offset + int64(n)
+
+	fsys.mu.Lock()
+	var (
+		fault Fault
+		found bool
+	)
+	for i := range fsys.faults {
+		f := fsys.faults[i]
+		if fsys.fired[i] >= f.Times || f.Offset < offset || f.Offset >= end {
+			continue
+		}
+		if readAt && f.Kind == ShortRead {
+			continue
+		}
+		if !readAt && f.Kind == TransientError && f.Offset > offset {
+			// stop the read right before the offset, the error is returned by the next read.
+			fault, found = Fault{Kind: ShortRead, Offset: f.Offset}, true
+
+			break
+		}
+		fsys.fired[i]++
+		fault, found = f, true
+
+		break
+	}
+	fsys.mu.Unlock()
+
+	if !found {
+		return n, nil
+	}
+	switch fault.Kind {
+	case ShortRead:
+		if fault.Offset > offset {
+			return int(fault.Offset - offset), nil
+		}
+
+		return 1, nil
+	case TransientError:
+		return int(fault.Offset - offset), fault.Err
+	case Delay:
+		time.Sleep(fault.Delay)
+	}
+
+	return n, nil
+}
+
+// file is a file whose reads are subject to its file system's faults.
+type file struct {
+	internal.File
+	fsys *FS
+}
+
+// Read reads up to len(p) bytes into p, injecting eventual faults.
+func (f *file) Read(p []byte) (int, error) {
+	offset, err := f.File.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	n, faultErr := f.fsys.inject(offset, len(p), false)
+	if faultErr != nil {
+		return 0, faultErr
+	}
+
+	return f.File.Read(p[:n])
+}
+
+// ReadAt reads up to len(p) bytes into p starting at given offset, injecting eventual faults.
+func (f *file) ReadAt(p []byte, offset int64) (int, error) {
+	n, faultErr := f.fsys.inject(offset, len(p), true)
+	if faultErr == nil {
+		return f.File.ReadAt(p[:n], offset)
+	}
+	n, err := f.File.ReadAt(p[:n], offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return n, err
+	}
+
+	return n, faultErr
+}
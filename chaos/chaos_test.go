@@ -0,0 +1,194 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package chaos_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+	"github.com/actforgood/bigcsvreader/chaos"
+)
+
+func TestFS(t *testing.T) {
+	t.Parallel()
+
+	t.Run("short reads do not alter rows", testFSShortReads)
+	t.Run("transient error is sent through errors channel", testFSTransientError)
+	t.Run("delayed reads do not alter rows", testFSDelay)
+	t.Run("read at stops right before transient error", testFSReadAtTransientError)
+}
+
+func testFSShortReads(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath, size := writeCsvFile(t, 1000)
+	faults := make([]chaos.Fault, 0, 50)
+	for offset := int64(0); offset < size; offset += size / 50 {
+		faults = append(faults, chaos.Fault{Kind: chaos.ShortRead, Offset: offset, Times: 3})
+	}
+	fsys := chaos.New(nil, faults...)
+	subject := newCsvReader(filePath, fsys)
+
+	// act
+	rowsCount, err := read(t, subject)
+
+	// assert
+	assertNil(t, err)
+	if rowsCount != 1000 {
+		t.Errorf("expected 1000 rows, got %d", rowsCount)
+	}
+	if fsys.Fired() == 0 {
+		t.Error("expected faults to be injected")
+	}
+}
+
+func testFSTransientError(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath, size := writeCsvFile(t, 1000)
+	// the first fault is injected in the data head the reader samples for its longest line.
+	fsys := chaos.New(nil, chaos.Fault{Kind: chaos.TransientError, Offset: size / 2, Times: 2})
+	subject := newCsvReader(filePath, fsys)
+
+	// act
+	rowsCount, err := read(t, subject)
+
+	// assert
+	if !errors.Is(err, chaos.ErrTransient) {
+		t.Errorf("expected error to be ErrTransient, got %v", err)
+	}
+	if rowsCount >= 1000 {
+		t.Errorf("expected less than 1000 rows, got %d", rowsCount)
+	}
+	if fsys.Fired() != 2 {
+		t.Errorf("expected 2 faults to be injected, got %d", fsys.Fired())
+	}
+}
+
+func testFSDelay(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath, size := writeCsvFile(t, 1000)
+	fsys := chaos.New(nil, chaos.Fault{Kind: chaos.Delay, Offset: size / 3, Delay: 50 * time.Millisecond})
+	subject := newCsvReader(filePath, fsys)
+	startedAt := time.Now()
+
+	// act
+	rowsCount, err := read(t, subject)
+
+	// assert
+	assertNil(t, err)
+	if rowsCount != 1000 {
+		t.Errorf("expected 1000 rows, got %d", rowsCount)
+	}
+	if time.Since(startedAt) < 50*time.Millisecond {
+		t.Error("expected read to be delayed")
+	}
+}
+
+func testFSReadAtTransientError(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath, _ := writeCsvFile(t, 10)
+	fsys := chaos.New(nil, chaos.Fault{Kind: chaos.TransientError, Offset: 5})
+	f, err := fsys.Open(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	buf := make([]byte, 10)
+
+	// act
+	n, err := f.ReadAt(buf, 2)
+	n2, err2 := f.ReadAt(buf, 2)
+
+	// assert
+	if n != 3 || !errors.Is(err, chaos.ErrTransient) {
+		t.Errorf("expected 3 bytes and ErrTransient, got %d, %v", n, err)
+	}
+	if n2 != 10 || (err2 != nil && !errors.Is(err2, io.EOF)) {
+		t.Errorf("expected 10 bytes and no error, got %d, %v", n2, err2)
+	}
+}
+
+// writeCsvFile writes a CSV file with given number of rows, and returns its path and size.
+func writeCsvFile(t *testing.T, rowsCount int) (string, int64) {
+	t.Helper()
+	var sb strings.Builder
+	for i := 1; i <= rowsCount; i++ {
+		sb.WriteString(strconv.Itoa(i) + ",name " + strconv.Itoa(i) + ",description of row " + strconv.Itoa(i) + "\n")
+	}
+	filePath := filepath.Join(t.TempDir(), "chaos.csv")
+	if err := os.WriteFile(filePath, []byte(sb.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return filePath, int64(sb.Len())
+}
+
+func newCsvReader(filePath string, fsys *chaos.FS) *bigcsvreader.CsvReader {
+	cr := bigcsvreader.New()
+	cr.SetFilePath(filePath)
+	cr.ColumnsCount = 3
+	cr.MaxGoroutinesNo = 4
+	cr.BufferSize = 512
+	cr.FS = fsys
+
+	return cr
+}
+
+// read consumes all the rows and returns their number, and the run's errors.
+func read(t *testing.T, cr *bigcsvreader.CsvReader) (int, error) {
+	t.Helper()
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	run := cr.Start(ctx)
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		count int
+	)
+	for _, rowsChan := range run.RowsChans() {
+		wg.Add(1)
+		go func(rowsChan bigcsvreader.RowsChan) {
+			defer wg.Done()
+			for range rowsChan {
+				mu.Lock()
+				count++
+				mu.Unlock()
+			}
+		}(rowsChan)
+	}
+	go func() {
+		for range run.ErrsChan() {
+			// errors are collected by Run.Err.
+		}
+	}()
+	wg.Wait()
+
+	return count, run.Err()
+}
+
+func assertNil(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+}
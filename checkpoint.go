@@ -0,0 +1,219 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CheckpointVersion is the version of the checkpoint file format written by [SaveCheckpoint].
+//
+// The format is a JSON object:
+//
+//	{
+//	  "version": 1,
+//	  "file": "data.csv",
+//	  "fileSize": 1048576,
+//	  "savedAt": "2024-01-02T03:04:05Z",
+//	  "chunks": [
+//	    {"offsetStart": 0, "offsetEnd": 524287, "ackedOffset": 1200, "ackedRows": 40},
+//	    {"offsetStart": 524288, "offsetEnd": 1048575, "ackedOffset": -1, "ackedRows": 0}
+//	  ]
+//	}
+//
+// Fields may be added within a version (readers disregard unknown fields); a version is bumped only
+// by changes older readers would misinterpret, [LoadCheckpoint] rejecting versions it does not know.
+const CheckpointVersion = 1
+
+// ErrUnsupportedCheckpointVersion is the error returned by [LoadCheckpoint] for a checkpoint
+// written in a format version it does not know.
+var ErrUnsupportedCheckpointVersion = errors.New("unsupported checkpoint version")
+
+// ErrCheckpointMismatch is the error sent through ErrsChan when [CsvReader.Checkpoint] does not match the file.
+var ErrCheckpointMismatch = errors.New("checkpoint does not match the file")
+
+// errUnsupportedWithCheckpoint is the error returned when resuming from a checkpoint
+// is combined with an option it does not support.
+var errUnsupportedWithCheckpoint = errors.New(
+	"resuming from a checkpoint is not supported when reading backwards, a data range, UTF-16 or plain gzip data",
+)
+
+// Checkpoint records, for each chunk of a file, up to which row the rows read were acknowledged
+// (processed by the application), so reading can resume from there after a restart, see [CsvReader.Checkpoint].
+// A run's checkpoint is returned by [Run.Checkpoint] (or is the empty one set into [CsvReader.Checkpoint]
+// before the first run), rows are acknowledged by [Checkpoint.Ack], and it is persisted by [SaveCheckpoint]
+// and loaded back by [LoadCheckpoint].
+// It is safe for concurrent use.
+type Checkpoint struct {
+	// Version is the format version, see [CheckpointVersion].
+	Version int `json:"version"`
+	// File is the CSV file path.
+	File string `json:"file"`
+	// FileSize is the size of the (decompressed) data, a checkpoint can't be resumed from for a file of another size.
+	FileSize int64 `json:"fileSize"`
+	// SavedAt is the time the checkpoint was saved.
+	SavedAt time.Time `json:"savedAt"`
+	// Chunks holds the chunks, one for each goroutine of the run, in file order.
+	Chunks []CheckpointChunk `json:"chunks"`
+
+	mu sync.Mutex
+}
+
+// CheckpointChunk records the progress of a chunk of file.
+type CheckpointChunk struct {
+	// OffsetStart is the offset the chunk starts at, as allocated to its goroutine.
+	OffsetStart int `json:"offsetStart"`
+	// OffsetEnd is the offset the chunk ends at, as allocated to its goroutine.
+	OffsetEnd int `json:"offsetEnd"`
+	// AckedOffset is the offset of the chunk's last acknowledged row, or -1 if none was acknowledged.
+	AckedOffset int `json:"ackedOffset"`
+	// AckedRows is the number of rows of the chunk acknowledged.
+	AckedRows int64 `json:"ackedRows"`
+}
+
+// init initializes the checkpoint for the file having given size, with a chunk for each goroutine.
+func (cp *Checkpoint) init(file string, fileSize int, threadsInfo [][2]int) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.Version = CheckpointVersion
+	cp.File = file
+	cp.FileSize = int64(fileSize)
+	cp.Chunks = make([]CheckpointChunk, len(threadsInfo))
+	for i, offsets := range threadsInfo {
+		cp.Chunks[i] = CheckpointChunk{OffsetStart: offsets[0], OffsetEnd: offsets[1], AckedOffset: -1}
+	}
+}
+
+// resumes returns whether the checkpoint has chunks to resume.
+func (cp *Checkpoint) resumes() bool {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	return len(cp.Chunks) > 0
+}
+
+// Ack acknowledges given rows, read by [CsvReader.ReadWithMeta] from the run the checkpoint belongs to.
+// Rows of a chunk must be acknowledged in the order they were read: resuming skips a chunk's rows
+// up to its last acknowledged one. The header row is disregarded.
+func (cp *Checkpoint) Ack(rows ...Row) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	for _, row := range rows {
+		if row.Header || row.ChunkIndex < 0 || row.ChunkIndex >= len(cp.Chunks) {
+			continue
+		}
+		chunk := &cp.Chunks[row.ChunkIndex]
+		chunk.AckedRows++
+		if row.Offset > chunk.AckedOffset {
+			chunk.AckedOffset = row.Offset
+		}
+	}
+}
+
+// AckedRows returns the number of rows acknowledged, by all chunks.
+func (cp *Checkpoint) AckedRows() int64 {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	var count int64
+	for _, chunk := range cp.Chunks {
+		count += chunk.AckedRows
+	}
+
+	return count
+}
+
+// resumeOffsets returns the [start, end] offsets the goroutines resuming the chunks read,
+// or an error if the checkpoint does not match the file.
+// A chunk having acknowledged rows is resumed from the byte following its last acknowledged row's start,
+// so that row (the line the offset falls into) is skipped, like the partial line a goroutine starts in.
+func (cp *Checkpoint) resumeOffsets(fileSize, dataStart, dataEnd int) ([][2]int, error) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if cp.FileSize != int64(fileSize) {
+		return nil, fmt.Errorf("%w: file size is %d, checkpoint's is %d", ErrCheckpointMismatch, fileSize, cp.FileSize)
+	}
+	threadsInfo := make([][2]int, len(cp.Chunks))
+	for i, chunk := range cp.Chunks {
+		if chunk.OffsetStart < dataStart || chunk.OffsetEnd < chunk.OffsetStart || chunk.OffsetEnd >= dataEnd ||
+			(i > 0 && chunk.OffsetStart != cp.Chunks[i-1].OffsetEnd+1) {
+			return nil, fmt.Errorf("%w: invalid chunk #%d [%d, %d]", ErrCheckpointMismatch, i+1, chunk.OffsetStart, chunk.OffsetEnd)
+		}
+		threadsInfo[i] = [2]int{chunk.OffsetStart, chunk.OffsetEnd}
+		if chunk.AckedOffset >= 0 {
+			threadsInfo[i][0] = chunk.AckedOffset + 1
+		}
+	}
+
+	return threadsInfo, nil
+}
+
+// SaveCheckpoint writes the checkpoint, JSON encoded (see [CheckpointVersion]), into the file at given path.
+// The checkpoint is written to a temporary file which is then renamed,
+// so a partially written checkpoint is never observed, even if the application crashes meanwhile.
+func SaveCheckpoint(path string, cp *Checkpoint) error {
+	cp.mu.Lock()
+	cp.Version = CheckpointVersion
+	cp.SavedAt = time.Now().UTC()
+	content, err := json.MarshalIndent(cp, "", "  ")
+	cp.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("bigcsvreader: could not encode checkpoint (%w)", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("bigcsvreader: could not write checkpoint (%w)", err)
+	}
+	tmpName := f.Name()
+	_, err = f.Write(content)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpName, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+
+		return fmt.Errorf("bigcsvreader: could not write checkpoint (%w)", err)
+	}
+
+	return nil
+}
+
+// LoadCheckpoint reads the checkpoint written by [SaveCheckpoint] from the file at given path,
+// to be resumed from by [CsvReader.Checkpoint].
+// An error wrapping [ErrUnsupportedCheckpointVersion] is returned for a format version it does not know.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not read checkpoint (%w)", err)
+	}
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(content, cp); err != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not decode checkpoint (%w)", err)
+	}
+	if cp.Version < 1 || cp.Version > CheckpointVersion {
+		return nil, fmt.Errorf("bigcsvreader: could not load checkpoint (%w: %d)", ErrUnsupportedCheckpointVersion, cp.Version)
+	}
+
+	return cp, nil
+}
+
+// Checkpoint returns the checkpoint the run's rows are acknowledged into: [CsvReader.Checkpoint], if set,
+// or a new one, with a chunk for each goroutine (not meaningful for plain gzip data, which has no chunks).
+func (rn *Run) Checkpoint() *Checkpoint {
+	return rn.checkpoint
+}
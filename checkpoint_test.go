@@ -0,0 +1,156 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	const rowsCount = 20000
+	var content strings.Builder
+	content.WriteString("id,value\n")
+	for i := 1; i <= rowsCount; i++ {
+		content.WriteString(strconv.Itoa(i) + ",value " + strconv.Itoa(i) + "\n")
+	}
+	dir := t.TempDir()
+	filePath := writeTmpFile(t, dir, "data.csv", content.String())
+	newReader := func() *bigcsvreader.CsvReader {
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 4
+
+		return subject
+	}
+
+	t.Run("reading resumes after the acknowledged rows", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		checkpointPath := filepath.Join(t.TempDir(), "data.checkpoint.json")
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+		// acknowledges, in batches of 500 rows, the first 1500 rows of each chunk, and all the full batches of the first one.
+		checkpoint := &bigcsvreader.Checkpoint{}
+		reader := newReader()
+		reader.Checkpoint = checkpoint
+		metaChans, errsChan := reader.ReadWithMeta(ctx)
+		var (
+			wg    sync.WaitGroup
+			mu    sync.Mutex
+			acked = make(map[string]bool, rowsCount)
+		)
+		for chunk, metaChan := range metaChans {
+			wg.Add(1)
+			go func(chunk int, metaChan bigcsvreader.MetaRowsChan) {
+				defer wg.Done()
+				var batch []bigcsvreader.Row
+				for row := range metaChan {
+					if chunk > 0 && len(batch) == 1500 {
+						continue
+					}
+					batch = append(batch, row)
+					if len(batch)%500 == 0 {
+						checkpoint.Ack(batch[len(batch)-500:]...)
+					}
+				}
+				mu.Lock()
+				for _, row := range batch[:len(batch)/500*500] {
+					acked[row.Fields[0]] = true
+				}
+				mu.Unlock()
+			}(chunk, metaChan)
+		}
+		err := bigcsvreader.CollectErrors(errsChan)
+		wg.Wait()
+		if !assertNil(t, err) {
+			return
+		}
+		if !assertNil(t, bigcsvreader.SaveCheckpoint(checkpointPath, checkpoint)) {
+			return
+		}
+		checkpoint, err = bigcsvreader.LoadCheckpoint(checkpointPath)
+		if !assertNil(t, err) {
+			return
+		}
+		subject := newReader()
+		subject.MaxGoroutinesNo = 1 // disregarded, the checkpoint's chunks are resumed.
+		subject.Checkpoint = checkpoint
+
+		// act
+		resumedRun := subject.Start(ctx)
+		rows := gatherRowsInOrder(resumedRun.RowsChans())
+		err = resumedRun.Err()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, 4, len(checkpoint.Chunks))
+		assertEqual(t, int64(len(acked)), checkpoint.AckedRows())
+		assertEqual(t, rowsCount, len(rows)+len(acked))
+		resumed := make(map[string]bool, len(rows))
+		for _, row := range rows {
+			assertTrue(t, !acked[row[0]])
+			resumed[row[0]] = true
+		}
+		assertEqual(t, len(rows), len(resumed))
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		checkpointPath := writeTmpFile(t, t.TempDir(), "data.checkpoint.json", `{"version":2,"chunks":[]}`)
+
+		// act
+		checkpoint, err := bigcsvreader.LoadCheckpoint(checkpointPath)
+
+		// assert
+		assertTrue(t, checkpoint == nil)
+		assertTrue(t, errors.Is(err, bigcsvreader.ErrUnsupportedCheckpointVersion))
+	})
+
+	t.Run("checkpoint of another file", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		checkpointPath := filepath.Join(t.TempDir(), "data.checkpoint.json")
+		run := newReader().Start(context.Background())
+		drainRows(run.RowsChans())
+		assertNil(t, run.Err())
+		assertNil(t, bigcsvreader.SaveCheckpoint(checkpointPath, run.Checkpoint()))
+		checkpoint, err := bigcsvreader.LoadCheckpoint(checkpointPath)
+		if !assertNil(t, err) {
+			return
+		}
+		subject := newReader()
+		subject.SetFilePath(writeTmpFile(t, t.TempDir(), "other.csv", "id,value\n1,value 1\n"))
+		subject.Checkpoint = checkpoint
+
+		// act
+		rowsChans, errsChan := subject.Read(context.Background())
+		err = bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertEqual(t, 0, len(rowsChans))
+		assertTrue(t, errors.Is(err, bigcsvreader.ErrCheckpointMismatch))
+		_, errStat := os.Stat(checkpointPath)
+		assertNil(t, errStat)
+	})
+}
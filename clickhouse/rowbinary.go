@@ -0,0 +1,309 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+// Package clickhouse encodes rows read by a [bigcsvreader.CsvReader] in ClickHouse RowBinary format,
+// ready to be bulk inserted (INSERT INTO table FORMAT RowBinary).
+package clickhouse
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02 15:04:05"
+	// defaultBlockSize is the default number of rows in a block.
+	defaultBlockSize = 10000
+	// chanBufferSize is the buffer size of blocks channels and errors channel.
+	chanBufferSize = 16
+)
+
+// kind is the kind of a ClickHouse type.
+type kind int
+
+const (
+	kindString kind = iota
+	kindFixedString
+	kindInt8
+	kindInt16
+	kindInt32
+	kindInt64
+	kindUInt8
+	kindUInt16
+	kindUInt32
+	kindUInt64
+	kindFloat32
+	kindFloat64
+	kindBool
+	kindDate
+	kindDateTime
+)
+
+var kinds = map[string]kind{
+	"String":   kindString,
+	"Int8":     kindInt8,
+	"Int16":    kindInt16,
+	"Int32":    kindInt32,
+	"Int64":    kindInt64,
+	"UInt8":    kindUInt8,
+	"UInt16":   kindUInt16,
+	"UInt32":   kindUInt32,
+	"UInt64":   kindUInt64,
+	"Float32":  kindFloat32,
+	"Float64":  kindFloat64,
+	"Bool":     kindBool,
+	"Date":     kindDate,
+	"DateTime": kindDateTime,
+}
+
+// columnType is a parsed ClickHouse column type.
+type columnType struct {
+	kind     kind
+	size     int // FixedString size.
+	nullable bool
+}
+
+// Encoder encodes rows in RowBinary format, according to the columns' ClickHouse types.
+// Empty values of Nullable columns are encoded as NULL.
+type Encoder struct {
+	types []columnType
+}
+
+// NewEncoder instantiates a new [Encoder] for given ClickHouse columns types, in the CSV columns order.
+// Supported types are String, FixedString(N), Int8-64, UInt8-64, Float32, Float64, Bool, Date, DateTime
+// (values in UTC, formatted as "2006-01-02 15:04:05"), and Nullable of those.
+func NewEncoder(types []string) (*Encoder, error) {
+	enc := &Encoder{types: make([]columnType, len(types))}
+	for col, typ := range types {
+		ct, err := parseType(typ)
+		if err != nil {
+			return nil, fmt.Errorf("clickhouse: column #%d (%w)", col, err)
+		}
+		enc.types[col] = ct
+	}
+
+	return enc, nil
+}
+
+// parseType parses a ClickHouse type.
+func parseType(typ string) (columnType, error) {
+	var ct columnType
+	typ = strings.TrimSpace(typ)
+	if inner, ok := unwrapType(typ, "Nullable"); ok {
+		ct.nullable = true
+		typ = inner
+	}
+	if inner, ok := unwrapType(typ, "FixedString"); ok {
+		size, err := strconv.Atoi(inner)
+		if err != nil || size <= 0 {
+			return ct, fmt.Errorf("invalid type %q", typ)
+		}
+		ct.kind, ct.size = kindFixedString, size
+
+		return ct, nil
+	}
+	k, ok := kinds[typ]
+	if !ok {
+		return ct, fmt.Errorf("unsupported type %q", typ)
+	}
+	ct.kind = k
+
+	return ct, nil
+}
+
+// unwrapType returns T from wrapper(T).
+func unwrapType(typ, wrapper string) (string, bool) {
+	if strings.HasPrefix(typ, wrapper+"(") && strings.HasSuffix(typ, ")") {
+		return strings.TrimSpace(typ[len(wrapper)+1 : len(typ)-1]), true
+	}
+
+	return "", false
+}
+
+// AppendRow appends the RowBinary encoding of the row to buf and returns the extended buffer.
+// On error, buf is returned unchanged.
+func (enc *Encoder) AppendRow(buf []byte, row []string) ([]byte, error) {
+	if len(row) != len(enc.types) {
+		return buf, fmt.Errorf("clickhouse: expected %d columns, got %d", len(enc.types), len(row))
+	}
+	out := buf
+	for col, value := range row {
+		var err error
+		out, err = appendValue(out, enc.types[col], value)
+		if err != nil {
+			return buf, fmt.Errorf("clickhouse: column #%d (%w)", col, err)
+		}
+	}
+
+	return out, nil
+}
+
+// appendValue appends the RowBinary encoding of a value.
+func appendValue(buf []byte, ct columnType, value string) ([]byte, error) {
+	if ct.nullable {
+		if value == "" {
+			return append(buf, 1), nil
+		}
+		buf = append(buf, 0)
+	}
+
+	switch ct.kind {
+	case kindString:
+		buf = binary.AppendUvarint(buf, uint64(len(value)))
+
+		return append(buf, value...), nil
+	case kindFixedString:
+		if len(value) > ct.size {
+			return buf, fmt.Errorf("value %q exceeds FixedString(%d)", value, ct.size)
+		}
+		buf = append(buf, value...)
+
+		return append(buf, make([]byte, ct.size-len(value))...), nil
+	case kindInt8, kindInt16, kindInt32, kindInt64:
+		bitSize := 8 << (ct.kind - kindInt8)
+		n, err := strconv.ParseInt(value, 10, bitSize)
+		if err != nil {
+			return buf, err
+		}
+
+		return appendUint(buf, uint64(n), bitSize), nil
+	case kindUInt8, kindUInt16, kindUInt32, kindUInt64:
+		bitSize := 8 << (ct.kind - kindUInt8)
+		n, err := strconv.ParseUint(value, 10, bitSize)
+		if err != nil {
+			return buf, err
+		}
+
+		return appendUint(buf, n, bitSize), nil
+	case kindFloat32:
+		f, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return buf, err
+		}
+
+		return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(f))), nil
+	case kindFloat64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return buf, err
+		}
+
+		return binary.LittleEndian.AppendUint64(buf, math.Float64bits(f)), nil
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return buf, err
+		}
+		if b {
+			return append(buf, 1), nil
+		}
+
+		return append(buf, 0), nil
+	case kindDate:
+		t, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return buf, err
+		}
+		days := t.Unix() / 86400
+		if days < 0 || days > math.MaxUint16 {
+			return buf, fmt.Errorf("date %q out of range", value)
+		}
+
+		return binary.LittleEndian.AppendUint16(buf, uint16(days)), nil
+	default: // kindDateTime
+		t, err := time.Parse(dateTimeLayout, value)
+		if err != nil {
+			return buf, err
+		}
+		seconds := t.Unix()
+		if seconds < 0 || seconds > math.MaxUint32 {
+			return buf, fmt.Errorf("date time %q out of range", value)
+		}
+
+		return binary.LittleEndian.AppendUint32(buf, uint32(seconds)), nil
+	}
+}
+
+// appendUint appends the little endian encoding of n, on given number of bits.
+func appendUint(buf []byte, n uint64, bitSize int) []byte {
+	for i := 0; i < bitSize/8; i++ {
+		buf = append(buf, byte(n>>(8*i)))
+	}
+
+	return buf
+}
+
+// Blocks encodes the rows read by a [bigcsvreader.CsvReader] into RowBinary blocks,
+// of at most blockSize rows (defaults to 10000 if <= 0), per reading goroutine.
+// Each block can be sent as the body of a bulk insert.
+// It returns a channel of blocks for each rows channel, and an errors channel
+// which carries both the errors received through errsChan and the encoding errors.
+// Rows which could not be encoded are disregarded.
+func Blocks(
+	rowsChans []bigcsvreader.RowsChan,
+	errsChan bigcsvreader.ErrsChan,
+	enc *Encoder,
+	blockSize int,
+) ([]<-chan []byte, bigcsvreader.ErrsChan) {
+	if blockSize <= 0 {
+		blockSize = defaultBlockSize
+	}
+	var (
+		outChans = make([]<-chan []byte, len(rowsChans))
+		outErrs  = make(chan error, chanBufferSize)
+		wg       sync.WaitGroup
+	)
+
+	wg.Add(len(rowsChans) + 1)
+	for i := 0; i < len(rowsChans); i++ {
+		outChan := make(chan []byte, chanBufferSize)
+		outChans[i] = outChan
+		go func(rowsChan bigcsvreader.RowsChan, outChan chan<- []byte) {
+			defer wg.Done()
+			defer close(outChan)
+			var (
+				block     []byte
+				rowsCount int
+				err       error
+			)
+			for row := range rowsChan {
+				block, err = enc.AppendRow(block, row)
+				if err != nil {
+					outErrs <- err
+
+					continue
+				}
+				rowsCount++
+				if rowsCount == blockSize {
+					outChan <- block
+					block, rowsCount = nil, 0
+				}
+			}
+			if rowsCount > 0 {
+				outChan <- block
+			}
+		}(rowsChans[i], outChan)
+	}
+	go func() {
+		defer wg.Done()
+		for err := range errsChan {
+			outErrs <- err
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(outErrs)
+	}()
+
+	return outChans, outErrs
+}
@@ -0,0 +1,113 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package clickhouse_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+	"github.com/actforgood/bigcsvreader/clickhouse"
+)
+
+func TestEncoder_AppendRow(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, err := clickhouse.NewEncoder([]string{
+		"String", "FixedString(3)", "Int16", "UInt32", "Float64", "Bool", "Date", "DateTime", "Nullable(Int8)", "Nullable(Int8)",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := []string{"ab", "x", "-2", "258", "1.5", "true", "1970-01-03", "1970-01-01 00:01:00", "", "7"}
+	expected := []byte{
+		2, 'a', 'b', // String
+		'x', 0, 0, // FixedString(3)
+		0xfe, 0xff, // Int16
+		0x02, 0x01, 0, 0, // UInt32
+		0, 0, 0, 0, 0, 0, 0xf8, 0x3f, // Float64
+		1,    // Bool
+		2, 0, // Date
+		60, 0, 0, 0, // DateTime
+		1,    // Nullable(Int8) NULL
+		0, 7, // Nullable(Int8)
+	}
+
+	// act
+	result, err := subject.AppendRow([]byte{0xaa}, row)
+
+	// assert
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !bytes.Equal(append([]byte{0xaa}, expected...), result) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestEncoder_AppendRow_errors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := clickhouse.NewEncoder([]string{"Decimal(10, 2)"}); err == nil {
+		t.Error("expected unsupported type error")
+	}
+	subject, err := clickhouse.NewEncoder([]string{"UInt8", "FixedString(1)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range [][]string{{"256", "a"}, {"1", "ab"}, {"1"}} {
+		buf := []byte{1}
+		result, err := subject.AppendRow(buf, row)
+		if err == nil {
+			t.Errorf("expected error for row %q", row)
+		}
+		if !bytes.Equal(buf, result) {
+			t.Errorf("expected buffer unchanged for row %q, got %v", row, result)
+		}
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	csvReader := bigcsvreader.New()
+	csvReader.SetFilePath("../testdata/file_with_header.csv")
+	csvReader.ColumnsCount = 3
+	csvReader.FileHasHeader = true
+	csvReader.ColumnsDelimiter = ';'
+	enc, err := clickhouse.NewEncoder([]string{"UInt8", "String", "UInt8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+	rowsChans, errsChan := csvReader.Read(ctx)
+
+	// act
+	blocksChans, outErrs := clickhouse.Blocks(rowsChans, errsChan, enc, 2)
+
+	// assert
+	var blocks [][]byte
+	for _, blocksChan := range blocksChans {
+		for block := range blocksChan {
+			blocks = append(blocks, block)
+		}
+	}
+	for err := range outErrs {
+		t.Errorf("unexpected error %v", err)
+	}
+	if len(blocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %d", len(blocks))
+	}
+	expectedFirst := []byte{1, 4, 'J', 'o', 'h', 'n', 33, 2, 4, 'J', 'a', 'n', 'e', 30}
+	if !bytes.Equal(expectedFirst, blocks[0]) {
+		t.Errorf("expected %v, got %v", expectedFirst, blocks[0])
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+// column describes a CSV column.
+type column struct {
+	name string
+	typ  bigcsvreader.ColumnType
+}
+
+// schemaTypes maps schema types to column types.
+var schemaTypes = map[string]bigcsvreader.ColumnType{
+	"bool":   bigcsvreader.ColumnTypeBool,
+	"int":    bigcsvreader.ColumnTypeInt,
+	"float":  bigcsvreader.ColumnTypeFloat,
+	"string": bigcsvreader.ColumnTypeString,
+}
+
+// initialisms are upper cased entirely in fields names.
+var initialisms = map[string]bool{
+	"ID": true, "URL": true, "URI": true, "API": true, "UUID": true,
+	"HTTP": true, "IP": true, "SKU": true, "SQL": true, "JSON": true,
+}
+
+// parseSchema parses a schema like "id:int,name:string".
+func parseSchema(schema string) ([]column, error) {
+	var columns []column
+	for _, def := range strings.Split(schema, ",") {
+		name, typ, found := strings.Cut(strings.TrimSpace(def), ":")
+		if !found {
+			typ = "string"
+		}
+		ct, ok := schemaTypes[strings.ToLower(strings.TrimSpace(typ))]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %q for column %q", typ, name)
+		}
+		columns = append(columns, column{name: strings.TrimSpace(name), typ: ct})
+	}
+
+	return columns, nil
+}
+
+// fieldName returns an exported Go identifier for the column.
+func fieldName(name string, col int) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var sb strings.Builder
+	for _, word := range words {
+		if upper := strings.ToUpper(word); initialisms[upper] {
+			sb.WriteString(upper)
+
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	field := sb.String()
+	if field == "" {
+		return "Col" + strconv.Itoa(col+1)
+	}
+	if first := []rune(field)[0]; !unicode.IsLetter(first) {
+		field = "Col" + field
+	}
+
+	return field
+}
+
+// generate returns the formatted source of the struct and its decoding function.
+func generate(pkg, typeName string, columns []column) ([]byte, error) {
+	var (
+		buf          bytes.Buffer
+		names        = make([]string, len(columns))
+		seen         = make(map[string]int)
+		needsStrconv bool
+	)
+	for col, c := range columns {
+		names[col] = fieldName(c.name, col)
+		if seen[names[col]]++; seen[names[col]] > 1 {
+			names[col] += strconv.Itoa(seen[names[col]])
+		}
+		if c.typ != bigcsvreader.ColumnTypeString && c.typ != bigcsvreader.ColumnTypeUnknown {
+			needsStrconv = true
+		}
+	}
+
+	fmt.Fprintf(&buf, "// Code generated by bigcsvgen; DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	if needsStrconv {
+		buf.WriteString("import (\n\"fmt\"\n\"strconv\"\n)\n\n")
+	} else {
+		buf.WriteString("import \"fmt\"\n\n")
+	}
+
+	fmt.Fprintf(&buf, "// %s is a row of the CSV file.\ntype %s struct {\n", typeName, typeName)
+	for col, c := range columns {
+		fmt.Fprintf(&buf, "%s %s // column #%d %q\n", names[col], goType(c.typ), col, c.name)
+	}
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(&buf, "// Decode%s decodes a CSV row into a %s.\n", typeName, typeName)
+	fmt.Fprintf(&buf, "// Empty values are decoded as zero values.\n")
+	fmt.Fprintf(&buf, "func Decode%s(row []string) (%s, error) {\n", typeName, typeName)
+	fmt.Fprintf(&buf, "var v %s\n", typeName)
+	fmt.Fprintf(&buf, "if len(row) != %d {\nreturn v, fmt.Errorf(\"expected %d columns, got %%d\", len(row))\n}\n", len(columns), len(columns))
+	if needsStrconv {
+		buf.WriteString("var err error\n")
+	}
+	for col, c := range columns {
+		field := "v." + names[col]
+		value := fmt.Sprintf("row[%d]", col)
+		var parse string
+		switch c.typ {
+		case bigcsvreader.ColumnTypeBool:
+			parse = fmt.Sprintf("%s, err = strconv.ParseBool(%s)", field, value)
+		case bigcsvreader.ColumnTypeInt:
+			parse = fmt.Sprintf("%s, err = strconv.ParseInt(%s, 10, 64)", field, value)
+		case bigcsvreader.ColumnTypeFloat:
+			parse = fmt.Sprintf("%s, err = strconv.ParseFloat(%s, 64)", field, value)
+		default:
+			fmt.Fprintf(&buf, "%s = %s\n", field, value)
+
+			continue
+		}
+		fmt.Fprintf(&buf, "if %s != \"\" {\nif %s; err != nil {\n", value, parse)
+		fmt.Fprintf(&buf, "return v, fmt.Errorf(\"column #%d %%q: %%w\", %q, err)\n}\n}\n", col, c.name)
+	}
+	buf.WriteString("\nreturn v, nil\n}\n")
+
+	return format.Source(buf.Bytes())
+}
+
+// goType returns the Go type for a column type.
+func goType(ct bigcsvreader.ColumnType) string {
+	switch ct {
+	case bigcsvreader.ColumnTypeBool:
+		return "bool"
+	case bigcsvreader.ColumnTypeInt:
+		return "int64"
+	case bigcsvreader.ColumnTypeFloat:
+		return "float64"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	columns, err := parseSchema("id:int, unit price:float,is-active:bool,2nd:string,name,name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	src, err := generate("products", "Product", columns)
+
+	// assert
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	for _, expected := range []string{
+		"package products",
+		"ID        int64   // column #0 \"id\"",
+		"UnitPrice float64 // column #1 \"unit price\"",
+		"IsActive  bool    // column #2 \"is-active\"",
+		"Col2nd    string  // column #3 \"2nd\"",
+		"Name      string  // column #4 \"name\"",
+		"Name2     string  // column #5 \"name\"",
+		"func DecodeProduct(row []string) (Product, error) {",
+		"v.UnitPrice, err = strconv.ParseFloat(row[1], 64)",
+		"v.IsActive, err = strconv.ParseBool(row[2])",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected generated source to contain %q, got:\n%s", expected, src)
+		}
+	}
+}
+
+func TestParseSchema_unknownType(t *testing.T) {
+	t.Parallel()
+
+	if _, err := parseSchema("id:uuid"); err == nil {
+		t.Error("expected unknown type error")
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+// Package main contains bigcsvgen, a code generator which emits a typed struct
+// and its reflection free decoding function, to be used with [bigcsvreader.MapRows].
+//
+// The columns are described either by a sample CSV file, whose columns types are inferred,
+// or by a schema, like "id:int,name:string,price:float,active:bool".
+// It is meant to be invoked through go generate, like:
+//
+//	//go:generate go run github.com/actforgood/bigcsvreader/cmd/bigcsvgen -sample products.csv -header -type Product
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+var (
+	sampleFlag    = flag.String("sample", "", "Sample CSV file to infer columns from.")
+	headerFlag    = flag.Bool("header", false, "Sample CSV file has header (used for fields names).")
+	delimiterFlag = flag.String("delimiter", ",", "Sample CSV file columns delimiter.")
+	schemaFlag    = flag.String("schema", "", `Columns schema, like "id:int,name:string,price:float,active:bool".`)
+	typeFlag      = flag.String("type", "Row", "Name of the generated struct.")
+	packageFlag   = flag.String("package", "", "Package of the generated file. Defaults to $GOPACKAGE, or main.")
+	outputFlag    = flag.String("o", "", "Output file. Defaults to <type>_bigcsvgen.go.")
+)
+
+func main() {
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("bigcsvgen: ")
+
+	var (
+		columns []column
+		err     error
+	)
+	switch {
+	case *schemaFlag != "":
+		columns, err = parseSchema(*schemaFlag)
+	case *sampleFlag != "":
+		columns, err = inferColumns(*sampleFlag, *headerFlag, *delimiterFlag)
+	default:
+		log.Fatal("one of -sample or -schema must be provided")
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	pkg := *packageFlag
+	if pkg == "" {
+		pkg = os.Getenv("GOPACKAGE")
+	}
+	if pkg == "" {
+		pkg = "main"
+	}
+	src, err := generate(pkg, *typeFlag, columns)
+	if err != nil {
+		log.Fatal(err)
+	}
+	output := *outputFlag
+	if output == "" {
+		output = strings.ToLower(*typeFlag) + "_bigcsvgen.go"
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// inferColumns infers the columns from a sample CSV file.
+func inferColumns(samplePath string, hasHeader bool, delimiter string) ([]column, error) {
+	cr := bigcsvreader.New()
+	cr.SetFilePath(samplePath)
+	cr.FileHasHeader = hasHeader
+	cr.ColumnsDelimiter, _ = utf8.DecodeRuneInString(delimiter)
+	artifacts, err := cr.Analyze(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	var header []string
+	if hasHeader {
+		if header, err = cr.Header(); err != nil {
+			return nil, err
+		}
+	}
+	columns := make([]column, artifacts.ColumnsCount)
+	for col := range columns {
+		columns[col].typ = artifacts.ColumnsTypes[col]
+		if col < len(header) {
+			columns[col].name = header[col]
+		}
+	}
+
+	return columns, nil
+}
@@ -0,0 +1,156 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ColumnStats holds a column's statistics, over the rows read so far.
+type ColumnStats struct {
+	// Column is the 0-based index of the column.
+	Column int
+	// Name is the column's name, taken from the header, if the file has one.
+	Name string
+	// Count is the number of values.
+	Count int64
+	// Nulls is the number of empty values.
+	Nulls int64
+	// Numeric is the number of numeric values.
+	Numeric int64
+	// Min is the minimum of numeric values, meaningful only if Numeric > 0.
+	Min float64
+	// Max is the maximum of numeric values, meaningful only if Numeric > 0.
+	Max float64
+}
+
+// ColumnsStats is a snapshot of the columns' statistics, see [CsvReader.ColumnsStatsInterval].
+type ColumnsStats struct {
+	// Time is the time the snapshot was taken.
+	Time time.Time
+	// RowsCount is the number of rows the statistics are computed over.
+	RowsCount int64
+	// Final is a flag indicating this is the last snapshot, taken after all goroutines finished.
+	Final bool
+	// Columns holds each column's statistics.
+	Columns []ColumnStats
+}
+
+// ColumnsStatsChan is the channel columns statistics snapshots are pushed into.
+type ColumnsStatsChan <-chan ColumnsStats
+
+// ColumnsStatsChan returns the channel where columns statistics snapshots are pushed into,
+// if [CsvReader.ColumnsStatsInterval] is set, nil otherwise.
+// The channel holds only the latest snapshot (a stale one is replaced),
+// so a slow consumer never slows down reading. It is closed after the final snapshot.
+func (rn *Run) ColumnsStatsChan() ColumnsStatsChan {
+	if rn.colStatsChan == nil {
+		return nil
+	}
+
+	return rn.colStatsChan
+}
+
+// columnsStatsAcc accumulates the statistics of the rows emitted by a goroutine.
+type columnsStatsAcc struct {
+	mu      sync.Mutex
+	rows    int64
+	columns []ColumnStats
+}
+
+// add accounts given record's values.
+func (acc *columnsStatsAcc) add(record []string) {
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+
+	acc.rows++
+	for col, value := range record {
+		if col == len(acc.columns) {
+			acc.columns = append(acc.columns, ColumnStats{Column: col})
+		}
+		stats := &acc.columns[col]
+		stats.Count++
+		if value == "" {
+			stats.Nulls++
+
+			continue
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(number) {
+			continue
+		}
+		if stats.Numeric == 0 || number < stats.Min {
+			stats.Min = number
+		}
+		if stats.Numeric == 0 || number > stats.Max {
+			stats.Max = number
+		}
+		stats.Numeric++
+	}
+}
+
+// columnsStats merges the goroutines' statistics into a snapshot.
+func (rn *Run) columnsStats(now time.Time, final bool) ColumnsStats {
+	snapshot := ColumnsStats{Time: now, Final: final}
+	for i := range rn.colStats {
+		acc := &rn.colStats[i]
+		acc.mu.Lock()
+		snapshot.RowsCount += acc.rows
+		for _, stats := range acc.columns {
+			if stats.Column == len(snapshot.Columns) {
+				snapshot.Columns = append(snapshot.Columns, ColumnStats{Column: stats.Column})
+			}
+			merged := &snapshot.Columns[stats.Column]
+			if stats.Numeric > 0 {
+				if merged.Numeric == 0 || stats.Min < merged.Min {
+					merged.Min = stats.Min
+				}
+				if merged.Numeric == 0 || stats.Max > merged.Max {
+					merged.Max = stats.Max
+				}
+			}
+			merged.Count += stats.Count
+			merged.Nulls += stats.Nulls
+			merged.Numeric += stats.Numeric
+		}
+		acc.mu.Unlock()
+	}
+	for col := range snapshot.Columns {
+		if col < len(rn.header) {
+			snapshot.Columns[col].Name = rn.header[col]
+		}
+	}
+
+	return snapshot
+}
+
+// publishColumnsStats pushes a columns statistics snapshot, replacing the stale one, if not consumed.
+// It is called only from the goroutine which owns the channel.
+func (rn *Run) publishColumnsStats(now time.Time, final bool) {
+	snapshot := rn.columnsStats(now, final)
+	select {
+	case <-rn.colStatsChan:
+	default:
+	}
+	rn.colStatsChan <- snapshot
+}
+
+// streamColumnsStats periodically publishes the columns statistics until done is closed.
+func (cr *CsvReader) streamColumnsStats(rn *Run, done <-chan struct{}) {
+	ticks, stop := cr.Clock.NewTicker(cr.ColumnsStatsInterval)
+	defer stop()
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticks:
+			rn.publishColumnsStats(now.UTC(), false)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestRun_ColumnsStatsChan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("incremental stats", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 3000
+		var content strings.Builder
+		content.WriteString("id,name,score\n")
+		for i := 1; i <= rowsCount; i++ {
+			if i%10 == 0 {
+				fmt.Fprintf(&content, "%d,Name %d,\n", i, i)
+			} else {
+				fmt.Fprintf(&content, "%d,Name %d,%.1f\n", i, i, float64(i)/2)
+			}
+		}
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, t.TempDir(), "stats.csv", content.String()))
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.MaxGoroutinesNo = 4
+		subject.ColumnsStatsInterval = time.Millisecond
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		snapshotsChan := make(chan []bigcsvreader.ColumnsStats, 1)
+		go func() {
+			var snapshots []bigcsvreader.ColumnsStats
+			for snapshot := range run.ColumnsStatsChan() {
+				snapshots = append(snapshots, snapshot)
+			}
+			snapshotsChan <- snapshots
+		}()
+		drainRows(run.RowsChans())
+		err := run.Err()
+		snapshots := <-snapshotsChan
+
+		// assert
+		assertNil(t, err)
+		if !assertTrue(t, len(snapshots) > 0) {
+			return
+		}
+		for i := 1; i < len(snapshots); i++ {
+			assertTrue(t, snapshots[i-1].RowsCount <= snapshots[i].RowsCount)
+			assertTrue(t, !snapshots[i-1].Final)
+		}
+		final := snapshots[len(snapshots)-1]
+		assertTrue(t, final.Final)
+		assertEqual(t, int64(rowsCount), final.RowsCount)
+		assertEqual(t, []bigcsvreader.ColumnStats{
+			{Column: 0, Name: "id", Count: rowsCount, Numeric: rowsCount, Min: 1, Max: rowsCount},
+			{Column: 1, Name: "name", Count: rowsCount},
+			{Column: 2, Name: "score", Count: rowsCount, Nulls: rowsCount / 10, Numeric: rowsCount * 9 / 10, Min: 0.5, Max: 1499.5},
+		}, final.Columns)
+	})
+
+	t.Run("no stats by default", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_without_header.csv")
+		subject.ColumnsCount = 3
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		drainRows(run.RowsChans())
+		err := run.Err()
+
+		// assert
+		assertNil(t, err)
+		assertNil(t, run.ColumnsStatsChan())
+	})
+}
@@ -0,0 +1,195 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// Case is a case normalization applied to a column's values.
+type Case int
+
+const (
+	// CaseUnchanged leaves values as they are.
+	CaseUnchanged Case = iota
+	// CaseLower converts values to lower case.
+	CaseLower
+	// CaseUpper converts values to upper case.
+	CaseUpper
+)
+
+// ColumnOptions holds options applied to a column's values, inside the reading goroutines.
+type ColumnOptions struct {
+	// TrimSpace is a flag indicating that leading and trailing white space should be removed.
+	TrimSpace bool
+	// NormalizeWindows1252 is a flag indicating that Windows-1252 punctuation bytes (smart quotes, dashes,
+	// ellipsis...) which are not part of valid UTF-8 sequences should be translated into UTF-8.
+	// It is applied before trimming / case normalization. Replacements are counted, see [Run.Windows1252Replacements].
+	NormalizeWindows1252 bool
+	// Case is the case normalization applied to values.
+	Case Case
+	// AllowedValues holds the closed set of values the column accepts (after trimming / case normalization).
+	// A row having another value is disregarded, and an [*InvalidEnumError] is sent through ErrsChan.
+	// Empty set means any value is accepted.
+	AllowedValues []string
+	// Pattern is a regular expression (see [regexp] syntax) the column values must match
+	// (after trimming / case normalization). It is compiled once per run, and shared by all goroutines.
+	// A row having a value which does not match is disregarded, and a [*PatternMismatchError]
+	// is sent through ErrsChan. Empty pattern means any value is accepted.
+	Pattern string
+}
+
+// compiledColumn holds the column options prepared for a run.
+type compiledColumn struct {
+	ColumnOptions
+	// allowed is the set of AllowedValues.
+	allowed map[string]struct{}
+	// pattern is the compiled Pattern.
+	pattern *regexp.Regexp
+	// violations is the number of values which did not match the pattern, updated atomically.
+	violations int64
+}
+
+// InvalidEnumError is the error returned when a column value is not among the column's allowed values.
+type InvalidEnumError struct {
+	// Column is the column's index (0-based).
+	Column int
+	// Value is the invalid value.
+	Value string
+	// Offset is the offset of the row in the file.
+	Offset int
+}
+
+// Error returns the error's message.
+func (e *InvalidEnumError) Error() string {
+	return fmt.Sprintf("invalid value %q for column #%d at offset %d", e.Value, e.Column, e.Offset)
+}
+
+// PatternMismatchError is the error returned when a column value does not match the column's pattern.
+type PatternMismatchError struct {
+	// Column is the column's index (0-based).
+	Column int
+	// Value is the invalid value.
+	Value string
+	// Pattern is the column's pattern.
+	Pattern string
+	// Offset is the offset of the row in the file.
+	Offset int
+}
+
+// Error returns the error's message.
+func (e *PatternMismatchError) Error() string {
+	return fmt.Sprintf(
+		"value %q for column #%d at offset %d does not match pattern %q",
+		e.Value, e.Column, e.Offset, e.Pattern,
+	)
+}
+
+// compileColumnsOptions returns the columns options as a slice indexed by column,
+// for fast access inside the reading goroutines, or nil if there is nothing to apply.
+// The second returned value indicates whether there are validation rules to apply.
+// An error is returned if a column's pattern could not be compiled.
+func (cr *CsvReader) compileColumnsOptions() ([]compiledColumn, bool, error) {
+	maxCol := -1
+	for col := range cr.ColumnsOptions {
+		if col > maxCol {
+			maxCol = col
+		}
+	}
+	global := cr.TrimSpace || cr.NormalizeWindows1252
+	if maxCol < 0 && !global {
+		return nil, false, nil
+	}
+	if global && maxCol < cr.ColumnsCount-1 {
+		maxCol = cr.ColumnsCount - 1
+	}
+
+	columns := make([]compiledColumn, maxCol+1)
+	for col := range columns {
+		columns[col].TrimSpace = cr.TrimSpace
+		columns[col].NormalizeWindows1252 = cr.NormalizeWindows1252
+	}
+	hasRules := false
+	for col, opts := range cr.ColumnsOptions {
+		if col < 0 {
+			continue
+		}
+		opts.TrimSpace = opts.TrimSpace || cr.TrimSpace
+		opts.NormalizeWindows1252 = opts.NormalizeWindows1252 || cr.NormalizeWindows1252
+		columns[col].ColumnOptions = opts
+		if len(opts.AllowedValues) > 0 {
+			hasRules = true
+			columns[col].allowed = make(map[string]struct{}, len(opts.AllowedValues))
+			for _, value := range opts.AllowedValues {
+				columns[col].allowed[value] = struct{}{}
+			}
+		}
+		if opts.Pattern != "" {
+			pattern, err := regexp.Compile(opts.Pattern)
+			if err != nil {
+				return nil, false, fmt.Errorf("column #%d pattern: %w", col, err)
+			}
+			hasRules = true
+			columns[col].pattern = pattern
+		}
+	}
+
+	return columns, hasRules, nil
+}
+
+// transformRecord applies in place the columns options to the record's values.
+func (rn *Run) transformRecord(record []string) {
+	for col := 0; col < len(record); col++ {
+		var opts ColumnOptions
+		if col < len(rn.columns) {
+			opts = rn.columns[col].ColumnOptions
+		} else if !rn.trimSpace && !rn.normalizeWindows1252 {
+			break
+		} else {
+			opts.TrimSpace, opts.NormalizeWindows1252 = rn.trimSpace, rn.normalizeWindows1252
+		}
+		if opts.NormalizeWindows1252 {
+			var replacements int
+			if record[col], replacements = normalizeWindows1252(record[col]); replacements > 0 {
+				atomic.AddInt64(&rn.windows1252Replacements, int64(replacements))
+			}
+		}
+		if opts.TrimSpace {
+			record[col] = strings.TrimSpace(record[col])
+		}
+		switch opts.Case {
+		case CaseLower:
+			record[col] = strings.ToLower(record[col])
+		case CaseUpper:
+			record[col] = strings.ToUpper(record[col])
+		}
+	}
+}
+
+// validateRecord checks the record's values against the columns validation rules.
+// All the columns are checked, so each pattern's violations are counted, and the first error is returned.
+func (rn *Run) validateRecord(record []string, offset int) error {
+	var err error
+	for col := 0; col < len(record) && col < len(rn.columns); col++ {
+		column := &rn.columns[col]
+		if column.allowed != nil && err == nil {
+			if _, ok := column.allowed[record[col]]; !ok {
+				err = &InvalidEnumError{Column: col, Value: record[col], Offset: offset}
+			}
+		}
+		if column.pattern != nil && !column.pattern.MatchString(record[col]) {
+			atomic.AddInt64(&column.violations, 1)
+			if err == nil {
+				err = &PatternMismatchError{Column: col, Value: record[col], Pattern: column.Pattern, Offset: offset}
+			}
+		}
+	}
+
+	return err
+}
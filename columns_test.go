@@ -0,0 +1,207 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ColumnsOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name            string
+		trimSpace       bool
+		columnsOptions  map[int]bigcsvreader.ColumnOptions
+		expectedRecords [][]string
+	}{
+		{
+			name:      "global trim space",
+			trimSpace: true,
+			expectedRecords: [][]string{
+				{"1", "John", "ADMIN", "Ro"},
+				{"2", "Jane", "user", "en"},
+				{"3", "Mike", "Guest", "It"},
+			},
+		},
+		{
+			name: "per column options",
+			columnsOptions: map[int]bigcsvreader.ColumnOptions{
+				1: {TrimSpace: true},
+				2: {TrimSpace: true, Case: bigcsvreader.CaseLower},
+				3: {Case: bigcsvreader.CaseUpper},
+			},
+			expectedRecords: [][]string{
+				{"1", "John", "admin", "RO"},
+				{"2", "Jane", "user", "EN"},
+				{"3", "Mike", "guest", "IT"},
+			},
+		},
+		{
+			name:      "global trim space and per column options",
+			trimSpace: true,
+			columnsOptions: map[int]bigcsvreader.ColumnOptions{
+				1: {Case: bigcsvreader.CaseUpper},
+			},
+			expectedRecords: [][]string{
+				{"1", "JOHN", "ADMIN", "Ro"},
+				{"2", "JANE", "user", "en"},
+				{"3", "MIKE", "Guest", "It"},
+			},
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath("testdata/file_with_spaces.csv")
+			subject.ColumnsCount = 4
+			subject.TrimSpace = test.trimSpace
+			subject.ColumnsOptions = test.columnsOptions
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			rowsChans, errsChan := subject.Read(ctx)
+			records, err := gatherRecords(rowsChans, errsChan)
+
+			// assert
+			assertNil(t, err)
+			sort.Slice(records, func(i, j int) bool { return records[i][0] < records[j][0] })
+			assertEqual(t, test.expectedRecords, records)
+		})
+	}
+}
+
+func TestCsvReader_AllowedValues(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/file_with_spaces.csv")
+	subject.ColumnsCount = 4
+	subject.ColumnsOptions = map[int]bigcsvreader.ColumnOptions{
+		2: {TrimSpace: true, Case: bigcsvreader.CaseLower, AllowedValues: []string{"admin", "user"}},
+	}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	run := subject.Start(ctx)
+	rowsCount := drainRows(run.RowsChans())
+	err := run.Err()
+
+	// assert
+	assertEqual(t, 2, rowsCount)
+	var enumErr *bigcsvreader.InvalidEnumError
+	if assertTrue(t, errors.As(err, &enumErr)) {
+		assertEqual(t, bigcsvreader.InvalidEnumError{Column: 2, Value: "guest", Offset: 39}, *enumErr)
+	}
+}
+
+func TestCsvReader_Pattern(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rows not matching pattern are disregarded", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_spaces.csv")
+		subject.ColumnsCount = 4
+		subject.ColumnsOptions = map[int]bigcsvreader.ColumnOptions{
+			3: {Pattern: "^[A-Z][a-z]$"},
+		}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		rowsCount := drainRows(run.RowsChans())
+		err := run.Err()
+
+		// assert
+		assertEqual(t, 2, rowsCount)
+		var patternErr *bigcsvreader.PatternMismatchError
+		if assertTrue(t, errors.As(err, &patternErr)) {
+			assertEqual(
+				t,
+				bigcsvreader.PatternMismatchError{Column: 3, Value: "en", Pattern: "^[A-Z][a-z]$", Offset: 22},
+				*patternErr,
+			)
+		}
+		assertEqual(t, map[int]int64{3: 1}, run.PatternViolations())
+	})
+
+	t.Run("violations are counted on all columns", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, t.TempDir(), "patterns.csv", "1,a,x\nb,2,y\nc,d,z\n"))
+		subject.ColumnsCount = 3
+		subject.ColumnsOptions = map[int]bigcsvreader.ColumnOptions{
+			0: {Pattern: "^[0-9]$"},
+			1: {Pattern: "^[0-9]$"},
+		}
+		summaries := make(chan bigcsvreader.Summary, 1)
+		subject.OnComplete = func(summary bigcsvreader.Summary) {
+			summaries <- summary
+		}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		rowsCount := drainRows(run.RowsChans())
+		err := run.Err()
+		summary := <-summaries
+		report, reportErr := json.Marshal(summary)
+
+		// assert
+		assertEqual(t, 0, rowsCount)
+		assertNotNil(t, err)
+		assertEqual(t, map[int]int64{0: 2, 1: 2}, run.PatternViolations())
+		assertEqual(t, map[int]int64{0: 2, 1: 2}, summary.PatternViolations)
+		assertNil(t, reportErr)
+		assertTrue(t, strings.Contains(string(report), `"patternViolations":{"0":2,"1":2}`))
+	})
+
+	t.Run("invalid pattern fails the run", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_spaces.csv")
+		subject.ColumnsCount = 4
+		subject.ColumnsOptions = map[int]bigcsvreader.ColumnOptions{
+			1: {Pattern: "[a-z"},
+		}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		err := run.Err()
+
+		// assert
+		assertTrue(t, err != nil)
+		assertEqual(t, 0, len(run.RowsChans()))
+		assertEqual(t, map[int]int64{}, run.PatternViolations())
+	})
+}
@@ -0,0 +1,386 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+// compression is the compression of a file.
+type compression int
+
+const (
+	// compressionNone is the compression of a file which is not compressed.
+	compressionNone compression = iota
+	// compressionGzip is the compression of a plain gzip file, which can only be decompressed sequentially.
+	compressionGzip
+	// compressionBGZF is the compression of a BGZF (blocked gzip) file, made of independent gzip members,
+	// which can be decompressed starting at any block.
+	compressionBGZF
+	// compressionZstd is the zstd compression, the file being expected in the seekable format,
+	// made of independent frames, which can be decompressed starting at any frame.
+	compressionZstd
+)
+
+// ErrUnsupportedCompression is the error an [*UnsupportedCompressionError] wraps.
+var ErrUnsupportedCompression = errors.New("unsupported compression")
+
+// UnsupportedCompressionError is the error sent through ErrsChan when the file is compressed
+// in a format which can't be decompressed (or not with the reader's configuration),
+// instead of each goroutine failing to parse compressed data.
+type UnsupportedCompressionError struct {
+	// Format is the compression format the file's magic bytes tell ("zstd", "bzip2", "xz", "lz4" or "zip").
+	Format string
+	// Hint tells how the file can be read.
+	Hint string
+}
+
+// Error returns the error's message.
+func (e *UnsupportedCompressionError) Error() string {
+	return fmt.Sprintf("%s: file is %s compressed, %s", ErrUnsupportedCompression, e.Format, e.Hint)
+}
+
+// Unwrap returns [ErrUnsupportedCompression].
+func (e *UnsupportedCompressionError) Unwrap() error {
+	return ErrUnsupportedCompression
+}
+
+// recompressHint is the hint of the errors returned for compression formats which are not supported.
+const recompressHint = "decompress it, or recompress it with bgzip"
+
+// unsupportedMagics are the magic bytes of the compression formats which are not supported (bzip2 aside).
+var unsupportedMagics = [...]struct {
+	format string
+	magic  []byte
+	hint   string
+}{
+	{format: "xz", magic: []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}, hint: recompressHint},
+	{format: "lz4", magic: []byte{0x04, 0x22, 0x4d, 0x18}, hint: recompressHint},
+	{format: "zip", magic: []byte{'P', 'K', 0x03, 0x04}, hint: "read it through SetFS with an archive/zip reader"},
+}
+
+// isBzip2 checks whether given header is a bzip2 one: "BZh" and the block size digit, followed by
+// the first block's magic, or the end of stream one (empty data).
+func isBzip2(header []byte) bool {
+	if len(header) < 10 || !bytes.HasPrefix(header, []byte("BZh")) || header[3] < '1' || header[3] > '9' {
+		return false
+	}
+
+	return bytes.HasPrefix(header[4:], []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}) ||
+		bytes.HasPrefix(header[4:], []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90})
+}
+
+// detectCompression detects whether the file is compressed, by its magic bytes (file extensions are not trusted).
+// An [*UnsupportedCompressionError] is returned if it is compressed in a format which is not supported.
+func detectCompression(f io.ReaderAt) (compression, error) {
+	header := make([]byte, bgzfHeaderSize)
+	n, err := f.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return compressionNone, err
+	}
+	header = header[:n]
+	switch {
+	case bgzfBlockSize(header) > 0:
+		return compressionBGZF, nil
+	case bytes.HasPrefix(header, gzipMagic):
+		return compressionGzip, nil
+	case bytes.HasPrefix(header, zstdMagic):
+		return compressionZstd, nil
+	}
+	if isBzip2(header) {
+		return compressionNone, &UnsupportedCompressionError{Format: "bzip2", Hint: recompressHint}
+	}
+	for _, unsupported := range unsupportedMagics {
+		if bytes.HasPrefix(header, unsupported.magic) {
+			return compressionNone, &UnsupportedCompressionError{Format: unsupported.format, Hint: unsupported.hint}
+		}
+	}
+
+	return compressionNone, nil
+}
+
+// decompressingFS returns the file system the file is read from: cr.FS, or, if the file is compressed,
+// a file system decompressing it. It also returns whether the file is a plain gzip file, which can only be
+// decompressed sequentially.
+func (cr *CsvReader) decompressingFS() (internal.FS, bool, error) {
+	f, err := cr.FS.Open(cr.filePath)
+	if err != nil {
+		return cr.FS, false, err
+	}
+	defer f.Close()
+	kind, err := detectCompression(f)
+	if err != nil || kind == compressionNone {
+		return cr.FS, false, err
+	}
+	if kind == compressionZstd && cr.ZstdDecoder == nil {
+		return cr.FS, false, &UnsupportedCompressionError{Format: "zstd", Hint: "set CsvReader.ZstdDecoder to decompress it"}
+	}
+
+	return &compressedFS{FS: cr.FS, kind: kind, zstd: cr.ZstdDecoder}, kind == compressionGzip, nil
+}
+
+// withDecompressingFS returns a copy of the reader whose file system decompresses the file,
+// if it is compressed (see [CsvReader.decompressingFS]), and whether it is a plain gzip file.
+func (cr *CsvReader) withDecompressingFS() (*CsvReader, bool, error) {
+	fsys, stream, err := cr.decompressingFS()
+	if err != nil {
+		return nil, false, err
+	}
+	reader := *cr
+	reader.FS = fsys
+
+	return &reader, stream, nil
+}
+
+// compressedFS is the file system decompressing the compressed files it opens (other files are opened as they are).
+// It is meant to open a single compressed file, the blocks index being built once.
+type compressedFS struct {
+	internal.FS
+	// kind is the compression of the compressed file.
+	kind compression
+	// zstd decompresses zstd frames.
+	zstd ZstdDecoder
+
+	once  sync.Once
+	index *blocksIndex
+	err   error
+}
+
+// Open opens the named file for reading, decompressing it, if it is compressed.
+func (fsys *compressedFS) Open(name string) (internal.File, error) {
+	f, err := fsys.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	kind, err := detectCompression(f)
+	if err != nil {
+		_ = f.Close()
+
+		return nil, err
+	}
+	if kind == compressionNone {
+		return f, nil
+	}
+	if fsys.kind == compressionGzip {
+		return &gzipStreamFile{f: f}, nil
+	}
+	index, err := fsys.blocksIndex(name, f)
+	if err != nil {
+		_ = f.Close()
+
+		return nil, err
+	}
+	bf := &blocksFile{f: f, index: index, cached: -1}
+	if fsys.kind == compressionZstd {
+		bf.decoder = &zstdFrameDecoder{zstd: fsys.zstd}
+	} else {
+		bf.decoder = &bgzfBlockDecoder{}
+	}
+
+	return bf, nil
+}
+
+// Stat returns the named file's info, with the decompressed size for a file having a blocks index.
+func (fsys *compressedFS) Stat(name string) (os.FileInfo, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return f.Stat()
+}
+
+// blocksIndex returns the blocks index of the compressed file, building it the first time.
+func (fsys *compressedFS) blocksIndex(name string, f internal.File) (*blocksIndex, error) {
+	fsys.once.Do(func() {
+		info, err := f.Stat()
+		if err != nil {
+			fsys.err = err
+
+			return
+		}
+		if fsys.kind == compressionZstd {
+			fsys.index, fsys.err = readZstdSeekTable(f, info.Size())
+		} else {
+			fsys.index, fsys.err = readBGZFIndex(fsys.FS, name, f, info.Size())
+		}
+	})
+
+	return fsys.index, fsys.err
+}
+
+// rawFS returns the file system given one decompresses files from, if any, or the given one otherwise.
+func rawFS(fsys internal.FS) internal.FS {
+	if cfs, ok := fsys.(*compressedFS); ok {
+		return cfs.FS
+	}
+
+	return fsys
+}
+
+// compressedBlock locates an independently compressed block (a BGZF block, a zstd frame).
+type compressedBlock struct {
+	// coffset is the offset of the block in the compressed file.
+	coffset int64
+	// uoffset is the offset of the block's data in the decompressed data.
+	uoffset int64
+}
+
+// blocksIndex holds the blocks of a compressed file, followed by a block locating the ends
+// of the compressed blocks and of the decompressed data.
+type blocksIndex struct {
+	blocks []compressedBlock
+}
+
+// size returns the size of the decompressed data.
+func (idx *blocksIndex) size() int64 {
+	return idx.blocks[len(idx.blocks)-1].uoffset
+}
+
+// blockAt returns the index of the block holding the decompressed data at given offset (lower than size).
+func (idx *blocksIndex) blockAt(offset int64) int {
+	return sort.Search(len(idx.blocks)-1, func(i int) bool {
+		return idx.blocks[i+1].uoffset > offset // empty blocks (like the BGZF EOF marker one) are skipped.
+	})
+}
+
+// blockDecoder decompresses the blocks of a compressed file.
+type blockDecoder interface {
+	// decode decompresses the block read by r, appending the decompressed data to dst.
+	decode(r *io.SectionReader, dst []byte) ([]byte, error)
+}
+
+// blocksFile is a compressed file made of independently compressed blocks, decompressed block by block,
+// at any offset. The last decompressed block is cached.
+type blocksFile struct {
+	f     internal.File
+	index *blocksIndex
+	pos   int64
+
+	mu      sync.Mutex
+	decoder blockDecoder
+	cached  int
+	block   []byte
+}
+
+// Read reads decompressed data.
+func (bf *blocksFile) Read(p []byte) (int, error) {
+	n, err := bf.ReadAt(p, bf.pos)
+	bf.pos += int64(n)
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+
+	return n, err
+}
+
+// ReadAt reads len(p) bytes of decompressed data starting at given offset.
+func (bf *blocksFile) ReadAt(p []byte, off int64) (int, error) {
+	bf.mu.Lock()
+	defer bf.mu.Unlock()
+
+	var n int
+	for n < len(p) && off < bf.index.size() {
+		i := bf.index.blockAt(off)
+		if err := bf.load(i); err != nil {
+			return n, err
+		}
+		copied := copy(p[n:], bf.block[off-bf.index.blocks[i].uoffset:])
+		n += copied
+		off += int64(copied)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+// load decompresses the i-th block into the cache, if it is not cached already.
+func (bf *blocksFile) load(i int) error {
+	if bf.cached == i {
+		return nil
+	}
+	block, next := bf.index.blocks[i], bf.index.blocks[i+1]
+	size := int(next.uoffset - block.uoffset)
+	bf.cached = -1
+	data, err := bf.decoder.decode(
+		io.NewSectionReader(bf.f, block.coffset, next.coffset-block.coffset),
+		slices.Grow(bf.block[:0], size),
+	)
+	if err == nil && len(data) != size {
+		err = fmt.Errorf("decompressed %d bytes instead of %d", len(data), size)
+	}
+	if err != nil {
+		return fmt.Errorf("bigcsvreader: could not decompress block at offset %d (%w)", block.coffset, err)
+	}
+	bf.block = data
+	bf.cached = i
+
+	return nil
+}
+
+// Seek sets the offset for the next Read.
+func (bf *blocksFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += bf.pos
+	case io.SeekEnd:
+		offset += bf.index.size()
+	}
+	if offset < 0 {
+		return bf.pos, errors.New("bigcsvreader: negative position")
+	}
+	bf.pos = offset
+
+	return offset, nil
+}
+
+// Close closes the file.
+func (bf *blocksFile) Close() error {
+	return bf.f.Close()
+}
+
+// Stat returns the file's info, having the decompressed size.
+func (bf *blocksFile) Stat() (os.FileInfo, error) {
+	info, err := bf.f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return decompressedFileInfo{FileInfo: info, size: bf.index.size()}, nil
+}
+
+// decompressedFileInfo is the info of a compressed file, having the decompressed size.
+type decompressedFileInfo struct {
+	os.FileInfo
+	size int64
+}
+
+// Size returns the decompressed size.
+func (fi decompressedFileInfo) Size() int64 {
+	return fi.size
+}
+
+// compressedFileInfo returns the info of the compressed file, given the info of a decompressed file.
+func compressedFileInfo(info os.FileInfo) os.FileInfo {
+	if fi, ok := info.(decompressedFileInfo); ok {
+		return fi.FileInfo
+	}
+
+	return info
+}
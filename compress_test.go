@@ -0,0 +1,140 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_unsupportedCompression(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	tests := [...]struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "bzip2",
+			content: "BZh9\x31\x41\x59\x26\x53\x59compressed data",
+		},
+		{
+			name:    "xz",
+			content: "\xfd7zXZ\x00compressed data",
+		},
+		{
+			name:    "lz4",
+			content: "\x04\x22\x4d\x18compressed data",
+		},
+		{
+			name:    "zip",
+			content: "PK\x03\x04compressed data",
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(writeTmpFile(t, dir, "data."+test.name+".csv", test.content))
+			subject.ColumnsCount = 2
+			subject.MaxGoroutinesNo = 4
+
+			// act
+			rowsChans, errsChan := subject.Read(context.Background())
+			err := bigcsvreader.CollectErrors(errsChan)
+
+			// assert
+			assertEqual(t, 0, len(rowsChans))
+			var compressionErr *bigcsvreader.UnsupportedCompressionError
+			if assertTrue(t, errors.As(err, &compressionErr)) {
+				assertEqual(t, test.name, compressionErr.Format)
+				assertTrue(t, errors.Is(err, bigcsvreader.ErrUnsupportedCompression))
+			}
+		})
+	}
+
+	t.Run("synchronous operations", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, dir, "data.xz.csv", tests[1].content))
+		subject.ColumnsCount = 2
+		ctx := context.Background()
+		operations := map[string]func() error{
+			"ReadHeader": func() error {
+				_, err := subject.ReadHeader(ctx)
+
+				return err
+			},
+			"Analyze": func() error {
+				_, err := subject.Analyze(ctx)
+
+				return err
+			},
+			"AnalyzeEncoding": func() error {
+				_, err := subject.AnalyzeEncoding(ctx)
+
+				return err
+			},
+			"PreScan": func() error {
+				_, err := subject.PreScan(ctx)
+
+				return err
+			},
+			"EstimateRows": func() error {
+				_, err := subject.EstimateRows(ctx, 1024)
+
+				return err
+			},
+			"WriteShards": func() error {
+				_, err := subject.WriteShards(ctx, t.TempDir(), 1024)
+
+				return err
+			},
+		}
+
+		for name, operation := range operations {
+			operation := operation // capture range variable
+			t.Run(name, func(t *testing.T) {
+				// act
+				err := operation()
+
+				// assert
+				var compressionErr *bigcsvreader.UnsupportedCompressionError
+				if assertTrue(t, errors.As(err, &compressionErr)) {
+					assertEqual(t, "xz", compressionErr.Format)
+				}
+			})
+		}
+	})
+
+	t.Run("bzip2 look-alike text is not compressed", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, dir, "bzh.csv", "BZh1,John\n2,Jane\n"))
+		subject.ColumnsCount = 2
+
+		// act
+		rowsChans, errsChan := subject.Read(context.Background())
+		rows := gatherRowsInOrder(rowsChans)
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, [][]string{{"BZh1", "John"}, {"2", "Jane"}}, rows)
+	})
+}
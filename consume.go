@@ -0,0 +1,151 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// RowsHandler processes a batch of rows.
+type RowsHandler func(ctx context.Context, rows [][]string) error
+
+// ConsumeOptions holds the options for [Consume].
+type ConsumeOptions struct {
+	// WorkersNo is the number of goroutines calling the handler.
+	// Defaults to the number of rows channels (one worker per reading goroutine).
+	WorkersNo int
+	// BatchSize is the maximum number of rows passed at once to the handler.
+	// Defaults to 1.
+	BatchSize int
+	// StopOnError is a flag indicating that, after the first handler error,
+	// no more rows are passed to the handler.
+	// Defaults to false.
+	StopOnError bool
+}
+
+// Consume processes the rows read by a [CsvReader] with a bounded pool of workers,
+// calling handler for each batch of rows, and blocks until everything was consumed.
+// It returns all the errors received through errsChan and returned by the handler,
+// joined with [errors.Join], or nil if there was no error.
+//
+// If the context is done (or, with StopOnError, the handler failed), the remaining rows
+// are drained without being passed to the handler, so that reading goroutines can terminate gracefully.
+func Consume(
+	ctx context.Context,
+	rowsChans []RowsChan,
+	errsChan ErrsChan,
+	opts ConsumeOptions,
+	handler RowsHandler,
+) error {
+	workersNo := opts.WorkersNo
+	if workersNo <= 0 {
+		workersNo = len(rowsChans)
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	var (
+		errs    []error
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		stopped = make(chan struct{})
+		stop    sync.Once
+	)
+	addErr := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+	worker := func(rowsChan RowsChan) {
+		defer wg.Done()
+		batch := make([][]string, 0, batchSize)
+		flush := func() {
+			if len(batch) == 0 {
+				return
+			}
+			select {
+			case <-ctx.Done():
+			case <-stopped:
+			default:
+				if err := handler(ctx, batch); err != nil {
+					addErr(err)
+					if opts.StopOnError {
+						stop.Do(func() { close(stopped) })
+					}
+				}
+			}
+			batch = make([][]string, 0, batchSize)
+		}
+		for row := range rowsChan {
+			batch = append(batch, row)
+			if len(batch) == batchSize {
+				flush()
+			}
+		}
+		flush()
+	}
+
+	// pair workers 1:1 with rows channels, or fan-in all rows channels into one
+	// shared by workers, if a different number of workers was requested.
+	if workersNo == len(rowsChans) {
+		wg.Add(workersNo)
+		for i := 0; i < workersNo; i++ {
+			go worker(rowsChans[i])
+		}
+	} else {
+		merged := fanIn(rowsChans)
+		wg.Add(workersNo)
+		for i := 0; i < workersNo; i++ {
+			go worker(merged)
+		}
+	}
+
+	for err := range errsChan {
+		addErr(err)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// fanIn merges multiple rows channels into a single one.
+func fanIn(rowsChans []RowsChan) RowsChan {
+	merged := make(chan []string, defaultChanBufferSize)
+	var wg sync.WaitGroup
+	wg.Add(len(rowsChans))
+	for i := 0; i < len(rowsChans); i++ {
+		go func(rowsChan RowsChan) {
+			defer wg.Done()
+			for row := range rowsChan {
+				merged <- row
+			}
+		}(rowsChans[i])
+	}
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+
+	return merged
+}
+
+// Process reads the file and calls handler for each row, from one goroutine per rows channel,
+// and blocks until all the rows were handled. It saves the usual [sync.WaitGroup] plumbing
+// over [CsvReader.Read] channels, see [Consume] for a configurable pool of workers or batches of rows.
+// It returns all the errors of the read and the ones returned by handler, joined with [errors.Join],
+// or nil if there was no error. Rows are still passed to handler after it failed; if the context is done,
+// the remaining rows are drained without being passed to it.
+func (cr *CsvReader) Process(ctx context.Context, handler func(record []string) error) error {
+	rowsChans, errsChan := cr.Read(ctx)
+
+	return Consume(ctx, rowsChans, errsChan, ConsumeOptions{}, func(_ context.Context, rows [][]string) error {
+		return handler(rows[0])
+	})
+}
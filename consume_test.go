@@ -0,0 +1,194 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestConsume(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default options", testConsumeWithOptions(bigcsvreader.ConsumeOptions{}))
+	t.Run("more workers and batches", testConsumeWithOptions(bigcsvreader.ConsumeOptions{WorkersNo: 7, BatchSize: 10}))
+	t.Run("one worker", testConsumeWithOptions(bigcsvreader.ConsumeOptions{WorkersNo: 1, BatchSize: 3}))
+	t.Run("reader errors are aggregated", testConsumeWithReaderErrors)
+	t.Run("stop on handler error", testConsumeStopOnError)
+}
+
+func testConsumeWithOptions(opts bigcsvreader.ConsumeOptions) func(t *testing.T) {
+	return func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 1e4
+		fName, err := setUpTmpCsvFile(rowsCount)
+		if err != nil {
+			t.Fatalf("prerequisite failed: could not generate CSV file: %v", err)
+		}
+		defer tearDownTmpCsvFile(fName)
+		subject := bigcsvreader.New()
+		subject.SetFilePath(fName)
+		subject.ColumnsCount = 5
+		subject.MaxGoroutinesNo = 4
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+		var sumIDs, batchesCount int64
+		expectedMaxBatchSize := opts.BatchSize
+		if expectedMaxBatchSize == 0 {
+			expectedMaxBatchSize = 1
+		}
+
+		// act
+		rowsChans, errsChan := subject.Read(ctx)
+		err = bigcsvreader.Consume(ctx, rowsChans, errsChan, opts, func(_ context.Context, rows [][]string) error {
+			assertTrue(t, len(rows) > 0 && len(rows) <= expectedMaxBatchSize)
+			atomic.AddInt64(&batchesCount, 1)
+			for _, row := range rows {
+				id, _ := strconv.ParseInt(row[colID], 10, 64)
+				atomic.AddInt64(&sumIDs, id)
+			}
+
+			return nil
+		})
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, int64(rowsCount*(rowsCount+1)/2), sumIDs)
+		assertTrue(t, batchesCount >= rowsCount/int64(expectedMaxBatchSize))
+	}
+}
+
+func testConsumeWithReaderErrors(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/invalid_row.csv")
+	subject.ColumnsCount = 3
+	subject.FileHasHeader = true
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+	var rowsCount int64
+	var expectedErr *csv.ParseError
+
+	// act
+	rowsChans, errsChan := subject.Read(ctx)
+	err := bigcsvreader.Consume(ctx, rowsChans, errsChan, bigcsvreader.ConsumeOptions{}, func(_ context.Context, rows [][]string) error {
+		atomic.AddInt64(&rowsCount, int64(len(rows)))
+
+		return nil
+	})
+
+	// assert
+	assertTrue(t, errors.As(err, &expectedErr))
+	assertEqual(t, int64(4), rowsCount)
+}
+
+func testConsumeStopOnError(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/file_without_header.csv")
+	subject.ColumnsCount = 3
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+	var callsCount int64
+	handlerErr := errors.New("intentionally triggered handler error")
+
+	// act
+	rowsChans, errsChan := subject.Read(ctx)
+	err := bigcsvreader.Consume(
+		ctx,
+		rowsChans,
+		errsChan,
+		bigcsvreader.ConsumeOptions{StopOnError: true},
+		func(context.Context, [][]string) error {
+			atomic.AddInt64(&callsCount, 1)
+
+			return handlerErr
+		},
+	)
+
+	// assert
+	assertTrue(t, errors.Is(err, handlerErr))
+	assertEqual(t, int64(1), callsCount)
+}
+
+func TestCsvReader_Process(t *testing.T) {
+	t.Parallel()
+
+	t.Run("all rows are handled", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 1e4
+		fName, err := setUpTmpCsvFile(rowsCount)
+		if err != nil {
+			t.Fatalf("prerequisite failed: could not generate CSV file: %v", err)
+		}
+		defer tearDownTmpCsvFile(fName)
+		subject := bigcsvreader.New()
+		subject.SetFilePath(fName)
+		subject.ColumnsCount = 5
+		subject.MaxGoroutinesNo = 8
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+		var handledCount int64
+
+		// act
+		err = subject.Process(ctx, func(row []string) error {
+			if len(row) == 5 {
+				atomic.AddInt64(&handledCount, 1)
+			}
+
+			return nil
+		})
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, int64(rowsCount), handledCount)
+	})
+
+	t.Run("read and handler errors are aggregated", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/invalid_row.csv")
+		subject.ColumnsCount = 3
+		subject.FileHasHeader = true
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+		var handledCount int64
+		handlerErr := errors.New("intentionally triggered handler error")
+		var expectedErr *csv.ParseError
+
+		// act
+		err := subject.Process(ctx, func(row []string) error {
+			atomic.AddInt64(&handledCount, 1)
+			if row[1] == "Jane" {
+				return handlerErr
+			}
+
+			return nil
+		})
+
+		// assert
+		assertTrue(t, errors.As(err, &expectedErr))
+		assertTrue(t, errors.Is(err, handlerErr))
+		assertEqual(t, int64(4), handledCount)
+	})
+}
@@ -0,0 +1,222 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrControlMismatch is the error a [*ControlMismatchError] wraps.
+var ErrControlMismatch = errors.New("file does not match its control file")
+
+// ControlOptions configures the validation of the CSV file against its companion control file,
+// see [CsvReader.Control].
+type ControlOptions struct {
+	// Path is the path of the control file.
+	// Defaults to CSV file path suffixed with ".ctl".
+	Path string
+	// Parse can be set to parse control files having a custom format.
+	// Defaults to [ParseControl].
+	Parse func(content []byte) (Control, error)
+}
+
+// Control holds what a control file states about its CSV file.
+type Control struct {
+	// RowsCount is the expected number of rows (header excluded), or -1 if not stated.
+	RowsCount int64
+	// SHA256 is the expected hex encoded SHA-256 checksum of the whole file, if stated.
+	SHA256 string
+	// MD5 is the expected hex encoded MD5 checksum of the whole file, if stated.
+	MD5 string
+}
+
+// ParseControl parses a control file made of "key=value" (or "key: value") lines.
+// Keys are case insensitive: "rows" (or "count", "records") states the rows count,
+// "sha256" and "md5" state the file's checksums. Blank lines, lines starting with #
+// and unknown keys are disregarded.
+func ParseControl(content []byte) (Control, error) {
+	control := Control{RowsCount: -1}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			key, value, found = strings.Cut(line, ":")
+		}
+		if !found {
+			return Control{}, fmt.Errorf("line %d is not a key value pair", lineNo)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "rows", "count", "records":
+			count, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || count < 0 {
+				return Control{}, fmt.Errorf("line %d has an invalid rows count %q", lineNo, value)
+			}
+			control.RowsCount = count
+		case "sha256":
+			control.SHA256 = strings.ToLower(value)
+		case "md5":
+			control.MD5 = strings.ToLower(value)
+		}
+	}
+
+	return control, scanner.Err()
+}
+
+// ControlMismatchError is the error sent through ErrsChan when the file does not match its control file.
+type ControlMismatchError struct {
+	// Field is the mismatching field: "rows", "sha256" or "md5".
+	Field string
+	// Expected is the value stated by the control file.
+	Expected string
+	// Actual is the value of the file.
+	Actual string
+}
+
+// Error returns the error's message.
+func (e *ControlMismatchError) Error() string {
+	return fmt.Sprintf("%s: %s expected %s, got %s", ErrControlMismatch, e.Field, e.Expected, e.Actual)
+}
+
+// Unwrap returns [ErrControlMismatch].
+func (e *ControlMismatchError) Unwrap() error {
+	return ErrControlMismatch
+}
+
+// readControl reads and parses the control file.
+func (cr *CsvReader) readControl() (*Control, error) {
+	controlPath := cr.Control.Path
+	if controlPath == "" {
+		controlPath = cr.filePath + ".ctl"
+	}
+	f, err := cr.FS.Open(controlPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	parse := cr.Control.Parse
+	if parse == nil {
+		parse = ParseControl
+	}
+	control, err := parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &control, nil
+}
+
+// checksumAsync computes, in a separate goroutine, the checksums the control file states, reading the whole file.
+// The returned channel delivers the hex encoded checksums, by control field.
+func (cr *CsvReader) checksumAsync(rn *Run) <-chan map[string]string {
+	sums := make(chan map[string]string, 1)
+	hashes := make(map[string]hash.Hash, 2)
+	if rn.control.SHA256 != "" {
+		hashes["sha256"] = sha256.New()
+	}
+	if rn.control.MD5 != "" {
+		hashes["md5"] = md5.New()
+	}
+	if len(hashes) == 0 {
+		sums <- nil
+
+		return sums
+	}
+
+	go func() {
+		filePath := cr.filePath
+		if rn.pin != nil {
+			filePath = rn.pin.path
+		}
+		f, err := rawFS(cr.FS).Open(filePath) // checksums are computed on the file as delivered.
+		if err == nil {
+			writers := make([]io.Writer, 0, len(hashes))
+			for _, h := range hashes {
+				writers = append(writers, h)
+			}
+			_, err = io.Copy(io.MultiWriter(writers...), f)
+			_ = f.Close()
+		}
+		if err != nil {
+			rn.fail()
+			if rn.sendErr(fmt.Errorf("bigcsvreader: could not compute file checksum (%w)", err)) {
+				cr.Logger.Error("msg", "could not compute file checksum", "err", err, "file", cr.fileBaseName)
+			}
+			sums <- nil
+
+			return
+		}
+		computed := make(map[string]string, len(hashes))
+		for field, h := range hashes {
+			computed[field] = hex.EncodeToString(h.Sum(nil))
+		}
+		sums <- computed
+	}()
+
+	return sums
+}
+
+// checkControl compares the rows read and the file checksums against the control file,
+// if the whole file was read, and sends a [*ControlMismatchError] for each mismatch.
+func (cr *CsvReader) checkControl(ctx context.Context, rn *Run, sums map[string]string) {
+	if ctx.Err() != nil || rn.isTimedOut() || rn.isLimitReached() || rn.errorRate.isAborted() {
+		return // reading stopped early, rows count is partial.
+	}
+	var mismatches []*ControlMismatchError
+	if rn.control.RowsCount >= 0 {
+		var records int64
+		for i := range rn.threads {
+			records += rn.threads[i].records()
+		}
+		if records != rn.control.RowsCount {
+			mismatches = append(mismatches, &ControlMismatchError{
+				Field:    "rows",
+				Expected: strconv.FormatInt(rn.control.RowsCount, 10),
+				Actual:   strconv.FormatInt(records, 10),
+			})
+		}
+	}
+	for _, check := range [...]struct{ field, expected string }{
+		{field: "sha256", expected: rn.control.SHA256},
+		{field: "md5", expected: rn.control.MD5},
+	} {
+		if actual, computed := sums[check.field]; computed && !strings.EqualFold(actual, check.expected) {
+			mismatches = append(mismatches, &ControlMismatchError{
+				Field:    check.field,
+				Expected: check.expected,
+				Actual:   actual,
+			})
+		}
+	}
+
+	if len(mismatches) > 0 {
+		rn.fail()
+	}
+	for _, err := range mismatches {
+		if rn.sendErr(fmt.Errorf("bigcsvreader: control check failed (%w)", err)) {
+			cr.Logger.Error("msg", "control check failed", "err", err, "file", cr.fileBaseName)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestParseControl(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name            string
+		content         string
+		expectedControl bigcsvreader.Control
+		expectedErr     bool
+	}{
+		{
+			name:            "all fields",
+			content:         "# daily drop\nROWS=10\nsha256 = ABCD\n\nmd5: ef01\nsender=bank\n",
+			expectedControl: bigcsvreader.Control{RowsCount: 10, SHA256: "abcd", MD5: "ef01"},
+		},
+		{
+			name:            "rows count only",
+			content:         "count: 0",
+			expectedControl: bigcsvreader.Control{RowsCount: 0},
+		},
+		{
+			name:            "checksum only",
+			content:         "sha256=abcd",
+			expectedControl: bigcsvreader.Control{RowsCount: -1, SHA256: "abcd"},
+		},
+		{
+			name:        "invalid rows count",
+			content:     "rows=ten",
+			expectedErr: true,
+		},
+		{
+			name:        "not a key value pair",
+			content:     "rows=10\n42",
+			expectedErr: true,
+		},
+	}
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			control, err := bigcsvreader.ParseControl([]byte(test.content))
+
+			// assert
+			assertEqual(t, test.expectedErr, err != nil)
+			assertEqual(t, test.expectedControl, control)
+		})
+	}
+}
+
+func TestCsvReader_Control(t *testing.T) {
+	t.Parallel()
+
+	const rowsCount = 600 // big enough for several goroutines.
+	var content strings.Builder
+	content.WriteString("id,name,stock\n")
+	for i := 1; i <= rowsCount; i++ {
+		fmt.Fprintf(&content, "%d,\"name %d\",%d\n", i, i, i*10)
+	}
+	sha256Sum := sha256.Sum256([]byte(content.String()))
+	md5Sum := md5.Sum([]byte(content.String()))
+	sha256Hex, md5Hex := hex.EncodeToString(sha256Sum[:]), hex.EncodeToString(md5Sum[:])
+
+	tests := [...]struct {
+		name               string
+		control            string
+		filter             string
+		expectedMismatches []bigcsvreader.ControlMismatchError
+	}{
+		{
+			name:    "matching control file",
+			control: fmt.Sprintf("rows=%d\nsha256=%s\nmd5=%s\n", rowsCount, strings.ToUpper(sha256Hex), md5Hex),
+		},
+		{
+			name:    "rows are counted regardless of filter",
+			control: fmt.Sprintf("rows=%d\n", rowsCount),
+			filter:  `id == "1"`,
+		},
+		{
+			name:    "mismatching control file",
+			control: fmt.Sprintf("rows=%d\nsha256=%s\nmd5=%s\n", rowsCount+1, strings.Repeat("0", 64), md5Hex),
+			expectedMismatches: []bigcsvreader.ControlMismatchError{
+				{Field: "rows", Expected: fmt.Sprint(rowsCount + 1), Actual: fmt.Sprint(rowsCount)},
+				{Field: "sha256", Expected: strings.Repeat("0", 64), Actual: sha256Hex},
+			},
+		},
+	}
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			dir := t.TempDir()
+			subject := bigcsvreader.New()
+			subject.SetFilePath(writeTmpFile(t, dir, "drop.csv", content.String()))
+			writeTmpFile(t, dir, "drop.csv.ctl", test.control)
+			subject.ColumnsCount = 3
+			subject.FileHasHeader = true
+			subject.MaxGoroutinesNo = 3
+			subject.Filter = test.filter
+			subject.Control = &bigcsvreader.ControlOptions{}
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			rowsChans, errsChan := subject.Read(ctx)
+			drainRows(rowsChans)
+			var mismatches []bigcsvreader.ControlMismatchError
+			for err := range errsChan {
+				var mismatchErr *bigcsvreader.ControlMismatchError
+				if assertTrue(t, errors.As(err, &mismatchErr)) {
+					assertTrue(t, errors.Is(err, bigcsvreader.ErrControlMismatch))
+					mismatches = append(mismatches, *mismatchErr)
+				}
+			}
+
+			// assert
+			assertEqual(t, test.expectedMismatches, mismatches)
+		})
+	}
+
+	t.Run("custom control file", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		dir := t.TempDir()
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, dir, "drop.csv", content.String()))
+		subject.ColumnsCount = 3
+		subject.FileHasHeader = true
+		subject.Control = &bigcsvreader.ControlOptions{
+			Path: writeTmpFile(t, dir, "drop.trailer", fmt.Sprintf("TRL|%d", rowsCount-1)),
+			Parse: func(content []byte) (bigcsvreader.Control, error) {
+				var control bigcsvreader.Control
+				_, err := fmt.Sscanf(string(content), "TRL|%d", &control.RowsCount)
+
+				return control, err
+			},
+		}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rowsChans, errsChan := subject.Read(ctx)
+		drainRows(rowsChans)
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		var mismatchErr *bigcsvreader.ControlMismatchError
+		if assertTrue(t, errors.As(err, &mismatchErr)) {
+			assertEqual(t, "rows", mismatchErr.Field)
+		}
+	})
+
+	t.Run("invalid control file", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		dir := t.TempDir()
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, dir, "drop.csv", content.String()))
+		subject.ColumnsCount = 3
+		subject.Control = &bigcsvreader.ControlOptions{} // file does not exist.
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rowsChans, errsChan := subject.Read(ctx)
+		rowsRead := drainRows(rowsChans)
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertEqual(t, 0, rowsRead)
+		if assertNotNil(t, err) {
+			assertTrue(t, strings.Contains(err.Error(), "invalid control file"))
+		}
+	})
+}
@@ -0,0 +1,76 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+)
+
+// ErrQuotedLineBreakAtChunkBoundary is the error sent through ErrsChan when, with [CsvReader.CRLFInQuotedFields]
+// enabled, a quoted field's \r\n falls right where the next goroutine started reading from,
+// so that goroutine's first row(s) are not reliable.
+// It is not sent when the goroutines' start offsets were checked ahead of reading for lying inside quoted fields,
+// which is the case whenever the file is read by several goroutines.
+var ErrQuotedLineBreakAtChunkBoundary = errors.New("quoted field line break at goroutines chunks boundary")
+
+// errUnsupportedWithQuotedLineBreaks is the error returned when a feature locating rows by line breaks
+// is used together with [CsvReader.CRLFInQuotedFields] or [CsvReader.MultilineQuotedFields].
+var errUnsupportedWithQuotedLineBreaks = errors.New("not supported with line breaks in quoted fields")
+
+// readQuotedLine reads a line like readLine does, and, while it ends inside a quoted field
+// (see [CsvReader.continuesRecord]), appends the next line to it. inQuotes tells whether
+// the line starts inside a quoted field. Joined lines are copied into lh's buffer, as [bufio.Reader.ReadSlice]
+// invalidates the previously returned slice. The joined record must fit into [CsvReader.BufferSize]
+// (or [CsvReader.MaxLineSize], see [CsvReader.AutoGrowBuffer]).
+// It also returns the number of line breaks found inside quoted fields, and the (relative) index
+// of the last one, -1 if none.
+func (cr *CsvReader) readQuotedLine(
+	rn *Run,
+	lh *lineHandler,
+	r *bufio.Reader,
+	offsetPos int,
+	inQuotes bool,
+) ([]byte, int, int) {
+	line := cr.readLine(rn, r, lh.thread, offsetPos)
+	if line == nil || !cr.continuesRecord(line, inQuotes) {
+		return line, 0, -1
+	}
+
+	lh.joined = append(lh.joined[:0], line...)
+	breaks, lastBreak := 0, -1
+	for cr.continuesRecord(lh.joined, inQuotes) {
+		breaks++
+		lastBreak = len(lh.joined) - 1
+		line = cr.readLine(rn, r, lh.thread, offsetPos+len(lh.joined))
+		if line == nil {
+			break // EOF (or error already sent), let the CSV parser report the unterminated field.
+		}
+		if len(lh.joined)+len(line) > cr.maxRecordSize() {
+			cr.sendReadErr(rn, lh.thread, offsetPos, ErrRecordTooLong)
+
+			return nil, 0, -1
+		}
+		lh.joined = append(lh.joined, line...)
+	}
+
+	return lh.joined, breaks, lastBreak
+}
+
+// continuesRecord checks whether the record continues on the next line, given the record's lines read so far,
+// and whether they start inside a quoted field: with [CsvReader.MultilineQuotedFields], if they end inside
+// a quoted field, otherwise, if they end with \r\n inside a quoted field.
+// Lines starting inside a quoted field with a lone \n are the tail of a \r\n line break, split
+// between two goroutines' chunks (the \r being the previous chunk's last byte).
+func (cr *CsvReader) continuesRecord(lines []byte, inQuotes bool) bool {
+	if cr.MultilineQuotedFields {
+		return endsInsideQuotedField(lines, inQuotes)
+	}
+
+	return endsInsideQuotedField(lines, inQuotes) &&
+		(bytes.HasSuffix(lines, []byte("\r\n")) || inQuotes && len(lines) == 1 && lines[0] == '\n')
+}
@@ -0,0 +1,168 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_CRLFInQuotedFields(t *testing.T) {
+	t.Parallel()
+
+	// a file exported by Excel, with occasional line breaks inside cells.
+	const rowsCount = 500
+	var sb strings.Builder
+	sb.WriteString("id,note\r\n")
+	for i := 1; i <= rowsCount; i++ {
+		note := "note " + strconv.Itoa(i)
+		if i%50 == 0 {
+			note = `"first line` + "\r\n" + `second ""line"""`
+		}
+		sb.WriteString(strconv.Itoa(i) + "," + note + "\r\n")
+	}
+	filePath := filepath.Join(t.TempDir(), "excel.csv")
+	if err := os.WriteFile(filePath, []byte(sb.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("line breaks inside quoted fields are part of values", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 1
+		subject.CRLFInQuotedFields = true
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rn := subject.Start(ctx)
+		rows := gatherRowsInOrder(rn.RowsChans())
+
+		// assert
+		assertNil(t, rn.Err())
+		if assertEqual(t, rowsCount, len(rows)) {
+			assertEqual(t, []string{"49", "note 49"}, rows[48])
+			assertEqual(t, []string{"50", "first line\nsecond \"line\""}, rows[49])
+			assertEqual(t, []string{"500", "first line\nsecond \"line\""}, rows[499])
+		}
+	})
+
+	t.Run("line breaks inside quoted fields end rows if not enabled", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 1
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rn := subject.Start(ctx)
+		rows := gatherRowsInOrder(rn.RowsChans())
+
+		// assert
+		assertNotNil(t, rn.Err())
+		assertEqual(t, rowsCount-rowsCount/50, len(rows))
+	})
+
+	t.Run("line breaks inside quoted fields are part of values, several goroutines", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 5000
+		var sb strings.Builder
+		for i := 1; i <= rowsCount; i++ {
+			note := "note " + strconv.Itoa(i)
+			if i%3 == 0 {
+				note = `"a` + "\r\n" + `b ` + strconv.Itoa(i) + `"`
+			}
+			sb.WriteString(strconv.Itoa(i) + "," + note + "\r\n")
+		}
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, t.TempDir(), "crlf.csv", sb.String()))
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 6
+		subject.CRLFInQuotedFields = true
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rn := subject.Start(ctx)
+		rows := gatherRowsInOrder(rn.RowsChans())
+
+		// assert
+		assertNil(t, rn.Err())
+		assertEqual(t, 6, len(rn.RowsChans()))
+		if assertEqual(t, rowsCount, len(rows)) {
+			for i, row := range rows {
+				want := "note " + strconv.Itoa(i+1)
+				if (i+1)%3 == 0 {
+					want = "a\nb " + strconv.Itoa(i+1)
+				}
+				if !assertEqual(t, []string{strconv.Itoa(i + 1), want}, row) {
+					break
+				}
+			}
+		}
+	})
+
+	t.Run("line break at goroutines chunks boundary", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange: 4096 bytes file read by 2 goroutines, the 2nd one starting at offset 2048,
+		// where a quoted field's line break is.
+		const fileSize, boundary = 4096, 2048
+		var sb strings.Builder
+		for sb.Len() < boundary-100 {
+			sb.WriteString("1,abc\r\n")
+		}
+		sb.WriteString(`2,"` + strings.Repeat("x", boundary-sb.Len()-len(`2,"`)-1) + "\r\n" + `y"` + "\r\n")
+		filler := fileSize - sb.Len() - len("3,\r\n")
+		sb.WriteString("3," + strings.Repeat("z", filler) + "\r\n")
+		content := sb.String()
+		if len(content) != fileSize || content[boundary] != '\n' {
+			t.Fatal("prerequisite failed: unexpected file content")
+		}
+		boundaryFilePath := filepath.Join(t.TempDir(), "boundary.csv")
+		if err := os.WriteFile(boundaryFilePath, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		subject := bigcsvreader.New()
+		subject.SetFilePath(boundaryFilePath)
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 2
+		subject.CRLFInQuotedFields = true
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rn := subject.Start(ctx)
+		rows := gatherRowsInOrder(rn.RowsChans())
+
+		// assert
+		assertNil(t, rn.Err())
+		if assertEqual(t, (boundary-100)/7+3, len(rows)) {
+			assertEqual(t, "2", rows[len(rows)-2][0])
+			assertTrue(t, strings.HasSuffix(rows[len(rows)-2][1], "x\ny"))
+			assertEqual(t, "3", rows[len(rows)-1][0])
+		}
+	})
+}
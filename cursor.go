@@ -0,0 +1,165 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// Cursor iterates, row by row, over the rows read by a [CsvReader], see [CsvReader.ReadCursor].
+// It implements [driver.Rows], so reporting tools and ORMs consuming row cursors can read the file directly.
+// Values are strings.
+// A cursor is not safe for concurrent use.
+type Cursor struct {
+	columns  []string
+	rowsChan <-chan []string
+	errsChan ErrsChan
+	cancel   context.CancelFunc
+	err      error
+}
+
+// ReadCursor extracts asynchronously CSV rows, like [CsvReader.Read] does, and returns a cursor over them.
+// Columns are named after the header, if [CsvReader.FileHasHeader] is enabled, or "c1", "c2"... otherwise
+// (their count being [CsvReader.ColumnsCount], or the first record's fields count, if not set).
+// Rows of all goroutines are merged, so they follow the file's order only with one goroutine
+// (see [CsvReader.MaxGoroutinesNo]). [CsvReader.EmitHeader] is not applied.
+// The cursor stops at the first error, which [Cursor.Next] returns. It must be closed.
+func (cr *CsvReader) ReadCursor(ctx context.Context) (*Cursor, error) {
+	first, err := cr.ReadHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var columns []string
+	if cr.FileHasHeader {
+		columns = first
+	} else {
+		count := cr.ColumnsCount
+		if count <= 0 {
+			count = len(first)
+		}
+		columns = make([]string, count)
+		for col := range columns {
+			columns[col] = "c" + strconv.Itoa(col+1)
+		}
+	}
+
+	if cr.EmitHeader {
+		reader := cr.clone()
+		reader.Manifest = cr.Manifest
+		reader.EmitHeader = false
+		cr = reader
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	rowsChans, errsChan := cr.Read(ctx)
+	var (
+		outChan = make(chan []string, cr.chanBufferSize(0, 0))
+		wg      sync.WaitGroup
+	)
+	wg.Add(len(rowsChans))
+	for _, rowsChan := range rowsChans {
+		go func(rowsChan RowsChan) {
+			defer wg.Done()
+			for row := range rowsChan {
+				outChan <- row
+			}
+		}(rowsChan)
+	}
+	go func() {
+		wg.Wait()
+		close(outChan)
+	}()
+
+	return &Cursor{
+		columns:  columns,
+		rowsChan: outChan,
+		errsChan: errsChan,
+		cancel:   cancel,
+	}, nil
+}
+
+// Columns returns the names of the columns.
+func (c *Cursor) Columns() []string {
+	return c.columns
+}
+
+// ColumnTypeScanType returns the type values of given column can be scanned into, which is string.
+// It implements [driver.RowsColumnTypeScanType].
+func (c *Cursor) ColumnTypeScanType(int) reflect.Type {
+	return reflect.TypeOf("")
+}
+
+// Next populates dest with the next row's values (missing fields being nil, extra fields being disregarded).
+// It returns [io.EOF] when there are no more rows, or the first error received while reading.
+func (c *Cursor) Next(dest []driver.Value) error {
+	if c.err != nil {
+		return c.err
+	}
+	for {
+		select {
+		case err, ok := <-c.errsChan:
+			if !ok {
+				c.errsChan = nil
+
+				continue
+			}
+			c.err = err
+
+			return err
+		case row, ok := <-c.rowsChan:
+			if !ok {
+				// the errors channel is closed after the rows channels.
+				if c.errsChan != nil {
+					if err, ok := <-c.errsChan; ok {
+						c.err = err
+
+						return err
+					}
+				}
+				c.err = io.EOF
+
+				return io.EOF
+			}
+			for i := range dest {
+				if i < len(row) {
+					dest[i] = row[i]
+				} else {
+					dest[i] = nil
+				}
+			}
+
+			return nil
+		}
+	}
+}
+
+// Close stops reading, if not done yet, and releases the cursor's resources.
+// It is safe to call it several times.
+func (c *Cursor) Close() error {
+	c.cancel()
+	if c.err == nil {
+		c.err = io.EOF
+	}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range c.rowsChan { // drain rows left unconsumed.
+		}
+	}()
+	if c.errsChan != nil {
+		for range c.errsChan {
+		}
+	}
+	wg.Wait()
+	c.errsChan = nil
+
+	return nil
+}
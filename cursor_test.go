@@ -0,0 +1,152 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ReadCursor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rows are iterated", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		subject.FileHasHeader = true
+		subject.EmitHeader = true
+		subject.ColumnsCount = 3
+		subject.ColumnsDelimiter = ';'
+		subject.MaxGoroutinesNo = 1
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		cursor, err := subject.ReadCursor(ctx)
+
+		// assert
+		if !assertNil(t, err) {
+			return
+		}
+		var rows driver.Rows = cursor
+		defer rows.Close()
+		assertEqual(t, []string{"ID", "Name", "Age"}, rows.Columns())
+		var (
+			dest  = make([]driver.Value, len(rows.Columns()))
+			names []driver.Value
+		)
+		for {
+			err = rows.Next(dest)
+			if err != nil {
+				break
+			}
+			names = append(names, dest[1])
+		}
+		assertTrue(t, errors.Is(err, io.EOF))
+		assertEqual(t, []driver.Value{"John", "Jane", "Mike", "Ronaldinho", "Elisabeth"}, names)
+		assertNil(t, rows.Close())
+		assertTrue(t, errors.Is(rows.Next(dest), io.EOF))
+	})
+
+	t.Run("columns of a file without header", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_without_header.csv")
+
+		// act
+		cursor, err := subject.ReadCursor(context.Background())
+
+		// assert
+		if assertNil(t, err) {
+			assertEqual(t, []string{"c1", "c2", "c3"}, cursor.Columns())
+			assertNil(t, cursor.Close())
+		}
+	})
+
+	t.Run("first error stops the cursor", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, t.TempDir(), "invalid.csv", "1,a\n2,b,extra\n3,c\n"))
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 1
+		dest := make([]driver.Value, 2)
+
+		// act
+		cursor, err := subject.ReadCursor(context.Background())
+
+		// assert
+		if !assertNil(t, err) {
+			return
+		}
+		defer cursor.Close()
+		for err == nil {
+			err = cursor.Next(dest)
+		}
+		if assertNotNil(t, err) {
+			assertTrue(t, !errors.Is(err, io.EOF))
+			assertEqual(t, err, cursor.Next(dest))
+		}
+	})
+
+	t.Run("closing early stops reading", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var content strings.Builder
+		for i := 1; i <= 20000; i++ {
+			content.WriteString(strconv.Itoa(i) + ",value\n")
+		}
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, t.TempDir(), "big.csv", content.String()))
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 4
+		cursor, err := subject.ReadCursor(context.Background())
+		if !assertNil(t, err) {
+			return
+		}
+		dest := make([]driver.Value, 2)
+
+		// act
+		errNext := cursor.Next(dest)
+		errClose := cursor.Close()
+
+		// assert
+		assertNil(t, errNext)
+		assertNil(t, errClose)
+		assertNil(t, cursor.Close())
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/not_found.csv")
+
+		// act
+		cursor, err := subject.ReadCursor(context.Background())
+
+		// assert
+		assertTrue(t, cursor == nil)
+		assertTrue(t, errors.Is(err, os.ErrNotExist))
+	})
+}
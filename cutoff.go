@@ -0,0 +1,50 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrTooManyErrorsInChunk is the error sent through ErrsChan when a goroutine stopped reading its chunk
+// because [CsvReader.MaxErrorsPerChunk] rows of it could not be parsed.
+var ErrTooManyErrorsInChunk = errors.New("too many parse errors in chunk")
+
+// isCutOff returns whether the goroutine reached [CsvReader.MaxErrorsPerChunk] parse errors.
+func (cr *CsvReader) isCutOff(lh *lineHandler) bool {
+	return cr.MaxErrorsPerChunk > 0 && lh.parseErrs >= cr.MaxErrorsPerChunk
+}
+
+// cutOff records the range of the chunk the goroutine skips, from given offset to offsetEnd
+// (up to the end of the line spanning over offsetEnd+1), as it reached [CsvReader.MaxErrorsPerChunk] parse errors.
+func (cr *CsvReader) cutOff(rn *Run, lh *lineHandler, from, offsetEnd int) {
+	lh.stats.skipFrom(from)
+	rn.fail()
+	err := fmt.Errorf("%w: %d rows could not be parsed", ErrTooManyErrorsInChunk, lh.parseErrs)
+	if rn.sendErr(fmt.Errorf(
+		"bigcsvreader: thread #%d skipped range from offset %d to chunk's end offset %d (%w)",
+		lh.thread, from, offsetEnd, err,
+	)) {
+		cr.Logger.Error(
+			"msg", "skipped rest of chunk", "err", err,
+			"file", cr.fileBaseName, "thread", lh.thread,
+			"offset", from, "offsetEnd", offsetEnd,
+		)
+	}
+	cr.quarantineRest(rn, lh, from, offsetEnd, ErrTooManyErrorsInChunk)
+}
+
+// skipFrom marks the goroutine as having skipped the rest of its chunk, starting with the line at given offset.
+func (ts *threadStats) skipFrom(offset int) {
+	atomic.StoreInt64(&ts.skippedOffset, int64(offset))
+}
+
+// skipped returns the offset of the first line of the rest of the chunk the goroutine skipped, -1 otherwise.
+func (ts *threadStats) skipped() int {
+	return int(atomic.LoadInt64(&ts.skippedOffset))
+}
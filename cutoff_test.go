@@ -0,0 +1,101 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_MaxErrorsPerChunk(t *testing.T) {
+	t.Parallel()
+
+	// rows 350 to 449, in the middle of the second chunk, are corrupted.
+	var content strings.Builder
+	for i := 1; i <= 900; i++ {
+		if i >= 350 && i < 450 {
+			fmt.Fprintf(&content, "%d,\"bad \"quote\",x\n", i)
+		} else {
+			fmt.Fprintf(&content, "%d,\"name %d\",x\n", i, i)
+		}
+	}
+
+	tests := [...]struct {
+		name                 string
+		maxErrorsPerChunk    int
+		expectedRowsCount    int
+		expectedErrsCount    int
+		expectedSkippedRow   int // the first row skipped by the second goroutine, 0 if none.
+		expectedSkippedError bool
+	}{
+		{
+			name:              "no limit",
+			expectedRowsCount: 800,
+			expectedErrsCount: 100,
+		},
+		{
+			name:              "limit not reached",
+			maxErrorsPerChunk: 101,
+			expectedRowsCount: 800,
+			expectedErrsCount: 100,
+		},
+		{
+			name:                 "limit reached",
+			maxErrorsPerChunk:    5,
+			expectedRowsCount:    800 - 150, // rows 450 to 599 of the second chunk are skipped too.
+			expectedErrsCount:    5 + 1,
+			expectedSkippedRow:   355,
+			expectedSkippedError: true,
+		},
+	}
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(writeTmpFile(t, t.TempDir(), "corrupted.csv", content.String()))
+			subject.ColumnsCount = 3
+			subject.MaxGoroutinesNo = 3
+			subject.MaxErrorsPerChunk = test.maxErrorsPerChunk
+			summaries := make(chan bigcsvreader.Summary, 1)
+			subject.OnComplete = func(summary bigcsvreader.Summary) {
+				summaries <- summary
+			}
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			run := subject.Start(ctx)
+			rowsCount := drainRows(run.RowsChans())
+			_ = run.Err()
+			summary := <-summaries
+
+			// assert
+			secondChunk := summary.Threads[1]
+			if test.expectedSkippedRow == 0 {
+				assertEqual(t, test.expectedRowsCount, rowsCount)
+				assertEqual(t, -1, secondChunk.SkippedOffset)
+			} else {
+				skippedOffset := strings.Index(content.String(), fmt.Sprintf("\n%d,", test.expectedSkippedRow)) + 1
+				assertEqual(t, skippedOffset, secondChunk.SkippedOffset)
+				// the second chunk ends around row 600.
+				assertTrue(t, rowsCount > test.expectedRowsCount-10 && rowsCount < test.expectedRowsCount+10)
+			}
+			assertEqual(t, test.expectedErrsCount, summary.ErrorsCount)
+			assertEqual(t, test.expectedSkippedError, errors.Is(summary.Err, bigcsvreader.ErrTooManyErrorsInChunk))
+			assertEqual(t, -1, summary.Threads[0].SkippedOffset)
+			assertEqual(t, -1, summary.Threads[2].SkippedOffset)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ErrMaxRunDurationExceeded is the error a [*RunTimeoutError] wraps.
+var ErrMaxRunDurationExceeded = errors.New("max run duration exceeded")
+
+// RunTimeoutError is the error sent through ErrsChan when reading was stopped because [CsvReader.MaxRunDuration] elapsed.
+// It holds the progress made, so a next run can resume reading from where this one stopped.
+type RunTimeoutError struct {
+	// MaxRunDuration is the duration which elapsed.
+	MaxRunDuration time.Duration
+	// RowsCount is the number of rows pushed into channels.
+	RowsCount int64
+	// Threads holds each goroutine's progress. See [ThreadSummary.NextOffset] for the offset reading can resume from.
+	Threads []ThreadSummary
+}
+
+// Error returns the error's message.
+func (e *RunTimeoutError) Error() string {
+	return fmt.Sprintf("%s after %s, %d rows read", ErrMaxRunDurationExceeded, e.MaxRunDuration, e.RowsCount)
+}
+
+// Unwrap returns [ErrMaxRunDurationExceeded].
+func (e *RunTimeoutError) Unwrap() error {
+	return ErrMaxRunDurationExceeded
+}
+
+// watchRunDuration flags the run as timed out after [CsvReader.MaxRunDuration] elapsed,
+// unless done is closed before.
+func (cr *CsvReader) watchRunDuration(rn *Run, done <-chan struct{}) {
+	select {
+	case <-rn.clock.After(cr.MaxRunDuration):
+		atomic.StoreInt32(&rn.timedOut, 1)
+		cr.Logger.Debug("msg", "max run duration exceeded", "file", cr.fileBaseName)
+	case <-done:
+	}
+}
+
+// isTimedOut returns true if [CsvReader.MaxRunDuration] elapsed.
+func (rn *Run) isTimedOut() bool {
+	return atomic.LoadInt32(&rn.timedOut) == 1
+}
+
+// timeoutErr returns the [*RunTimeoutError] of the run, or nil if no goroutine stopped because
+// [CsvReader.MaxRunDuration] elapsed. It must be called after all goroutines finished.
+func (rn *Run) timeoutErr(maxRunDuration time.Duration) error {
+	if !rn.isTimedOut() {
+		return nil
+	}
+	threads, rowsCount, _ := rn.threadsSummaries()
+	for _, thread := range threads {
+		if thread.NextOffset >= 0 {
+			return &RunTimeoutError{MaxRunDuration: maxRunDuration, RowsCount: rowsCount, Threads: threads}
+		}
+	}
+
+	return nil // all goroutines finished before the duration elapsed.
+}
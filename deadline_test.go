@@ -0,0 +1,111 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_MaxRunDuration(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reading stops cleanly, with a resumable state", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 3000
+		var sb strings.Builder
+		for i := 1; i <= rowsCount; i++ {
+			sb.WriteString(strconv.Itoa(i) + ",name " + strconv.Itoa(i) + "\n")
+		}
+		content := sb.String()
+		filePath := filepath.Join(t.TempDir(), "slow.csv")
+		if err := os.WriteFile(filePath, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 4
+		subject.ChanBufferSize = 1
+		subject.MaxRunDuration = 50 * time.Millisecond
+		summaries := make(chan bigcsvreader.Summary, 1)
+		subject.OnComplete = func(summary bigcsvreader.Summary) {
+			summaries <- summary
+		}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rn := subject.Start(ctx)
+		var readCount int
+		for _, rowsChan := range rn.RowsChans() {
+			go func(rowsChan bigcsvreader.RowsChan) {
+				for range rowsChan {
+					time.Sleep(time.Millisecond) // slow consumer.
+				}
+			}(rowsChan)
+		}
+		for range rn.ErrsChan() { // errors are collected by Run.Err.
+		}
+		err := rn.Err()
+
+		// assert
+		var timeoutErr *bigcsvreader.RunTimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("expected a run timeout error, got %v", err)
+		}
+		assertTrue(t, errors.Is(err, bigcsvreader.ErrMaxRunDurationExceeded))
+		assertTrue(t, timeoutErr.RowsCount < rowsCount)
+		assertEqual(t, rn.RowsCount(), timeoutErr.RowsCount)
+		assertTrue(t, (<-summaries).TimedOut)
+		// rows read and rows left unread add up to all rows.
+		for _, thread := range timeoutErr.Threads {
+			if thread.NextOffset < 0 {
+				continue
+			}
+			assertTrue(t, thread.NextOffset == 0 || content[thread.NextOffset-1] == '\n')
+			for offset := thread.NextOffset; offset <= thread.OffsetEnd+1 && offset < len(content); offset++ {
+				if offset == 0 || content[offset-1] == '\n' {
+					readCount++
+				}
+			}
+		}
+		assertEqual(t, rowsCount, readCount+int(timeoutErr.RowsCount))
+	})
+
+	t.Run("no error if reading finishes in time", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_without_header.csv")
+		subject.ColumnsCount = 3
+		subject.MaxRunDuration = time.Minute
+		summaries := make(chan bigcsvreader.Summary, 1)
+		subject.OnComplete = func(summary bigcsvreader.Summary) {
+			summaries <- summary
+		}
+
+		// act
+		rn := subject.Start(context.Background())
+		rows := gatherRowsInOrder(rn.RowsChans())
+
+		// assert
+		assertNil(t, rn.Err())
+		assertEqual(t, 5, len(rows))
+		assertTrue(t, !(<-summaries).TimedOut)
+	})
+}
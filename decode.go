@@ -0,0 +1,43 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"errors"
+)
+
+// DecodedChan is the channel where decoded rows will be pushed into.
+type DecodedChan <-chan any
+
+// ReadDecoded extracts asynchronously CSV rows and decodes them with [CsvReader.Decoder]
+// inside the reading goroutines, each started goroutine putting decoded values into a DecodedChan.
+// Rows which could not be decoded are disregarded.
+// Error(s) occurred during parsing / decoding are sent through ErrsChan.
+func (cr *CsvReader) ReadDecoded(ctx context.Context) ([]DecodedChan, ErrsChan) {
+	var rn *Run
+	if cr.Decoder == nil {
+		rn = cr.failedRun(make(chan error, 1), "invalid decoder", errors.New("no decoder registered"))
+	} else {
+		rn = cr.start(ctx, modeDecoded, nil)
+	}
+
+	return rn.DecodedChans(), rn.ErrsChan()
+}
+
+// DecodedChans returns the channels where decoded rows are pushed into, one for each started goroutine,
+// if the run was started by [CsvReader.ReadDecoded].
+func (rn *Run) DecodedChans() []DecodedChan {
+	if len(rn.decodedChans) == 0 {
+		return nil
+	}
+	decodedChans := make([]DecodedChan, len(rn.decodedChans))
+	for i, decodedChan := range rn.decodedChans {
+		decodedChans[i] = decodedChan
+	}
+
+	return decodedChans
+}
@@ -0,0 +1,98 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+type person struct {
+	ID   int
+	Name string
+	Age  int
+}
+
+func decodePerson(row []string) (any, error) {
+	id, err := strconv.Atoi(row[0])
+	if err != nil {
+		return nil, err
+	}
+	age, err := strconv.Atoi(row[2])
+	if err != nil {
+		return nil, err
+	}
+
+	return person{ID: id, Name: row[1], Age: age}, nil
+}
+
+func TestCsvReader_ReadDecoded(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rows are decoded", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.ColumnsDelimiter = ';'
+		subject.Decoder = func(row []string) (any, error) {
+			if row[1] == "Mike" {
+				return nil, errors.New("intentionally triggered decode error")
+			}
+
+			return decodePerson(row)
+		}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		decodedChans, errsChan := subject.ReadDecoded(ctx)
+		var people []person
+		for _, decodedChan := range decodedChans {
+			for value := range decodedChan {
+				people = append(people, value.(person))
+			}
+		}
+		var errs []error
+		for err := range errsChan {
+			errs = append(errs, err)
+		}
+
+		// assert
+		sort.Slice(people, func(i, j int) bool { return people[i].ID < people[j].ID })
+		assertEqual(t, []person{
+			{ID: 1, Name: "John", Age: 33},
+			{ID: 2, Name: "Jane", Age: 30},
+			{ID: 4, Name: "Ronaldinho", Age: 23},
+			{ID: 5, Name: "Elisabeth", Age: 45},
+		}, people)
+		assertEqual(t, 1, len(errs))
+	})
+
+	t.Run("no decoder", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+
+		// act
+		decodedChans, errsChan := subject.ReadDecoded(context.Background())
+
+		// assert
+		assertEqual(t, 0, len(decodedChans))
+		assertNotNil(t, <-errsChan)
+	})
+}
@@ -0,0 +1,59 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"encoding/csv"
+	"sync/atomic"
+)
+
+// paddingParser parses lines with another parser accepting any fields count, and pads records
+// shorter than the expected fields count with [CsvReader.DefaultValues].
+// Records longer than the expected fields count are rejected with [csv.ErrFieldCount].
+type paddingParser struct {
+	parser      lineParser
+	fieldsCount int
+	defaults    []string
+	// padded is the counter of padded records, updated atomically, nil if they are not counted.
+	padded *int64
+}
+
+func (p *paddingParser) parse(line []byte) ([]string, error) {
+	record, err := p.parser.parse(line)
+	if err != nil {
+		return record, err
+	}
+	if len(record) > p.fieldsCount {
+		return record, &csv.ParseError{StartLine: 1, Line: 1, Column: 1, Err: csv.ErrFieldCount}
+	}
+	if len(record) < p.fieldsCount {
+		for col := len(record); col < p.fieldsCount; col++ {
+			var value string
+			if col < len(p.defaults) {
+				value = p.defaults[col]
+			}
+			record = append(record, value)
+		}
+		if p.padded != nil {
+			atomic.AddInt64(p.padded, 1)
+		}
+	}
+
+	return record, nil
+}
+
+// countPadded makes the parser, if it pads records, count them into the run's padded rows.
+func countPadded(parser lineParser, rn *Run) {
+	if p, ok := parser.(*paddingParser); ok {
+		p.padded = &rn.paddedRows
+	}
+}
+
+// PaddedRows returns the number of rows shorter than [CsvReader.ColumnsCount] which were padded with
+// [CsvReader.DefaultValues] so far. It is final after the run is done.
+func (rn *Run) PaddedRows() int64 {
+	return atomic.LoadInt64(&rn.paddedRows)
+}
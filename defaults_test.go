@@ -0,0 +1,89 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_DefaultValues(t *testing.T) {
+	t.Parallel()
+
+	content := "1,John,33,RO\n" +
+		"2,Jane\n" +
+		"3,Mike,18\n" +
+		"4,Ronaldinho,23,BR,extra\n"
+	filePath := writeTmpFile(t, t.TempDir(), "short_rows.csv", content)
+	expectedRows := [][]string{
+		{"1", "John", "33", "RO"},
+		{"2", "Jane", "0", ""},
+		{"3", "Mike", "18", ""},
+	}
+	tests := [...]struct {
+		name    string
+		dialect bool
+	}{
+		{name: "standard parser", dialect: false},
+		{name: "dialect parser", dialect: true},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(filePath)
+			subject.ColumnsCount = 4
+			subject.DefaultValues = []string{"", "", "0"}
+			if test.dialect {
+				subject.NullToken = "NULL"
+			}
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			run := subject.Start(ctx)
+			rows := gatherRowsInOrder(run.RowsChans())
+			err := run.Err()
+
+			// assert
+			sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+			assertEqual(t, expectedRows, rows)
+			assertEqual(t, int64(2), run.PaddedRows())
+			if assertNotNil(t, err) {
+				assertTrue(t, errors.Is(err, csv.ErrFieldCount))
+			}
+		})
+	}
+
+	t.Run("short rows are rejected without default values", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 4
+
+		// act
+		run := subject.Start(context.Background())
+		rows := gatherRowsInOrder(run.RowsChans())
+		err := run.Err()
+
+		// assert
+		assertEqual(t, [][]string{{"1", "John", "33", "RO"}}, rows)
+		assertEqual(t, int64(0), run.PaddedRows())
+		assertNotNil(t, err)
+	})
+}
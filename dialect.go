@@ -0,0 +1,251 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"unicode/utf8"
+)
+
+// Dialect bundles the conventions a CSV producer follows, see [CsvReader.SetDialect].
+type Dialect struct {
+	// Delimiter is the delimiter char between columns.
+	Delimiter rune
+	// Quote is the char fields are enclosed in. 0 means fields are never quoted.
+	Quote rune
+	// Escape is the char escaping the next char. 0 means there is no escaping (quotes are doubled).
+	Escape rune
+	// NullToken is the (unquoted) token written for NULL values.
+	NullToken string
+	// CRLFInQuotedFields is a flag indicating that lines are terminated by \r\n,
+	// which may also be found inside quoted fields.
+	CRLFInQuotedFields bool
+}
+
+var (
+	// DialectRFC4180 is the dialect described by RFC 4180:
+	// comma delimited, quotes doubled inside quoted fields, \r\n line terminator.
+	DialectRFC4180 = Dialect{Delimiter: ',', Quote: '"', CRLFInQuotedFields: true}
+	// DialectExcel is the dialect of Excel's "CSV" exports: like [DialectRFC4180],
+	// with cells holding line breaks exported as quoted fields.
+	DialectExcel = Dialect{Delimiter: ',', Quote: '"', CRLFInQuotedFields: true}
+	// DialectPostgres is the dialect of PostgreSQL's COPY default (text) format:
+	// tab delimited, no quoting, backslash escapes, \N for NULL values, \n line terminator.
+	DialectPostgres = Dialect{Delimiter: '\t', Escape: '\\', NullToken: `\N`}
+	// DialectPostgresCSV is the dialect of PostgreSQL's COPY CSV format:
+	// comma delimited, quotes doubled inside quoted fields, unquoted empty string for NULL values,
+	// \n line terminator.
+	DialectPostgresCSV = Dialect{Delimiter: ',', Quote: '"'}
+	// DialectMySQL is the dialect of MySQL's SELECT ... INTO OUTFILE / LOAD DATA defaults:
+	// tab delimited, no quoting, backslash escapes, \N for NULL values, \n line terminator.
+	DialectMySQL = Dialect{Delimiter: '\t', Escape: '\\', NullToken: `\N`}
+)
+
+// errUnsupportedWithDialect is the error returned when a feature expecting
+// standard quoting is used together with a custom quote char, escape char or null token.
+var errUnsupportedWithDialect = errors.New("not supported with custom quote char, escape char or null token")
+
+// SetDialect configures the reader to parse files written with given dialect's conventions.
+// It sets [CsvReader.ColumnsDelimiter], [CsvReader.QuoteChar], [CsvReader.NoQuoting], [CsvReader.EscapeChar],
+// [CsvReader.NullToken] and [CsvReader.CRLFInQuotedFields].
+func (cr *CsvReader) SetDialect(dialect Dialect) {
+	cr.ColumnsDelimiter = dialect.Delimiter
+	cr.QuoteChar = dialect.Quote
+	cr.NoQuoting = dialect.Quote == 0
+	cr.EscapeChar = dialect.Escape
+	cr.NullToken = dialect.NullToken
+	cr.CRLFInQuotedFields = dialect.CRLFInQuotedFields
+}
+
+// hasCustomDialect returns whether lines can't be parsed by the standard go CSV reader.
+func (cr *CsvReader) hasCustomDialect() bool {
+	return cr.quoteChar() != '"' || cr.EscapeChar != 0 || cr.NullToken != ""
+}
+
+// quoteChar returns the char fields are enclosed in, 0 if they are never quoted, see [CsvReader.NoQuoting].
+func (cr *CsvReader) quoteChar() rune {
+	switch {
+	case cr.NoQuoting:
+		return 0
+	case cr.QuoteChar == 0:
+		return '"'
+	default:
+		return cr.QuoteChar
+	}
+}
+
+// lineParser parses a line into a record.
+type lineParser interface {
+	parse(line []byte) ([]string, error)
+}
+
+// newLineParser returns a parser of lines following the reader's dialect.
+// fieldsCount has [csv.Reader.FieldsPerRecord] semantics, records shorter than a positive fieldsCount
+// being padded instead, if [CsvReader.DefaultValues] are set.
+func (cr *CsvReader) newLineParser(fieldsCount int) lineParser {
+	if fieldsCount > 0 && cr.DefaultValues != nil {
+		return &paddingParser{
+			parser:      cr.newLineParser(-1),
+			fieldsCount: fieldsCount,
+			defaults:    cr.DefaultValues,
+		}
+	}
+	if cr.hasCustomDialect() {
+		return &dialectParser{
+			comma:       encodeRune(cr.ColumnsDelimiter),
+			quote:       encodeRune(cr.quoteChar()),
+			escape:      encodeRune(cr.EscapeChar),
+			nullToken:   []byte(cr.NullToken),
+			lazyQuotes:  cr.LazyQuotes,
+			fieldsCount: fieldsCount,
+		}
+	}
+	p := &csvLineParser{bytesReader: bytes.NewReader(nil)}
+	p.csvReader = csv.NewReader(p.bytesReader)
+	p.csvReader.Comma = cr.ColumnsDelimiter
+	p.csvReader.FieldsPerRecord = fieldsCount
+	p.csvReader.LazyQuotes = cr.LazyQuotes
+
+	return p
+}
+
+// csvLineParser parses lines through standard go CSV reader.
+type csvLineParser struct {
+	bytesReader *bytes.Reader
+	csvReader   *csv.Reader
+}
+
+func (p *csvLineParser) parse(line []byte) ([]string, error) {
+	p.bytesReader.Reset(line)
+
+	return p.csvReader.Read()
+}
+
+// dialectParser parses lines having a custom quote char, escape char or null token.
+// Errors are the ones standard go CSV reader wraps into [*csv.ParseError].
+type dialectParser struct {
+	comma, quote, escape []byte
+	nullToken            []byte
+	lazyQuotes           bool
+	fieldsCount          int
+	// field is the buffer the current field's value is unescaped into.
+	field []byte
+}
+
+func (p *dialectParser) parse(line []byte) ([]string, error) {
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+
+	record := make([]string, 0, max(p.fieldsCount, 1))
+	pos := 0
+	for {
+		value, next, err := p.parseField(line, pos)
+		if err != nil {
+			return nil, err
+		}
+		record = append(record, value)
+		if next >= len(line) {
+			break
+		}
+		pos = next + len(p.comma)
+	}
+
+	if p.fieldsCount == 0 {
+		p.fieldsCount = len(record)
+	} else if p.fieldsCount > 0 && len(record) != p.fieldsCount {
+		return record, csv.ErrFieldCount
+	}
+
+	return record, nil
+}
+
+// parseField parses the field starting at given position,
+// and returns its value and the position of the delimiter following it.
+func (p *dialectParser) parseField(line []byte, pos int) (string, int, error) {
+	start := pos
+	quoted := len(p.quote) > 0 && bytes.HasPrefix(line[pos:], p.quote)
+	if quoted {
+		pos += len(p.quote)
+	}
+	wasQuoted := quoted
+	p.field = p.field[:0]
+	for pos < len(line) {
+		rest := line[pos:]
+		switch {
+		case len(p.escape) > 0 && bytes.HasPrefix(rest, p.escape) && len(rest) > len(p.escape):
+			pos += len(p.escape)
+			r, size := utf8.DecodeRune(line[pos:])
+			p.field = utf8.AppendRune(p.field, unescape(r))
+			pos += size
+		case quoted && bytes.HasPrefix(rest, p.quote):
+			pos += len(p.quote)
+			if bytes.HasPrefix(line[pos:], p.quote) { // doubled quote.
+				p.field = append(p.field, p.quote...)
+				pos += len(p.quote)
+
+				continue
+			}
+			quoted = false
+			if pos < len(line) && !bytes.HasPrefix(line[pos:], p.comma) {
+				if !p.lazyQuotes {
+					return "", 0, csv.ErrQuote
+				}
+				p.field = append(p.field, p.quote...)
+			}
+		case quoted && bytes.HasPrefix(rest, []byte("\r\n")):
+			p.field = append(p.field, '\n')
+			pos += 2
+		case !quoted && bytes.HasPrefix(rest, p.comma):
+			return p.value(line[start:pos], wasQuoted), pos, nil
+		case !quoted && len(p.quote) > 0 && bytes.HasPrefix(rest, p.quote) && !p.lazyQuotes:
+			return "", 0, csv.ErrBareQuote
+		default:
+			p.field = append(p.field, line[pos])
+			pos++
+		}
+	}
+	if quoted && !p.lazyQuotes {
+		return "", 0, csv.ErrQuote
+	}
+
+	return p.value(line[start:pos], wasQuoted), pos, nil
+}
+
+// value returns the parsed field's value, or empty string if the raw field is the null token.
+func (p *dialectParser) value(raw []byte, quoted bool) string {
+	if !quoted && len(p.nullToken) > 0 && bytes.Equal(raw, p.nullToken) {
+		return ""
+	}
+
+	return string(p.field)
+}
+
+// unescape returns the char given escape sequence stands for.
+func unescape(r rune) rune {
+	switch r {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case '0':
+		return 0
+	default:
+		return r
+	}
+}
+
+// encodeRune returns the UTF-8 encoding of given rune, nil for 0.
+func encodeRune(r rune) []byte {
+	if r == 0 {
+		return nil
+	}
+
+	return utf8.AppendRune(nil, r)
+}
@@ -0,0 +1,196 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_SetDialect(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name            string
+		dialect         bigcsvreader.Dialect
+		content         string
+		expectedRecords [][]string
+	}{
+		{
+			name:    "MySQL",
+			dialect: bigcsvreader.DialectMySQL,
+			content: "1\tJohn \\\"Jr\\\"\t\\N\n" +
+				"2\tline\\nbreak\tback\\\\slash\n" +
+				"3\ttab\\tinside\t\"quoted\"\n",
+			expectedRecords: [][]string{
+				{"1", `John "Jr"`, ""},
+				{"2", "line\nbreak", `back\slash`},
+				{"3", "tab\tinside", `"quoted"`},
+			},
+		},
+		{
+			name:    "Postgres",
+			dialect: bigcsvreader.DialectPostgres,
+			content: "1\t\\N\tescaped \\\\N is not null\n" +
+				"2\tdelimiter\\\tinside\t\n",
+			expectedRecords: [][]string{
+				{"1", "", `escaped \N is not null`},
+				{"2", "delimiter\tinside", ""},
+			},
+		},
+		{
+			name:    "Postgres CSV",
+			dialect: bigcsvreader.DialectPostgresCSV,
+			content: "1,\"Jane \"\"Doe\"\"\",\n" +
+				"2,,\"\"\n",
+			expectedRecords: [][]string{
+				{"1", `Jane "Doe"`, ""},
+				{"2", "", ""},
+			},
+		},
+		{
+			name:    "Excel",
+			dialect: bigcsvreader.DialectExcel,
+			content: "1,\"multi\r\nline\",x\r\n" +
+				"2,single,y\r\n",
+			expectedRecords: [][]string{
+				{"1", "multi\nline", "x"},
+				{"2", "single", "y"},
+			},
+		},
+		{
+			name:    "custom quote and null token",
+			dialect: bigcsvreader.Dialect{Delimiter: ';', Quote: '\'', NullToken: "NULL"},
+			content: "1;'it''s; quoted';NULL\n" +
+				"2;\"double\";'NULL'\n",
+			expectedRecords: [][]string{
+				{"1", "it's; quoted", ""},
+				{"2", `"double"`, "NULL"},
+			},
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(writeTmpFile(t, t.TempDir(), "dialect.csv", test.content))
+			subject.SetDialect(test.dialect)
+			subject.ColumnsCount = 3
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			records, err := gatherRecords(subject.Read(ctx))
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, test.expectedRecords, records)
+		})
+	}
+}
+
+func TestCsvReader_QuoteChar(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name            string
+		quoteChar       rune
+		noQuoting       bool
+		expectedRecords [][]string
+	}{
+		{
+			name:            "zero value stands for double quote",
+			expectedRecords: [][]string{{"1", "a,b", "x"}, {"2", `"c`, "y"}},
+		},
+		{
+			name:            "fields are never quoted",
+			quoteChar:       '"',
+			noQuoting:       true,
+			expectedRecords: [][]string{{"1", `"a`, `b"`, "x"}, {"2", `"""c"`, "y"}},
+		},
+	}
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(writeTmpFile(t, t.TempDir(), "quotes.csv", "1,\"a,b\",x\n2,\"\"\"c\",y\n"))
+			subject.QuoteChar = test.quoteChar
+			subject.NoQuoting = test.noQuoting
+			subject.ColumnsCount = -1
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			records, err := gatherRecords(subject.Read(ctx))
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, test.expectedRecords, records)
+		})
+	}
+}
+
+func TestCsvReader_customDialectErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unterminated quote", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, t.TempDir(), "dialect.csv", "1,'open,x\n2,ok,y\n"))
+		subject.QuoteChar = '\''
+		subject.ColumnsCount = 3
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rowsChans, errsChan := subject.Read(ctx)
+		var err error
+		done := make(chan struct{})
+		go func() {
+			err = bigcsvreader.CollectErrors(errsChan)
+			close(done)
+		}()
+		records := gatherRowsInOrder(rowsChans)
+		<-done
+
+		// assert
+		assertTrue(t, errors.Is(err, csv.ErrQuote))
+		assertEqual(t, [][]string{{"2", "ok", "y"}}, records)
+	})
+
+	t.Run("lazy rows are not supported", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_without_header.csv")
+		subject.SetDialect(bigcsvreader.DialectMySQL)
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		lazyRowsChans, errsChan := subject.ReadLazy(ctx)
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertNil(t, lazyRowsChans)
+		assertNotNil(t, err)
+	})
+}
@@ -0,0 +1,142 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+// Package duckdbload streams rows read by a [bigcsvreader.CsvReader] into DuckDB through appenders,
+// so the file can be queried right away, while the parallel reader does the IO and parsing.
+package duckdbload
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+// Appender appends rows into a DuckDB table.
+// It is satisfied by the go-duckdb driver's *duckdb.Appender.
+type Appender interface {
+	AppendRow(args ...driver.Value) error
+	Flush() error
+	Close() error
+}
+
+// NewAppenderFunc returns a new [Appender] into the target table.
+// DuckDB appenders are not safe for concurrent use, so it is called once per reading goroutine,
+// usually creating the appender on a dedicated connection, like:
+//
+//	func() (duckdbload.Appender, error) {
+//		conn, err := connector.Connect(ctx)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return duckdb.NewAppenderFromConn(conn, "", "products")
+//	}
+type NewAppenderFunc func() (Appender, error)
+
+// Append streams the rows into DuckDB, one appender per rows channel, converting values according to
+// given columns types (values of string / unknown columns and columns without type are appended as strings,
+// empty values of other columns are appended as NULL).
+// The columns types can be inferred with [bigcsvreader.CsvReader.Analyze].
+// It blocks until everything was consumed and returns the number of appended rows, and
+// all the errors received through errsChan and returned by appenders, joined with [errors.Join].
+func Append(
+	ctx context.Context,
+	rowsChans []bigcsvreader.RowsChan,
+	errsChan bigcsvreader.ErrsChan,
+	types []bigcsvreader.ColumnType,
+	newAppender NewAppenderFunc,
+) (int64, error) {
+	var (
+		appended int64
+		errs     []error
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+	)
+	addErr := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+
+	wg.Add(len(rowsChans) + 1)
+	for i := 0; i < len(rowsChans); i++ {
+		go func(rowsChan bigcsvreader.RowsChan) {
+			defer wg.Done()
+			defer func() {
+				for range rowsChan { // drain, in case of error.
+				}
+			}()
+			appender, err := newAppender()
+			if err != nil {
+				addErr(fmt.Errorf("duckdbload: could not create appender (%w)", err))
+
+				return
+			}
+			var args []driver.Value
+			for row := range rowsChan {
+				if err := ctx.Err(); err != nil {
+					addErr(fmt.Errorf("duckdbload: received context error (%w)", err))
+
+					break
+				}
+				args = args[:0]
+				for col, value := range row {
+					args = append(args, convert(types, col, value))
+				}
+				if err := appender.AppendRow(args...); err != nil {
+					addErr(fmt.Errorf("duckdbload: could not append row (%w)", err))
+
+					break
+				}
+				atomic.AddInt64(&appended, 1)
+			}
+			if err := appender.Close(); err != nil { // Close flushes too.
+				addErr(fmt.Errorf("duckdbload: could not close appender (%w)", err))
+			}
+		}(rowsChans[i])
+	}
+	go func() {
+		defer wg.Done()
+		for err := range errsChan {
+			addErr(err)
+		}
+	}()
+	wg.Wait()
+
+	return appended, errors.Join(errs...)
+}
+
+// convert converts the value of given column according to its type.
+func convert(types []bigcsvreader.ColumnType, col int, value string) driver.Value {
+	if col >= len(types) {
+		return value
+	}
+	switch types[col] {
+	case bigcsvreader.ColumnTypeBool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case bigcsvreader.ColumnTypeInt:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case bigcsvreader.ColumnTypeFloat:
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	default:
+		return value
+	}
+	if value == "" {
+		return nil
+	}
+
+	return value // let DuckDB report the conversion error.
+}
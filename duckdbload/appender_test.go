@@ -0,0 +1,134 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package duckdbload_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+	"github.com/actforgood/bigcsvreader/duckdbload"
+)
+
+func TestAppend(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rows are appended with converted values", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		rowsChans, errsChan := startReading(t)
+		var (
+			appenders []*fakeAppender
+			mu        sync.Mutex
+		)
+		newAppender := func() (duckdbload.Appender, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			appender := &fakeAppender{}
+			appenders = append(appenders, appender)
+
+			return appender, nil
+		}
+		types := []bigcsvreader.ColumnType{bigcsvreader.ColumnTypeInt, bigcsvreader.ColumnTypeString}
+
+		// act
+		appended, err := duckdbload.Append(context.Background(), rowsChans, errsChan, types, newAppender)
+
+		// assert
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if appended != 5 {
+			t.Errorf("expected 5 appended rows, got %d", appended)
+		}
+		var rows [][]driver.Value
+		for _, appender := range appenders {
+			if !appender.closed {
+				t.Error("expected appender to be closed")
+			}
+			rows = append(rows, appender.rows...)
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i][0].(int64) < rows[j][0].(int64) })
+		expected := [][]driver.Value{
+			{int64(1), "John", "33"},
+			{int64(2), "Jane", "30"},
+			{int64(3), "Mike", "18"},
+			{int64(4), "Ronaldinho", "23"},
+			{int64(5), "Elisabeth", "45"},
+		}
+		if !reflect.DeepEqual(expected, rows) {
+			t.Errorf("expected rows %v, got %v", expected, rows)
+		}
+	})
+
+	t.Run("appender error", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		rowsChans, errsChan := startReading(t)
+		appendErr := errors.New("intentionally triggered append error")
+		newAppender := func() (duckdbload.Appender, error) {
+			return &fakeAppender{err: appendErr}, nil
+		}
+
+		// act
+		appended, err := duckdbload.Append(context.Background(), rowsChans, errsChan, nil, newAppender)
+
+		// assert
+		if !errors.Is(err, appendErr) {
+			t.Errorf("expected append error, got %v", err)
+		}
+		if appended != 0 {
+			t.Errorf("expected 0 appended rows, got %d", appended)
+		}
+	})
+}
+
+func startReading(t *testing.T) ([]bigcsvreader.RowsChan, bigcsvreader.ErrsChan) {
+	t.Helper()
+
+	csvReader := bigcsvreader.New()
+	csvReader.SetFilePath("../testdata/file_with_header.csv")
+	csvReader.ColumnsCount = 3
+	csvReader.FileHasHeader = true
+	csvReader.ColumnsDelimiter = ';'
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	t.Cleanup(cancelCtx)
+
+	return csvReader.Read(ctx)
+}
+
+type fakeAppender struct {
+	rows   [][]driver.Value
+	closed bool
+	err    error
+}
+
+func (a *fakeAppender) AppendRow(args ...driver.Value) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.rows = append(a.rows, append([]driver.Value(nil), args...))
+
+	return nil
+}
+
+func (a *fakeAppender) Flush() error {
+	return nil
+}
+
+func (a *fakeAppender) Close() error {
+	a.closed = true
+
+	return nil
+}
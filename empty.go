@@ -0,0 +1,52 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import "sync/atomic"
+
+// closeIfEmpty closes the channel of the goroutine which read its whole chunk without finding a record
+// (no line starting within it, the line it starts in being read by the previous goroutine, or blank lines only),
+// right away, instead of after all goroutines finished, so consumers do not wait on it, see [Run.EmptyChunks].
+func (rn *Run) closeIfEmpty(thread int) {
+	stats := &rn.threads[thread-1]
+	if stats.records() > 0 || stats.rows() > 0 || (thread == 1 && rn.emitHeader) ||
+		stats.next() >= 0 || stats.skipped() >= 0 || rn.isLimitReached() {
+		return
+	}
+	atomic.StoreInt32(&stats.empty, 1)
+	rn.closeChans(thread - 1)
+}
+
+// closeChans closes the channel (or transport) of the goroutine having given index, whatever the run's mode.
+func (rn *Run) closeChans(i int) {
+	switch {
+	case rn.rowsChans != nil:
+		close(rn.rowsChans[i])
+	case rn.lazyChans != nil:
+		close(rn.lazyChans[i])
+	case rn.decodedChans != nil:
+		close(rn.decodedChans[i])
+	case rn.metaChans != nil:
+		close(rn.metaChans[i])
+	case rn.rings != nil:
+		rn.rings[i].Close()
+	}
+}
+
+// EmptyChunks returns the indexes of the chunks found so far to hold no record: their goroutine read
+// no line starting within them (like a chunk lying within a single long line), or blank lines only.
+// The channel of an empty chunk is closed as soon as its goroutine finished, instead of once all
+// goroutines finished, so it does not need to be waited on. See also [ThreadSummary.Empty].
+func (rn *Run) EmptyChunks() []int {
+	var chunks []int
+	for i := range rn.threads {
+		if rn.threads[i].isEmpty() {
+			chunks = append(chunks, i)
+		}
+	}
+
+	return chunks
+}
@@ -0,0 +1,97 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestRun_EmptyChunks(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	// a line longer than a chunk, after the data head sampled for the longest line, leaves a chunk without records.
+	var (
+		content   strings.Builder
+		rowsCount int
+	)
+	for content.Len() < 70000 {
+		rowsCount++
+		content.WriteString(strconv.Itoa(rowsCount) + ",name " + strconv.Itoa(rowsCount) + "\n")
+	}
+	rowsCount++
+	content.WriteString(strconv.Itoa(rowsCount) + "," + strings.Repeat("x", 30000) + "\n")
+	for content.Len() < 110000 {
+		rowsCount++
+		content.WriteString(strconv.Itoa(rowsCount) + ",name " + strconv.Itoa(rowsCount) + "\n")
+	}
+	subject := bigcsvreader.New()
+	subject.SetFilePath(writeTmpFile(t, t.TempDir(), "long_line.csv", content.String()))
+	subject.ColumnsCount = 2
+	subject.MaxGoroutinesNo = 8
+	subject.AutoGrowBuffer = true
+	subject.ChanBufferSize = 1
+	summaries := make(chan bigcsvreader.Summary, 1)
+	subject.OnComplete = func(summary bigcsvreader.Summary) {
+		summaries <- summary
+	}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	run := subject.Start(ctx)
+	rowsChans := run.RowsChans()
+	counts := make([]int, len(rowsChans))
+	closed := make(chan int, len(rowsChans))
+	for i := 1; i < len(rowsChans); i++ { // first chunk's rows are not consumed yet, so reading can't finish.
+		go func(i int) {
+			for range rowsChans[i] {
+				counts[i]++
+			}
+			closed <- i
+		}(i)
+	}
+	var firstClosed int
+	select {
+	case firstClosed = <-closed:
+	case <-ctx.Done():
+		t.Fatal("no channel was closed while reading was going on")
+	}
+	emptyChunks := run.EmptyChunks()
+	select {
+	case <-run.Done():
+		t.Error("reading should not be done")
+	default:
+	}
+	for range rowsChans[0] {
+		counts[0]++
+	}
+	for i := 2; i < len(rowsChans); i++ {
+		<-closed
+	}
+	err := run.Err()
+	summary := <-summaries
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 8, len(rowsChans))
+	assertEqual(t, []int{firstClosed}, emptyChunks)
+	assertEqual(t, 0, counts[firstClosed])
+	var total int
+	for _, count := range counts {
+		total += count
+	}
+	assertEqual(t, rowsCount, total)
+	for i, thread := range summary.Threads {
+		assertEqual(t, i == firstClosed, thread.Empty)
+	}
+}
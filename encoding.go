@@ -0,0 +1,176 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+// maxExactCardinality is the number of distinct values of a column tracked exactly,
+// above which the column's cardinality is estimated.
+const maxExactCardinality = 1 << 16
+
+// byteArrayLengthSize is the size of the length prefix of a plain encoded byte array (Parquet / ORC strings).
+const byteArrayLengthSize = 4
+
+// ColumnEncodingStats holds a column's statistics relevant for choosing its storage encoding.
+type ColumnEncodingStats struct {
+	// Column is the 0-based index of the column.
+	Column int
+	// Name is the column's name, taken from the header, if the file has one.
+	Name string
+	// Cardinality is the number of distinct values of the column.
+	Cardinality int64
+	// CardinalityExact is a flag indicating Cardinality is exact, not estimated.
+	// Cardinality is estimated (~1% error) for columns with more than 65536 distinct values.
+	CardinalityExact bool
+	// AvgLength is the average length, in bytes, of the column's values.
+	AvgLength float64
+	// PlainSize is the size, in bytes, of the column's values, plain encoded (each value length prefixed).
+	PlainSize int64
+	// DictionarySize is the estimated size, in bytes, of the column's values, dictionary encoded:
+	// the plain encoded distinct values, plus a bit packed dictionary index for each value.
+	DictionarySize int64
+}
+
+// EstimatedSavings returns the fraction of PlainSize saved by dictionary encoding.
+// It is negative if dictionary encoding is larger than plain encoding.
+func (s ColumnEncodingStats) EstimatedSavings() float64 {
+	if s.PlainSize == 0 {
+		return 0
+	}
+
+	return 1 - float64(s.DictionarySize)/float64(s.PlainSize)
+}
+
+// EncodingReport holds the columns' encoding statistics, computed by [CsvReader.AnalyzeEncoding].
+type EncodingReport struct {
+	// RowsCount is the number of rows in the file (header excluded).
+	RowsCount int64
+	// Columns holds each column's statistics.
+	Columns []ColumnEncodingStats
+}
+
+// String returns the report as a table.
+func (r EncodingReport) String() string {
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', tabwriter.AlignRight)
+	_, _ = fmt.Fprintln(tw, "column\tname\tcardinality\tavg length\tplain size\tdictionary size\tsavings\t")
+	for _, col := range r.Columns {
+		cardinality := fmt.Sprintf("%d", col.Cardinality)
+		if !col.CardinalityExact {
+			cardinality = "~" + cardinality
+		}
+		_, _ = fmt.Fprintf(
+			tw, "%d\t%s\t%s\t%.1f\t%d\t%d\t%.1f%%\t\n",
+			col.Column, col.Name, cardinality, col.AvgLength,
+			col.PlainSize, col.DictionarySize, 100*col.EstimatedSavings(),
+		)
+	}
+	_ = tw.Flush()
+
+	return sb.String()
+}
+
+// AnalyzeEncoding scans the whole CSV file and computes for each column its cardinality, average value length,
+// and the estimated dictionary encoding savings, helping to pick columnar (Parquet / ORC) encodings
+// before converting the file.
+// Memory usage is bounded: each column tracks up to 65536 distinct values exactly, then estimates its cardinality.
+func (cr *CsvReader) AnalyzeEncoding(ctx context.Context) (EncodingReport, error) {
+	var (
+		report  EncodingReport
+		columns []columnEncodingCounter
+	)
+	header, err := cr.scanRows(ctx, func(record []string, _ int) {
+		if columns == nil {
+			columns = make([]columnEncodingCounter, len(record))
+			for col := range columns {
+				columns[col].distinct = make(map[string]struct{})
+			}
+		}
+		for col := 0; col < len(record) && col < len(columns); col++ {
+			columns[col].add(record[col])
+		}
+		report.RowsCount++
+	})
+	if err != nil {
+		return EncodingReport{}, err
+	}
+
+	report.Columns = make([]ColumnEncodingStats, len(columns))
+	for col := range columns {
+		report.Columns[col] = columns[col].stats(report.RowsCount)
+		report.Columns[col].Column = col
+		if col < len(header) {
+			report.Columns[col].Name = header[col]
+		}
+	}
+
+	return report, nil
+}
+
+// columnEncodingCounter accumulates a column's encoding statistics.
+type columnEncodingCounter struct {
+	// distinct holds the distinct values, until their number exceeds maxExactCardinality.
+	distinct map[string]struct{}
+	// distinctBytes is the total length of the values in distinct.
+	distinctBytes int64
+	// hll estimates the cardinality, after distinct values exceeded maxExactCardinality.
+	hll *internal.HyperLogLog
+	// valuesBytes is the total length of the values.
+	valuesBytes int64
+}
+
+// add accounts given value.
+func (c *columnEncodingCounter) add(value string) {
+	c.valuesBytes += int64(len(value))
+	if c.hll != nil {
+		c.hll.Add(value)
+
+		return
+	}
+	if _, found := c.distinct[value]; found {
+		return
+	}
+	c.distinct[value] = struct{}{}
+	c.distinctBytes += int64(len(value))
+	if len(c.distinct) > maxExactCardinality {
+		// switch to estimation, keeping the values seen so far.
+		c.hll = internal.NewHyperLogLog()
+		for distinctValue := range c.distinct {
+			c.hll.Add(distinctValue)
+		}
+		c.distinct = map[string]struct{}{}
+	}
+}
+
+// stats returns the column's statistics, for given number of rows.
+func (c *columnEncodingCounter) stats(rowsCount int64) ColumnEncodingStats {
+	stats := ColumnEncodingStats{
+		Cardinality:      int64(len(c.distinct)),
+		CardinalityExact: c.hll == nil,
+		PlainSize:        c.valuesBytes + rowsCount*byteArrayLengthSize,
+	}
+	if rowsCount > 0 {
+		stats.AvgLength = float64(c.valuesBytes) / float64(rowsCount)
+	}
+	distinctBytes := c.distinctBytes
+	if c.hll != nil {
+		// extrapolate the distinct values' length from the ones tracked exactly.
+		stats.Cardinality = int64(c.hll.Estimate())
+		distinctBytes = c.distinctBytes * stats.Cardinality / (maxExactCardinality + 1)
+	}
+	indexBits := int64(bits.Len64(uint64(max(stats.Cardinality-1, 0))))
+	stats.DictionarySize = distinctBytes + stats.Cardinality*byteArrayLengthSize + (rowsCount*indexBits+7)/8
+
+	return stats
+}
@@ -0,0 +1,75 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_AnalyzeEncoding(t *testing.T) {
+	t.Parallel()
+
+	t.Run("columns with header", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsDelimiter = ';'
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		report, err := subject.AnalyzeEncoding(ctx)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, int64(5), report.RowsCount)
+		assertEqual(t, 3, len(report.Columns))
+		assertEqual(t, "ID", report.Columns[0].Name)
+		assertEqual(t, int64(5), report.Columns[0].Cardinality)
+		assertTrue(t, report.Columns[0].CardinalityExact)
+		assertEqual(t, 1.0, report.Columns[0].AvgLength)
+		assertEqual(t, int64(5+5*4), report.Columns[0].PlainSize)
+		assertTrue(t, strings.Contains(report.String(), "Name"))
+	})
+
+	t.Run("low and high cardinality columns", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		filePath, err := setUpTmpCsvFile(100_000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tearDownTmpCsvFile(filePath)
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		report, err := subject.AnalyzeEncoding(ctx)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, int64(100_000), report.RowsCount)
+		assertEqual(t, 5, len(report.Columns))
+		id, description := report.Columns[0], report.Columns[2]
+		assertTrue(t, !id.CardinalityExact) // more distinct values than tracked exactly.
+		assertTrue(t, id.Cardinality > 97_000 && id.Cardinality < 103_000)
+		assertTrue(t, id.EstimatedSavings() < 0)
+		assertTrue(t, description.CardinalityExact)
+		assertEqual(t, int64(1), description.Cardinality)
+		assertTrue(t, description.EstimatedSavings() > 0.9)
+	})
+}
@@ -0,0 +1,44 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// EnrichFunc computes the values of the columns appended to a row.
+// It is called concurrently, by each reading goroutine.
+type EnrichFunc func(row []string) ([]string, error)
+
+// Enrich writes into w a copy of the CSV file, having appended to each row the columns computed by enrich,
+// in the file's order, delimited by [CsvReader.ColumnsDelimiter].
+// If the file has a header, the header is written with given columns names appended.
+// Enrichment is computed in parallel, and rows are spooled on disk, so memory stays bounded.
+// Nothing is written into w if reading or enriching failed.
+func (cr *CsvReader) Enrich(ctx context.Context, w io.Writer, columns []string, enrich EnrichFunc) error {
+	var header []string
+	if cr.FileHasHeader {
+		fileHeader, err := cr.Header()
+		if err != nil {
+			return err
+		}
+		header = append(fileHeader, columns...)
+	}
+
+	return cr.writeOrdered(ctx, w, header, cr.ColumnsDelimiter, func(row []string) ([]string, error) {
+		values, err := enrich(row)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != len(columns) {
+			return nil, fmt.Errorf("expected %d enriched values, got %d", len(columns), len(values))
+		}
+
+		return append(row, values...), nil
+	})
+}
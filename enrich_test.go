@@ -0,0 +1,80 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_Enrich(t *testing.T) {
+	t.Parallel()
+
+	t.Run("columns are appended", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.ColumnsDelimiter = ';'
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+		var w bytes.Buffer
+
+		// act
+		err := subject.Enrich(ctx, &w, []string{"Adult", "NameLength"}, func(row []string) ([]string, error) {
+			age, err := strconv.Atoi(row[2])
+			if err != nil {
+				return nil, err
+			}
+
+			return []string{strconv.FormatBool(age >= 21), strconv.Itoa(len(row[1]))}, nil
+		})
+
+		// assert
+		assertNil(t, err)
+		expected := strings.Join([]string{
+			"ID;Name;Age;Adult;NameLength",
+			"1;John;33;true;4",
+			"2;Jane;30;true;4",
+			"3;Mike;18;false;4",
+			"4;Ronaldinho;23;true;10",
+			"5;Elisabeth;45;true;9",
+		}, "\n") + "\n"
+		assertEqual(t, expected, w.String())
+	})
+
+	t.Run("enrich error", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.ColumnsDelimiter = ';'
+		enrichErr := errors.New("intentionally triggered enrich error")
+		var w bytes.Buffer
+
+		// act
+		err := subject.Enrich(context.Background(), &w, []string{"Extra"}, func([]string) ([]string, error) {
+			return nil, enrichErr
+		})
+
+		// assert
+		assertTrue(t, errors.Is(err, enrichErr))
+		assertEqual(t, 0, w.Len())
+	})
+}
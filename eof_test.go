@@ -0,0 +1,127 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_endOfFile(t *testing.T) {
+	t.Parallel()
+
+	const rowsCount = 700 // big enough for several goroutines.
+	tails := [...]struct {
+		name string
+		tail string
+	}{
+		{name: "no trailing line break", tail: ""},
+		{name: "trailing CR", tail: "\r"},
+		{name: "trailing CRLF", tail: "\r\n"},
+		{name: "blank line", tail: "\r\n\r\n"},
+		{name: "blank line with trailing CR", tail: "\r\n\r"},
+	}
+	for _, tail := range tails {
+		var content strings.Builder
+		for i := 1; i <= rowsCount; i++ {
+			fmt.Fprintf(&content, "%d,\"name %d\",x\r\n", i, i)
+		}
+		fileContent := strings.TrimSuffix(content.String(), "\r\n") + tail.tail
+
+		for _, mode := range [...]string{"read", "lazy", "reverse", "crlf in quoted fields"} {
+			for goroutines := 1; goroutines <= 4; goroutines++ {
+				name := fmt.Sprintf("%s - %s - %d goroutine(s)", tail.name, mode, goroutines)
+				mode, goroutines := mode, goroutines // capture range variables
+				t.Run(name, func(t *testing.T) {
+					t.Parallel()
+
+					// arrange
+					subject := bigcsvreader.New()
+					subject.SetFilePath(writeTmpFile(t, t.TempDir(), "eof.csv", fileContent))
+					subject.ColumnsCount = 3
+					subject.MaxGoroutinesNo = goroutines
+					subject.CRLFInQuotedFields = mode == "crlf in quoted fields"
+					ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+					defer cancelCtx()
+
+					// act
+					rowsByID, err := readRowsByID(ctx, subject, mode)
+
+					// assert
+					assertNil(t, err)
+					assertEqual(t, rowsCount, len(rowsByID))
+					assertEqual(t, []string{"700", "name 700", "x"}, rowsByID["700"])
+					boundaryMap, err := subject.PreScan(ctx)
+					if assertNil(t, err) {
+						assertEqual(t, int64(rowsCount), boundaryMap.RowsCount)
+					}
+				})
+			}
+		}
+	}
+}
+
+// readRowsByID reads the file in given mode, and returns the rows keyed by their first field.
+func readRowsByID(ctx context.Context, subject *bigcsvreader.CsvReader, mode string) (map[string][]string, error) {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		rows = make(map[string][]string)
+		err  error
+		done = make(chan struct{})
+	)
+	add := func(row []string) {
+		mu.Lock()
+		rows[row[0]] = row
+		mu.Unlock()
+	}
+	switch mode {
+	case "lazy":
+		lazyRowsChans, errsChan := subject.ReadLazy(ctx)
+		go func() {
+			err = bigcsvreader.CollectErrors(errsChan)
+			close(done)
+		}()
+		for _, lazyRowsChan := range lazyRowsChans {
+			wg.Add(1)
+			go func(lazyRowsChan bigcsvreader.LazyRowsChan) {
+				defer wg.Done()
+				for lazyRow := range lazyRowsChan {
+					add(lazyRow.Fields())
+				}
+			}(lazyRowsChan)
+		}
+	default:
+		read := subject.Read
+		if mode == "reverse" {
+			read = subject.ReadReverse
+		}
+		rowsChans, errsChan := read(ctx)
+		go func() {
+			err = bigcsvreader.CollectErrors(errsChan)
+			close(done)
+		}()
+		for _, rowsChan := range rowsChans {
+			wg.Add(1)
+			go func(rowsChan bigcsvreader.RowsChan) {
+				defer wg.Done()
+				for row := range rowsChan {
+					add(row)
+				}
+			}(rowsChan)
+		}
+	}
+	wg.Wait()
+	<-done
+
+	return rows, err
+}
@@ -0,0 +1,30 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import "errors"
+
+// Failure classes sentinels, errors sent through ErrsChan (or returned) wrap,
+// so consumers can branch with [errors.Is] (the original cause is wrapped as well).
+var (
+	// ErrBufferFull is the error wrapped when a line does not fit into [CsvReader.BufferSize]
+	// (or [CsvReader.MaxLineSize], see [CsvReader.AutoGrowBuffer]).
+	ErrBufferFull = errors.New("line exceeds buffer size")
+	// ErrRecordTooLong is the error wrapped when a record spanning multiple lines
+	// (see [CsvReader.CRLFInQuotedFields]) does not fit into [CsvReader.BufferSize]
+	// (or [CsvReader.MaxLineSize], see [CsvReader.AutoGrowBuffer]).
+	ErrRecordTooLong = errors.New("record exceeds buffer size")
+	// ErrChunkIncomplete is the error wrapped when a goroutine stopped reading before the end of its chunk,
+	// because of a read error, or because the file was truncated meanwhile.
+	ErrChunkIncomplete = errors.New("chunk was not read entirely")
+	// ErrBadHeader is the error wrapped when the header could not be read or parsed.
+	ErrBadHeader = errors.New("bad header")
+	// ErrCanceledWithPartialData is the error wrapped (along with the context's error) when reading was canceled,
+	// meaning rows emitted so far are partial data.
+	ErrCanceledWithPartialData = errors.New("reading canceled, partial data was emitted")
+	// ErrFieldsCountMismatch is the error a [*FieldsCountMismatchError] wraps.
+	ErrFieldsCountMismatch = errors.New("chunks disagree on fields count")
+)
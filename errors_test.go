@@ -0,0 +1,104 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/actforgood/bigcsvreader"
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+func TestCsvReader_sentinelErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bad header", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("data.csv")
+		subject.FS = newMemFS(map[string]string{"data.csv": "id,\"name\n1,John\n"})
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+
+		// act
+		rn := subject.Start(context.Background())
+		err := rn.Err()
+
+		// assert
+		assertTrue(t, errors.Is(err, bigcsvreader.ErrBadHeader))
+	})
+
+	t.Run("canceled with partial data", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_without_header.csv")
+		subject.ColumnsCount = 3
+		ctx, cancelCtx := context.WithCancel(context.Background())
+		cancelCtx()
+
+		// act
+		rn := subject.Start(ctx)
+		_ = drainRows(rn.RowsChans())
+		err := rn.Err()
+
+		// assert
+		assertTrue(t, errors.Is(err, bigcsvreader.ErrCanceledWithPartialData))
+		assertTrue(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("chunk incomplete", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange: the file is truncated after its size was read.
+		subject := bigcsvreader.New()
+		subject.SetFilePath("data.csv")
+		subject.FS = grownFS{FS: newMemFS(map[string]string{"data.csv": "1,John\n2,Jane\n"}), extra: 10}
+		subject.ColumnsCount = 2
+
+		// act
+		rn := subject.Start(context.Background())
+		rowsCount := drainRows(rn.RowsChans())
+		err := rn.Err()
+
+		// assert
+		assertEqual(t, 2, rowsCount)
+		assertTrue(t, errors.Is(err, bigcsvreader.ErrChunkIncomplete))
+	})
+}
+
+// grownFS reports files bigger than they are.
+type grownFS struct {
+	internal.FS
+	extra int64
+}
+
+// Stat returns the named file's info, with its size increased.
+func (fsys grownFS) Stat(name string) (os.FileInfo, error) {
+	info, err := fsys.FS.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return grownFileInfo{FileInfo: info, extra: fsys.extra}, nil
+}
+
+// grownFileInfo reports a file size bigger than it is.
+type grownFileInfo struct {
+	os.FileInfo
+	extra int64
+}
+
+// Size returns the increased file size.
+func (info grownFileInfo) Size() int64 {
+	return info.FileInfo.Size() + info.extra
+}
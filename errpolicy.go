@@ -0,0 +1,43 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import "errors"
+
+// ErrTooManyBadRows is the error sent through ErrsChan when reading was aborted
+// because of the bad rows, according to [CsvReader.ErrorPolicy].
+var ErrTooManyBadRows = errors.New("too many bad rows")
+
+// ErrorPolicy tells how bad rows (rows which could not be parsed, are invalid, hold an unknown foreign key,
+// or could not be decoded) are handled, see [CsvReader.ErrorPolicy].
+type ErrorPolicy int
+
+const (
+	// SkipBadRows sends an error through ErrsChan for each bad row, and goes on reading. It is the default.
+	SkipBadRows ErrorPolicy = iota
+	// FailFast aborts reading, by all goroutines, at the first bad row.
+	FailFast
+	// CollectUpToN sends an error through ErrsChan for each of the first [CsvReader.MaxBadRows] bad rows,
+	// and aborts reading, by all goroutines, at the next one.
+	CollectUpToN
+)
+
+// newErrorRate returns the tracker of bad rows for a run, nil if they are not limited,
+// see [CsvReader.ErrorPolicy] and [CsvReader.AbortIfErrorRateExceeds].
+func (cr *CsvReader) newErrorRate() *errorRate {
+	maxBadRows := -1
+	switch cr.ErrorPolicy {
+	case FailFast:
+		maxBadRows = 0
+	case CollectUpToN:
+		maxBadRows = max(cr.MaxBadRows, 0)
+	}
+	if cr.maxErrorRate <= 0 && maxBadRows < 0 {
+		return nil
+	}
+
+	return &errorRate{maxRate: cr.maxErrorRate, minSample: int64(cr.errorRateMinSample), maxBadRows: int64(maxBadRows)}
+}
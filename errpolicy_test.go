@@ -0,0 +1,200 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ErrorPolicy(t *testing.T) {
+	t.Parallel()
+
+	// every 100th row is bad.
+	const (
+		rowsCount    = 20000
+		badRowsCount = rowsCount / 100
+	)
+	var content strings.Builder
+	for i := 1; i <= rowsCount; i++ {
+		if i%100 == 0 {
+			content.WriteString(strconv.Itoa(i) + ",bad\n")
+
+			continue
+		}
+		content.WriteString(strconv.Itoa(i) + ",name " + strconv.Itoa(i) + ",33\n")
+	}
+	filePath := writeTmpFile(t, t.TempDir(), "bad_rows.csv", content.String())
+	tests := [...]struct {
+		name       string
+		policy     bigcsvreader.ErrorPolicy
+		maxBadRows int
+		aborted    bool
+	}{
+		{
+			name:    "skip bad rows",
+			policy:  bigcsvreader.SkipBadRows,
+			aborted: false,
+		},
+		{
+			name:    "fail fast",
+			policy:  bigcsvreader.FailFast,
+			aborted: true,
+		},
+		{
+			name:       "collect up to N",
+			policy:     bigcsvreader.CollectUpToN,
+			maxBadRows: 10,
+			aborted:    true,
+		},
+		{
+			name:       "collect up to N, less bad rows",
+			policy:     bigcsvreader.CollectUpToN,
+			maxBadRows: badRowsCount,
+			aborted:    false,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(filePath)
+			subject.ColumnsCount = 3
+			subject.MaxGoroutinesNo = 4
+			subject.ErrorPolicy = test.policy
+			subject.MaxBadRows = test.maxBadRows
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			run := subject.Start(ctx)
+			emitted := drainRows(run.RowsChans())
+			err := run.Err()
+
+			// assert
+			var parseErrs, abortErrs int
+			if joinedErr, ok := err.(interface{ Unwrap() []error }); assertTrue(t, ok) {
+				for _, err := range joinedErr.Unwrap() {
+					var parseErr *csv.ParseError
+					switch {
+					case errors.As(err, &parseErr):
+						parseErrs++
+					case errors.Is(err, bigcsvreader.ErrTooManyBadRows):
+						abortErrs++
+					}
+				}
+			}
+			if !test.aborted {
+				assertEqual(t, 0, abortErrs)
+				assertEqual(t, badRowsCount, parseErrs)
+				assertEqual(t, rowsCount-badRowsCount, emitted)
+
+				return
+			}
+			assertEqual(t, 1, abortErrs)
+			// each goroutine may report a few more bad rows until it observes the abort.
+			assertTrue(t, parseErrs > test.maxBadRows && parseErrs < badRowsCount/2)
+			assertTrue(t, emitted < rowsCount-badRowsCount)
+		})
+	}
+}
+
+func TestCsvReader_ErrorPolicy_decodeAndForeignKeyErrors(t *testing.T) {
+	t.Parallel()
+
+	// every 100th row holds an unknown key, which can't be decoded either.
+	const rowsCount = 1000
+	var content strings.Builder
+	for i := 1; i <= rowsCount; i++ {
+		content.WriteString(strconv.Itoa(i) + ",name " + strconv.Itoa(i) + "\n")
+	}
+	filePath := writeTmpFile(t, t.TempDir(), "bad_keys.csv", content.String())
+	isBadKey := func(key string) bool {
+		return strings.HasSuffix(key, "00")
+	}
+
+	t.Run("decode errors", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 1
+		subject.ErrorPolicy = bigcsvreader.FailFast
+		subject.Decoder = func(row []string) (any, error) {
+			if isBadKey(row[0]) {
+				return nil, errors.New("intentionally triggered decode error")
+			}
+
+			return row[0], nil
+		}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		decodedChans, errsChan := subject.ReadDecoded(ctx)
+		var err error
+		done := make(chan struct{})
+		go func() {
+			err = bigcsvreader.CollectErrors(errsChan)
+			close(done)
+		}()
+		var decoded int
+		for _, decodedChan := range decodedChans {
+			for range decodedChan {
+				decoded++
+			}
+		}
+		<-done
+
+		// assert
+		assertTrue(t, errors.Is(err, bigcsvreader.ErrTooManyBadRows))
+		assertEqual(t, 99, decoded)
+	})
+
+	t.Run("unknown foreign keys", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 1
+		subject.ErrorPolicy = bigcsvreader.FailFast
+		subject.ForeignKey = &bigcsvreader.ForeignKeyCheck{
+			Column: 0,
+			Lookup: func(_ context.Context, key string) (bool, error) {
+				return !isBadKey(key), nil
+			},
+			BatchSize: 10,
+		}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		emitted := drainRows(run.RowsChans())
+		err := run.Err()
+
+		// assert
+		var fkErr *bigcsvreader.UnknownForeignKeyError
+		assertTrue(t, errors.As(err, &fkErr))
+		assertTrue(t, errors.Is(err, bigcsvreader.ErrTooManyBadRows))
+		assertEqual(t, 99, emitted)
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrErrorRateExceeded is the error sent through ErrsChan when reading was aborted
+// because the rate of bad rows exceeded the limit set with [CsvReader.AbortIfErrorRateExceeds].
+var ErrErrorRateExceeded = errors.New("bad rows rate exceeded")
+
+// AbortIfErrorRateExceeds configures the reader to stop reading as soon as the ratio
+// of bad rows (rows which could not be parsed or are invalid) to read rows exceeds given ratio,
+// once at least minSample rows were read.
+// This way, reading a clearly wrong file (wrong delimiter, wrong file) stops within seconds,
+// instead of pushing millions of errors through ErrsChan.
+// A ratio <= 0 disables the check.
+func (cr *CsvReader) AbortIfErrorRateExceeds(ratio float64, minSample int) {
+	cr.maxErrorRate = ratio
+	cr.errorRateMinSample = minSample
+}
+
+// errorRate keeps track of the rate and number of bad rows during a run.
+type errorRate struct {
+	// maxRate is the bad rows ratio above which reading is aborted, 0 means no limit.
+	maxRate   float64
+	minSample int64
+	// maxBadRows is the number of bad rows above which reading is aborted, -1 means no limit, see [CsvReader.ErrorPolicy].
+	maxBadRows int64
+	// rowsCount is the number of read rows, updated atomically.
+	rowsCount int64
+	// badRowsCount is the number of bad rows, updated atomically.
+	badRowsCount int64
+	// aborted is a flag (updated atomically) indicating a limit was exceeded: 0 if not,
+	// abortedByRate or abortedByCount otherwise.
+	aborted int32
+}
+
+const (
+	// abortedByRate tells reading was aborted as the bad rows rate was exceeded.
+	abortedByRate int32 = iota + 1
+	// abortedByCount tells reading was aborted as the number of bad rows was exceeded.
+	abortedByCount
+)
+
+// add accounts a read row. If the row is bad and a limit is exceeded (for the first time),
+// an error wrapping [ErrErrorRateExceeded] or [ErrTooManyBadRows] is returned.
+func (er *errorRate) add(bad bool) error {
+	rowsCount := atomic.AddInt64(&er.rowsCount, 1)
+	if !bad {
+		return nil
+	}
+	badRowsCount := atomic.AddInt64(&er.badRowsCount, 1)
+	var (
+		abortedBy int32
+		err       error
+	)
+	switch {
+	case er.maxBadRows >= 0 && badRowsCount > er.maxBadRows:
+		abortedBy = abortedByCount
+		err = fmt.Errorf("%d bad rows, %d tolerated (%w)", badRowsCount, er.maxBadRows, ErrTooManyBadRows)
+	case er.maxRate > 0 && rowsCount >= er.minSample && float64(badRowsCount)/float64(rowsCount) > er.maxRate:
+		abortedBy = abortedByRate
+		err = fmt.Errorf("%d bad rows out of %d (%w)", badRowsCount, rowsCount, ErrErrorRateExceeded)
+	default:
+		return nil
+	}
+	if !atomic.CompareAndSwapInt32(&er.aborted, 0, abortedBy) {
+		return nil // already reported.
+	}
+
+	return err
+}
+
+// isAborted returns true if a limit was exceeded.
+func (er *errorRate) isAborted() bool {
+	return er != nil && atomic.LoadInt32(&er.aborted) != 0
+}
+
+// abortErr returns the error reading was aborted for, [ErrErrorRateExceeded] or [ErrTooManyBadRows].
+func (er *errorRate) abortErr() error {
+	if atomic.LoadInt32(&er.aborted) == abortedByCount {
+		return ErrTooManyBadRows
+	}
+
+	return ErrErrorRateExceeded
+}
@@ -0,0 +1,77 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_AbortIfErrorRateExceeds(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reading garbage is aborted", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 100000
+		filePath := filepath.Join(t.TempDir(), "wrong_delimiter.csv")
+		err := os.WriteFile(filePath, []byte(strings.Repeat("1;John;33\n", rowsCount)), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 3
+		subject.AbortIfErrorRateExceeds(0.5, 10)
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		drainRows(run.RowsChans())
+		err = run.Err()
+
+		// assert
+		assertTrue(t, errors.Is(err, bigcsvreader.ErrErrorRateExceeded))
+		joinedErr, ok := err.(interface{ Unwrap() []error })
+		if assertTrue(t, ok) {
+			// each goroutine may report a few more errors until it observes the abort.
+			errsCount := len(joinedErr.Unwrap())
+			assertTrue(t, errsCount < rowsCount/100)
+		}
+	})
+
+	t.Run("rate below the limit is not aborted", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/invalid_row.csv")
+		subject.ColumnsCount = 3
+		subject.FileHasHeader = true
+		subject.AbortIfErrorRateExceeds(0.5, 1)
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		rowsCount := drainRows(run.RowsChans())
+		err := run.Err()
+
+		// assert
+		assertEqual(t, 4, rowsCount)
+		assertNotNil(t, err)
+		assertTrue(t, !errors.Is(err, bigcsvreader.ErrErrorRateExceeded))
+	})
+}
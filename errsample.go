@@ -0,0 +1,113 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrorSampling configures the sampling of errors sent through ErrsChan (and logged), per error class,
+// so that millions of similar errors (like a wrong number of fields on each row) do not drown
+// consumers and logs. The class of an error is given by its innermost wrapped error
+// (its type, or its message for plain errors created with [errors.New]).
+type ErrorSampling struct {
+	// First is the number of errors of a class which are sent verbatim.
+	First int
+	// Every is the sampling rate of the errors of a class, after the first ones:
+	// 1 out of Every errors is sent. Zero (or negative) means no other error is sent.
+	Every int
+}
+
+// SuppressedErrorsError is the error sent through ErrsChan, at the end of the run, for each error class
+// some errors were suppressed for by [CsvReader.ErrorSampling].
+type SuppressedErrorsError struct {
+	// Class is the errors' class.
+	Class string
+	// Count is the number of errors of the class which occurred.
+	Count int64
+	// Suppressed is the number of errors of the class which were not sent.
+	Suppressed int64
+}
+
+// Error returns the error's message.
+func (e *SuppressedErrorsError) Error() string {
+	return fmt.Sprintf("%d errors of class %q occurred, %d were suppressed by sampling", e.Count, e.Class, e.Suppressed)
+}
+
+// errorSampler samples errors by class during a run.
+type errorSampler struct {
+	ErrorSampling
+	mu sync.Mutex
+	// classes holds the counters of each error class.
+	classes map[string]*SuppressedErrorsError
+	// order holds the error classes, in first occurrence order.
+	order []string
+}
+
+// newErrorSampler instantiates the sampler for the configured [ErrorSampling], if any.
+func (cr *CsvReader) newErrorSampler() *errorSampler {
+	if cr.ErrorSampling == nil {
+		return nil
+	}
+
+	return &errorSampler{ErrorSampling: *cr.ErrorSampling, classes: make(map[string]*SuppressedErrorsError)}
+}
+
+// sample accounts the error, and returns whether it should be sent.
+func (s *errorSampler) sample(err error) bool {
+	class := errorClass(err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counter := s.classes[class]
+	if counter == nil {
+		counter = &SuppressedErrorsError{Class: class}
+		s.classes[class] = counter
+		s.order = append(s.order, class)
+	}
+	counter.Count++
+	sampled := counter.Count <= int64(s.First) ||
+		(s.Every > 0 && (counter.Count-int64(s.First))%int64(s.Every) == 0)
+	if !sampled {
+		counter.Suppressed++
+	}
+
+	return sampled
+}
+
+// suppressed returns the counters of the error classes some errors were suppressed for, in first occurrence order.
+func (s *errorSampler) suppressed() []*SuppressedErrorsError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var suppressed []*SuppressedErrorsError
+	for _, class := range s.order {
+		if counter := s.classes[class]; counter.Suppressed > 0 {
+			suppressedCounter := *counter
+			suppressed = append(suppressed, &suppressedCounter)
+		}
+	}
+
+	return suppressed
+}
+
+// errorClass returns the class of the error: the type of its innermost wrapped error,
+// or its message, for plain errors.
+func errorClass(err error) string {
+	for {
+		wrapped := errors.Unwrap(err)
+		if wrapped == nil {
+			break
+		}
+		err = wrapped
+	}
+	class := fmt.Sprintf("%T", err)
+	if class == "*errors.errorString" {
+		class = err.Error()
+	}
+
+	return class
+}
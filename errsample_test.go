@@ -0,0 +1,81 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ErrorSampling(t *testing.T) {
+	t.Parallel()
+
+	// arrange: 1000 rows having a wrong number of fields, and 10 rows having an invalid value.
+	var sb strings.Builder
+	for i := 0; i < 1000; i++ {
+		sb.WriteString("1,John\n")
+		if i%100 == 0 {
+			sb.WriteString("2,Jane,unknown\n")
+		}
+		sb.WriteString("3,Mike,18\n")
+	}
+	filePath := filepath.Join(t.TempDir(), "errors.csv")
+	if err := os.WriteFile(filePath, []byte(sb.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	subject := bigcsvreader.New()
+	subject.SetFilePath(filePath)
+	subject.ColumnsCount = 3
+	subject.MaxGoroutinesNo = 4
+	subject.ColumnsOptions = map[int]bigcsvreader.ColumnOptions{2: {Pattern: `^\d+$`}}
+	subject.ErrorSampling = &bigcsvreader.ErrorSampling{First: 5, Every: 100}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	rn := subject.Start(ctx)
+	rows := gatherRowsInOrder(rn.RowsChans())
+	err := rn.Err()
+
+	// assert
+	assertEqual(t, 1000, len(rows))
+	var (
+		fieldCountErrs, patternErrs int
+		suppressed                  []*bigcsvreader.SuppressedErrorsError
+	)
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var suppressedErr *bigcsvreader.SuppressedErrorsError
+		var patternErr *bigcsvreader.PatternMismatchError
+		switch {
+		case errors.As(e, &suppressedErr):
+			suppressed = append(suppressed, suppressedErr)
+		case errors.As(e, &patternErr):
+			patternErrs++
+		default:
+			fieldCountErrs++
+		}
+	}
+	assertEqual(t, 5+9, fieldCountErrs) // first 5, then 1 out of 100 of the next 995.
+	assertEqual(t, 5+0, patternErrs)    // first 5, then 1 out of 100 of the next 5.
+	if assertEqual(t, 2, len(suppressed)) {
+		for _, suppressedErr := range suppressed {
+			if suppressedErr.Class == "wrong number of fields" {
+				expected := bigcsvreader.SuppressedErrorsError{Class: "wrong number of fields", Count: 1000, Suppressed: 986}
+				assertEqual(t, expected, *suppressedErr)
+			} else {
+				expected := bigcsvreader.SuppressedErrorsError{Class: "*bigcsvreader.PatternMismatchError", Count: 10, Suppressed: 5}
+				assertEqual(t, expected, *suppressedErr)
+			}
+		}
+	}
+}
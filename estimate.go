@@ -0,0 +1,196 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	// estimateWindows is the maximum number of places the file is sampled at by [CsvReader.EstimateRows].
+	estimateWindows = 8
+	// estimateZ is the z-score of the ~95% confidence interval of an estimation.
+	estimateZ = 1.96
+)
+
+// RowsEstimate holds the estimated number of rows of a CSV file, see [CsvReader.EstimateRows].
+type RowsEstimate struct {
+	// Rows is the estimated number of rows.
+	Rows int64
+	// Low is the lower bound (~95% confidence) of the estimation.
+	Low int64
+	// High is the upper bound (~95% confidence) of the estimation.
+	High int64
+	// SampledRows is the number of rows the estimation is based on.
+	SampledRows int
+	// Exact is a flag indicating the whole file was sampled, so the number of rows is exact.
+	Exact bool
+}
+
+// Percent returns the percentage [0, 100] given number of read rows represents out of the estimated rows,
+// suitable for rendering progress. It is capped at 100, as the estimation can be exceeded.
+func (e RowsEstimate) Percent(rowsRead int64) float64 {
+	if e.Rows <= 0 {
+		return 100
+	}
+
+	return math.Min(100, 100*float64(rowsRead)/float64(e.Rows))
+}
+
+// EstimateRows estimates the number of rows of the CSV file (header excluded), without reading it all,
+// by sampling the average row length from approx. sampleBytes bytes taken from evenly spread places
+// in the file, and dividing the file's size by it. Error bounds are derived from the rows lengths variance.
+// If sampleBytes covers the whole file, rows are counted, and the estimation is exact.
+// Rows are assumed to be single lines. Compressed files are sampled decompressed (plain gzip ones are not supported).
+func (cr *CsvReader) EstimateRows(ctx context.Context, sampleBytes int) (RowsEstimate, error) {
+	if sampleBytes < 1 {
+		return RowsEstimate{}, errors.New("bigcsvreader: sample size must be positive")
+	}
+	cr, stream, err := cr.withDecompressingFS()
+	if err != nil {
+		return RowsEstimate{}, fmt.Errorf("bigcsvreader: could not open file (%w)", err)
+	}
+	if stream {
+		return RowsEstimate{}, fmt.Errorf("bigcsvreader: could not estimate rows (%w)", errUnsupportedWithGzip)
+	}
+	fileSize, err := cr.getFileSize()
+	if err != nil {
+		return RowsEstimate{}, fmt.Errorf("bigcsvreader: file size error (%w)", err)
+	}
+	f, err := cr.FS.Open(cr.filePath)
+	if err != nil {
+		return RowsEstimate{}, fmt.Errorf("bigcsvreader: could not open file (%w)", err)
+	}
+	defer f.Close()
+
+	dataStart := 0
+	if cr.FileHasHeader {
+		if _, dataStart, err = cr.readHeader(f); err != nil {
+			return RowsEstimate{}, err
+		}
+	}
+	dataSize := fileSize - dataStart
+	if dataSize <= 0 {
+		return RowsEstimate{Exact: true}, nil
+	}
+
+	var (
+		lengths lengthStats
+		windows = estimateWindows
+		exact   = sampleBytes >= dataSize
+	)
+	if exact {
+		windows = 1
+	}
+	windowSize := min(max(sampleBytes/windows, 1), dataSize)
+	stride := dataSize / windows
+	buf := make([]byte, windowSize)
+	for i := 0; i < windows; i++ {
+		if err := ctx.Err(); err != nil {
+			return RowsEstimate{}, fmt.Errorf("bigcsvreader: received context error (%w)", err)
+		}
+		offset := dataStart + i*stride
+		n, err := f.ReadAt(buf[:min(windowSize, fileSize-offset)], int64(offset))
+		if err != nil && !errors.Is(err, io.EOF) {
+			return RowsEstimate{}, fmt.Errorf("bigcsvreader: could not read at offset %d (%w)", offset, err)
+		}
+		window := buf[:n]
+		if i > 0 { // skip the line the window starts into, unless it starts right after a line.
+			if prev, err := readByteAt(f, offset-1); err != nil {
+				return RowsEstimate{}, fmt.Errorf("bigcsvreader: could not read at offset %d (%w)", offset-1, err)
+			} else if prev != '\n' {
+				idx := bytes.IndexByte(window, '\n')
+				if idx < 0 {
+					continue // the window is inside a line.
+				}
+				window = window[idx+1:]
+			}
+		}
+		lengths.addLines(window, offset+n == fileSize)
+	}
+
+	if exact {
+		rows := int64(lengths.count)
+
+		return RowsEstimate{Rows: rows, Low: rows, High: rows, SampledRows: lengths.count, Exact: true}, nil
+	}
+	if lengths.count == 0 { // rows are longer than the sampled windows.
+		return RowsEstimate{High: int64(dataSize / windowSize)}, nil
+	}
+	mean, margin := lengths.mean, estimateZ*lengths.stdDev()/math.Sqrt(float64(lengths.count))
+	estimate := RowsEstimate{
+		Rows:        int64(math.Round(float64(dataSize) / mean)),
+		Low:         int64(math.Floor(float64(dataSize) / (mean + margin))),
+		SampledRows: lengths.count,
+	}
+	if mean-margin >= 1 {
+		estimate.High = int64(math.Ceil(float64(dataSize) / (mean - margin)))
+	} else {
+		estimate.High = int64(dataSize) // a row has at least 1 byte.
+	}
+
+	return estimate, nil
+}
+
+// lengthStats accumulates lines lengths statistics (Welford's online algorithm).
+type lengthStats struct {
+	count int
+	mean  float64
+	m2    float64
+}
+
+// addLines accounts the complete lines in given data. If eof is true,
+// a last line without end line delimiter is accounted too.
+// Blank lines, skipped by reading, are not accounted as rows, their length being added to the next row's one.
+func (ls *lengthStats) addLines(data []byte, eof bool) {
+	var blanks int
+	for len(data) > 0 {
+		idx := bytes.IndexByte(data, '\n')
+		if idx < 0 {
+			if !eof {
+				return // partial line.
+			}
+			idx = len(data) - 1
+		}
+		if isBlankLine(data[:idx+1]) {
+			blanks += idx + 1
+			data = data[idx+1:]
+
+			continue
+		}
+		ls.count++
+		length := float64(blanks + idx + 1)
+		blanks = 0
+		delta := length - ls.mean
+		ls.mean += delta / float64(ls.count)
+		ls.m2 += delta * (length - ls.mean)
+		data = data[idx+1:]
+	}
+}
+
+// stdDev returns the sample standard deviation of the lengths.
+func (ls *lengthStats) stdDev() float64 {
+	if ls.count < 2 {
+		return 0
+	}
+
+	return math.Sqrt(ls.m2 / float64(ls.count-1))
+}
+
+// readByteAt returns the byte at given offset.
+func readByteAt(f io.ReaderAt, offset int) (byte, error) {
+	var b [1]byte
+	if _, err := f.ReadAt(b[:], int64(offset)); err != nil {
+		return 0, err
+	}
+
+	return b[0], nil
+}
@@ -0,0 +1,90 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_EstimateRows(t *testing.T) {
+	t.Parallel()
+
+	const rowsCount = 20000
+	var sb strings.Builder
+	sb.WriteString("id,name\n")
+	for i := 1; i <= rowsCount; i++ {
+		sb.WriteString(strconv.Itoa(i) + "," + strings.Repeat("x", i%17) + "\n")
+	}
+	filePath := filepath.Join(t.TempDir(), "estimate.csv")
+	if err := os.WriteFile(filePath, []byte(sb.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("rows are estimated from a sample", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+
+		// act
+		estimate, err := subject.EstimateRows(context.Background(), 16*1024)
+
+		// assert
+		assertNil(t, err)
+		assertTrue(t, !estimate.Exact)
+		assertTrue(t, estimate.SampledRows > 0 && estimate.SampledRows < rowsCount)
+		assertTrue(t, estimate.Low <= rowsCount && rowsCount <= estimate.High)
+		assertTrue(t, estimate.Low <= estimate.Rows && estimate.Rows <= estimate.High)
+		assertTrue(t, estimate.Rows > rowsCount*95/100 && estimate.Rows < rowsCount*105/100)
+		assertEqual(t, float64(100), estimate.Percent(2*rowsCount))
+	})
+
+	t.Run("rows are counted if the whole file is sampled", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+
+		// act
+		estimate, err := subject.EstimateRows(context.Background(), sb.Len())
+
+		// assert
+		assertNil(t, err)
+		assertEqual(
+			t,
+			bigcsvreader.RowsEstimate{Rows: rowsCount, Low: rowsCount, High: rowsCount, SampledRows: rowsCount, Exact: true},
+			estimate,
+		)
+		assertEqual(t, float64(50), estimate.Percent(rowsCount/2))
+	})
+
+	t.Run("blank lines are not counted", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		content := "1,a\n\n2,b\n\n"
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, t.TempDir(), "blank_lines.csv", content))
+
+		// act
+		estimate, err := subject.EstimateRows(context.Background(), len(content))
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, bigcsvreader.RowsEstimate{Rows: 2, Low: 2, High: 2, SampledRows: 2, Exact: true}, estimate)
+	})
+}
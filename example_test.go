@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"strconv"
 	"sync"
+	"sync/atomic"
 
 	"github.com/actforgood/bigcsvreader"
 )
@@ -105,3 +106,129 @@ func processRow(row []string) {
 func handleError(err error) {
 	fmt.Println(err)
 }
+
+func ExampleConsume() {
+	// initialize the big csv reader
+	bigCSV := bigcsvreader.New()
+	bigCSV.SetFilePath("testdata/example_products.csv")
+	bigCSV.ColumnsCount = noOfColumns
+	bigCSV.MaxGoroutinesNo = 16
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+	var totalQty int64
+
+	// start multi-thread reading
+	rowsChans, errsChan := bigCSV.Read(ctx)
+
+	// process rows with a pool of 4 workers, in batches of 100 rows.
+	opts := bigcsvreader.ConsumeOptions{WorkersNo: 4, BatchSize: 100}
+	err := bigcsvreader.Consume(ctx, rowsChans, errsChan, opts, func(_ context.Context, rows [][]string) error {
+		for _, row := range rows {
+			qty, err := strconv.Atoi(row[columnProductQty])
+			if err != nil {
+				return err
+			}
+			atomic.AddInt64(&totalQty, int64(qty))
+		}
+
+		return nil
+	})
+
+	fmt.Println(totalQty, err)
+
+	// Output:
+	// 271 <nil>
+}
+
+func ExampleCsvReader_Process() {
+	// initialize the big csv reader
+	bigCSV := bigcsvreader.New()
+	bigCSV.SetFilePath("testdata/example_products.csv")
+	bigCSV.ColumnsCount = noOfColumns
+	bigCSV.MaxGoroutinesNo = 16
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+	var totalQty int64
+
+	// read and process rows, one goroutine per rows channel, until done.
+	err := bigCSV.Process(ctx, func(row []string) error {
+		qty, err := strconv.Atoi(row[columnProductQty])
+		if err != nil {
+			return err
+		}
+		atomic.AddInt64(&totalQty, int64(qty))
+
+		return nil
+	})
+
+	fmt.Println(totalQty, err)
+
+	// Output:
+	// 271 <nil>
+}
+
+func ExampleMapRows() {
+	// initialize the big csv reader
+	bigCSV := bigcsvreader.New()
+	bigCSV.SetFilePath("testdata/example_products.csv")
+	bigCSV.ColumnsCount = noOfColumns
+	bigCSV.MaxGoroutinesNo = 16
+
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+	var wg sync.WaitGroup
+
+	// start multi-thread reading, and decode rows into products.
+	rowsChans, errsChan := bigCSV.Read(ctx)
+	productsChans, errsChan := bigcsvreader.MapRows(rowsChans, errsChan, decodeProduct)
+
+	// process products and errors:
+
+	for i := 0; i < len(productsChans); i++ {
+		wg.Add(1)
+		go func(productsChan <-chan Product) {
+			defer wg.Done()
+			for product := range productsChan {
+				fmt.Println(product.ID, product.Name, product.Price, product.Qty)
+			}
+		}(productsChans[i])
+	}
+
+	wg.Add(1)
+	go errWorker(errsChan, &wg)
+
+	wg.Wait()
+
+	// Unordered output:
+	// 1 Apple iPhone 13 1025.99 100
+	// 2 Samsung Galaxy S22 400.99 12
+	// 3 Apple MacBook Air 700.99 34
+	// 4 Lenovo ThinkPad X1 550.99 90
+	// 5 Logitech Mouse G203 30.5 35
+}
+
+// decodeProduct converts a row into a Product.
+func decodeProduct(row []string) (Product, error) {
+	id, err := strconv.Atoi(row[columnProductID])
+	if err != nil {
+		return Product{}, err
+	}
+	price, err := strconv.ParseFloat(row[columnProductPrice], 64)
+	if err != nil {
+		return Product{}, err
+	}
+	qty, err := strconv.Atoi(row[columnProductQty])
+	if err != nil {
+		return Product{}, err
+	}
+
+	return Product{
+		ID:    id,
+		Name:  row[columnProductName],
+		Desc:  row[columnProductDescription],
+		Price: price,
+		Qty:   qty,
+	}, nil
+}
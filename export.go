@@ -0,0 +1,275 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+// Export writes into w a copy of the CSV file holding only given columns, in given order,
+// delimited by given delimiter. Columns are identified by their header names, so the file must have a header.
+// Rows are read and projected in parallel, and written in the file's order.
+// Nothing is written into w if reading failed.
+func (cr *CsvReader) Export(ctx context.Context, w io.Writer, columns []string, delimiter rune) error {
+	if !cr.FileHasHeader {
+		return errors.New("bigcsvreader: export requires a file with header")
+	}
+	cr = cr.withContextFS(ctx)
+	header, err := cr.ReadHeader(ctx)
+	if err != nil {
+		return err
+	}
+	indexes, err := columnsIndexes(header, columns)
+	if err != nil {
+		return err
+	}
+
+	return cr.writeOrdered(ctx, w, columns, delimiter, func(row []string) ([]string, error) {
+		return project(row, indexes), nil
+	})
+}
+
+// columnsIndexes returns the indexes of given columns in the header.
+func columnsIndexes(header, columns []string) ([]int, error) {
+	indexes := make([]int, len(columns))
+	for i, column := range columns {
+		indexes[i] = -1
+		for col, name := range header {
+			if name == column {
+				indexes[i] = col
+
+				break
+			}
+		}
+		if indexes[i] < 0 {
+			return nil, fmt.Errorf("bigcsvreader: unknown column %q", column)
+		}
+	}
+
+	return indexes, nil
+}
+
+// project returns a new row holding the values of the row at given indexes (empty for negative indexes).
+func project(row []string, indexes []int) []string {
+	projected := make([]string, len(indexes))
+	for i, col := range indexes {
+		if col >= 0 && col < len(row) {
+			projected[i] = row[col]
+		}
+	}
+
+	return projected
+}
+
+// Header returns the parsed header (first line) of the CSV file.
+// It is [CsvReader.ReadHeader] with a background context.
+func (cr *CsvReader) Header() ([]string, error) {
+	return cr.ReadHeader(context.Background())
+}
+
+// ReadHeader opens the file, reads just its first record and returns it, synchronously,
+// without starting any goroutine; useful for letting users map the columns before the full parallel read.
+// The first record is parsed the same way a run parses the header, whatever [CsvReader.FileHasHeader] is.
+// Reading stops with an error wrapping the context's one if ctx is done.
+func (cr *CsvReader) ReadHeader(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("bigcsvreader: received context error (%w)", err)
+	}
+	cr = cr.withContextFS(ctx)
+	fsys, _, err := cr.decompressingFS()
+	var f internal.File
+	if err == nil {
+		f, err = fsys.Open(cr.filePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not open file (%w)", err)
+	}
+	defer f.Close()
+	header, _, err := cr.readHeader(ctxReaderAt{ctx: ctx, r: f})
+
+	return header, err
+}
+
+// ctxReaderAt is an [io.ReaderAt] which stops reading once its context is done.
+type ctxReaderAt struct {
+	ctx context.Context
+	r   io.ReaderAt
+}
+
+// ReadAt reads from the underlying reader, unless the context is done.
+func (r ctxReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return r.r.ReadAt(p, off)
+}
+
+// readHeader reads and parses the header (first line) of given file.
+// It returns also the header's size, end line delimiter included.
+func (cr *CsvReader) readHeader(f io.ReaderAt) ([]string, int, error) {
+	if cr.Encoding.byteOrder() != nil {
+		return cr.readUTF16Header(f)
+	}
+	line, err := readLineAt(f, 0)
+	if err != nil {
+		return nil, 0, fmt.Errorf("bigcsvreader: could not read header (%w: %w)", ErrBadHeader, err)
+	}
+	header, err := cr.newLineParser(-1).parse(line)
+	if err != nil {
+		return nil, 0, fmt.Errorf("bigcsvreader: could not parse header (%w: %w)", ErrBadHeader, err)
+	}
+
+	return header, len(line), nil
+}
+
+// writeOrdered reads the file, passes each row through transform, and writes the result into w,
+// preceded by header (if not nil), delimited by given delimiter, in the file's order.
+// Each reading goroutine's rows are spooled into a temporary file, in parallel,
+// and spools are concatenated in order into w after reading succeeded, keeping memory bounded.
+// The transform function is called concurrently, for rows of different goroutines,
+// so it must not share state across calls unless guarded; the returned row is written
+// before the next call for the same goroutine.
+func (cr *CsvReader) writeOrdered(
+	ctx context.Context,
+	w io.Writer,
+	header []string,
+	delimiter rune,
+	transform func(row []string) ([]string, error),
+) error {
+	var headers [][]string
+	if header != nil {
+		headers = [][]string{header}
+	}
+
+	return cr.writeOrderedMulti(ctx, []io.Writer{w}, headers, delimiter, func(row []string) ([][]string, error) {
+		out, err := transform(row)
+
+		return [][]string{out}, err
+	})
+}
+
+// writeOrderedMulti is the multiple outputs version of [CsvReader.writeOrdered]:
+// transform returns a row for each writer, and headers, if not nil, hold a header for each writer.
+// Rows are read once, and each reading goroutine spools a temporary file for each writer.
+func (cr *CsvReader) writeOrderedMulti(
+	ctx context.Context,
+	ws []io.Writer,
+	headers [][]string,
+	delimiter rune,
+	transform func(row []string) ([][]string, error),
+) error {
+	reader := cr.snapshot()
+	reader.EmitHeader = false // headers, if any, are written separately.
+	rn := reader.Start(ctx)
+	rowsChans := rn.RowsChans()
+	var (
+		spools = make([][]*os.File, len(rowsChans))
+		errs   = make([]error, len(rowsChans))
+		wg     sync.WaitGroup
+	)
+	defer func() {
+		for _, threadSpools := range spools {
+			for _, spool := range threadSpools {
+				if spool != nil {
+					_ = spool.Close()
+					_ = os.Remove(spool.Name())
+				}
+			}
+		}
+	}()
+
+	wg.Add(len(rowsChans))
+	for i := range rowsChans {
+		go func(i int) {
+			defer wg.Done()
+			spools[i] = make([]*os.File, len(ws))
+			for j := range ws {
+				spool, err := os.CreateTemp("", "bigcsvreader-spool-*.csv")
+				if err != nil {
+					errs[i] = err
+
+					break
+				}
+				spools[i][j] = spool
+			}
+			if errs[i] == nil {
+				errs[i] = writeSpools(spools[i], rowsChans[i], delimiter, cr.OutputQuotePolicy, transform)
+			}
+			for range rowsChans[i] { // drain, in case of error.
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := errors.Join(rn.Err(), errors.Join(errs...)); err != nil {
+		return err
+	}
+
+	for j, w := range ws {
+		if headers != nil {
+			if err := writeHeader(w, headers[j], delimiter, cr.OutputQuotePolicy); err != nil {
+				return err
+			}
+		}
+		for _, threadSpools := range spools {
+			spool := threadSpools[j]
+			if _, err := spool.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("bigcsvreader: could not read spool (%w)", err)
+			}
+			if _, err := io.Copy(w, spool); err != nil {
+				return fmt.Errorf("bigcsvreader: could not write rows (%w)", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeSpools writes the transformed rows into the spool files, the i-th transformed row into the i-th spool.
+func writeSpools(
+	spools []*os.File,
+	rowsChan RowsChan,
+	delimiter rune,
+	policy QuotePolicy,
+	transform func(row []string) ([][]string, error),
+) error {
+	bws := make([]*bufio.Writer, len(spools))
+	csvWriters := make([]recordWriter, len(spools))
+	for i, spool := range spools {
+		bws[i] = bufio.NewWriter(spool)
+		csvWriters[i] = newRecordWriter(bws[i], delimiter, policy)
+	}
+	for row := range rowsChan {
+		outs, err := transform(row)
+		if err != nil {
+			return fmt.Errorf("bigcsvreader: could not transform row (%w)", err)
+		}
+		for i, out := range outs {
+			if err := csvWriters[i].Write(out); err != nil {
+				return fmt.Errorf("bigcsvreader: could not write spool (%w)", err)
+			}
+		}
+	}
+	for i := range spools {
+		csvWriters[i].Flush()
+		if err := csvWriters[i].Error(); err != nil {
+			return fmt.Errorf("bigcsvreader: could not write spool (%w)", err)
+		}
+		if err := bws[i].Flush(); err != nil {
+			return fmt.Errorf("bigcsvreader: could not write spool (%w)", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_Export(t *testing.T) {
+	t.Parallel()
+
+	t.Run("columns are projected and reordered, rows order is kept", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 5000
+		var input, expected strings.Builder
+		input.WriteString("id,name,age\n")
+		expected.WriteString("age|id\n")
+		for i := 1; i <= rowsCount; i++ {
+			fmt.Fprintf(&input, "%d,Name_%d,%d\n", i, i, i%90)
+			fmt.Fprintf(&expected, "%d|%d\n", i%90, i)
+		}
+		filePath := filepath.Join(t.TempDir(), "people.csv")
+		if err := os.WriteFile(filePath, []byte(input.String()), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.MaxGoroutinesNo = 8
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+		var w bytes.Buffer
+
+		// act
+		err := subject.Export(ctx, &w, []string{"age", "id"}, '|')
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, expected.String(), w.String())
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsDelimiter = ';'
+		var w bytes.Buffer
+
+		// act
+		err := subject.Export(context.Background(), &w, []string{"Email"}, ',')
+
+		// assert
+		assertNotNil(t, err)
+		assertEqual(t, 0, w.Len())
+	})
+
+	t.Run("fields are re-quoted according to the output quote policy", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		content := "id;name;note\n" +
+			"1;a|b;\"say \"\"hi\"\"\"\n" +
+			"2;plain;\"multi\r\nline\"\n" +
+			"3;;x\n"
+		filePath := writeTmpFile(t, t.TempDir(), "notes.csv", content)
+		expectedRecords := [][]string{
+			{"note", "name", "id"},
+			{`say "hi"`, "a|b", "1"},
+			{"multi\nline", "plain", "2"},
+			{"x", "", "3"},
+		}
+		expected := map[bigcsvreader.QuotePolicy]string{
+			bigcsvreader.QuoteMinimal: "note|name|id\n" +
+				"\"say \"\"hi\"\"\"|\"a|b\"|1\n" +
+				"\"multi\nline\"|plain|2\n" +
+				"x||3\n",
+			bigcsvreader.QuoteAlways: "\"note\"|\"name\"|\"id\"\n" +
+				"\"say \"\"hi\"\"\"|\"a|b\"|\"1\"\n" +
+				"\"multi\nline\"|\"plain\"|\"2\"\n" +
+				"\"x\"|\"\"|\"3\"\n",
+		}
+		for policy, expectedOutput := range expected {
+			subject := bigcsvreader.New()
+			subject.SetFilePath(filePath)
+			subject.FileHasHeader = true
+			subject.ColumnsCount = 3
+			subject.ColumnsDelimiter = ';'
+			subject.CRLFInQuotedFields = true
+			subject.OutputQuotePolicy = policy
+			var w bytes.Buffer
+
+			// act
+			err := subject.Export(context.Background(), &w, []string{"note", "name", "id"}, '|')
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, expectedOutput, w.String())
+			csvReader := csv.NewReader(&w)
+			csvReader.Comma = '|'
+			records, err := csvReader.ReadAll()
+			assertNil(t, err)
+			assertEqual(t, expectedRecords, records)
+		}
+	})
+
+	t.Run("invalid output delimiter", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.ColumnsDelimiter = ';'
+		subject.OutputQuotePolicy = bigcsvreader.QuoteAlways
+		var w bytes.Buffer
+
+		// act
+		err := subject.Export(context.Background(), &w, []string{"Name"}, '"')
+
+		// assert
+		assertNotNil(t, err)
+		assertEqual(t, 0, w.Len())
+	})
+
+	t.Run("nothing is written on read error", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/invalid_row.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		var w bytes.Buffer
+
+		// act
+		err := subject.Export(context.Background(), &w, []string{"Name"}, ',')
+
+		// assert
+		assertNotNil(t, err)
+		assertEqual(t, 0, w.Len())
+	})
+}
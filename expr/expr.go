@@ -0,0 +1,279 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+// Package expr provides a small expression language to filter and project CSV rows,
+// like `price > 100 && country == "US"`.
+//
+// Expressions support:
+//   - column references, by header name (`price`) or by 1-based position (`$3`);
+//   - string ("US" or 'US'), number (100, 2.5) and boolean (true, false) literals;
+//   - arithmetic operators: + - * / % (+ concatenates if an operand is a non-numeric string);
+//   - comparison operators: == != < <= > >= (numeric if an operand is a number, lexicographic otherwise);
+//   - logical operators: && || !;
+//   - functions: lower(s), upper(s), trim(s), len(s), contains(s, sub), startsWith(s, prefix), endsWith(s, suffix).
+//
+// Column values are strings, converted on demand: `price > 100` parses price as a number.
+// Expressions are compiled once into a [Program], which is safe for concurrent use.
+package expr
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Program is a compiled expression.
+// It is safe for concurrent use.
+type Program struct {
+	src  string
+	eval evalFunc
+}
+
+// evalFunc evaluates a compiled (sub)expression against a row.
+type evalFunc func(row []string) (Value, error)
+
+// Compile compiles the expression, resolving column names against given columns (usually the header).
+func Compile(src string, columns []string) (*Program, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, fmt.Errorf("expr: %w", err)
+	}
+	p := &parser{tokens: tokens, columns: columns}
+	eval, err := p.parseExpr(0)
+	if err == nil && p.peek().kind != tokenEOF {
+		err = fmt.Errorf("unexpected %q at position %d", p.peek().text, p.peek().pos)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("expr: %w", err)
+	}
+
+	return &Program{src: src, eval: eval}, nil
+}
+
+// String returns the program's source expression.
+func (p *Program) String() string {
+	return p.src
+}
+
+// Eval evaluates the program against given row.
+func (p *Program) Eval(row []string) (Value, error) {
+	value, err := p.eval(row)
+	if err != nil {
+		return Value{}, fmt.Errorf("expr: %w", err)
+	}
+
+	return value, nil
+}
+
+// Match evaluates the program against given row, and returns its result as a boolean.
+func (p *Program) Match(row []string) (bool, error) {
+	value, err := p.Eval(row)
+	if err != nil {
+		return false, err
+	}
+	match, err := value.AsBool()
+	if err != nil {
+		return false, fmt.Errorf("expr: %w", err)
+	}
+
+	return match, nil
+}
+
+// binaryPrecedence holds the binary operators' precedence, higher binds tighter.
+var binaryPrecedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5, "%": 5,
+}
+
+// parser is a precedence climbing parser, which compiles the tokens into closures.
+type parser struct {
+	tokens  []token
+	pos     int
+	columns []string
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+// parseExpr parses a binary expression whose operators have a precedence higher than minPrecedence.
+func (p *parser) parseExpr(minPrecedence int) (evalFunc, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		precedence, ok := binaryPrecedence[tok.text]
+		if tok.kind != tokenOperator || !ok || precedence <= minPrecedence {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseExpr(precedence)
+		if err != nil {
+			return nil, err
+		}
+		left = binary(tok.text, left, right)
+	}
+}
+
+// parseUnary parses a unary expression.
+func (p *parser) parseUnary() (evalFunc, error) {
+	tok := p.peek()
+	if tok.kind == tokenOperator && (tok.text == "!" || tok.text == "-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if tok.text == "!" {
+			return func(row []string) (Value, error) {
+				v, err := operand(row)
+				if err != nil {
+					return Value{}, err
+				}
+				b, err := v.AsBool()
+
+				return Bool(!b), err
+			}, nil
+		}
+
+		return func(row []string) (Value, error) {
+			v, err := operand(row)
+			if err != nil {
+				return Value{}, err
+			}
+			n, err := v.AsNumber()
+
+			return Number(-n), err
+		}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a literal, a column reference, a function call, or a parenthesized expression.
+func (p *parser) parsePrimary() (evalFunc, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+
+		return constant(Number(n)), nil
+	case tokenString:
+		return constant(String(tok.text)), nil
+	case tokenColumn:
+		col, err := strconv.Atoi(tok.text)
+		if err != nil || col < 1 {
+			return nil, fmt.Errorf("invalid column $%s at position %d", tok.text, tok.pos)
+		}
+
+		return column(col - 1), nil
+	case tokenIdent:
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(tok)
+		}
+		switch tok.text {
+		case "true":
+			return constant(Bool(true)), nil
+		case "false":
+			return constant(Bool(false)), nil
+		}
+		for col, name := range p.columns {
+			if name == tok.text {
+				return column(col), nil
+			}
+		}
+
+		return nil, fmt.Errorf("unknown column %q at position %d", tok.text, tok.pos)
+	case tokenLParen:
+		inner, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ) at position %d", closing.pos)
+		}
+
+		return inner, nil
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+	}
+}
+
+// parseCall parses the arguments of a call to given function.
+func (p *parser) parseCall(name token) (evalFunc, error) {
+	fn, found := functions[name.text]
+	if !found {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.text, name.pos)
+	}
+	p.next() // (
+	var args []evalFunc
+	if p.peek().kind != tokenRParen {
+		for {
+			arg, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if closing := p.next(); closing.kind != tokenRParen {
+		return nil, fmt.Errorf("expected ) at position %d", closing.pos)
+	}
+	if len(args) != fn.arity {
+		return nil, fmt.Errorf("function %q expects %d arguments, got %d", name.text, fn.arity, len(args))
+	}
+
+	return func(row []string) (Value, error) {
+		values := make([]Value, len(args))
+		for i, arg := range args {
+			var err error
+			if values[i], err = arg(row); err != nil {
+				return Value{}, err
+			}
+		}
+
+		return fn.call(values)
+	}, nil
+}
+
+// constant returns an evalFunc returning given value.
+func constant(v Value) evalFunc {
+	return func([]string) (Value, error) {
+		return v, nil
+	}
+}
+
+// column returns an evalFunc returning the value of given column (0-based).
+func column(col int) evalFunc {
+	return func(row []string) (Value, error) {
+		if col >= len(row) {
+			return Value{}, fmt.Errorf("row has no column #%d", col+1)
+		}
+
+		return String(row[col]), nil
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/actforgood/bigcsvreader/expr"
+)
+
+func TestProgram_Eval(t *testing.T) {
+	t.Parallel()
+
+	columns := []string{"id", "price", "country", "active"}
+	row := []string{"7", "150.5", "US", "true"}
+	tests := [...]struct {
+		src      string
+		expected string
+	}{
+		{src: `price > 100 && country == "US"`, expected: "true"},
+		{src: `price > 200 || country == 'RO'`, expected: "false"},
+		{src: `!(price <= 100)`, expected: "true"},
+		{src: `$1 * 2 + 1`, expected: "15"},
+		{src: `-id + 10 % 4`, expected: "-5"},
+		{src: `country + "-" + id`, expected: "US-7"},
+		{src: `price`, expected: "150.5"},
+		{src: `active && id >= 7`, expected: "true"},
+		{src: `lower(country) == "us" && len(country) == 2`, expected: "true"},
+		{src: `contains(price, ".") && startsWith(country, "U") && endsWith(country, "S")`, expected: "true"},
+		{src: `upper(trim("  a ")) != "A"`, expected: "false"},
+		{src: `country < "VN"`, expected: "true"},
+		{src: `1e2 == 100`, expected: "true"},
+		{src: `false || id == 8 || country == "US"`, expected: "true"},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.src, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject, err := expr.Compile(test.src, columns)
+			if err != nil {
+				t.Fatalf("could not compile: %v", err)
+			}
+
+			// act
+			value, err := subject.Eval(row)
+
+			// assert
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if value.String() != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, value.String())
+			}
+		})
+	}
+}
+
+func TestCompile_errors(t *testing.T) {
+	t.Parallel()
+
+	for _, src := range [...]string{
+		`price >`,
+		`unknown == 1`,
+		`(price > 1`,
+		`price > 1)`,
+		`"unterminated`,
+		`nope(price)`,
+		`lower(price, id)`,
+		`$0 == 1`,
+		`price # 1`,
+	} {
+		if _, err := expr.Compile(src, []string{"price", "id"}); err == nil {
+			t.Errorf("expected compile error for %q", src)
+		}
+	}
+}
+
+func TestProgram_Match_errors(t *testing.T) {
+	t.Parallel()
+
+	subject, err := expr.Compile(`price > 100`, []string{"price"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := subject.Match([]string{"abc"}); err == nil {
+		t.Error("expected error for non numeric value")
+	}
+	if _, err := subject.Match(nil); err == nil {
+		t.Error("expected error for missing column")
+	}
+	if match, err := subject.Match([]string{"99"}); err != nil || match {
+		t.Errorf("expected no match, got %v, %v", match, err)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// tokenKind is the kind of a lexical token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenColumn // $N
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+// token is a lexical token.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators holds the operators, longest first, so that "<=" is matched before "<".
+var operators = [...]string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "%"}
+
+// tokenize splits the source into tokens.
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	for pos := 0; pos < len(src); {
+		r, size := utf8.DecodeRuneInString(src[pos:])
+		switch {
+		case unicode.IsSpace(r):
+			pos += size
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: pos})
+			pos++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: pos})
+			pos++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: pos})
+			pos++
+		case r == '"' || r == '\'':
+			end, err := scanString(src, pos)
+			if err != nil {
+				return nil, err
+			}
+			text := src[pos:end]
+			if r == '\'' {
+				text = `"` + strings.ReplaceAll(strings.ReplaceAll(text[1:len(text)-1], `\'`, `'`), `"`, `\"`) + `"`
+			}
+			value, err := strconv.Unquote(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %w", pos, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value, pos: pos})
+			pos = end
+		case r >= '0' && r <= '9' || r == '.':
+			end := pos
+			for end < len(src) && (src[end] >= '0' && src[end] <= '9' || src[end] == '.' ||
+				src[end] == 'e' || src[end] == 'E' ||
+				(src[end] == '-' || src[end] == '+') && (src[end-1] == 'e' || src[end-1] == 'E')) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[pos:end], pos: pos})
+			pos = end
+		case r == '$':
+			end := pos + 1
+			for end < len(src) && src[end] >= '0' && src[end] <= '9' {
+				end++
+			}
+			if end == pos+1 {
+				return nil, fmt.Errorf("expected column number after $ at position %d", pos)
+			}
+			tokens = append(tokens, token{kind: tokenColumn, text: src[pos+1 : end], pos: pos})
+			pos = end
+		case r == '_' || unicode.IsLetter(r):
+			end := pos
+			for end < len(src) {
+				r, size := utf8.DecodeRuneInString(src[end:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				end += size
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[pos:end], pos: pos})
+			pos = end
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(src[pos:], candidate) {
+					op = candidate
+
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, pos)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: pos})
+			pos += len(op)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+// scanString returns the end (exclusive) of the quoted string starting at given position.
+func scanString(src string, start int) (int, error) {
+	quote := src[start]
+	for pos := start + 1; pos < len(src); pos++ {
+		switch src[pos] {
+		case '\\':
+			pos++
+		case quote:
+			return pos + 1, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unterminated string at position %d", start)
+}
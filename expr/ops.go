@@ -0,0 +1,195 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// binary returns the evalFunc of given binary operator.
+func binary(op string, left, right evalFunc) evalFunc {
+	switch op {
+	case "&&", "||":
+		return logical(op == "&&", left, right)
+	case "==", "!=", "<", "<=", ">", ">=":
+		return comparison(op, left, right)
+	default:
+		return arithmetic(op, left, right)
+	}
+}
+
+// logical returns the short-circuiting evalFunc of && (and is true) or || operators.
+func logical(and bool, left, right evalFunc) evalFunc {
+	return func(row []string) (Value, error) {
+		l, err := evalBool(left, row)
+		if err != nil || l != and {
+			return Bool(l), err
+		}
+		r, err := evalBool(right, row)
+
+		return Bool(r), err
+	}
+}
+
+// comparison returns the evalFunc of given comparison operator.
+func comparison(op string, left, right evalFunc) evalFunc {
+	return func(row []string) (Value, error) {
+		l, r, err := evalBoth(left, right, row)
+		if err != nil {
+			return Value{}, err
+		}
+		var cmp int
+		switch {
+		case l.kind == KindNumber || r.kind == KindNumber:
+			ln, err := l.AsNumber()
+			if err != nil {
+				return Value{}, err
+			}
+			rn, err := r.AsNumber()
+			if err != nil {
+				return Value{}, err
+			}
+			cmp = compareNumbers(ln, rn)
+		case l.kind == KindBool || r.kind == KindBool:
+			lb, err := l.AsBool()
+			if err != nil {
+				return Value{}, err
+			}
+			rb, err := r.AsBool()
+			if err != nil {
+				return Value{}, err
+			}
+			if op != "==" && op != "!=" {
+				return Value{}, fmt.Errorf("booleans can't be compared with %s", op)
+			}
+			if lb != rb {
+				cmp = 1
+			}
+		default:
+			cmp = strings.Compare(l.str, r.str)
+		}
+
+		switch op {
+		case "==":
+			return Bool(cmp == 0), nil
+		case "!=":
+			return Bool(cmp != 0), nil
+		case "<":
+			return Bool(cmp < 0), nil
+		case "<=":
+			return Bool(cmp <= 0), nil
+		case ">":
+			return Bool(cmp > 0), nil
+		default:
+			return Bool(cmp >= 0), nil
+		}
+	}
+}
+
+// arithmetic returns the evalFunc of given arithmetic operator.
+func arithmetic(op string, left, right evalFunc) evalFunc {
+	return func(row []string) (Value, error) {
+		l, r, err := evalBoth(left, right, row)
+		if err != nil {
+			return Value{}, err
+		}
+		ln, lErr := l.AsNumber()
+		rn, rErr := r.AsNumber()
+		if op == "+" && (lErr != nil || rErr != nil) && (l.kind == KindString || r.kind == KindString) {
+			return String(l.String() + r.String()), nil
+		}
+		if err := errors.Join(lErr, rErr); err != nil {
+			return Value{}, err
+		}
+		switch op {
+		case "+":
+			return Number(ln + rn), nil
+		case "-":
+			return Number(ln - rn), nil
+		case "*":
+			return Number(ln * rn), nil
+		case "/":
+			if rn == 0 {
+				return Value{}, errors.New("division by zero")
+			}
+
+			return Number(ln / rn), nil
+		default:
+			if rn == 0 {
+				return Value{}, errors.New("division by zero")
+			}
+
+			return Number(math.Mod(ln, rn)), nil
+		}
+	}
+}
+
+// evalBoth evaluates both operands.
+func evalBoth(left, right evalFunc, row []string) (Value, Value, error) {
+	l, err := left(row)
+	if err != nil {
+		return Value{}, Value{}, err
+	}
+	r, err := right(row)
+
+	return l, r, err
+}
+
+// evalBool evaluates the operand as a boolean.
+func evalBool(operand evalFunc, row []string) (bool, error) {
+	v, err := operand(row)
+	if err != nil {
+		return false, err
+	}
+
+	return v.AsBool()
+}
+
+// compareNumbers returns -1, 0 or 1 if a is less than, equal to, or greater than b.
+func compareNumbers(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// function is a built-in function.
+type function struct {
+	arity int
+	call  func(args []Value) (Value, error)
+}
+
+// functions holds the built-in functions, by name.
+var functions = map[string]function{
+	"lower": {arity: 1, call: func(args []Value) (Value, error) {
+		return String(strings.ToLower(args[0].String())), nil
+	}},
+	"upper": {arity: 1, call: func(args []Value) (Value, error) {
+		return String(strings.ToUpper(args[0].String())), nil
+	}},
+	"trim": {arity: 1, call: func(args []Value) (Value, error) {
+		return String(strings.TrimSpace(args[0].String())), nil
+	}},
+	"len": {arity: 1, call: func(args []Value) (Value, error) {
+		return Number(float64(len([]rune(args[0].String())))), nil
+	}},
+	"contains": {arity: 2, call: func(args []Value) (Value, error) {
+		return Bool(strings.Contains(args[0].String(), args[1].String())), nil
+	}},
+	"startsWith": {arity: 2, call: func(args []Value) (Value, error) {
+		return Bool(strings.HasPrefix(args[0].String(), args[1].String())), nil
+	}},
+	"endsWith": {arity: 2, call: func(args []Value) (Value, error) {
+		return Bool(strings.HasSuffix(args[0].String(), args[1].String())), nil
+	}},
+}
@@ -0,0 +1,103 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package expr
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Kind is the kind of a [Value].
+type Kind int
+
+const (
+	// KindString is the kind of string values, like column values and string literals.
+	KindString Kind = iota
+	// KindNumber is the kind of numeric (float64) values.
+	KindNumber
+	// KindBool is the kind of boolean values.
+	KindBool
+)
+
+// Value is the result of an expression's evaluation.
+type Value struct {
+	kind Kind
+	str  string
+	num  float64
+	b    bool
+}
+
+// String returns a string value.
+func String(s string) Value {
+	return Value{kind: KindString, str: s}
+}
+
+// Number returns a numeric value.
+func Number(n float64) Value {
+	return Value{kind: KindNumber, num: n}
+}
+
+// Bool returns a boolean value.
+func Bool(b bool) Value {
+	return Value{kind: KindBool, b: b}
+}
+
+// Kind returns the value's kind.
+func (v Value) Kind() Kind {
+	return v.kind
+}
+
+// String returns the value formatted as a string.
+func (v Value) String() string {
+	switch v.kind {
+	case KindNumber:
+		return strconv.FormatFloat(v.num, 'f', -1, 64)
+	case KindBool:
+		return strconv.FormatBool(v.b)
+	default:
+		return v.str
+	}
+}
+
+// AsNumber returns the value converted to a number.
+// Strings are parsed as floats.
+func (v Value) AsNumber() (float64, error) {
+	switch v.kind {
+	case KindNumber:
+		return v.num, nil
+	case KindBool:
+		if v.b {
+			return 1, nil
+		}
+
+		return 0, nil
+	default:
+		n, err := strconv.ParseFloat(v.str, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a number", v.str)
+		}
+
+		return n, nil
+	}
+}
+
+// AsBool returns the value converted to a boolean.
+// Strings are parsed with [strconv.ParseBool], numbers are true if not zero.
+func (v Value) AsBool() (bool, error) {
+	switch v.kind {
+	case KindBool:
+		return v.b, nil
+	case KindNumber:
+		return v.num != 0, nil
+	default:
+		b, err := strconv.ParseBool(v.str)
+		if err != nil {
+			return false, fmt.Errorf("%q is not a boolean", v.str)
+		}
+
+		return b, nil
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"fmt"
+	"sync"
+)
+
+// FieldsCountMismatchError is the error sent through ErrsChan when [CsvReader.ColumnsCount] is not set
+// (each goroutine's parser infers the fields count from the first record of its chunk), and the first record
+// of a chunk has a different fields count than the first record parsed by another goroutine.
+// It usually means the delimiter was misdetected, or a chunk started in the middle of a multiline record.
+type FieldsCountMismatchError struct {
+	// Thread is the number of the goroutine whose chunk diverges.
+	Thread int
+	// Offset is the offset of the chunk's first record.
+	Offset int
+	// FieldsCount is the fields count of the chunk's first record.
+	FieldsCount int
+	// ExpectedFieldsCount is the fields count of the reference record.
+	ExpectedFieldsCount int
+	// ReferenceThread is the number of the goroutine which parsed the reference record.
+	ReferenceThread int
+	// ReferenceOffset is the offset of the reference record.
+	ReferenceOffset int
+}
+
+// Error returns the error's message.
+func (e *FieldsCountMismatchError) Error() string {
+	return fmt.Sprintf(
+		"%s: chunk #%d at offset %d has %d fields, chunk #%d at offset %d has %d",
+		ErrFieldsCountMismatch, e.Thread, e.Offset, e.FieldsCount,
+		e.ReferenceThread, e.ReferenceOffset, e.ExpectedFieldsCount,
+	)
+}
+
+// Unwrap returns [ErrFieldsCountMismatch].
+func (e *FieldsCountMismatchError) Unwrap() error {
+	return ErrFieldsCountMismatch
+}
+
+// fieldsCountRef holds the reference fields count chunks are checked against, if it is inferred.
+type fieldsCountRef struct {
+	mu     sync.Mutex
+	set    bool
+	count  int
+	thread int
+	offset int
+}
+
+// newFieldsCountRef returns the reference fields count, if [CsvReader.ColumnsCount] is not set
+// and the file is split into several chunks, nil otherwise.
+func (cr *CsvReader) newFieldsCountRef(totalThreads int) *fieldsCountRef {
+	if cr.ColumnsCount != 0 || totalThreads < 2 {
+		return nil
+	}
+
+	return &fieldsCountRef{}
+}
+
+// check compares the fields count of the first record of given goroutine's chunk with the reference one,
+// which it becomes, if not set yet. It returns an error if they differ.
+func (ref *fieldsCountRef) check(thread, offset, count int) *FieldsCountMismatchError {
+	ref.mu.Lock()
+	defer ref.mu.Unlock()
+	if !ref.set {
+		ref.set, ref.count, ref.thread, ref.offset = true, count, thread, offset
+
+		return nil
+	}
+	if count == ref.count {
+		return nil
+	}
+
+	return &FieldsCountMismatchError{
+		Thread:              thread,
+		Offset:              offset,
+		FieldsCount:         count,
+		ExpectedFieldsCount: ref.count,
+		ReferenceThread:     ref.thread,
+		ReferenceOffset:     ref.offset,
+	}
+}
+
+// checkFieldsCount checks the fields count of the first record parsed by the goroutine,
+// read at given offset, against the reference one.
+// Rows are not altered: the divergence is only reported, and the run flagged as failed.
+func (cr *CsvReader) checkFieldsCount(rn *Run, lh *lineHandler, record []string, offset int) {
+	lh.fieldsChecked = true
+	err := rn.fieldsCount.check(lh.thread, offset, len(record))
+	if err == nil {
+		return
+	}
+	rn.fail()
+	if rn.sendErr(fmt.Errorf("bigcsvreader: thread #%d fields count check failed (%w)", lh.thread, err)) {
+		cr.Logger.Error(
+			"msg", "fields count check failed", "err", err,
+			"file", cr.fileBaseName, "thread", lh.thread,
+			"offset", offset,
+		)
+	}
+}
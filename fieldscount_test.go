@@ -0,0 +1,77 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_inferredFieldsCount(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name            string
+		content         string
+		maxGoroutinesNo int
+		expectedErr     bool
+	}{
+		{
+			name:            "chunks agree on fields count",
+			content:         numberedRows(600),
+			maxGoroutinesNo: 3,
+		},
+		{
+			name:            "last chunk diverges",
+			content:         numberedRows(400) + strings.ReplaceAll(numberedRows(200), ",x\n", ",x,y\n"),
+			maxGoroutinesNo: 3,
+			expectedErr:     true,
+		},
+		{
+			name:            "single chunk is not checked",
+			content:         numberedRows(400) + strings.ReplaceAll(numberedRows(200), ",x\n", ",x,y\n"),
+			maxGoroutinesNo: 1,
+			expectedErr:     true, // the single goroutine's parser rejects the last rows.
+		},
+	}
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(writeTmpFile(t, t.TempDir(), "inferred.csv", test.content))
+			subject.MaxGoroutinesNo = test.maxGoroutinesNo
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			run := subject.Start(ctx)
+			rowsCount := drainRows(run.RowsChans())
+			err := run.Err()
+
+			// assert
+			assertEqual(t, test.expectedErr, err != nil)
+			var mismatchErr *bigcsvreader.FieldsCountMismatchError
+			isMismatch := errors.As(err, &mismatchErr)
+			assertEqual(t, test.expectedErr && test.maxGoroutinesNo > 1, isMismatch)
+			if isMismatch {
+				assertTrue(t, errors.Is(err, bigcsvreader.ErrFieldsCountMismatch))
+				assertTrue(t, mismatchErr.Thread == 3 || mismatchErr.ReferenceThread == 3)
+				assertEqual(t, 7-mismatchErr.FieldsCount, mismatchErr.ExpectedFieldsCount)
+			}
+			if !test.expectedErr {
+				assertEqual(t, 600, rowsCount)
+			}
+		})
+	}
+}
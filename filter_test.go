@@ -0,0 +1,99 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_Filter(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name          string
+		fileHasHeader bool
+		filePath      string
+		filter        string
+		expectedIDs   []string
+		expectedErr   bool
+	}{
+		{
+			name:          "filter by header names",
+			fileHasHeader: true,
+			filePath:      "testdata/file_with_header.csv",
+			filter:        `Age >= 30 && Name != "Jane"`,
+			expectedIDs:   []string{"1", "5"},
+		},
+		{
+			name:          "filter by columns positions",
+			fileHasHeader: true,
+			filePath:      "testdata/file_with_header.csv",
+			filter:        `startsWith($2, "J")`,
+			expectedIDs:   []string{"1", "2"},
+		},
+		{
+			name:          "filter evaluation error",
+			fileHasHeader: true,
+			filePath:      "testdata/file_with_header.csv",
+			filter:        `Name > 10`,
+			expectedIDs:   nil,
+			expectedErr:   true,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(test.filePath)
+			subject.FileHasHeader = test.fileHasHeader
+			subject.ColumnsCount = 3
+			subject.ColumnsDelimiter = ';'
+			subject.Filter = test.filter
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			run := subject.Start(ctx)
+			rows := gatherRowsInOrder(run.RowsChans())
+			err := run.Err()
+
+			// assert
+			var ids []string
+			for _, row := range rows {
+				ids = append(ids, row[0])
+			}
+			sort.Strings(ids)
+			assertEqual(t, test.expectedIDs, ids)
+			assertEqual(t, test.expectedErr, err != nil)
+		})
+	}
+
+	t.Run("invalid filter fails the run", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_without_header.csv")
+		subject.Filter = `Age >`
+
+		// act
+		run := subject.Start(context.Background())
+		err := run.Err()
+
+		// assert
+		assertNil(t, run.RowsChans())
+		assertNotNil(t, err)
+	})
+}
@@ -0,0 +1,216 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	defaultForeignKeyBatchSize   = 64
+	defaultForeignKeyConcurrency = 8
+)
+
+// ForeignKeyCheck flags rows referencing unknown foreign keys during read,
+// instead of failing later, at the database layer.
+type ForeignKeyCheck struct {
+	// Column is the index (0-based) of the column holding the foreign key.
+	Column int
+	// Lookup reports whether given key exists.
+	// It is called at most once per distinct key per run, as results are cached,
+	// and it can be called concurrently.
+	Lookup func(ctx context.Context, key string) (bool, error)
+	// BatchSize is the number of rows each goroutine accumulates before
+	// looking up their keys. Rows are pushed into RowsChan after their batch was checked.
+	// Defaults to 64.
+	BatchSize int
+	// Concurrency is the maximum number of concurrent lookups performed for a batch.
+	// Defaults to 8.
+	Concurrency int
+}
+
+// UnknownForeignKeyError is the error returned when a row references an unknown foreign key.
+type UnknownForeignKeyError struct {
+	// Column is the column's index (0-based).
+	Column int
+	// Key is the unknown key.
+	Key string
+	// Offset is the offset of the row in the file.
+	Offset int
+}
+
+// Error returns the error's message.
+func (e *UnknownForeignKeyError) Error() string {
+	return fmt.Sprintf("unknown foreign key %q for column #%d at offset %d", e.Key, e.Column, e.Offset)
+}
+
+// foreignKeyChecker checks foreign keys during a run, caching lookups results.
+type foreignKeyChecker struct {
+	ForeignKeyCheck
+	// cache holds lookups results, key => bool.
+	cache sync.Map
+}
+
+// foreignKeyBatch holds the rows a goroutine accumulated, pending foreign key checking.
+type foreignKeyBatch struct {
+	records [][]string
+	offsets []int
+	lines   []int64
+	// raws holds the raw bytes of the records, if they are to be quarantined, see [CsvReader.Quarantine].
+	raws [][]byte
+}
+
+// newForeignKeyChecker instantiates the checker for the configured [ForeignKeyCheck], if any.
+func (cr *CsvReader) newForeignKeyChecker() (*foreignKeyChecker, error) {
+	if cr.ForeignKey == nil {
+		return nil, nil
+	}
+	if cr.ForeignKey.Lookup == nil {
+		return nil, errors.New("foreign key check has no lookup")
+	}
+	col := cr.ForeignKey.Column
+	if col < 0 || (cr.ColumnsCount > 0 && col >= cr.ColumnsCount) {
+		return nil, fmt.Errorf("foreign key column #%d is out of range", col)
+	}
+	checker := &foreignKeyChecker{ForeignKeyCheck: *cr.ForeignKey}
+	if checker.BatchSize <= 0 {
+		checker.BatchSize = defaultForeignKeyBatchSize
+	}
+	if checker.Concurrency <= 0 {
+		checker.Concurrency = defaultForeignKeyConcurrency
+	}
+
+	return checker, nil
+}
+
+// add appends the record to the batch, and returns true if batch is full.
+func (fk *foreignKeyChecker) add(batch *foreignKeyBatch, record []string, raw []byte, offset int, line int64) bool {
+	batch.records = append(batch.records, record)
+	batch.offsets = append(batch.offsets, offset)
+	batch.lines = append(batch.lines, line)
+	if raw != nil {
+		batch.raws = append(batch.raws, raw)
+	}
+
+	return len(batch.records) >= fk.BatchSize
+}
+
+// key returns the foreign key of the record.
+func (fk *foreignKeyChecker) key(record []string) string {
+	if fk.Column < len(record) {
+		return record[fk.Column]
+	}
+
+	return ""
+}
+
+// resolve looks up, concurrently, the batch's keys which are not cached yet.
+// It returns the lookup errors, by key.
+func (fk *foreignKeyChecker) resolve(ctx context.Context, batch *foreignKeyBatch) map[string]error {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		errs    map[string]error
+		pending = make(map[string]struct{})
+		sem     = make(chan struct{}, fk.Concurrency)
+	)
+	for _, record := range batch.records {
+		key := fk.key(record)
+		if _, cached := fk.cache.Load(key); cached {
+			continue
+		}
+		if _, found := pending[key]; found {
+			continue
+		}
+		pending[key] = struct{}{}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(key string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			exists, err := fk.Lookup(ctx, key)
+			if err != nil {
+				mu.Lock()
+				if errs == nil {
+					errs = make(map[string]error)
+				}
+				errs[key] = err
+				mu.Unlock()
+
+				return
+			}
+			fk.cache.Store(key, exists)
+		}(key)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// flushForeignKeys checks the goroutine's batch of foreign keys and emits the rows referencing known keys.
+// Unknown keys and lookup errors are sent through ErrsChan (and the rows eventually quarantined).
+func (cr *CsvReader) flushForeignKeys(ctx context.Context, rn *Run, lh *lineHandler) {
+	batch, thread := lh.fkBatch, lh.thread
+	if len(batch.records) == 0 {
+		return
+	}
+	lookupErrs := rn.foreignKey.resolve(ctx, batch)
+	for i, record := range batch.records {
+		key := rn.foreignKey.key(record)
+		offset, line := batch.offsets[i], batch.lines[i]
+		if lookupErr, found := lookupErrs[key]; found {
+			rn.fail()
+			if rn.sendErr(fmt.Errorf(
+				"bigcsvreader: thread #%d could not lookup foreign key %q at offset %d (%w)",
+				thread, key, offset, wrapLineErr(lookupErr, line),
+			)) {
+				cr.Logger.Error(
+					"msg", "could not lookup foreign key", "err", lookupErr,
+					"file", cr.fileBaseName, "thread", thread,
+					"offset", offset, "key", key,
+				)
+			}
+			cr.accountRow(rn, true, thread)
+			if batch.raws != nil {
+				cr.quarantineRow(rn, lh, batch.raws[i], offset, lookupErr)
+			}
+
+			continue
+		}
+		if exists, _ := rn.foreignKey.cache.Load(key); !exists.(bool) {
+			err := &UnknownForeignKeyError{Column: rn.foreignKey.Column, Key: key, Offset: offset}
+			if rn.sendErr(fmt.Errorf(
+				"bigcsvreader: thread #%d invalid row at offset %d (%w)",
+				thread, offset, wrapLineErr(err, line),
+			)) {
+				cr.Logger.Error(
+					"msg", "invalid row", "err", err,
+					"file", cr.fileBaseName, "thread", thread,
+					"offset", offset,
+				)
+			}
+			cr.accountRow(rn, true, thread)
+			if batch.raws != nil {
+				cr.quarantineRow(rn, lh, batch.raws[i], offset, err)
+			}
+
+			continue
+		}
+		decoded := cr.emitRecord(rn, record, thread, offset, line, lh.stats)
+		cr.accountRow(rn, !decoded, thread)
+	}
+	batch.records = batch.records[:0]
+	batch.offsets = batch.offsets[:0]
+	batch.lines = batch.lines[:0]
+	if batch.raws != nil {
+		batch.raws = batch.raws[:0]
+	}
+}
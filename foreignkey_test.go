@@ -0,0 +1,67 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ForeignKey(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		lookedUp   []string
+		lookedUpMu sync.Mutex
+		lookupErr  = errors.New("intentionally triggered lookup error")
+	)
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/file_with_duplicates.csv")
+	subject.ColumnsCount = 2
+	subject.ForeignKey = &bigcsvreader.ForeignKeyCheck{
+		Column: 0,
+		Lookup: func(_ context.Context, key string) (bool, error) {
+			lookedUpMu.Lock()
+			lookedUp = append(lookedUp, key)
+			lookedUpMu.Unlock()
+			if key == "3" {
+				return false, lookupErr
+			}
+
+			return key == "1", nil
+		},
+		BatchSize: 2,
+	}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	run := subject.Start(ctx)
+	var records [][]string
+	for _, rowsChan := range run.RowsChans() {
+		for record := range rowsChan {
+			records = append(records, record)
+		}
+	}
+	err := run.Err()
+
+	// assert
+	assertEqual(t, [][]string{{"1", "a"}, {"1", "c"}}, records) // file is small, read by a single goroutine.
+	assertTrue(t, errors.Is(err, lookupErr))
+	var fkErr *bigcsvreader.UnknownForeignKeyError
+	if assertTrue(t, errors.As(err, &fkErr)) {
+		assertEqual(t, bigcsvreader.UnknownForeignKeyError{Column: 0, Key: "2", Offset: 4}, *fkErr)
+	}
+	sort.Strings(lookedUp)
+	assertEqual(t, []string{"1", "2", "3"}, lookedUp) // results are cached
+}
@@ -0,0 +1,236 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+func TestCsvReader_FSAndClock(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reads from given file system", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("data.csv")
+		subject.FS = newMemFS(map[string]string{
+			"data.csv": "id,name\n1,John\n2,Jane\n3,Jim\n",
+		})
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 2
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		rowsCount := drainRows(run.RowsChans())
+		err := run.Err()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, []string{"id", "name"}, run.Header())
+		assertEqual(t, 3, rowsCount)
+	})
+
+	t.Run("file not found in given file system", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("missing.csv")
+		subject.FS = newMemFS(nil)
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		err := run.Err()
+
+		// assert
+		assertTrue(t, errors.Is(err, fs.ErrNotExist))
+	})
+
+	t.Run("summary is timed by given clock", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		startedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		clock := newFakeClock(startedAt, time.Minute)
+		summaries := make(chan bigcsvreader.Summary, 1)
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_without_header.csv")
+		subject.ColumnsCount = 3
+		subject.Clock = clock
+		subject.OnComplete = func(summary bigcsvreader.Summary) {
+			summaries <- summary
+		}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		drainRows(run.RowsChans())
+		assertNil(t, run.Err())
+		summary := <-summaries
+
+		// assert
+		assertEqual(t, startedAt, summary.StartedAt)
+		assertEqual(t, time.Minute, summary.Duration)
+	})
+}
+
+func TestCsvReader_SetFS(t *testing.T) {
+	t.Parallel()
+
+	const content = "id,name\n1,John\n2,Jane\n3,Jim\n4,Joe\n5,Jack\n"
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	w, err := zw.Create("dir/data.csv") // deflated, so not supporting random access.
+	if err == nil {
+		_, err = w.Write([]byte(content))
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipFS, err := zip.NewReader(bytes.NewReader(zipped.Bytes()), int64(zipped.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := [...]struct {
+		name string
+		fsys fs.FS
+	}{
+		{
+			name: "map file system",
+			fsys: fstest.MapFS{"dir/data.csv": &fstest.MapFile{Data: []byte(content)}},
+		},
+		{
+			name: "zip archive",
+			fsys: zipFS,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFS(test.fsys, "dir/data.csv")
+			subject.FileHasHeader = true
+			subject.ColumnsCount = 2
+			subject.MaxGoroutinesNo = 2
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			run := subject.Start(ctx)
+			rows := gatherRowsInOrder(run.RowsChans())
+			err := run.Err()
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, []string{"id", "name"}, run.Header())
+			assertEqual(t, [][]string{{"1", "John"}, {"2", "Jane"}, {"3", "Jim"}, {"4", "Joe"}, {"5", "Jack"}}, rows)
+		})
+	}
+
+	t.Run("file not found in given file system", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFS(zipFS, "missing.csv")
+
+		// act
+		run := subject.Start(context.Background())
+		err := run.Err()
+
+		// assert
+		assertTrue(t, errors.Is(err, fs.ErrNotExist))
+	})
+}
+
+// memFS is an in-memory internal.FS.
+type memFS struct {
+	files fstest.MapFS
+}
+
+func newMemFS(files map[string]string) memFS {
+	mapFS := make(fstest.MapFS, len(files))
+	for name, content := range files {
+		mapFS[name] = &fstest.MapFile{Data: []byte(content), Mode: 0o600}
+	}
+
+	return memFS{files: mapFS}
+}
+
+func (mfs memFS) Open(name string) (internal.File, error) {
+	f, err := mfs.files.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	file, ok := f.(internal.File)
+	if !ok {
+		_ = f.Close()
+
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	return file, nil
+}
+
+func (mfs memFS) Stat(name string) (os.FileInfo, error) {
+	return mfs.files.Stat(name)
+}
+
+// fakeClock is an internal.Clock which advances with a fixed step on each Now call,
+// and whose timers never fire.
+type fakeClock struct {
+	mu   sync.Mutex
+	now  time.Time
+	step time.Duration
+}
+
+func newFakeClock(now time.Time, step time.Duration) *fakeClock {
+	return &fakeClock{now: now, step: step}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now
+	c.now = c.now.Add(c.step)
+
+	return now
+}
+
+func (c *fakeClock) After(time.Duration) <-chan time.Time {
+	return make(chan time.Time)
+}
+
+func (c *fakeClock) NewTicker(time.Duration) (<-chan time.Time, func()) {
+	return make(chan time.Time), func() {}
+}
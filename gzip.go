@@ -0,0 +1,361 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+// errUnsupportedWithGzip is the error returned when a feature which needs random access
+// to the data is used on a plain gzip file (which is not BGZF).
+var errUnsupportedWithGzip = errors.New("not supported with plain gzip compression (only with BGZF)")
+
+// errInvalidBGZF is the error returned when a BGZF file holds a block which is not a BGZF block.
+var errInvalidBGZF = errors.New("invalid BGZF block")
+
+// gzipMagic are the bytes gzip files start with.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+const (
+	// bgzfIndexExt is the extension of the blocks index file (as written by bgzip -i) following a BGZF file's name.
+	bgzfIndexExt = ".gzi"
+	// bgzfHeaderSize is the size of a BGZF block's header (the gzip header with the BC extra subfield).
+	bgzfHeaderSize = 18
+	// streamBatchSize is the number of lines a plain gzip file's decompressing goroutine hands over at once.
+	streamBatchSize = 256
+)
+
+// bgzfBlockSize returns the total size of the BGZF block given header belongs to, or 0 if it is not a BGZF header.
+func bgzfBlockSize(header []byte) int64 {
+	if len(header) < bgzfHeaderSize ||
+		header[0] != 0x1f || header[1] != 0x8b || header[2] != 8 || header[3]&4 == 0 || // gzip, deflate, extra field.
+		binary.LittleEndian.Uint16(header[10:]) != 6 ||
+		header[12] != 'B' || header[13] != 'C' || binary.LittleEndian.Uint16(header[14:]) != 2 {
+		return 0
+	}
+
+	return int64(binary.LittleEndian.Uint16(header[16:])) + 1
+}
+
+// gzipStreamFile is a plain gzip file, decompressed sequentially.
+// Seeking backwards, and reading at an offset, decompress the file again from its beginning,
+// so they are meant for reading its beginning (like the header) only.
+type gzipStreamFile struct {
+	f   internal.File
+	zr  *gzip.Reader
+	pos int64
+	// err is the first decompression error, if any.
+	err error
+}
+
+// Read reads decompressed data.
+func (sf *gzipStreamFile) Read(p []byte) (int, error) {
+	if sf.zr == nil {
+		if err := sf.reset(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := sf.zr.Read(p)
+	sf.pos += int64(n)
+	if err != nil && err != io.EOF && sf.err == nil {
+		sf.err = err
+	}
+
+	return n, err
+}
+
+// reset restarts decompressing from the beginning of the file.
+func (sf *gzipStreamFile) reset() error {
+	if _, err := sf.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	var err error
+	if sf.zr == nil {
+		sf.zr, err = gzip.NewReader(sf.f)
+	} else {
+		err = sf.zr.Reset(sf.f)
+	}
+	sf.pos = 0
+	if err != nil && sf.err == nil {
+		sf.err = err
+	}
+
+	return err
+}
+
+// Seek sets the offset for the next Read, relative to the start of the file or to the current offset.
+func (sf *gzipStreamFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += sf.pos
+	default:
+		return sf.pos, errors.New("bigcsvreader: seek relative to the end of a plain gzip file")
+	}
+	if offset < 0 {
+		return sf.pos, errors.New("bigcsvreader: negative position")
+	}
+	if sf.zr == nil || offset < sf.pos {
+		if err := sf.reset(); err != nil {
+			return sf.pos, err
+		}
+	}
+	_, err := io.CopyN(io.Discard, sf, offset-sf.pos)
+	if err == io.EOF {
+		err = nil // as for files, seeking beyond the end is not an error.
+	}
+
+	return sf.pos, err
+}
+
+// ReadAt reads len(p) bytes of decompressed data starting at given offset.
+func (sf *gzipStreamFile) ReadAt(p []byte, off int64) (int, error) {
+	zr, err := gzip.NewReader(io.NewSectionReader(sf.f, 0, math.MaxInt64))
+	if err != nil {
+		return 0, err
+	}
+	if _, err = io.CopyN(io.Discard, zr, off); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(zr, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+
+	return n, err
+}
+
+// Close closes the file.
+func (sf *gzipStreamFile) Close() error {
+	return sf.f.Close()
+}
+
+// Stat returns the (compressed) file's info, as the decompressed size is not known.
+func (sf *gzipStreamFile) Stat() (os.FileInfo, error) {
+	return sf.f.Stat()
+}
+
+// readBGZFIndex returns the blocks index of the BGZF file. The blocks are located with the file's
+// blocks index (see [bgzfIndexExt]), if present, otherwise by walking the blocks' headers.
+func readBGZFIndex(fsys internal.FS, name string, f io.ReaderAt, size int64) (*blocksIndex, error) {
+	blocks, err := readBGZFIndexFile(fsys, name+bgzfIndexExt)
+	if err != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not read BGZF index (%w)", err)
+	}
+	// walk the blocks following the last indexed one.
+	last := blocks[len(blocks)-1]
+	blocks = blocks[:len(blocks)-1]
+	var (
+		header  = make([]byte, bgzfHeaderSize)
+		trailer = make([]byte, 4)
+	)
+	for last.coffset < size {
+		if _, err := f.ReadAt(header, last.coffset); err != nil {
+			return nil, fmt.Errorf("bigcsvreader: could not read BGZF block at offset %d (%w)", last.coffset, err)
+		}
+		blockSize := bgzfBlockSize(header)
+		if blockSize < int64(bgzfHeaderSize+len(trailer)) {
+			return nil, fmt.Errorf("bigcsvreader: could not read BGZF block at offset %d (%w)", last.coffset, errInvalidBGZF)
+		}
+		// the block ends with the size of its decompressed data.
+		if _, err := f.ReadAt(trailer, last.coffset+blockSize-int64(len(trailer))); err != nil {
+			return nil, fmt.Errorf("bigcsvreader: could not read BGZF block at offset %d (%w)", last.coffset, err)
+		}
+		blocks = append(blocks, last)
+		last.coffset += blockSize
+		last.uoffset += int64(binary.LittleEndian.Uint32(trailer))
+	}
+
+	return &blocksIndex{blocks: append(blocks, last)}, nil
+}
+
+// readBGZFIndexFile reads the BGZF blocks index file, if it exists, made of the number of entries,
+// followed by the compressed and decompressed offsets of each block but the first, as little endian uint64s.
+// It returns the located blocks, starting with the first one.
+func readBGZFIndexFile(fsys internal.FS, name string) ([]compressedBlock, error) {
+	blocks := []compressedBlock{{}}
+	f, err := fsys.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return blocks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var count uint64
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return nil, err
+	}
+	entry := make([]uint64, 2)
+	for i := uint64(0); i < count; i++ {
+		if err := binary.Read(r, binary.LittleEndian, entry); err != nil {
+			return nil, err
+		}
+		block := compressedBlock{coffset: int64(entry[0]), uoffset: int64(entry[1])}
+		if prev := blocks[len(blocks)-1]; block.coffset <= prev.coffset || block.uoffset < prev.uoffset {
+			return nil, errors.New("offsets are not increasing")
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
+
+// bgzfBlockDecoder decompresses BGZF blocks.
+type bgzfBlockDecoder struct {
+	zr *gzip.Reader
+}
+
+// decode decompresses the BGZF block read by r, appending the decompressed data to dst.
+func (d *bgzfBlockDecoder) decode(r *io.SectionReader, dst []byte) ([]byte, error) {
+	var err error
+	if d.zr == nil {
+		d.zr, err = gzip.NewReader(r)
+	} else {
+		err = d.zr.Reset(r)
+	}
+	if err != nil {
+		return dst, err
+	}
+	d.zr.Multistream(false)
+	for {
+		if len(dst) == cap(dst) {
+			dst = append(dst, 0)[:len(dst)]
+		}
+		n, err := d.zr.Read(dst[len(dst):cap(dst)])
+		dst = dst[:len(dst)+n]
+		if err == io.EOF {
+			return dst, nil
+		}
+		if err != nil {
+			return dst, err
+		}
+	}
+}
+
+// streamLine is a line read out of a plain gzip file, handed over to a worker goroutine.
+type streamLine struct {
+	raw    []byte
+	offset int
+	line   int64
+}
+
+// decompressAsync decompresses the plain gzip file sequentially, reading it line by line
+// (record by record), and fans out batches of lines to the worker goroutines (see [CsvReader.readStreamAsync]).
+func (cr *CsvReader) decompressAsync(ctx context.Context, rn *Run) {
+	defer close(rn.streamLines)
+	const thread = 1 // errors are reported as coming from the first goroutine.
+	f := cr.openFile(rn, thread)
+	if f == nil {
+		return
+	}
+	defer f.Close()
+
+	offset := rn.threadsInfo[0][0]
+	if _, err := f.Seek(int64(offset), io.SeekStart); err != nil {
+		rn.fail()
+		if rn.sendErr(fmt.Errorf("bigcsvreader: thread #%d could not decompress file (%w)", thread, err)) {
+			cr.Logger.Error("msg", "could not decompress file", "err", err, "file", cr.fileBaseName, "thread", thread)
+		}
+
+		return
+	}
+	var (
+		r      = bufio.NewReaderSize(f, cr.BufferSize)
+		lh     = &lineHandler{thread: thread}
+		lineNo int64
+		batch  = make([]streamLine, 0, streamBatchSize)
+	)
+	if rn.firstLines != nil {
+		lineNo = rn.firstLines[0]
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			rn.sendErr(fmt.Errorf(
+				"bigcsvreader: thread #%d received context error (%w: %w)",
+				thread, ErrCanceledWithPartialData, err,
+			))
+
+			break
+		}
+		if rn.errorRate.isAborted() || rn.isTimedOut() || rn.isLimitReached() {
+			break
+		}
+		if rn.memThreshold > 0 && !rn.waitMemory(ctx) {
+			continue // context is done, let the check above handle it.
+		}
+		var (
+			line   []byte
+			breaks int
+		)
+		if cr.CRLFInQuotedFields || cr.MultilineQuotedFields {
+			line, breaks, _ = cr.readQuotedLine(rn, lh, r, offset, false)
+		} else {
+			line = cr.readLine(rn, r, thread, offset)
+		}
+		if line == nil {
+			break
+		}
+		batch = append(batch, streamLine{raw: slices.Clone(line), offset: offset, line: lineNo})
+		offset += len(line)
+		if lineNo > 0 {
+			lineNo += int64(breaks) + 1
+		}
+		if len(batch) == streamBatchSize {
+			rn.streamLines <- batch
+			batch = make([]streamLine, 0, streamBatchSize)
+		}
+	}
+	if len(batch) > 0 {
+		rn.streamLines <- batch
+	}
+	if sf, ok := f.(*gzipStreamFile); ok && sf.err != nil {
+		rn.fail() // the error was sent while reading the line.
+	}
+
+	cr.Logger.Debug("msg", "decompressed", "file", cr.fileBaseName, "bytesCount", offset-rn.threadsInfo[0][0])
+}
+
+// readStreamAsync handles the lines of a plain gzip file the decompressing goroutine fans out
+// (see [CsvReader.decompressAsync]), emitting the rows into the thread's channel.
+func (cr *CsvReader) readStreamAsync(
+	ctx context.Context,
+	rn *Run,
+	currentThreadNo, _, _ int,
+	wg *sync.WaitGroup,
+) {
+	defer wg.Done()
+	stats := &rn.threads[currentThreadNo-1]
+	defer stats.markDone()
+
+	lh := cr.newLineHandler(rn, currentThreadNo, stats)
+	defer cr.closeQuarantine(rn, lh)
+	if currentThreadNo == 1 && rn.emitHeader {
+		cr.emitHeaderRow(rn)
+	}
+	for batch := range rn.streamLines {
+		for _, sl := range batch {
+			lh.line = sl.line
+			cr.handleLine(ctx, rn, lh, sl.raw, sl.offset)
+			stats.addBytes(len(sl.raw))
+		}
+	}
+	cr.finishLines(ctx, rn, lh, 0, 0)
+}
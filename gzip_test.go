@@ -0,0 +1,296 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_gzip(t *testing.T) {
+	t.Parallel()
+
+	// random values, so the compressed file is big enough to be read by several goroutines.
+	const rowsCount = 3000
+	var (
+		content  strings.Builder
+		expected = make(map[string]string, rowsCount)
+		rnd      = rand.New(rand.NewSource(1))
+	)
+	content.WriteString("id,value\n")
+	for i := 1; i <= rowsCount; i++ {
+		value := fmt.Sprintf("%016x%016x", rnd.Uint64(), rnd.Uint64())
+		expected[strconv.Itoa(i)] = value
+		content.WriteString(strconv.Itoa(i) + "," + value + "\n")
+	}
+	dir := t.TempDir()
+	tests := [...]struct {
+		name     string
+		filePath string
+	}{
+		{
+			name:     "plain gzip",
+			filePath: writeTmpFile(t, dir, "plain.csv.gz", gzipContent(t, content.String())),
+		},
+		{
+			name:     "plain gzip detected by magic bytes",
+			filePath: writeTmpFile(t, dir, "plain.csv", gzipContent(t, content.String())),
+		},
+		{
+			name:     "BGZF",
+			filePath: writeBGZFFile(t, dir, "blocked.csv.gz", content.String(), 1000, false),
+		},
+		{
+			name:     "BGZF with blocks index",
+			filePath: writeBGZFFile(t, dir, "indexed.csv.gz", content.String(), 1000, true),
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(test.filePath)
+			subject.FileHasHeader = true
+			subject.ColumnsCount = 2
+			subject.MaxGoroutinesNo = 4
+			subject.NumberLines = true
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			header, errHeader := subject.ReadHeader(ctx)
+			rowsChans, errsChan := subject.ReadWithMeta(ctx)
+			var (
+				mu    sync.Mutex
+				wg    sync.WaitGroup
+				rows  = make(map[string]string, rowsCount)
+				lines = make(map[string]int64, rowsCount)
+			)
+			for _, rowsChan := range rowsChans {
+				wg.Add(1)
+				go func(rowsChan bigcsvreader.MetaRowsChan) {
+					defer wg.Done()
+					for row := range rowsChan {
+						mu.Lock()
+						rows[row.Fields[0]] = row.Fields[1]
+						lines[row.Fields[0]] = row.Line
+						mu.Unlock()
+					}
+				}(rowsChan)
+			}
+			err := bigcsvreader.CollectErrors(errsChan)
+			wg.Wait()
+
+			// assert
+			assertNil(t, errHeader)
+			assertEqual(t, []string{"id", "value"}, header)
+			assertNil(t, err)
+			assertEqual(t, 4, len(rowsChans))
+			assertEqual(t, expected, rows)
+			assertEqual(t, int64(2), lines["1"])
+			assertEqual(t, int64(rowsCount+1), lines[strconv.Itoa(rowsCount)])
+		})
+	}
+
+	t.Run("reading backwards a plain gzip file", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(tests[0].filePath)
+		subject.FileHasHeader = true
+
+		// act
+		rowsChans, errsChan := subject.ReadReverse(context.Background())
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertEqual(t, 0, len(rowsChans))
+		if assertNotNil(t, err) {
+			assertTrue(t, strings.Contains(err.Error(), "not supported with plain gzip compression"))
+		}
+	})
+
+	t.Run("not compressed file with gzip extension", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, t.TempDir(), "fake.csv.gz", "1,John\n2,Jane\n3,Mike\n"))
+		subject.ColumnsCount = 2
+
+		// act
+		rowsChans, errsChan := subject.Read(context.Background())
+		rows := gatherRowsInOrder(rowsChans)
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, [][]string{{"1", "John"}, {"2", "Jane"}, {"3", "Mike"}}, rows)
+	})
+}
+
+func TestCsvReader_gzipScans(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	const rowsCount = 1000
+	var content strings.Builder
+	content.WriteString("id,value\n")
+	for i := 1; i <= rowsCount; i++ {
+		content.WriteString(strconv.Itoa(i) + ",\"value " + strconv.Itoa(i) + "\"\n")
+	}
+	dir := t.TempDir()
+	tests := [...]struct {
+		name     string
+		filePath string
+		stream   bool
+	}{
+		{
+			name:     "plain gzip",
+			filePath: writeTmpFile(t, dir, "plain.csv.gz", gzipContent(t, content.String())),
+			stream:   true,
+		},
+		{
+			name:     "BGZF",
+			filePath: writeBGZFFile(t, dir, "blocked.csv.gz", content.String(), 1000, false),
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(test.filePath)
+			subject.FileHasHeader = true
+			subject.ColumnsCount = 2
+			subject.MaxGoroutinesNo = 4
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+			shardsDir := t.TempDir()
+
+			// act
+			artifacts, analyzeErr := subject.Analyze(ctx)
+			boundaryMap, preScanErr := subject.PreScan(ctx)
+			estimate, estimateErr := subject.EstimateRows(ctx, 1<<20)
+			shards, shardsErr := subject.WriteShards(ctx, shardsDir, 4096)
+
+			// assert
+			assertNil(t, analyzeErr)
+			assertEqual(t, rowsCount, artifacts.RowsCount)
+			if assertNil(t, preScanErr) {
+				assertEqual(t, int64(rowsCount), boundaryMap.RowsCount)
+			}
+			if test.stream {
+				if assertNotNil(t, estimateErr) {
+					assertTrue(t, strings.Contains(estimateErr.Error(), "not supported with plain gzip compression"))
+				}
+				if assertNotNil(t, shardsErr) {
+					assertTrue(t, strings.Contains(shardsErr.Error(), "not supported with plain gzip compression"))
+				}
+
+				return
+			}
+			assertNil(t, estimateErr)
+			assertEqual(
+				t,
+				bigcsvreader.RowsEstimate{Rows: rowsCount, Low: rowsCount, High: rowsCount, SampledRows: rowsCount, Exact: true},
+				estimate,
+			)
+			if assertNil(t, shardsErr) && assertTrue(t, len(shards) > 1) {
+				var shardsContent strings.Builder
+				for i, shard := range shards {
+					shardContent, err := os.ReadFile(shard)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if i > 0 {
+						shardContent = bytes.TrimPrefix(shardContent, []byte("id,value\n"))
+					}
+					shardsContent.Write(shardContent)
+				}
+				assertEqual(t, content.String(), shardsContent.String())
+			}
+		})
+	}
+}
+
+// gzipContent returns the gzip compression of given content.
+func gzipContent(t *testing.T, content string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+// writeBGZFFile writes the content, compressed as BGZF blocks holding blockSize bytes each, followed by
+// the empty EOF marker block, into the named file from given directory, and returns its path.
+// If withIndex is true, the blocks index file is written too.
+func writeBGZFFile(t *testing.T, dir, name, content string, blockSize int, withIndex bool) string {
+	t.Helper()
+	var (
+		buf   bytes.Buffer
+		index bytes.Buffer
+		count uint64
+	)
+	blocks := make([]string, 0, len(content)/blockSize+2)
+	for start := 0; start < len(content); start += blockSize {
+		blocks = append(blocks, content[start:min(start+blockSize, len(content))])
+	}
+	blocks = append(blocks, "") // EOF marker.
+	for i, block := range blocks {
+		if i > 0 && block != "" {
+			count++
+			_ = binary.Write(&index, binary.LittleEndian, [2]uint64{uint64(buf.Len()), uint64(i * blockSize)})
+		}
+		var compressed bytes.Buffer
+		zw := gzip.NewWriter(&compressed)
+		zw.Extra = []byte{'B', 'C', 2, 0, 0, 0} // the block size is patched once known.
+		if _, err := zw.Write([]byte(block)); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		b := compressed.Bytes()
+		binary.LittleEndian.PutUint16(b[16:], uint16(len(b)-1))
+		buf.Write(b)
+	}
+	if withIndex {
+		var indexFile bytes.Buffer
+		_ = binary.Write(&indexFile, binary.LittleEndian, count)
+		indexFile.Write(index.Bytes())
+		writeTmpFile(t, dir, name+".gzi", indexFile.String())
+	}
+
+	return writeTmpFile(t, dir, name, buf.String())
+}
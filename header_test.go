@@ -0,0 +1,189 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_EmitHeader(t *testing.T) {
+	t.Parallel()
+
+	const rowsCount = 500 // big enough for several goroutines.
+	var content strings.Builder
+	content.WriteString("id,name,stock\n")
+	for i := 1; i <= rowsCount; i++ {
+		fmt.Fprintf(&content, "%d,\"name %d\",%d\n", i, i, i*10)
+	}
+	filePath := writeTmpFile(t, t.TempDir(), "header.csv", content.String())
+	header := []string{"id", "name", "stock"}
+
+	for _, mode := range [...]string{"read", "reverse"} {
+		for _, goroutines := range [...]int{1, 3} {
+			mode, goroutines := mode, goroutines // capture range variables
+			t.Run(fmt.Sprintf("%s - %d goroutine(s)", mode, goroutines), func(t *testing.T) {
+				t.Parallel()
+
+				// arrange
+				subject := bigcsvreader.New()
+				subject.SetFilePath(filePath)
+				subject.ColumnsCount = 3
+				subject.FileHasHeader = true
+				subject.EmitHeader = true
+				subject.MaxGoroutinesNo = goroutines
+				subject.LimitRows = 1 << 40 // header is not accounted by the limit.
+				ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+				defer cancelCtx()
+
+				// act
+				read := subject.Read
+				if mode == "reverse" {
+					read = subject.ReadReverse
+				}
+				rowsChans, errsChan := read(ctx)
+				var err error
+				done := make(chan struct{})
+				go func() {
+					err = bigcsvreader.CollectErrors(errsChan)
+					close(done)
+				}()
+				rows := gatherRowsInOrder(rowsChans)
+				<-done
+
+				// assert
+				assertNil(t, err)
+				assertEqual(t, goroutines, len(rowsChans))
+				if assertEqual(t, rowsCount+1, len(rows)) {
+					if mode == "reverse" {
+						assertEqual(t, header, rows[rowsCount])
+						assertEqual(t, []string{"1", "name 1", "10"}, rows[rowsCount-1])
+					} else {
+						assertEqual(t, header, rows[0])
+						assertEqual(t, []string{"1", "name 1", "10"}, rows[1])
+					}
+				}
+			})
+		}
+	}
+
+	t.Run("with meta", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 3
+		subject.FileHasHeader = true
+		subject.EmitHeader = true
+		subject.MaxGoroutinesNo = 3
+		subject.NumberLines = true
+		subject.Filter = `id == "7"` // header is not filtered.
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		metaRowsChans, errsChan := subject.ReadWithMeta(ctx)
+		var rows []bigcsvreader.Row
+		for _, metaRowsChan := range metaRowsChans {
+			for row := range metaRowsChan {
+				rows = append(rows, row)
+			}
+		}
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertNil(t, err)
+		if assertEqual(t, 2, len(rows)) {
+			assertEqual(t, bigcsvreader.Row{Fields: header, Line: 1, RowIndexInChunk: -1, Header: true}, rows[0])
+			assertEqual(t, []string{"7", "name 7", "70"}, rows[1].Fields)
+			assertEqual(t, int64(8), rows[1].Line)
+			assertTrue(t, !rows[1].Header)
+		}
+	})
+
+	t.Run("not emitted by sample", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 3
+		subject.FileHasHeader = true
+		subject.EmitHeader = true
+		subject.MaxGoroutinesNo = 3
+		var out strings.Builder
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		err := subject.Sample(ctx, &out, bigcsvreader.SampleOptions{Size: rowsCount + 1})
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, rowsCount+1, strings.Count(out.String(), "\n"))
+		assertEqual(t, 1, strings.Count(out.String(), "id,name,stock\n"))
+	})
+}
+
+func TestCsvReader_ReadHeader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("first record is returned", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		subject.ColumnsDelimiter = ';'
+
+		// act
+		header, err := subject.ReadHeader(context.Background())
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, []string{"ID", "Name", "Age"}, header)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		ctx, cancelCtx := context.WithCancel(context.Background())
+		cancelCtx()
+
+		// act
+		header, err := subject.ReadHeader(ctx)
+
+		// assert
+		assertNil(t, header)
+		assertTrue(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/this_file_does_not_exist.csv")
+
+		// act
+		header, err := subject.ReadHeader(context.Background())
+
+		// assert
+		assertNil(t, header)
+		assertTrue(t, errors.Is(err, os.ErrNotExist))
+	})
+}
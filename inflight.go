@@ -0,0 +1,71 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import "sync"
+
+// WaitDone blocks until all the runs started by the reader (and by its copies, like the ones
+// [CsvReader.ReadFile] and [CsvReader.ReadFiles] use) so far have finished their cleanup:
+// all their goroutines exited, and all the files they opened (CSV file, pinned file, quarantine sidecars,
+// temporary decompressed files...) were closed or removed. It returns immediately if no run is in flight.
+//
+// Cancellation semantics: when the context given to a read is canceled, each goroutine stops before its next row,
+// sends an error wrapping [ErrCanceledWithPartialData] and exits; then all channels are closed.
+// Rows and errors channels must still be drained, as goroutines block while pushing into full channels,
+// so WaitDone would block forever if they are not.
+func (cr *CsvReader) WaitDone() {
+	cr.inflight.wait()
+}
+
+// runsTracker tracks the runs in flight.
+type runsTracker struct {
+	mu    sync.Mutex
+	count int
+	// idle is closed when count drops to 0.
+	idle chan struct{}
+}
+
+// add registers a run which started.
+func (rt *runsTracker) add() {
+	if rt == nil {
+		return
+	}
+	rt.mu.Lock()
+	if rt.count == 0 {
+		rt.idle = make(chan struct{})
+	}
+	rt.count++
+	rt.mu.Unlock()
+}
+
+// done unregisters a run which finished its cleanup.
+func (rt *runsTracker) done() {
+	if rt == nil {
+		return
+	}
+	rt.mu.Lock()
+	rt.count--
+	if rt.count == 0 {
+		close(rt.idle)
+	}
+	rt.mu.Unlock()
+}
+
+// wait blocks until no run is in flight.
+func (rt *runsTracker) wait() {
+	if rt == nil {
+		return
+	}
+	rt.mu.Lock()
+	if rt.count == 0 {
+		rt.mu.Unlock()
+
+		return
+	}
+	idle := rt.idle
+	rt.mu.Unlock()
+	<-idle
+}
@@ -0,0 +1,138 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package internal
+
+import (
+	"io/fs"
+	"math"
+	"os"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// availableCPUs caches the number of CPUs available to the process, as the CPU quota rarely changes.
+var availableCPUs = sync.OnceValue(func() int {
+	cpus := runtime.NumCPU()
+	if quota, ok := CPUQuota(os.DirFS("/")); ok {
+		cpus = min(cpus, max(int(math.Ceil(quota)), 1))
+	}
+
+	return cpus
+})
+
+// AvailableCPUs returns the number of CPUs the process can use: [runtime.NumCPU],
+// capped by the CPU quota of the process's cgroup, if any (containers with a CPU limit).
+func AvailableCPUs() int {
+	return availableCPUs()
+}
+
+// CPUQuota returns the CPU quota (in CPUs, possibly fractional) of the process's cgroup,
+// found in the given root file system, and whether there is a quota at all.
+// Both cgroup v1 (cpu.cfs_quota_us / cpu.cfs_period_us) and v2 (cpu.max) are supported.
+// The lowest quota of the cgroup and its ancestors is returned.
+func CPUQuota(root fs.FS) (float64, bool) {
+	content, err := fs.ReadFile(root, "proc/self/cgroup")
+	if err != nil {
+		return 0, false
+	}
+	quota := math.Inf(1)
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		// lines are "hierarchy-ID:controller-list:cgroup-path".
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		switch {
+		case fields[0] == "0" && fields[1] == "": // cgroup v2 unified hierarchy.
+			for _, dir := range cgroupDirs("sys/fs/cgroup", fields[2]) {
+				if q, ok := cgroupV2Quota(root, dir); ok {
+					quota = min(quota, q)
+				}
+			}
+		case hasCPUController(fields[1]): // cgroup v1 cpu hierarchy.
+			for _, mount := range [...]string{"sys/fs/cgroup/" + fields[1], "sys/fs/cgroup/cpu"} {
+				for _, dir := range cgroupDirs(mount, fields[2]) {
+					if q, ok := cgroupV1Quota(root, dir); ok {
+						quota = min(quota, q)
+					}
+				}
+			}
+		}
+	}
+	if math.IsInf(quota, 1) {
+		return 0, false
+	}
+
+	return quota, true
+}
+
+// cgroupDirs returns the directories of the cgroup at given path and of its ancestors, under given mount point.
+// Inside a container, the mount point may be the container's cgroup itself, case in which the cgroup's
+// directory does not exist, and the mount point holds its quota.
+func cgroupDirs(mount, cgroupPath string) []string {
+	dirs := []string{mount}
+	for p := path.Clean("/" + cgroupPath); p != "/"; p = path.Dir(p) {
+		dirs = append(dirs, mount+p)
+	}
+
+	return dirs
+}
+
+// hasCPUController returns whether given cgroup v1 controllers list holds the cpu controller.
+func hasCPUController(controllers string) bool {
+	for _, controller := range strings.Split(controllers, ",") {
+		if controller == "cpu" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cgroupV2Quota reads the quota from the cpu.max file ("$MAX $PERIOD", $MAX being "max" if there is no limit).
+func cgroupV2Quota(root fs.FS, dir string) (float64, bool) {
+	content, err := fs.ReadFile(root, dir+"/cpu.max")
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) != 2 || fields[0] == "max" {
+		return 0, false
+	}
+
+	return quotaRatio(fields[0], fields[1])
+}
+
+// cgroupV1Quota reads the quota from the cpu.cfs_quota_us and cpu.cfs_period_us files (quota is -1 if there is no limit).
+func cgroupV1Quota(root fs.FS, dir string) (float64, bool) {
+	quota, err := fs.ReadFile(root, dir+"/cpu.cfs_quota_us")
+	if err != nil {
+		return 0, false
+	}
+	period, err := fs.ReadFile(root, dir+"/cpu.cfs_period_us")
+	if err != nil {
+		return 0, false
+	}
+
+	return quotaRatio(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+}
+
+// quotaRatio returns the quota / period ratio, if both are positive.
+func quotaRatio(quota, period string) (float64, bool) {
+	q, err := strconv.ParseInt(quota, 10, 64)
+	if err != nil || q <= 0 {
+		return 0, false
+	}
+	p, err := strconv.ParseInt(period, 10, 64)
+	if err != nil || p <= 0 {
+		return 0, false
+	}
+
+	return float64(q) / float64(p), true
+}
@@ -0,0 +1,102 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package internal_test
+
+import (
+	"runtime"
+	"testing"
+	"testing/fstest"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+func TestCPUQuota(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name          string
+		root          fstest.MapFS
+		expectedQuota float64
+		expectedOk    bool
+	}{
+		{
+			name: "cgroup v2 with quota, inside a cgroup namespace",
+			root: fstest.MapFS{
+				"proc/self/cgroup":      {Data: []byte("0::/\n")},
+				"sys/fs/cgroup/cpu.max": {Data: []byte("200000 100000\n")},
+			},
+			expectedQuota: 2,
+			expectedOk:    true,
+		},
+		{
+			name: "cgroup v2 with quota on an ancestor",
+			root: fstest.MapFS{
+				"proc/self/cgroup":                    {Data: []byte("0::/kubepods/pod1/container1\n")},
+				"sys/fs/cgroup/kubepods/cpu.max":      {Data: []byte("max 100000\n")},
+				"sys/fs/cgroup/kubepods/pod1/cpu.max": {Data: []byte("150000 100000\n")},
+			},
+			expectedQuota: 1.5,
+			expectedOk:    true,
+		},
+		{
+			name: "cgroup v2 without quota",
+			root: fstest.MapFS{
+				"proc/self/cgroup":      {Data: []byte("0::/user.slice\n")},
+				"sys/fs/cgroup/cpu.max": {Data: []byte("max 100000\n")},
+			},
+		},
+		{
+			name: "cgroup v1 with quota",
+			root: fstest.MapFS{
+				"proc/self/cgroup": {Data: []byte(
+					"12:memory:/docker/abc\n4:cpu,cpuacct:/docker/abc\n1:name=systemd:/docker/abc\n",
+				)},
+				"sys/fs/cgroup/cpu,cpuacct/cpu.cfs_quota_us":  {Data: []byte("50000\n")},
+				"sys/fs/cgroup/cpu,cpuacct/cpu.cfs_period_us": {Data: []byte("100000\n")},
+			},
+			expectedQuota: 0.5,
+			expectedOk:    true,
+		},
+		{
+			name: "cgroup v1 without quota",
+			root: fstest.MapFS{
+				"proc/self/cgroup":                    {Data: []byte("4:cpu,cpuacct:/\n")},
+				"sys/fs/cgroup/cpu/cpu.cfs_quota_us":  {Data: []byte("-1\n")},
+				"sys/fs/cgroup/cpu/cpu.cfs_period_us": {Data: []byte("100000\n")},
+			},
+		},
+		{
+			name: "no cgroup",
+			root: fstest.MapFS{},
+		},
+	}
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			quota, ok := internal.CPUQuota(test.root)
+
+			// assert
+			if quota != test.expectedQuota || ok != test.expectedOk {
+				t.Errorf("expected (%v, %v), but got (%v, %v)", test.expectedQuota, test.expectedOk, quota, ok)
+			}
+		})
+	}
+}
+
+func TestAvailableCPUs(t *testing.T) {
+	t.Parallel()
+
+	// act
+	result := internal.AvailableCPUs()
+
+	// assert
+	if result < 1 || result > runtime.NumCPU() {
+		t.Errorf("expected a number of CPUs in [1, %d], but got %d", runtime.NumCPU(), result)
+	}
+}
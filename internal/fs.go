@@ -0,0 +1,126 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package internal
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// File is a file opened for reading.
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+	io.Closer
+	Stat() (os.FileInfo, error)
+}
+
+// FS opens files for reading.
+type FS interface {
+	// Open opens the named file for reading.
+	Open(name string) (File, error)
+	// Stat returns the named file's info.
+	Stat(name string) (os.FileInfo, error)
+}
+
+// OSFS is the [FS] backed by the operating system's file system.
+type OSFS struct{}
+
+// Open opens the named file for reading.
+func (OSFS) Open(name string) (File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err // avoid returning a non nil interface holding a nil *os.File.
+	}
+
+	return f, nil
+}
+
+// Stat returns the named file's info.
+func (OSFS) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// IOFS is the [FS] backed by an [fs.FS], like an [embed.FS], a zip archive, or a test fake.
+// Files not supporting random access (not implementing [io.ReaderAt] and [io.Seeker],
+// like compressed zip archive entries) are read into memory once, and kept.
+// It is safe for concurrent use.
+type IOFS struct {
+	fsys     fs.FS
+	mu       sync.Mutex
+	contents map[string][]byte
+}
+
+// NewIOFS instantiates a new [FS] backed by given [fs.FS].
+func NewIOFS(fsys fs.FS) *IOFS {
+	return &IOFS{fsys: fsys}
+}
+
+// Open opens the named file for reading.
+func (fsys *IOFS) Open(name string) (File, error) {
+	f, err := fsys.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if file, ok := f.(File); ok {
+		return file, nil
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	content, err := fsys.content(name, f)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+
+	return &memFile{Reader: bytes.NewReader(content), info: info}, nil
+}
+
+// Stat returns the named file's info.
+func (fsys *IOFS) Stat(name string) (os.FileInfo, error) {
+	return fs.Stat(fsys.fsys, name)
+}
+
+// content returns the named file's content, reading it from f the first time.
+func (fsys *IOFS) content(name string, f io.Reader) ([]byte, error) {
+	fsys.mu.Lock()
+	defer fsys.mu.Unlock()
+	if content, found := fsys.contents[name]; found {
+		return content, nil
+	}
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if fsys.contents == nil {
+		fsys.contents = make(map[string][]byte)
+	}
+	fsys.contents[name] = content
+
+	return content, nil
+}
+
+// memFile is a file read from memory.
+type memFile struct {
+	*bytes.Reader
+	info os.FileInfo
+}
+
+// Close does nothing.
+func (*memFile) Close() error {
+	return nil
+}
+
+// Stat returns the file's info.
+func (f *memFile) Stat() (os.FileInfo, error) {
+	return f.info, nil
+}
@@ -47,3 +47,28 @@ func ComputeGoroutineOffsets(totalBytes, maxGoroutines, minBytesReadByAGoroutine
 
 	return distribution
 }
+
+// AlignGoroutineOffsets moves the [start, end] intervals computed by [ComputeGoroutineOffsets] boundaries
+// backwards to multiples of unitSize (the size of a multi-byte encoding's code unit),
+// so each goroutine starts reading at a code unit's beginning.
+// Intervals which become empty are dropped.
+func AlignGoroutineOffsets(distribution [][2]int, unitSize int) [][2]int {
+	if unitSize <= 1 {
+		return distribution
+	}
+	aligned := distribution[:0]
+	for i, interval := range distribution {
+		if i > 0 {
+			interval[0] -= interval[0] % unitSize
+			if interval[0] <= aligned[len(aligned)-1][0] {
+				aligned[len(aligned)-1][1] = interval[1] // merge into previous interval.
+
+				continue
+			}
+			aligned[len(aligned)-1][1] = interval[0] - 1
+		}
+		aligned = append(aligned, interval)
+	}
+
+	return aligned
+}
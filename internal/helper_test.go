@@ -134,6 +134,56 @@ func TestComputeGoroutineOffsets(t *testing.T) {
 	}
 }
 
+func TestAlignGoroutineOffsets(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tests := [...]struct {
+		name              string
+		inputDistribution [][2]int
+		inputUnitSize     int
+		expectedResult    [][2]int
+	}{
+		{
+			name:              "boundaries are moved backwards to code units",
+			inputDistribution: [][2]int{{0, 6}, {7, 13}, {14, 20}},
+			inputUnitSize:     2,
+			expectedResult:    [][2]int{{0, 5}, {6, 13}, {14, 20}},
+		},
+		{
+			name:              "aligned boundaries are kept",
+			inputDistribution: [][2]int{{0, 5}, {6, 11}, {12, 17}},
+			inputUnitSize:     2,
+			expectedResult:    [][2]int{{0, 5}, {6, 11}, {12, 17}},
+		},
+		{
+			name:              "empty intervals are merged into previous ones",
+			inputDistribution: [][2]int{{0, 0}, {1, 1}, {2, 4}},
+			inputUnitSize:     2,
+			expectedResult:    [][2]int{{0, 1}, {2, 4}},
+		},
+		{
+			name:              "single byte units leave the distribution unchanged",
+			inputDistribution: [][2]int{{0, 6}, {7, 13}},
+			inputUnitSize:     1,
+			expectedResult:    [][2]int{{0, 6}, {7, 13}},
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			// act
+			result := internal.AlignGoroutineOffsets(test.inputDistribution, test.inputUnitSize)
+
+			// assert
+			if !reflect.DeepEqual(result, test.expectedResult) {
+				t.Errorf("expected %v, but got %v | %s", test.expectedResult, result, test.name)
+			}
+		})
+	}
+}
+
 func BenchmarkComputeGoroutineOffsets_1(b *testing.B) {
 	b.ReportAllocs()
 	for n := 0; n < b.N; n++ {
@@ -0,0 +1,61 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package internal
+
+import (
+	"hash/maphash"
+	"math"
+	"math/bits"
+)
+
+// hllPrecision is the number of hash bits indexing the registers of a [HyperLogLog].
+// 2^14 registers give a standard error of about 0.8%.
+const hllPrecision = 14
+
+// HyperLogLog is a fixed memory (16KiB) cardinality estimator.
+// It is not safe for concurrent use.
+type HyperLogLog struct {
+	seed      maphash.Seed
+	registers [1 << hllPrecision]uint8
+}
+
+// NewHyperLogLog instantiates a new [HyperLogLog].
+func NewHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{seed: maphash.MakeSeed()}
+}
+
+// Add adds the value.
+func (hll *HyperLogLog) Add(value string) {
+	hash := maphash.String(hll.seed, value)
+	idx := hash >> (64 - hllPrecision)
+	rank := uint8(bits.LeadingZeros64(hash<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	if rank > hll.registers[idx] {
+		hll.registers[idx] = rank
+	}
+}
+
+// Estimate returns the estimated number of distinct values added.
+func (hll *HyperLogLog) Estimate() uint64 {
+	const m = float64(len(hll.registers))
+	var (
+		sum   float64
+		zeros int
+	)
+	for _, rank := range hll.registers {
+		sum += 1 / float64(uint64(1)<<rank)
+		if rank == 0 {
+			zeros++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// small range correction (linear counting).
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return uint64(estimate + 0.5)
+}
@@ -0,0 +1,110 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package internal
+
+import (
+	"hash/maphash"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// keySetShardsNo is the number of shards of a [ShardedKeySet].
+const keySetShardsNo = 64
+
+// ShardedKeySet is a concurrent safe set of keys, which remembers the offset each key was first added at.
+// Keys are spread across shards, each guarded by its own mutex, to reduce contention.
+type ShardedKeySet struct {
+	seed   maphash.Seed
+	shards [keySetShardsNo]keySetShard
+}
+
+type keySetShard struct {
+	mu   sync.Mutex
+	keys map[string]int
+}
+
+// NewShardedKeySet instantiates a new [ShardedKeySet].
+func NewShardedKeySet() *ShardedKeySet {
+	set := &ShardedKeySet{seed: maphash.MakeSeed()}
+	for i := range set.shards {
+		set.shards[i].keys = make(map[string]int)
+	}
+
+	return set
+}
+
+// Add adds the key, if absent.
+// It returns true if key was added, or false and the offset the key was first added at.
+func (set *ShardedKeySet) Add(key string, offset int) (int, bool) {
+	shard := &set.shards[maphash.String(set.seed, key)%keySetShardsNo]
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	if firstOffset, found := shard.keys[key]; found {
+		return firstOffset, false
+	}
+	shard.keys[key] = offset
+
+	return offset, true
+}
+
+// BloomFilter is a concurrent safe, fixed memory, probabilistic set of keys.
+// A key reported as present may be a false positive; a key reported as absent is surely absent.
+type BloomFilter struct {
+	seed     maphash.Seed
+	bits     []uint64
+	bitsNo   uint64
+	hashesNo uint64
+}
+
+// NewBloomFilter instantiates a new [BloomFilter], sized for expectedKeys
+// with given false positive rate (0 < rate < 1).
+func NewBloomFilter(expectedKeys int, falsePositiveRate float64) *BloomFilter {
+	if expectedKeys < 1 {
+		expectedKeys = 1
+	}
+	// m = -n*ln(p)/ln(2)^2, k = m/n*ln(2).
+	bitsNo := uint64(math.Ceil(-float64(expectedKeys) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	if bitsNo < 64 {
+		bitsNo = 64
+	}
+	hashesNo := uint64(math.Round(float64(bitsNo) / float64(expectedKeys) * math.Ln2))
+	if hashesNo < 1 {
+		hashesNo = 1
+	}
+
+	return &BloomFilter{
+		seed:     maphash.MakeSeed(),
+		bits:     make([]uint64, (bitsNo+63)/64),
+		bitsNo:   bitsNo,
+		hashesNo: hashesNo,
+	}
+}
+
+// Add adds the key. It returns true if key was (surely) absent before.
+func (bf *BloomFilter) Add(key string) bool {
+	// double hashing: h(i) = h1 + i*h2.
+	h1 := maphash.String(bf.seed, key)
+	h2 := h1>>32 | h1<<32 | 1
+	absent := false
+	for i := uint64(0); i < bf.hashesNo; i++ {
+		bit := (h1 + i*h2) % bf.bitsNo
+		word, mask := &bf.bits[bit/64], uint64(1)<<(bit%64)
+		for {
+			old := atomic.LoadUint64(word)
+			if old&mask != 0 {
+				break
+			}
+			if atomic.CompareAndSwapUint64(word, old, old|mask) {
+				absent = true
+
+				break
+			}
+		}
+	}
+
+	return absent
+}
@@ -0,0 +1,82 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package internal_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+func TestShardedKeySet(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := internal.NewShardedKeySet()
+
+	// act & assert
+	offset, added := subject.Add("a", 10)
+	if !added || offset != 10 {
+		t.Errorf("expected key to be added at offset 10, got %d, %t", offset, added)
+	}
+	offset, added = subject.Add("b", 20)
+	if !added || offset != 20 {
+		t.Errorf("expected key to be added at offset 20, got %d, %t", offset, added)
+	}
+	offset, added = subject.Add("a", 30)
+	if added || offset != 10 {
+		t.Errorf("expected duplicate key first seen at offset 10, got %d, %t", offset, added)
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	const keysNo = 10000
+	subject := internal.NewBloomFilter(2*keysNo, 0.01) // checked keys are added too
+	for i := 0; i < keysNo; i++ {
+		subject.Add(strconv.Itoa(i))
+	}
+
+	// act & assert
+	for i := 0; i < keysNo; i++ {
+		if subject.Add(strconv.Itoa(i)) {
+			t.Fatalf("expected key %d to be reported as present", i)
+		}
+	}
+	falsePositives := 0
+	for i := keysNo; i < 2*keysNo; i++ {
+		if !subject.Add(strconv.Itoa(i)) {
+			falsePositives++
+		}
+	}
+	if falsePositives > keysNo/50 {
+		t.Errorf("too many false positives: %d", falsePositives)
+	}
+}
+
+func TestHyperLogLog(t *testing.T) {
+	t.Parallel()
+
+	for _, distinct := range [...]int{0, 10, 1000, 100000} {
+		// arrange
+		subject := internal.NewHyperLogLog()
+
+		// act
+		for i := 0; i < 2*distinct; i++ {
+			subject.Add(strconv.Itoa(i % distinct))
+		}
+		estimate := subject.Estimate()
+
+		// assert (small counts may be off by one: two values may share a register)
+		if diff := math.Abs(float64(estimate) - float64(distinct)); diff > max(1, 0.03*float64(distinct)) {
+			t.Errorf("expected estimate close to %d, got %d", distinct, estimate)
+		}
+	}
+}
@@ -22,3 +22,32 @@ func (NopLogger) Debug(...interface{}) {}
 
 // Error does nothing.
 func (NopLogger) Error(...interface{}) {}
+
+// WithKeyValues returns a Logger which prepends given key values to the ones of each call, before passing them to logger.
+func WithKeyValues(logger Logger, keyValues ...interface{}) Logger {
+	return prefixLogger{logger: logger, keyValues: keyValues}
+}
+
+// prefixLogger is a Logger prepending key values to the ones of each call.
+type prefixLogger struct {
+	logger    Logger
+	keyValues []interface{}
+}
+
+// Debug logs debug information, prefixed with the logger's key values.
+func (l prefixLogger) Debug(keyValues ...interface{}) {
+	l.logger.Debug(l.prefix(keyValues)...)
+}
+
+// Error logs any error occurred, prefixed with the logger's key values.
+func (l prefixLogger) Error(keyValues ...interface{}) {
+	l.logger.Error(l.prefix(keyValues)...)
+}
+
+// prefix returns the logger's key values followed by given ones.
+func (l prefixLogger) prefix(keyValues []interface{}) []interface{} {
+	all := make([]interface{}, 0, len(l.keyValues)+len(keyValues))
+	all = append(all, l.keyValues...)
+
+	return append(all, keyValues...)
+}
@@ -6,6 +6,7 @@
 package internal_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/actforgood/bigcsvreader/internal"
@@ -26,3 +27,32 @@ func TestNopLogger(t *testing.T) {
 	subject.Error("foo", "bar", "abc", 123)
 	subject.Debug("foo", "bar", "abc", 123, "err", "some error")
 }
+
+func TestWithKeyValues(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var logged [][]interface{}
+	logger := funcLogger(func(keyValues ...interface{}) { logged = append(logged, keyValues) })
+	subject := internal.WithKeyValues(logger, "run", "abc")
+
+	// act
+	subject.Debug("msg", "debug")
+	subject.Error("msg", "error", "err", "some error")
+
+	// assert
+	expected := [][]interface{}{
+		{"run", "abc", "msg", "debug"},
+		{"run", "abc", "msg", "error", "err", "some error"},
+	}
+	if !reflect.DeepEqual(expected, logged) {
+		t.Errorf("expected %v, but got %v", expected, logged)
+	}
+}
+
+// funcLogger is a Logger calling itself for both levels.
+type funcLogger func(keyValues ...interface{})
+
+func (l funcLogger) Debug(keyValues ...interface{}) { l(keyValues...) }
+
+func (l funcLogger) Error(keyValues ...interface{}) { l(keyValues...) }
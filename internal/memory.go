@@ -0,0 +1,48 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package internal
+
+import (
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+)
+
+const (
+	metricTotalMemory    = "/memory/classes/total:bytes"
+	metricReleasedMemory = "/memory/classes/heap/released:bytes"
+)
+
+// MemoryLimit returns the Go runtime soft memory limit (see [debug.SetMemoryLimit]),
+// or 0 if no limit is set.
+func MemoryLimit() int64 {
+	limit := debug.SetMemoryLimit(-1) // negative input only queries the limit.
+	if limit <= 0 || limit == math.MaxInt64 {
+		return 0
+	}
+
+	return limit
+}
+
+// MemoryUsage returns the memory accounted by the Go runtime against the soft memory limit,
+// which is the total memory mapped by the runtime minus the heap memory released to the OS.
+func MemoryUsage() int64 {
+	samples := []metrics.Sample{
+		{Name: metricTotalMemory},
+		{Name: metricReleasedMemory},
+	}
+	metrics.Read(samples)
+
+	var usage int64
+	if samples[0].Value.Kind() == metrics.KindUint64 {
+		usage = int64(samples[0].Value.Uint64())
+	}
+	if samples[1].Value.Kind() == metrics.KindUint64 {
+		usage -= int64(samples[1].Value.Uint64())
+	}
+
+	return usage
+}
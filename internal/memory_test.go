@@ -0,0 +1,45 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package internal_test
+
+import (
+	"math"
+	"runtime/debug"
+	"testing"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+func TestMemoryLimit(t *testing.T) {
+	// Note: not parallel, as it alters the process wide memory limit.
+
+	// arrange
+	initialLimit := debug.SetMemoryLimit(-1)
+	defer debug.SetMemoryLimit(initialLimit)
+
+	// act & assert
+	debug.SetMemoryLimit(math.MaxInt64)
+	if result := internal.MemoryLimit(); result != 0 {
+		t.Errorf("expected 0, but got %d", result)
+	}
+
+	debug.SetMemoryLimit(512 << 20)
+	if result := internal.MemoryLimit(); result != 512<<20 {
+		t.Errorf("expected %d, but got %d", 512<<20, result)
+	}
+}
+
+func TestMemoryUsage(t *testing.T) {
+	t.Parallel()
+
+	// act
+	result := internal.MemoryUsage()
+
+	// assert
+	if result <= 0 {
+		t.Errorf("expected positive memory usage, but got %d", result)
+	}
+}
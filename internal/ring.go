@@ -0,0 +1,93 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package internal
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// ringSpinsBeforeSleep is the number of failed attempts a [SPSCRing] side yields the processor
+// before it starts sleeping between attempts.
+const ringSpinsBeforeSleep = 128
+
+// ringSleep is the period a [SPSCRing] side sleeps between attempts, after spinning.
+const ringSleep = 20 * time.Microsecond
+
+// cacheLinePad prevents false sharing between fields updated by different goroutines.
+type cacheLinePad [64]byte
+
+// SPSCRing is a bounded, lock-free, single-producer single-consumer queue.
+// Exactly one goroutine may push, and exactly one goroutine may pop.
+// A side waiting for the other spins (yielding the processor), then sleeps briefly between attempts.
+type SPSCRing[T any] struct {
+	buf  []T
+	mask uint64
+	_    cacheLinePad
+	// head is the position of the next value to pop, written by the consumer.
+	head atomic.Uint64
+	_    cacheLinePad
+	// tail is the position of the next value to push, written by the producer.
+	tail atomic.Uint64
+	_    cacheLinePad
+	// closed is set by the producer after the last push.
+	closed atomic.Bool
+}
+
+// NewSPSCRing instantiates a new [SPSCRing] holding up to capacity values (rounded up to a power of 2).
+func NewSPSCRing[T any](capacity int) *SPSCRing[T] {
+	size := 1
+	for size < capacity {
+		size <<= 1
+	}
+
+	return &SPSCRing[T]{buf: make([]T, size), mask: uint64(size - 1)}
+}
+
+// Push appends the value, waiting while the ring is full.
+func (r *SPSCRing[T]) Push(value T) {
+	tail := r.tail.Load()
+	for attempt := 0; tail-r.head.Load() == uint64(len(r.buf)); attempt++ {
+		wait(attempt)
+	}
+	r.buf[tail&r.mask] = value
+	r.tail.Store(tail + 1)
+}
+
+// Pop removes and returns the oldest value, waiting while the ring is empty.
+// It returns false if the ring is empty and closed.
+func (r *SPSCRing[T]) Pop() (T, bool) {
+	head := r.head.Load()
+	for attempt := 0; head == r.tail.Load(); attempt++ {
+		if r.closed.Load() && head == r.tail.Load() {
+			var zero T
+
+			return zero, false
+		}
+		wait(attempt)
+	}
+	value := r.buf[head&r.mask]
+	var zero T
+	r.buf[head&r.mask] = zero // release the reference.
+	r.head.Store(head + 1)
+
+	return value, true
+}
+
+// Close marks the end of pushes. Values pushed before can still be popped.
+func (r *SPSCRing[T]) Close() {
+	r.closed.Store(true)
+}
+
+// wait backs off before the given (0-based) attempt's retry.
+func wait(attempt int) {
+	if attempt < ringSpinsBeforeSleep {
+		runtime.Gosched()
+	} else {
+		time.Sleep(ringSleep)
+	}
+}
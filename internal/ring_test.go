@@ -0,0 +1,79 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+func TestSPSCRing(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	const valuesNo = 100000
+	subject := internal.NewSPSCRing[int](10)
+	go func() {
+		for i := 0; i < valuesNo; i++ {
+			subject.Push(i)
+		}
+		subject.Close()
+	}()
+
+	// act & assert
+	expected := 0
+	for {
+		value, ok := subject.Pop()
+		if !ok {
+			break
+		}
+		if value != expected {
+			t.Fatalf("expected %d, got %d", expected, value)
+		}
+		expected++
+	}
+	if expected != valuesNo {
+		t.Errorf("expected %d values, got %d", valuesNo, expected)
+	}
+}
+
+func benchmarkTransport(newTransport func() internal.Transport[int]) func(b *testing.B) {
+	return func(b *testing.B) {
+		subject := newTransport()
+		done := make(chan struct{})
+		go func() {
+			for {
+				if _, ok := subject.Pop(); !ok {
+					close(done)
+
+					return
+				}
+			}
+		}()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for n := 0; n < b.N; n++ {
+			subject.Push(n)
+		}
+		subject.Close()
+		<-done
+	}
+}
+
+func BenchmarkSPSCRing(b *testing.B) {
+	benchmarkTransport(func() internal.Transport[int] {
+		return internal.NewSPSCRing[int](256)
+	})(b)
+}
+
+func BenchmarkChanTransport(b *testing.B) {
+	benchmarkTransport(func() internal.Transport[int] {
+		return internal.NewChanTransport[int](256)
+	})(b)
+}
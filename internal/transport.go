@@ -0,0 +1,45 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package internal
+
+// Transport passes values from exactly one producer goroutine to exactly one consumer goroutine.
+type Transport[T any] interface {
+	// Push appends the value, waiting while the transport is full.
+	Push(value T)
+	// Pop removes and returns the oldest value, waiting while the transport is empty.
+	// It returns false if the transport is empty and closed.
+	Pop() (T, bool)
+	// Close marks the end of pushes. Values pushed before can still be popped.
+	Close()
+}
+
+// ChanTransport is a [Transport] backed by a buffered channel.
+type ChanTransport[T any] struct {
+	ch chan T
+}
+
+// NewChanTransport instantiates a new [ChanTransport] buffering up to capacity values.
+func NewChanTransport[T any](capacity int) *ChanTransport[T] {
+	return &ChanTransport[T]{ch: make(chan T, capacity)}
+}
+
+// Push appends the value, waiting while the channel is full.
+func (t *ChanTransport[T]) Push(value T) {
+	t.ch <- value
+}
+
+// Pop removes and returns the oldest value, waiting while the channel is empty.
+// It returns false if the channel is empty and closed.
+func (t *ChanTransport[T]) Pop() (T, bool) {
+	value, ok := <-t.ch
+
+	return value, ok
+}
+
+// Close closes the channel.
+func (t *ChanTransport[T]) Close() {
+	close(t.ch)
+}
@@ -0,0 +1,134 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ProfilerLabels(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath, err := setUpTmpCsvFile(5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tearDownTmpCsvFile(filePath)
+	subject := bigcsvreader.New()
+	subject.SetFilePath(filePath)
+	subject.ColumnsCount = 5
+	subject.MaxGoroutinesNo = 2
+	subject.ChanBufferSize = 0 // goroutines block until rows are consumed.
+	subject.ProfilerLabels = true
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+	expectedLabels := []string{
+		fmt.Sprintf(`"chunk":"1", "file":%q`, filePath),
+		fmt.Sprintf(`"chunk":"2", "file":%q`, filePath),
+	}
+
+	// act
+	run := subject.Start(ctx)
+	var profile bytes.Buffer
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		profile.Reset()
+		_ = pprof.Lookup("goroutine").WriteTo(&profile, 1)
+		if bytes.Contains(profile.Bytes(), []byte(expectedLabels[0])) &&
+			bytes.Contains(profile.Bytes(), []byte(expectedLabels[1])) {
+			break
+		}
+	}
+	drainRows(run.RowsChans())
+	err = run.Err()
+
+	// assert
+	assertNil(t, err)
+	for _, labels := range expectedLabels {
+		assertTrue(t, bytes.Contains(profile.Bytes(), []byte(labels)))
+	}
+}
+
+func TestCsvReader_RunLabel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	logger := &recordingLogger{}
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/invalid_row.csv")
+	subject.FileHasHeader = true
+	subject.ColumnsCount = 3
+	subject.Logger = logger
+	subject.RunLabel = "import-42"
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	runs := []*bigcsvreader.Run{subject.Start(ctx), subject.Start(ctx)}
+	for _, run := range runs {
+		drainRows(run.RowsChans())
+		_ = run.Err()
+	}
+
+	// assert
+	assertEqual(t, 16, len(runs[0].ID()))
+	assertTrue(t, runs[0].ID() != runs[1].ID())
+	logs := logger.logs()
+	runLogs := make(map[string]int, len(runs))
+	var threadLogs int
+	for _, keyValues := range logs {
+		if assertTrue(t, len(keyValues) >= 4) {
+			assertEqual(t, "run", keyValues[0])
+			assertEqual(t, "runLabel", keyValues[2])
+			assertEqual(t, "import-42", keyValues[3])
+			runLogs[fmt.Sprint(keyValues[1])]++
+		}
+		for i := 0; i < len(keyValues); i += 2 {
+			if keyValues[i] == "thread" {
+				threadLogs++
+			}
+		}
+	}
+	assertEqual(t, 2, len(runLogs))
+	assertTrue(t, runLogs[runs[0].ID()] > 0)
+	assertTrue(t, runLogs[runs[1].ID()] > 0)
+	assertTrue(t, threadLogs >= 2) // the invalid row of each run, at least.
+}
+
+// recordingLogger records the key values of each call.
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries [][]interface{}
+}
+
+func (l *recordingLogger) Debug(keyValues ...interface{}) {
+	l.record(keyValues)
+}
+
+func (l *recordingLogger) Error(keyValues ...interface{}) {
+	l.record(keyValues)
+}
+
+func (l *recordingLogger) record(keyValues []interface{}) {
+	l.mu.Lock()
+	l.entries = append(l.entries, keyValues)
+	l.mu.Unlock()
+}
+
+func (l *recordingLogger) logs() [][]interface{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([][]interface{}(nil), l.entries...)
+}
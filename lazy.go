@@ -0,0 +1,188 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strings"
+	"unicode/utf8"
+)
+
+// LazyRowsChan is the channel where lazy rows will be pushed into.
+type LazyRowsChan <-chan *LazyRow
+
+// LazyRow holds a raw CSV line and its fields' boundaries.
+// Fields are materialized into strings only on demand, so consumers which only touch
+// a few columns avoid allocating the rest.
+type LazyRow struct {
+	// Offset is the offset of the row in the file.
+	Offset int
+	// Line is the 1-based line number of the row in the file, if [CsvReader.NumberLines] is enabled, 0 otherwise.
+	Line int64
+	// ChunkIndex is the 0-based index of the chunk (read by a goroutine) the row belongs to.
+	// Chunks are indexed in file order.
+	ChunkIndex int
+	// RowIndexInChunk is the 0-based index of the row among the rows emitted from its chunk.
+	RowIndexInChunk int64
+	line            []byte
+	fields          []lazyField
+}
+
+// lazyField holds a field's boundaries in the line (quotes excluded).
+type lazyField struct {
+	start, end int
+	// escaped is a flag indicating the field holds doubled quotes ("").
+	escaped bool
+}
+
+// Len returns the number of fields.
+func (lr *LazyRow) Len() int {
+	return len(lr.fields)
+}
+
+// Field returns the i-th (0-based) field's value.
+func (lr *LazyRow) Field(i int) string {
+	field := lr.fields[i]
+	value := string(lr.line[field.start:field.end])
+	if field.escaped {
+		value = strings.ReplaceAll(value, `""`, `"`)
+	}
+
+	return value
+}
+
+// Fields returns all fields' values.
+func (lr *LazyRow) Fields() []string {
+	fields := make([]string, len(lr.fields))
+	for i := range lr.fields {
+		fields[i] = lr.Field(i)
+	}
+
+	return fields
+}
+
+// Raw returns the raw line, end line delimiter excluded.
+// The returned slice must not be modified.
+func (lr *LazyRow) Raw() []byte {
+	return lr.line
+}
+
+// ReadLazy extracts asynchronously CSV rows as [LazyRow]s, each started goroutine putting them into a LazyRowsChan.
+// Only fields' boundaries are computed while reading, values being materialized on demand.
+// Error(s) occurred during parsing are sent through ErrsChan.
+// Note: [CsvReader.TrimSpace], [CsvReader.ColumnsOptions], [CsvReader.Unique] and [CsvReader.ForeignKey]
+// are not applied to lazy rows, as they would require materializing all values.
+func (cr *CsvReader) ReadLazy(ctx context.Context) ([]LazyRowsChan, ErrsChan) {
+	rn := cr.start(ctx, modeLazy, nil)
+
+	return rn.LazyRowsChans(), rn.ErrsChan()
+}
+
+// LazyRowsChans returns the channels where lazy rows are pushed into, one for each started goroutine,
+// if the run was started by [CsvReader.ReadLazy].
+func (rn *Run) LazyRowsChans() []LazyRowsChan {
+	if len(rn.lazyChans) == 0 {
+		return nil
+	}
+	lazyChans := make([]LazyRowsChan, len(rn.lazyChans))
+	for i, lazyChan := range rn.lazyChans {
+		lazyChans[i] = lazyChan
+	}
+
+	return lazyChans
+}
+
+// emitLazyRow splits the line into fields and pushes the lazy row into the thread's channel.
+func (cr *CsvReader) emitLazyRow(rn *Run, line []byte, thread, offset int, lineNo int64, stats *threadStats) {
+	lazyRow := &LazyRow{Offset: offset, Line: lineNo, line: bytes.Clone(line)}
+	err := splitLazy(lazyRow, cr.ColumnsDelimiter, cr.LazyQuotes)
+	if err == nil && cr.ColumnsCount > 0 && len(lazyRow.fields) != cr.ColumnsCount {
+		err = csv.ErrFieldCount
+	}
+	cr.accountRow(rn, err != nil, thread)
+	if err != nil {
+		cr.sendParseErr(rn, thread, offset, lineNo, line, err)
+
+		return
+	}
+	if !rn.takeRow() {
+		return
+	}
+	lazyRow.ChunkIndex, lazyRow.RowIndexInChunk = thread-1, stats.rows()
+	rn.lazyChans[thread-1] <- lazyRow
+	stats.observeChan(len(rn.lazyChans[thread-1]))
+	stats.addRow()
+}
+
+// splitLazy computes the fields' boundaries of the lazy row's line.
+func splitLazy(lr *LazyRow, comma rune, lazyQuotes bool) error {
+	line := bytes.TrimSuffix(lr.line, []byte{'\n'})
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	lr.line = line
+	sep := make([]byte, utf8.RuneLen(comma))
+	utf8.EncodeRune(sep, comma)
+
+	pos := 0
+	for {
+		if pos < len(line) && line[pos] == '"' {
+			// quoted field.
+			field := lazyField{start: pos + 1}
+			i := pos + 1
+			for {
+				j := bytes.IndexByte(line[i:], '"')
+				if j < 0 {
+					if !lazyQuotes {
+						return csv.ErrQuote
+					}
+					field.end = len(line)
+					pos = len(line)
+
+					break
+				}
+				i += j
+				if i+1 < len(line) && line[i+1] == '"' {
+					field.escaped = true
+					i += 2
+
+					continue
+				}
+				field.end = i
+				pos = i + 1
+
+				break
+			}
+			if pos < len(line) && !bytes.HasPrefix(line[pos:], sep) {
+				if !lazyQuotes {
+					return csv.ErrQuote
+				}
+				// treat the rest, up to separator, as part of the field.
+				next := bytes.Index(line[pos:], sep)
+				if next < 0 {
+					next = len(line) - pos
+				}
+				pos += next
+				field.end = pos
+			}
+			lr.fields = append(lr.fields, field)
+		} else {
+			next := bytes.Index(line[pos:], sep)
+			if next < 0 {
+				next = len(line) - pos
+			}
+			if !lazyQuotes && bytes.IndexByte(line[pos:pos+next], '"') >= 0 {
+				return csv.ErrBareQuote
+			}
+			lr.fields = append(lr.fields, lazyField{start: pos, end: pos + next})
+			pos += next
+		}
+		if pos >= len(line) {
+			return nil
+		}
+		pos += len(sep)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ReadLazy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lazy rows match eager rows", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		filePath, err := setUpTmpCsvFile(3000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tearDownTmpCsvFile(filePath)
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 5
+		subject.MaxGoroutinesNo = 4
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+		expected, err := gatherRecords(subject.Read(ctx))
+		assertNil(t, err)
+
+		// act
+		lazyChans, errsChan := subject.ReadLazy(ctx)
+		var (
+			records [][]string
+			mu      sync.Mutex
+			wg      sync.WaitGroup
+		)
+		wg.Add(len(lazyChans))
+		for _, lazyChan := range lazyChans {
+			go func(lazyChan bigcsvreader.LazyRowsChan) {
+				defer wg.Done()
+				for lazyRow := range lazyChan {
+					assertEqual(t, 5, lazyRow.Len())
+					mu.Lock()
+					records = append(records, lazyRow.Fields())
+					mu.Unlock()
+				}
+			}(lazyChan)
+		}
+		for err := range errsChan {
+			t.Errorf("unexpected error %v", err)
+		}
+		wg.Wait()
+
+		// assert
+		byID := func(records [][]string) func(i, j int) bool {
+			return func(i, j int) bool { return records[i][0] < records[j][0] }
+		}
+		sort.Slice(expected, byID(expected))
+		sort.Slice(records, byID(records))
+		assertEqual(t, expected, records)
+	})
+
+	t.Run("quoted fields and errors", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		filePath := filepath.Join(t.TempDir(), "quotes.csv")
+		content := "1;\"say \"\"hi\"\"\";;x\r\n" +
+			"2;\"a;b\";c;\"\"\n" +
+			"3;bad\"quote;c;d\n" +
+			"4;b;c\n" +
+			"5;\"unterminated;c;d\n"
+		if err := os.WriteFile(filePath, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 4
+		subject.ColumnsDelimiter = ';'
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		lazyChans, errsChan := subject.ReadLazy(ctx)
+		var (
+			records [][]string
+			offsets []int
+		)
+		for _, lazyChan := range lazyChans {
+			for lazyRow := range lazyChan {
+				records = append(records, lazyRow.Fields())
+				offsets = append(offsets, lazyRow.Offset)
+			}
+		}
+		var errs []error
+		for err := range errsChan {
+			errs = append(errs, err)
+		}
+
+		// assert
+		assertEqual(t, [][]string{{"1", `say "hi"`, "", "x"}, {"2", "a;b", "c", ""}}, records)
+		assertEqual(t, []int{0, 19}, offsets)
+		if assertEqual(t, 3, len(errs)) {
+			assertTrue(t, errors.Is(errs[0], csv.ErrBareQuote))
+			assertTrue(t, errors.Is(errs[1], csv.ErrFieldCount))
+			assertTrue(t, errors.Is(errs[2], csv.ErrQuote))
+		}
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_LimitRows(t *testing.T) {
+	t.Parallel()
+
+	const rowsCount = 50000
+	filePath, err := setUpTmpCsvFile(rowsCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tearDownTmpCsvFile(filePath) })
+
+	t.Run("reading stops once the limit is reached", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		summaries := make(chan bigcsvreader.Summary, 1)
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 5
+		subject.MaxGoroutinesNo = 4
+		subject.ChanBufferSize = 1
+		subject.LimitRows = 100
+		subject.OnComplete = func(summary bigcsvreader.Summary) {
+			summaries <- summary
+		}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		count := drainRows(run.RowsChans())
+		err := run.Err()
+		summary := <-summaries
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, 100, count)
+		assertEqual(t, int64(100), summary.RowsCount)
+		assertTrue(t, summary.BytesCount < summary.DataSize/10)
+	})
+
+	t.Run("limit above rows count", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 5
+		subject.MaxGoroutinesNo = 4
+		subject.LimitRows = rowsCount + 1
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		count := drainRows(run.RowsChans())
+		err := run.Err()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, rowsCount, count)
+	})
+
+	t.Run("lazy rows", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 5
+		subject.MaxGoroutinesNo = 1
+		subject.LimitRows = 3
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		lazyRowsChans, errsChan := subject.ReadLazy(ctx)
+		var ids []string
+		for lazyRow := range lazyRowsChans[0] {
+			ids = append(ids, lazyRow.Field(0))
+		}
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, []string{"1", "2", "3"}, ids)
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// lineCountBufferSize is the buffer size used to count lines ahead of reading.
+const lineCountBufferSize = 64 * 1024
+
+// LineError is an error occurred on a row, holding the row's line number in the file.
+// Errors are wrapped into it only if [CsvReader.NumberLines] is enabled.
+type LineError struct {
+	// Line is the 1-based line number of the row in the file.
+	Line int64
+	// Err is the error occurred on the row.
+	Err error
+}
+
+// Error returns the error message.
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the error occurred on the row.
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// wrapLineErr wraps given error into a [*LineError], if line is known (not 0).
+func wrapLineErr(err error, line int64) error {
+	if line == 0 {
+		return err
+	}
+
+	return &LineError{Line: line, Err: err}
+}
+
+// countFirstLines returns for each goroutine the line number of the first line it handles,
+// by counting, concurrently, the lines preceding each goroutine's start offset.
+func (cr *CsvReader) countFirstLines(pin *pinnedFile, threadsInfo [][2]int) ([]int64, error) {
+	var f io.ReaderAt
+	if pin != nil {
+		f = pin.f
+	} else {
+		file, err := cr.FS.Open(cr.filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		f = file
+	}
+
+	// counts[i] holds the number of lines in the [threadsInfo[i-1][0], threadsInfo[i][0]) range.
+	var (
+		counts = make([]int64, len(threadsInfo))
+		errs   = make([]error, len(threadsInfo))
+		wg     sync.WaitGroup
+	)
+	wg.Add(len(threadsInfo))
+	for i := range threadsInfo {
+		go func(i int) {
+			defer wg.Done()
+			start := 0
+			if i > 0 {
+				start = threadsInfo[i-1][0]
+			}
+			counts[i], errs[i] = countByte(f, start, threadsInfo[i][0], '\n')
+		}(i)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	firstLines := make([]int64, len(threadsInfo))
+	var linesBefore int64
+	for i := range threadsInfo {
+		linesBefore += counts[i]
+		firstLines[i] = linesBefore + 1
+		if i > 0 {
+			// the goroutine skips the line containing its start offset.
+			firstLines[i]++
+		}
+	}
+
+	return firstLines, nil
+}
+
+// countByte returns the number of occurrences of given byte (like the end line delimiter) in the [start, end) range.
+func countByte(f io.ReaderAt, start, end int, c byte) (int64, error) {
+	var (
+		count int64
+		buf   = make([]byte, min(lineCountBufferSize, max(end-start, 0)))
+	)
+	for start < end {
+		n := min(len(buf), end-start)
+		read, err := f.ReadAt(buf[:n], int64(start))
+		count += int64(bytes.Count(buf[:read], []byte{c}))
+		if err != nil && (read < n || !errors.Is(err, io.EOF)) {
+			return 0, err
+		}
+		start += n
+	}
+
+	return count, nil
+}
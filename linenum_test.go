@@ -0,0 +1,105 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_NumberLines(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rows are numbered with their line in the file", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 3000
+		var sb strings.Builder
+		sb.WriteString("id,name\n")
+		for i := 1; i <= rowsCount; i++ {
+			sb.WriteString(strconv.Itoa(i) + ",name " + strconv.Itoa(i) + "\n")
+		}
+		filePath := filepath.Join(t.TempDir(), "lines.csv")
+		if err := os.WriteFile(filePath, []byte(sb.String()), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 6
+		subject.NumberLines = true
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		metaChans, errsChan := subject.ReadWithMeta(ctx)
+		var (
+			wg    sync.WaitGroup
+			count int64
+		)
+		wg.Add(len(metaChans))
+		for _, metaChan := range metaChans {
+			go func(metaChan bigcsvreader.MetaRowsChan) {
+				defer wg.Done()
+				for row := range metaChan {
+					// header is line 1, so the row with id N is on line N+1.
+					if strconv.FormatInt(row.Line-1, 10) != row.Fields[0] {
+						t.Errorf("row %s has line %d", row.Fields[0], row.Line)
+					}
+					if row.Line == rowsCount+1 {
+						count = row.Line
+					}
+				}
+			}(metaChan)
+		}
+		err := bigcsvreader.CollectErrors(errsChan)
+		wg.Wait()
+
+		// assert
+		assertNil(t, err)
+		assertTrue(t, len(metaChans) > 1)
+		assertEqual(t, int64(rowsCount+1), count)
+	})
+
+	t.Run("errors hold the line number", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/invalid_row.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.NumberLines = true
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+		var (
+			lineErr  *bigcsvreader.LineError
+			parseErr *csv.ParseError
+		)
+
+		// act
+		run := subject.Start(ctx)
+		drainRows(run.RowsChans())
+		err := run.Err()
+
+		// assert
+		assertTrue(t, errors.As(err, &lineErr))
+		assertTrue(t, errors.As(err, &parseErr))
+		assertEqual(t, int64(4), lineErr.Line) // header is line 1.
+	})
+}
@@ -0,0 +1,142 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ManifestOptions configures the manifest written after reading, see [CsvReader.Manifest].
+type ManifestOptions struct {
+	// Path is the path of the manifest file.
+	// Defaults to CSV file path suffixed with ".manifest.json".
+	Path string
+	// JobID identifies the job run which ingested the file.
+	JobID string
+	// Key, if set, is used to sign the manifest (HMAC-SHA256).
+	Key []byte
+}
+
+// Manifest describes exactly which bytes of a CSV file were ingested, by which job run.
+type Manifest struct {
+	// File is the CSV file path.
+	File string `json:"file"`
+	// JobID identifies the job run which ingested the file.
+	JobID string `json:"jobId,omitempty"`
+	// StartedAt is the time reading started.
+	StartedAt time.Time `json:"startedAt"`
+	// FinishedAt is the time reading finished.
+	FinishedAt time.Time `json:"finishedAt"`
+	// Chunks holds the chunks read by each goroutine, sorted by offset.
+	Chunks []ManifestChunk `json:"chunks"`
+	// Signature is the hex encoded HMAC-SHA256 of the manifest (with empty signature),
+	// if a key was configured.
+	Signature string `json:"signature,omitempty"`
+}
+
+// ManifestChunk describes a chunk of file read by a goroutine.
+type ManifestChunk struct {
+	// Thread is the number of the goroutine which read the chunk.
+	Thread int `json:"thread"`
+	// OffsetStart is the offset of the first byte of the chunk.
+	OffsetStart int `json:"offsetStart"`
+	// OffsetEnd is the offset following the last byte of the chunk.
+	OffsetEnd int `json:"offsetEnd"`
+	// RowsCount is the number of rows emitted from the chunk.
+	RowsCount int64 `json:"rowsCount"`
+	// SHA256 is the hex encoded checksum of the chunk's bytes.
+	SHA256 string `json:"sha256"`
+}
+
+// Verify checks the manifest's signature against given key.
+func (m Manifest) Verify(key []byte) bool {
+	signature, err := hex.DecodeString(m.Signature)
+	if err != nil {
+		return false
+	}
+	expected, err := m.sign(key)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(signature, expected)
+}
+
+// sign returns the HMAC-SHA256 of the manifest, with empty signature.
+func (m Manifest) sign(key []byte) ([]byte, error) {
+	m.Signature = ""
+	content, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(content)
+
+	return mac.Sum(nil), nil
+}
+
+// addChunk records a chunk read by a goroutine.
+func (rn *Run) addChunk(chunk ManifestChunk) {
+	rn.chunksMu.Lock()
+	rn.chunks = append(rn.chunks, chunk)
+	rn.chunksMu.Unlock()
+}
+
+// writeManifest writes the manifest of the run, signed if a key is configured.
+// The manifest is written to a temporary file which is then renamed,
+// so a partially written manifest is never observed.
+func (cr *CsvReader) writeManifest(rn *Run) error {
+	manifest := Manifest{
+		File:       cr.filePath,
+		JobID:      cr.Manifest.JobID,
+		StartedAt:  rn.startedAt,
+		FinishedAt: cr.Clock.Now().UTC(),
+		Chunks:     rn.chunks,
+	}
+	sort.Slice(manifest.Chunks, func(i, j int) bool {
+		return manifest.Chunks[i].OffsetStart < manifest.Chunks[j].OffsetStart
+	})
+	if len(cr.Manifest.Key) > 0 {
+		signature, err := manifest.sign(cr.Manifest.Key)
+		if err != nil {
+			return err
+		}
+		manifest.Signature = hex.EncodeToString(signature)
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	manifestPath := cr.Manifest.Path
+	if manifestPath == "" {
+		manifestPath = cr.filePath + ".manifest.json"
+	}
+	f, err := os.CreateTemp(filepath.Dir(manifestPath), filepath.Base(manifestPath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := f.Name()
+	_, err = f.Write(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpName, manifestPath)
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+	}
+
+	return err
+}
@@ -0,0 +1,82 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_Manifest(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	const rowsCount = 2000
+	filePath, err := setUpTmpCsvFile(rowsCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tearDownTmpCsvFile(filePath)
+	fileContent, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+	key := []byte("secret")
+	subject := bigcsvreader.New()
+	subject.SetFilePath(filePath)
+	subject.ColumnsCount = 5
+	subject.MaxGoroutinesNo = 4
+	subject.Manifest = &bigcsvreader.ManifestOptions{
+		Path:  manifestPath,
+		JobID: "job-1",
+		Key:   key,
+	}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	run := subject.Start(ctx)
+	drainRows(run.RowsChans())
+	err = run.Err()
+
+	// assert
+	assertNil(t, err)
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest bigcsvreader.Manifest
+	assertNil(t, json.Unmarshal(content, &manifest))
+	assertEqual(t, "job-1", manifest.JobID)
+	assertTrue(t, manifest.Verify(key))
+	assertTrue(t, !manifest.Verify([]byte("other secret")))
+	assertEqual(t, 4, len(manifest.Chunks))
+	var (
+		offset    int
+		rowsTotal int64
+	)
+	for _, chunk := range manifest.Chunks {
+		assertEqual(t, offset, chunk.OffsetStart)
+		checksum := sha256.Sum256(fileContent[chunk.OffsetStart:chunk.OffsetEnd])
+		assertEqual(t, hex.EncodeToString(checksum[:]), chunk.SHA256)
+		offset = chunk.OffsetEnd
+		rowsTotal += chunk.RowsCount
+	}
+	assertEqual(t, len(fileContent), offset)
+	assertEqual(t, int64(rowsCount), rowsTotal)
+
+	manifest.Chunks[0].RowsCount++ // tamper
+	assertTrue(t, !manifest.Verify(key))
+}
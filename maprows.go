@@ -0,0 +1,59 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"fmt"
+	"sync"
+)
+
+// MapRows pipes the rows read by a [CsvReader] through given decode function,
+// which usually converts a row into a domain struct.
+// It returns a channel of decoded values for each rows channel, and an errors channel
+// which carries both the errors received through errsChan and the decoding errors.
+// Rows which could not be decoded are disregarded.
+func MapRows[T any](
+	rowsChans []RowsChan,
+	errsChan ErrsChan,
+	decode func(row []string) (T, error),
+) ([]<-chan T, ErrsChan) {
+	var (
+		outChans = make([]<-chan T, len(rowsChans))
+		outErrs  = make(chan error, defaultChanBufferSize)
+		wg       sync.WaitGroup
+	)
+
+	wg.Add(len(rowsChans) + 1)
+	for i := 0; i < len(rowsChans); i++ {
+		outChan := make(chan T, defaultChanBufferSize)
+		outChans[i] = outChan
+		go func(rowsChan RowsChan, outChan chan<- T) {
+			defer wg.Done()
+			defer close(outChan)
+			for row := range rowsChan {
+				value, err := decode(row)
+				if err != nil {
+					outErrs <- fmt.Errorf("bigcsvreader: could not decode row (%w)", err)
+
+					continue
+				}
+				outChan <- value
+			}
+		}(rowsChans[i], outChan)
+	}
+	go func() {
+		defer wg.Done()
+		for err := range errsChan {
+			outErrs <- err
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(outErrs)
+	}()
+
+	return outChans, outErrs
+}
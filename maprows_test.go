@@ -0,0 +1,76 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestMapRows(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	type person struct {
+		ID   int
+		Name string
+		Age  int
+	}
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/file_without_header.csv")
+	subject.ColumnsCount = 3
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+	decodeErr := errors.New("intentionally triggered decode error")
+	decode := func(row []string) (person, error) {
+		id, _ := strconv.Atoi(row[0])
+		if id == 3 {
+			return person{}, decodeErr
+		}
+		age, err := strconv.Atoi(row[2])
+
+		return person{ID: id, Name: row[1], Age: age}, err
+	}
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		persons = make(map[int]person)
+		errs    []error
+	)
+
+	// act
+	rowsChans, errsChan := subject.Read(ctx)
+	personsChans, errsChan := bigcsvreader.MapRows(rowsChans, errsChan, decode)
+	for i := range personsChans {
+		wg.Add(1)
+		go func(personsChan <-chan person) {
+			defer wg.Done()
+			for p := range personsChan {
+				mu.Lock()
+				persons[p.ID] = p
+				mu.Unlock()
+			}
+		}(personsChans[i])
+	}
+	for err := range errsChan {
+		errs = append(errs, err)
+	}
+	wg.Wait()
+
+	// assert
+	assertEqual(t, len(rowsChans), len(personsChans))
+	assertEqual(t, 4, len(persons))
+	assertEqual(t, person{ID: 4, Name: "Ronaldinho", Age: 23}, persons[4])
+	if assertEqual(t, 1, len(errs)) {
+		assertTrue(t, errors.Is(errs[0], decodeErr))
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+const (
+	defaultMemoryLimitThreshold = 0.9
+	memoryPollInterval          = 100 * time.Millisecond
+)
+
+// memoryThreshold returns the memory usage (in bytes) above which the reading is throttled,
+// or 0 if the reader is not memory limit aware / no soft memory limit is set.
+func (cr *CsvReader) memoryThreshold() int64 {
+	if !cr.MemoryLimitAware {
+		return 0
+	}
+	limit := internal.MemoryLimit()
+	if limit == 0 {
+		return 0
+	}
+	threshold := cr.MemoryLimitThreshold
+	if threshold <= 0 || threshold > 1 {
+		threshold = defaultMemoryLimitThreshold
+	}
+
+	return int64(float64(limit) * threshold)
+}
+
+// chanBufferSize returns the RowsChan buffer size to use.
+// If the reader is memory limit aware, the buffer is shrunk so that rows buffered
+// into channels (each estimated to be at most [CsvReader.BufferSize] long)
+// do not exceed half of the memory headroom left until the threshold is reached.
+func (cr *CsvReader) chanBufferSize(totalThreads int, memThreshold int64) int {
+	size := cr.ChanBufferSize
+	if size < 0 {
+		size = 0
+	}
+	if memThreshold == 0 || totalThreads == 0 {
+		return size
+	}
+	headroom := (memThreshold - internal.MemoryUsage()) / 2
+	maxSize := headroom / int64(totalThreads*cr.BufferSize)
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	if int64(size) > maxSize {
+		cr.Logger.Debug(
+			"msg", "shrinking channels buffer size due to memory limit",
+			"file", cr.fileBaseName, "chanBufferSize", size, "newChanBufferSize", maxSize,
+		)
+		size = int(maxSize)
+	}
+
+	return size
+}
+
+// monitorMemory periodically checks the memory usage until done is closed.
+func (cr *CsvReader) monitorMemory(rn *Run, done <-chan struct{}) {
+	ticks, stop := cr.Clock.NewTicker(memoryPollInterval)
+	defer stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticks:
+			cr.checkMemory(rn)
+		}
+	}
+}
+
+// checkMemory flags the run as being under memory pressure if memory usage exceeds the threshold,
+// or clears the flag otherwise.
+func (cr *CsvReader) checkMemory(rn *Run) {
+	usage := internal.MemoryUsage()
+	if usage >= rn.memThreshold {
+		if atomic.CompareAndSwapInt32(&rn.memPressure, 0, 1) {
+			cr.Logger.Debug(
+				"msg", "memory pressure, pausing reading",
+				"file", cr.fileBaseName, "usage", usage, "threshold", rn.memThreshold,
+			)
+		}
+	} else if atomic.CompareAndSwapInt32(&rn.memPressure, 1, 0) {
+		cr.Logger.Debug(
+			"msg", "memory pressure relieved, resuming reading",
+			"file", cr.fileBaseName, "usage", usage, "threshold", rn.memThreshold,
+		)
+	}
+}
+
+// waitMemory blocks while the run is under memory pressure.
+// It returns false if the context got done meanwhile.
+func (rn *Run) waitMemory(ctx context.Context) bool {
+	for atomic.LoadInt32(&rn.memPressure) == 1 {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-rn.clock.After(memoryPollInterval):
+		}
+	}
+
+	return true
+}
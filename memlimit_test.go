@@ -0,0 +1,63 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_MemoryLimitAware(t *testing.T) {
+	// Note: not parallel, as it alters the process wide soft memory limit.
+	initialLimit := debug.SetMemoryLimit(-1)
+	defer debug.SetMemoryLimit(initialLimit)
+
+	t.Run("memory usage under threshold", testCsvReaderMemoryLimitAwareUnderThreshold)
+	t.Run("memory usage above threshold", testCsvReaderMemoryLimitAwareAboveThreshold)
+}
+
+func testCsvReaderMemoryLimitAwareUnderThreshold(t *testing.T) {
+	// arrange
+	debug.SetMemoryLimit(1 << 40)
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/file_without_header.csv")
+	subject.ColumnsCount = 3
+	subject.MemoryLimitAware = true
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	rowsChans, errsChan := subject.Read(ctx)
+	records, err := gatherRecords(rowsChans, errsChan)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 5, len(records))
+}
+
+func testCsvReaderMemoryLimitAwareAboveThreshold(t *testing.T) {
+	// arrange
+	debug.SetMemoryLimit(1 << 20) // surely below current usage.
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/file_without_header.csv")
+	subject.ColumnsCount = 3
+	subject.MemoryLimitAware = true
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancelCtx()
+
+	// act
+	rowsChans, errsChan := subject.Read(ctx)
+	records, err := gatherRecords(rowsChans, errsChan)
+
+	// assert
+	assertTrue(t, errors.Is(err, context.DeadlineExceeded))
+	assertNil(t, records)
+}
@@ -0,0 +1,140 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// Merge concatenates the input CSV files into the out file, in inputs order.
+// Inputs are read with this reader's configuration (file path aside), each in parallel,
+// and rows are written in files' order, delimited by [CsvReader.ColumnsDelimiter].
+// If the files have a header, headers are reconciled: they must be identical, or,
+// if [CsvReader.MergeUnionHeaders] is enabled, the out file gets the union of the headers' columns
+// (in first seen order), and rows get empty values for the columns their file does not have.
+// On error, the out file is removed.
+func (cr *CsvReader) Merge(ctx context.Context, inputs []string, out string) error {
+	cr = cr.withContextFS(ctx)
+	readers := make([]*CsvReader, len(inputs))
+	for i, input := range inputs {
+		readers[i] = cr.clone()
+		readers[i].SetFilePath(input)
+	}
+	var (
+		header  []string
+		indexes = make([][]int, len(inputs))
+	)
+	if cr.FileHasHeader {
+		headers := make([][]string, len(inputs))
+		for i := range readers {
+			var err error
+			if headers[i], err = readers[i].ReadHeader(ctx); err != nil {
+				return fmt.Errorf("bigcsvreader: could not read header of %s (%w)", inputs[i], err)
+			}
+			if cr.MergeUnionHeaders && cr.ColumnsCount > 0 {
+				readers[i].ColumnsCount = len(headers[i]) // each input has its own columns.
+			}
+		}
+		var err error
+		if header, indexes, err = cr.reconcileHeaders(inputs, headers); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("bigcsvreader: could not create merge file (%w)", err)
+	}
+	bw := bufio.NewWriter(f)
+	err = writeHeader(bw, header, cr.ColumnsDelimiter, cr.OutputQuotePolicy)
+	for i := 0; i < len(readers) && err == nil; i++ {
+		transform := func(row []string) ([]string, error) { return row, nil }
+		if indexes[i] != nil {
+			toHeader := indexes[i]
+			transform = func(row []string) ([]string, error) {
+				return project(row, toHeader), nil
+			}
+		}
+		if err = readers[i].writeOrdered(ctx, bw, nil, cr.ColumnsDelimiter, transform); err != nil {
+			err = fmt.Errorf("bigcsvreader: could not merge %s (%w)", inputs[i], err)
+		}
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(out)
+		cr.Logger.Error("msg", "could not merge files", "err", err, "out", out)
+
+		return err
+	}
+
+	return nil
+}
+
+// reconcileHeaders returns the merged header, and, for each input whose header differs from it,
+// the indexes of the merged header's columns in the input's header (-1 for missing columns).
+func (cr *CsvReader) reconcileHeaders(inputs []string, headers [][]string) ([]string, [][]int, error) {
+	var merged []string
+	for i, header := range headers {
+		if i > 0 && !cr.MergeUnionHeaders && !slices.Equal(header, headers[0]) {
+			return nil, nil, fmt.Errorf(
+				"bigcsvreader: header of %s %q differs from header of %s %q",
+				inputs[i], header, inputs[0], headers[0],
+			)
+		}
+		for _, column := range header {
+			if !slices.Contains(merged, column) {
+				merged = append(merged, column)
+			}
+		}
+	}
+
+	indexes := make([][]int, len(headers))
+	for i, header := range headers {
+		if slices.Equal(header, merged) {
+			continue
+		}
+		indexes[i] = make([]int, len(merged))
+		for j, column := range merged {
+			indexes[i][j] = slices.Index(header, column)
+		}
+	}
+
+	return merged, indexes, nil
+}
+
+// writeHeader writes the header, if not nil, into w, quoted according to given policy.
+func writeHeader(w io.Writer, header []string, delimiter rune, policy QuotePolicy) error {
+	if header == nil {
+		return nil
+	}
+	csvWriter := newRecordWriter(w, delimiter, policy)
+	_ = csvWriter.Write(header)
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return fmt.Errorf("bigcsvreader: could not write header (%w)", err)
+	}
+
+	return nil
+}
+
+// clone returns a copy of the reader, sharing its logger, cache and callbacks.
+// The copy does not write a manifest.
+func (cr *CsvReader) clone() *CsvReader {
+	clone := *cr
+	clone.Manifest = nil
+
+	return &clone
+}
@@ -0,0 +1,115 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_Merge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("identical headers", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		tmpDir := t.TempDir()
+		var expected strings.Builder
+		expected.WriteString("id,name\n")
+		inputs := make([]string, 3)
+		for i := range inputs {
+			var input strings.Builder
+			input.WriteString("id,name\n")
+			for j := 1; j <= 2000; j++ {
+				fmt.Fprintf(&input, "%d,Name_%d_%d\n", j, i, j)
+				fmt.Fprintf(&expected, "%d,Name_%d_%d\n", j, i, j)
+			}
+			inputs[i] = writeTmpFile(t, tmpDir, fmt.Sprintf("input-%d.csv", i), input.String())
+		}
+		out := filepath.Join(tmpDir, "merged.csv")
+		subject := bigcsvreader.New()
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 4
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		err := subject.Merge(ctx, inputs, out)
+
+		// assert
+		assertNil(t, err)
+		content, err := os.ReadFile(out)
+		assertNil(t, err)
+		assertEqual(t, expected.String(), string(content))
+	})
+
+	t.Run("different headers are rejected", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		tmpDir := t.TempDir()
+		inputs := []string{
+			writeTmpFile(t, tmpDir, "a.csv", "id,name\n1,John\n"),
+			writeTmpFile(t, tmpDir, "b.csv", "id,age\n2,30\n"),
+		}
+		out := filepath.Join(tmpDir, "merged.csv")
+		subject := bigcsvreader.New()
+		subject.FileHasHeader = true
+
+		// act
+		err := subject.Merge(context.Background(), inputs, out)
+
+		// assert
+		assertNotNil(t, err)
+		_, statErr := os.Stat(out)
+		assertTrue(t, os.IsNotExist(statErr))
+	})
+
+	t.Run("union of headers", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		tmpDir := t.TempDir()
+		inputs := []string{
+			writeTmpFile(t, tmpDir, "a.csv", "id,name\n1,John\n2,Jane\n"),
+			writeTmpFile(t, tmpDir, "b.csv", "age,id,city\n30,3,Paris\n"),
+		}
+		out := filepath.Join(tmpDir, "merged.csv")
+		subject := bigcsvreader.New()
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+		subject.MergeUnionHeaders = true
+
+		// act
+		err := subject.Merge(context.Background(), inputs, out)
+
+		// assert
+		assertNil(t, err)
+		content, err := os.ReadFile(out)
+		assertNil(t, err)
+		assertEqual(t, "id,name,age,city\n1,John,,\n2,Jane,,\n3,,30,Paris\n", string(content))
+	})
+}
+
+// writeTmpFile writes the content into the named file from given directory, and returns its path.
+func writeTmpFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	filePath := filepath.Join(dir, name)
+	if err := os.WriteFile(filePath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return filePath
+}
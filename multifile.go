@@ -0,0 +1,184 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+// FileSource is a file read by [CsvReader.ReadFiles].
+type FileSource struct {
+	// Path is the file's path.
+	Path string
+	// Gzip is a flag indicating the file is gzip compressed.
+	// As a compressed file can't be read from arbitrary offsets, it is decompressed first
+	// into a temporary file (removed after reading), which is then read like any other file.
+	// Rows offsets are offsets in the decompressed data.
+	Gzip bool
+	// Configure can be set to override, for this file, the reader's configuration
+	// (like delimiter, header, columns count...). It is called with a copy of the reader.
+	Configure func(cr *CsvReader)
+}
+
+// SourcedRowsChan is the channel where rows read from multiple files will be pushed into.
+type SourcedRowsChan <-chan SourcedRow
+
+// SourcedRow holds a CSV row, its position metadata, and the file it was read from.
+type SourcedRow struct {
+	Row
+	// Source is the 0-based index of the file, among the sources.
+	Source int
+	// File is the path of the file.
+	File string
+}
+
+// FileError is the error sent through ErrsChan by [CsvReader.ReadFiles], holding the file an error occurred for.
+type FileError struct {
+	// File is the path of the file.
+	File string
+	// Err is the error occurred.
+	Err error
+}
+
+// Error returns the error's message.
+func (e *FileError) Error() string {
+	return fmt.Sprintf("file %s: %v", e.File, e.Err)
+}
+
+// Unwrap returns the error occurred.
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// ReadFiles reads the given files, each in parallel, like [CsvReader.ReadWithMeta] does, and pushes their rows,
+// round-robin (one row from each file in turn, in sources order), into a single stream.
+// Rows of a file keep their file order. Each row carries the file it was read from, and
+// errors are wrapped into a [*FileError].
+// Files are read with this reader's configuration (file path aside), eventually overridden per file,
+// see [FileSource.Configure].
+func (cr *CsvReader) ReadFiles(ctx context.Context, sources []FileSource) (SourcedRowsChan, ErrsChan) {
+	var (
+		rowsChan  = make(chan SourcedRow, cr.chanBufferSize(0, 0))
+		errsChan  = make(chan error, cr.chanBufferSize(0, 0))
+		streams   = make([]chan Row, len(sources))
+		forwardWg sync.WaitGroup
+	)
+	forwardWg.Add(len(sources))
+	cr.inflight.add()
+	for i := range sources {
+		streams[i] = make(chan Row, cr.chanBufferSize(0, 0))
+		go cr.readSource(ctx, sources[i], streams[i], errsChan, &forwardWg)
+	}
+
+	go func() {
+		defer func() {
+			close(rowsChan)
+			forwardWg.Wait()
+			close(errsChan)
+			cr.inflight.done()
+		}()
+		active := make([]int, len(sources))
+		for i := range active {
+			active[i] = i
+		}
+		for len(active) > 0 {
+			for i := 0; i < len(active); {
+				source := active[i]
+				row, ok := <-streams[source]
+				if !ok { // file is exhausted.
+					active = append(active[:i], active[i+1:]...)
+
+					continue
+				}
+				rowsChan <- SourcedRow{Row: row, Source: source, File: sources[source].Path}
+				i++
+			}
+		}
+	}()
+
+	return rowsChan, errsChan
+}
+
+// readSource reads the source, pushing its rows, in file order, into stream, and its errors, wrapped, into errsChan.
+func (cr *CsvReader) readSource(
+	ctx context.Context,
+	source FileSource,
+	stream chan<- Row,
+	errsChan chan<- error,
+	wg *sync.WaitGroup,
+) {
+	defer wg.Done()
+	defer close(stream)
+
+	reader := cr.clone()
+	reader.SetFilePath(source.Path)
+	if source.Configure != nil {
+		source.Configure(reader)
+	}
+	if source.Gzip {
+		tmpPath, err := reader.decompress(source.Path)
+		if err != nil {
+			errsChan <- &FileError{File: source.Path, Err: fmt.Errorf("bigcsvreader: could not decompress file (%w)", err)}
+			cr.Logger.Error("msg", "could not decompress file", "err", err, "file", source.Path)
+
+			return
+		}
+		defer os.Remove(tmpPath)
+		reader.FS = internal.OSFS{}
+		reader.filePath = tmpPath // keep the base name of the source in logs.
+	}
+
+	rn := reader.start(ctx, modeMeta, nil)
+	var errsWg sync.WaitGroup
+	errsWg.Add(1)
+	go func() {
+		defer errsWg.Done()
+		for err := range rn.ErrsChan() {
+			errsChan <- &FileError{File: source.Path, Err: err}
+		}
+	}()
+	for _, metaChan := range rn.MetaRowsChans() { // chunks are consumed in file order.
+		for row := range metaChan {
+			stream <- row
+		}
+	}
+	errsWg.Wait()
+}
+
+// decompress decompresses the gzip file at given path into a temporary file, and returns its path.
+func (cr *CsvReader) decompress(path string) (string, error) {
+	f, err := cr.FS.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp("", "bigcsvreader-*.csv")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(tmp, gz)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return "", err
+	}
+
+	return tmp.Name(), nil
+}
@@ -0,0 +1,99 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ReadFiles(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rows are read round-robin, with provenance", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		dir := t.TempDir()
+		first := writeTmpFile(t, dir, "first.csv", "id,name\n1,a\n2,b\n3,c\n")
+		second := writeTmpFile(t, dir, "second.csv", "id,name\n10,x\n")
+		var gz bytes.Buffer
+		gzWriter := gzip.NewWriter(&gz)
+		_, _ = gzWriter.Write([]byte("id;name\n20;y\n21;z\n"))
+		_ = gzWriter.Close()
+		third := writeTmpFile(t, dir, "third.csv.gz", gz.String())
+		subject := bigcsvreader.New()
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rowsChan, errsChan := subject.ReadFiles(ctx, []bigcsvreader.FileSource{
+			{Path: first},
+			{Path: second},
+			{Path: third, Gzip: true, Configure: func(cr *bigcsvreader.CsvReader) {
+				cr.ColumnsDelimiter = ';'
+			}},
+		})
+		var (
+			got   []string
+			files []int
+		)
+		for row := range rowsChan {
+			got = append(got, strings.Join(row.Fields, ","))
+			files = append(files, row.Source)
+			if row.File == third {
+				assertTrue(t, row.Offset > 0)
+			}
+		}
+
+		// assert
+		assertNil(t, bigcsvreader.CollectErrors(errsChan))
+		assertEqual(t, []string{"1,a", "10,x", "20,y", "2,b", "21,z", "3,c"}, got)
+		assertEqual(t, []int{0, 1, 2, 0, 2, 0}, files)
+	})
+
+	t.Run("errors hold the file they occurred for", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		dir := t.TempDir()
+		valid := writeTmpFile(t, dir, "valid.csv", "1,a\n2,b\n")
+		invalid := writeTmpFile(t, dir, "invalid.csv", "3,c\n4\n")
+		subject := bigcsvreader.New()
+		subject.ColumnsCount = 2
+
+		// act
+		rowsChan, errsChan := subject.ReadFiles(context.Background(), []bigcsvreader.FileSource{
+			{Path: valid},
+			{Path: invalid},
+			{Path: invalid + ".gz", Gzip: true},
+		})
+		go func() {
+			for range rowsChan {
+			}
+		}()
+		var fileErrs []string
+		for err := range errsChan {
+			var fileErr *bigcsvreader.FileError
+			if assertTrue(t, errors.As(err, &fileErr)) {
+				fileErrs = append(fileErrs, fileErr.File)
+			}
+		}
+
+		// assert
+		assertEqual(t, 2, len(fileErrs))
+		assertTrue(t, strings.Contains(strings.Join(fileErrs, " "), invalid+".gz"))
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+)
+
+// endsInsideQuotedField checks whether given lines end inside a quoted field, given whether they start
+// inside one, by counting their quotes (escaped quotes are doubled, so they do not change the parity).
+func endsInsideQuotedField(lines []byte, inQuotes bool) bool {
+	return (bytes.Count(lines, []byte{'"'})%2 == 1) != inQuotes
+}
+
+// scanQuotesParity returns for each goroutine whether its start offset lies inside a quoted field,
+// see [CsvReader.MultilineQuotedFields] and [CsvReader.CRLFInQuotedFields], by counting, concurrently, the quotes preceding each goroutine's
+// start offset (the header, if any, is expected to hold balanced quotes).
+func (cr *CsvReader) scanQuotesParity(pin *pinnedFile, threadsInfo [][2]int) ([]bool, error) {
+	var f io.ReaderAt
+	if pin != nil {
+		f = pin.f
+	} else {
+		file, err := cr.FS.Open(cr.filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		f = file
+	}
+
+	// counts[i] holds the number of quotes in the [threadsInfo[i-1][0], threadsInfo[i][0]) range.
+	var (
+		counts = make([]int64, len(threadsInfo))
+		errs   = make([]error, len(threadsInfo))
+		wg     sync.WaitGroup
+	)
+	wg.Add(len(threadsInfo) - 1)
+	for i := 1; i < len(threadsInfo); i++ {
+		go func(i int) {
+			defer wg.Done()
+			counts[i], errs[i] = countByte(f, threadsInfo[i-1][0], threadsInfo[i][0], '"')
+		}(i)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	inQuotes := make([]bool, len(threadsInfo))
+	var quotesBefore int64
+	for i := range threadsInfo {
+		quotesBefore += counts[i]
+		inQuotes[i] = quotesBefore%2 == 1
+	}
+
+	return inQuotes, nil
+}
@@ -0,0 +1,96 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_MultilineQuotedFields(t *testing.T) {
+	t.Parallel()
+
+	// most of the file lies inside multi-line quoted fields, so goroutines' chunks start inside them.
+	const rowsCount = 300
+	var (
+		sb            strings.Builder
+		expectedNotes = make([]string, rowsCount)
+		expectedLines = make([]int64, rowsCount)
+		line          = int64(2)
+	)
+	sb.WriteString("id,note,flag\n")
+	for i := 0; i < rowsCount; i++ {
+		note := "note " + strconv.Itoa(i+1)
+		if i%2 == 0 {
+			lines := make([]string, 10)
+			for j := range lines {
+				lines[j] = "line " + strconv.Itoa(j+1) + ", with \"\"quotes\"\" and, commas"
+			}
+			note = strings.Join(lines, "\n")
+		}
+		expectedNotes[i] = strings.ReplaceAll(note, `""`, `"`)
+		expectedLines[i] = line
+		line += int64(strings.Count(note, "\n")) + 1
+		sb.WriteString(strconv.Itoa(i+1) + ",\"" + note + "\",y\n")
+	}
+	filePath := writeTmpFile(t, t.TempDir(), "multiline.csv", sb.String())
+
+	for _, goroutines := range [...]int{1, 4, 16} {
+		goroutines := goroutines // capture range variable
+		t.Run("goroutines "+strconv.Itoa(goroutines), func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(filePath)
+			subject.FileHasHeader = true
+			subject.ColumnsCount = 3
+			subject.MaxGoroutinesNo = goroutines
+			subject.MultilineQuotedFields = true
+			subject.NumberLines = true
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			rowsChans, errsChan := subject.ReadWithMeta(ctx)
+			var (
+				mu   sync.Mutex
+				wg   sync.WaitGroup
+				rows = make(map[string]bigcsvreader.Row, rowsCount)
+			)
+			for _, rowsChan := range rowsChans {
+				wg.Add(1)
+				go func(rowsChan bigcsvreader.MetaRowsChan) {
+					defer wg.Done()
+					for row := range rowsChan {
+						mu.Lock()
+						rows[row.Fields[0]] = row
+						mu.Unlock()
+					}
+				}(rowsChan)
+			}
+			err := bigcsvreader.CollectErrors(errsChan)
+			wg.Wait()
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, goroutines, len(rowsChans))
+			if assertEqual(t, rowsCount, len(rows)) {
+				for i := 0; i < rowsCount; i++ {
+					row := rows[strconv.Itoa(i+1)]
+					assertEqual(t, []string{strconv.Itoa(i + 1), expectedNotes[i], "y"}, row.Fields)
+					assertEqual(t, expectedLines[i], row.Line)
+				}
+			}
+		})
+	}
+}
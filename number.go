@@ -0,0 +1,209 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// NumberFormat describes how a locale formats numbers, like "1,234.56" (en) or "1.234,56" (de).
+// It can be used in decoding functions (see [MapRows]) to parse numbers from exports
+// which break naive [strconv.ParseFloat] based pipelines.
+type NumberFormat struct {
+	// DecimalSeparator separates the integer part from the fractional part.
+	DecimalSeparator rune
+	// ThousandsSeparators holds the accepted digits grouping separators.
+	ThousandsSeparators []rune
+	// CurrencySymbols holds currency symbols / codes which are allowed
+	// (and disregarded) as prefix or suffix of a number.
+	CurrencySymbols []string
+}
+
+// defaultCurrencySymbols holds common currency symbols and codes.
+var defaultCurrencySymbols = []string{
+	"$", "€", "£", "¥", "₹", "₽", "₺", "₩",
+	"USD", "EUR", "GBP", "CHF", "JPY", "RON", "PLN", "SEK", "NOK", "DKK",
+	"lei", "zł", "kr", "Fr.",
+}
+
+// numberFormats holds the number formats of known languages / regions.
+var numberFormats = map[string]NumberFormat{
+	"en": {DecimalSeparator: '.', ThousandsSeparators: []rune{','}},
+	"de": {DecimalSeparator: ',', ThousandsSeparators: []rune{'.'}},
+	"fr": {DecimalSeparator: ',', ThousandsSeparators: []rune{' ', '\u00a0', '\u202f'}},
+	"ch": {DecimalSeparator: '.', ThousandsSeparators: []rune{'\'', '\u2019'}},
+	"it": {DecimalSeparator: ',', ThousandsSeparators: []rune{'.'}},
+	"es": {DecimalSeparator: ',', ThousandsSeparators: []rune{'.'}},
+	"nl": {DecimalSeparator: ',', ThousandsSeparators: []rune{'.'}},
+	"pt": {DecimalSeparator: ',', ThousandsSeparators: []rune{'.', ' '}},
+	"ro": {DecimalSeparator: ',', ThousandsSeparators: []rune{'.'}},
+	"pl": {DecimalSeparator: ',', ThousandsSeparators: []rune{' ', '\u00a0'}},
+	"sv": {DecimalSeparator: ',', ThousandsSeparators: []rune{' ', '\u00a0'}},
+}
+
+// LocaleNumberFormat returns the number format for given locale tag, like "de", "de-DE", "fr_FR", "de-CH".
+// Region specific formats take precedence over language formats.
+// The returned format accepts common currency symbols.
+func LocaleNumberFormat(tag string) (NumberFormat, bool) {
+	tag = strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+	language, region, _ := strings.Cut(tag, "-")
+	nf, ok := numberFormats[region]
+	if !ok {
+		nf, ok = numberFormats[language]
+	}
+	if ok {
+		nf.CurrencySymbols = defaultCurrencySymbols
+	}
+
+	return nf, ok
+}
+
+// ParseFloat parses a number formatted according to the number format.
+// Negative numbers can be prefixed by "-" or enclosed in parentheses (accounting notation).
+// It returns a [*strconv.NumError] if value is not a valid number.
+func (nf NumberFormat) ParseFloat(value string) (float64, error) {
+	normalized, ok := nf.normalize(value)
+	if !ok {
+		return 0, &strconv.NumError{Func: "ParseFloat", Num: value, Err: strconv.ErrSyntax}
+	}
+	f, err := strconv.ParseFloat(normalized, 64)
+	if err != nil {
+		return 0, &strconv.NumError{Func: "ParseFloat", Num: value, Err: err.(*strconv.NumError).Err}
+	}
+
+	return f, nil
+}
+
+// ParseInt parses an integer formatted according to the number format.
+// It returns a [*strconv.NumError] if value is not a valid integer.
+func (nf NumberFormat) ParseInt(value string) (int64, error) {
+	normalized, ok := nf.normalize(value)
+	if !ok || strings.Contains(normalized, ".") {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: value, Err: strconv.ErrSyntax}
+	}
+	i, err := strconv.ParseInt(normalized, 10, 64)
+	if err != nil {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: value, Err: err.(*strconv.NumError).Err}
+	}
+
+	return i, nil
+}
+
+// normalize converts a locale formatted number into a [strconv] parsable one,
+// stripping currency symbols and validating digits grouping.
+func (nf NumberFormat) normalize(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	negative := false
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+		value = strings.TrimSpace(value[1 : len(value)-1])
+	}
+	value = nf.trimCurrency(value)
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		negative = negative != (value[0] == '-')
+		value = nf.trimCurrency(strings.TrimSpace(value[1:]))
+	}
+	if value == "" {
+		return "", false
+	}
+
+	decimalSep := nf.DecimalSeparator
+	if decimalSep == 0 {
+		decimalSep = '.'
+	}
+	intPart, fracPart, hasFrac := strings.Cut(value, string(decimalSep))
+	if intPart == "" || hasFrac && (fracPart == "" || !isDigits(fracPart)) {
+		return "", false
+	}
+	intPart, ok := nf.ungroup(intPart)
+	if !ok {
+		return "", false
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(intPart) + len(fracPart) + 2)
+	if negative {
+		sb.WriteByte('-')
+	}
+	sb.WriteString(intPart)
+	if hasFrac {
+		sb.WriteByte('.')
+		sb.WriteString(fracPart)
+	}
+
+	return sb.String(), true
+}
+
+// trimCurrency removes a currency symbol from the beginning or end of value.
+func (nf NumberFormat) trimCurrency(value string) string {
+	for _, symbol := range nf.CurrencySymbols {
+		if strings.HasPrefix(value, symbol) {
+			return strings.TrimSpace(value[len(symbol):])
+		}
+		if strings.HasSuffix(value, symbol) {
+			return strings.TrimSpace(value[:len(value)-len(symbol)])
+		}
+	}
+
+	return value
+}
+
+// ungroup removes thousands separators from the integer part, validating that
+// digits are grouped by 3 (except the first group, which can have 1 to 3 digits).
+func (nf NumberFormat) ungroup(intPart string) (string, bool) {
+	var (
+		sb        strings.Builder
+		groupLen  int
+		grouped   bool
+		firstDone bool
+	)
+	for i := 0; i < len(intPart); {
+		r, size := utf8.DecodeRuneInString(intPart[i:])
+		i += size
+		if r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+			groupLen++
+
+			continue
+		}
+		if !nf.isThousandsSeparator(r) {
+			return "", false
+		}
+		if groupLen == 0 || (!firstDone && groupLen > 3) || (firstDone && groupLen != 3) {
+			return "", false
+		}
+		grouped, firstDone, groupLen = true, true, 0
+	}
+	if grouped && groupLen != 3 {
+		return "", false
+	}
+
+	return sb.String(), sb.Len() > 0
+}
+
+// isThousandsSeparator checks if given rune is one of the thousands separators.
+func (nf NumberFormat) isThousandsSeparator(r rune) bool {
+	for _, sep := range nf.ThousandsSeparators {
+		if r == sep {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isDigits checks if given string contains only ASCII digits.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
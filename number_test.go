@@ -0,0 +1,100 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestNumberFormat_ParseFloat(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name           string
+		locale         string
+		input          string
+		expectedResult float64
+		expectedErr    error
+	}{
+		{name: "en simple", locale: "en", input: "1234.56", expectedResult: 1234.56},
+		{name: "en grouped", locale: "en-US", input: "1,234,567.5", expectedResult: 1234567.5},
+		{name: "en currency prefix", locale: "en", input: "$1,234.56", expectedResult: 1234.56},
+		{name: "en negative currency", locale: "en", input: "-$ 12.5", expectedResult: -12.5},
+		{name: "en accounting negative", locale: "en", input: "(1,000.25)", expectedResult: -1000.25},
+		{name: "de grouped", locale: "de-DE", input: "1.234,56", expectedResult: 1234.56},
+		{name: "de currency suffix", locale: "de_DE", input: "1.234,56 €", expectedResult: 1234.56},
+		{name: "de without fraction", locale: "de", input: "1.234", expectedResult: 1234},
+		{name: "fr nbsp grouping", locale: "fr-FR", input: "1\u00a0234,5 EUR", expectedResult: 1234.5},
+		{name: "de-CH apostrophe grouping", locale: "de-CH", input: "CHF 1'234.50", expectedResult: 1234.5},
+		{name: "wrong grouping", locale: "de", input: "12.34,5", expectedErr: strconv.ErrSyntax},
+		{name: "wrong decimal separator", locale: "de", input: "1,234.56", expectedErr: strconv.ErrSyntax},
+		{name: "empty", locale: "en", input: " ", expectedErr: strconv.ErrSyntax},
+		{name: "letters", locale: "en", input: "12a", expectedErr: strconv.ErrSyntax},
+		{name: "missing fraction", locale: "en", input: "12.", expectedErr: strconv.ErrSyntax},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject, ok := bigcsvreader.LocaleNumberFormat(test.locale)
+			if !ok {
+				t.Fatalf("prerequisite failed: unknown locale %q", test.locale)
+			}
+
+			// act
+			result, err := subject.ParseFloat(test.input)
+
+			// assert
+			assertEqual(t, test.expectedResult, result)
+			if test.expectedErr != nil {
+				var numErr *strconv.NumError
+				if assertTrue(t, errors.As(err, &numErr)) {
+					assertEqual(t, test.input, numErr.Num)
+				}
+				assertTrue(t, errors.Is(err, test.expectedErr))
+			} else {
+				assertNil(t, err)
+			}
+		})
+	}
+}
+
+func TestNumberFormat_ParseInt(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject, _ := bigcsvreader.LocaleNumberFormat("de")
+
+	// act
+	result1, err1 := subject.ParseInt("-1.234.567")
+	result2, err2 := subject.ParseInt("1.234,5")
+
+	// assert
+	assertNil(t, err1)
+	assertEqual(t, int64(-1234567), result1)
+	assertTrue(t, errors.Is(err2, strconv.ErrSyntax))
+	assertEqual(t, int64(0), result2)
+}
+
+func TestLocaleNumberFormat(t *testing.T) {
+	t.Parallel()
+
+	// act
+	_, ok1 := bigcsvreader.LocaleNumberFormat("xx-YY")
+	nf, ok2 := bigcsvreader.LocaleNumberFormat("it-IT")
+
+	// assert
+	assertTrue(t, !ok1)
+	assertTrue(t, ok2)
+	assertEqual(t, ',', nf.DecimalSeparator)
+}
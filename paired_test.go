@@ -0,0 +1,132 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ReadPaired(t *testing.T) {
+	t.Parallel()
+
+	for name, transport := range map[string]bigcsvreader.Transport{
+		"ring":    bigcsvreader.TransportRing,
+		"channel": bigcsvreader.TransportChannel,
+	} {
+		transport := transport
+		t.Run("all rows are consumed, in file order per thread, through "+name+" transport", func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			const rowsCount = 5000
+			filePath, err := setUpTmpCsvFile(rowsCount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer tearDownTmpCsvFile(filePath)
+			subject := bigcsvreader.New()
+			subject.SetFilePath(filePath)
+			subject.ColumnsCount = 5
+			subject.MaxGoroutinesNo = 4
+			subject.ChanBufferSize = 16
+			subject.Transport = transport
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+			var (
+				mu     sync.Mutex
+				lastID = make(map[int]int)
+				count  int
+			)
+
+			// act
+			err = subject.ReadPaired(ctx, func(thread int, row []string) error {
+				id, err := strconv.Atoi(row[0])
+				if err != nil {
+					return err
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if id <= lastID[thread] {
+					t.Errorf("thread #%d consumed row %d after row %d", thread, id, lastID[thread])
+				}
+				lastID[thread] = id
+				count++
+
+				return nil
+			})
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, rowsCount, count)
+			assertEqual(t, 4, len(lastID))
+		})
+	}
+
+	t.Run("consume errors are returned", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_without_header.csv")
+		subject.ColumnsCount = 3
+		errConsume := errors.New("intentionally triggered consume error")
+
+		// act
+		err := subject.ReadPaired(context.Background(), func(_ int, row []string) error {
+			if row[0] == "2" {
+				return errConsume
+			}
+
+			return nil
+		})
+
+		// assert
+		assertTrue(t, errors.Is(err, errConsume))
+	})
+}
+
+func benchmarkReadPaired(transport bigcsvreader.Transport) func(b *testing.B) {
+	return func(b *testing.B) {
+		const rowsCount = 200000
+		fName, err := setUpTmpCsvFile(rowsCount)
+		if err != nil {
+			b.Fatalf("prerequisite failed: could not generate CSV file: %v", err)
+		}
+		defer tearDownTmpCsvFile(fName)
+		subject := bigcsvreader.New()
+		subject.SetFilePath(fName)
+		subject.ColumnsCount = 5
+		subject.MaxGoroutinesNo = 8
+		subject.Transport = transport
+		ctx, cancelCtx := context.WithCancel(context.Background())
+		defer cancelCtx()
+		consume := func(int, []string) error { return nil }
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for n := 0; n < b.N; n++ {
+			if err := subject.ReadPaired(ctx, consume); err != nil {
+				b.Error(err)
+			}
+		}
+	}
+}
+
+func BenchmarkCsvReader_ReadPaired_ringTransport(b *testing.B) {
+	benchmarkReadPaired(bigcsvreader.TransportRing)(b)
+}
+
+func BenchmarkCsvReader_ReadPaired_channelTransport(b *testing.B) {
+	benchmarkReadPaired(bigcsvreader.TransportChannel)(b)
+}
@@ -0,0 +1,78 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+// ErrFileChanged is an error returned if the CSV file was replaced
+// (symlink swapped, file renamed over, etc.) while it was read.
+// It can occur only if [CsvReader.PinFile] is enabled.
+var ErrFileChanged = errors.New("csv file changed during read")
+
+// pinnedFile holds the CSV file target resolved and opened once at the beginning of a read.
+type pinnedFile struct {
+	// path is the resolved path (symlinks evaluated) of the file.
+	path string
+	// f is the file descriptor kept open during the whole read,
+	// so that the pinned inode cannot be reused.
+	f internal.File
+	// info is the fstat result of f.
+	info os.FileInfo
+}
+
+// pinFile resolves eventual symlinks of the file path, opens the target and fstat-s it.
+// Symlinks are evaluated only on the operating system's file system.
+func pinFile(fsys internal.FS, filePath string) (*pinnedFile, error) {
+	resolvedPath := filePath
+	if _, isOS := rawFS(fsys).(internal.OSFS); isOS {
+		var err error
+		if resolvedPath, err = filepath.EvalSymlinks(filePath); err != nil {
+			return nil, err
+		}
+	}
+	f, err := fsys.Open(resolvedPath)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+
+		return nil, err
+	}
+
+	return &pinnedFile{
+		path: resolvedPath,
+		f:    f,
+		info: info,
+	}, nil
+}
+
+// check verifies that given fd points to the pinned file (same device and inode, same size).
+func (pf *pinnedFile) check(f internal.File) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(compressedFileInfo(pf.info), compressedFileInfo(info)) || pf.info.Size() != info.Size() {
+		return ErrFileChanged
+	}
+
+	return nil
+}
+
+// close releases the pinned fd.
+func (pf *pinnedFile) close() {
+	if pf != nil {
+		_ = pf.f.Close()
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+)
+
+// preScanBufferSize is the size of the sequential reads performed by [CsvReader.PreScan].
+const preScanBufferSize = 1 << 20
+
+// errUnsupportedByPreScan is the error returned when pre-scanning a file whose delimiter, quote or escape char
+// is not a single byte.
+var errUnsupportedByPreScan = errors.New("pre-scan supports only single byte delimiter, quote and escape chars")
+
+// BoundaryMap holds the rows' boundaries found by [CsvReader.PreScan].
+type BoundaryMap struct {
+	// RowsCount is the number of rows in the file (header excluded).
+	RowsCount int64 `json:"rowsCount"`
+	// FileSize is the size of the scanned file, the map is disregarded by reads of a file having another size.
+	FileSize int64 `json:"fileSize"`
+	// Offsets holds the offset of every 1024th row (0th, 1024th, 2048th...).
+	Offsets []int64 `json:"offsets"`
+}
+
+// WriteFile writes the map, JSON encoded, into the index file at given path,
+// to be loaded by [CsvReader.LoadBoundaryMap], sparing the scan of the CSV file by later processes.
+func (bm *BoundaryMap) WriteFile(indexFilePath string) error {
+	content, err := json.Marshal(bm)
+	if err != nil {
+		return fmt.Errorf("bigcsvreader: could not encode boundary map (%w)", err)
+	}
+	if err := os.WriteFile(indexFilePath, content, 0o644); err != nil {
+		return fmt.Errorf("bigcsvreader: could not write boundary map (%w)", err)
+	}
+
+	return nil
+}
+
+// LoadBoundaryMap loads the map written by [BoundaryMap.WriteFile] from the index file at given path
+// into [CsvReader.BoundaryMap].
+func (cr *CsvReader) LoadBoundaryMap(indexFilePath string) error {
+	f, err := cr.FS.Open(indexFilePath)
+	if err != nil {
+		return fmt.Errorf("bigcsvreader: could not open boundary map (%w)", err)
+	}
+	defer f.Close()
+	var boundaryMap BoundaryMap
+	if err := json.NewDecoder(f).Decode(&boundaryMap); err != nil {
+		return fmt.Errorf("bigcsvreader: could not decode boundary map (%w)", err)
+	}
+	cr.BoundaryMap = &boundaryMap
+
+	return nil
+}
+
+// preScan states.
+const (
+	scanFieldStart = iota
+	scanUnquoted
+	scanQuoted
+	scanQuoteInQuoted
+	scanClosed
+)
+
+// PreScan validates the structure of the whole file (quoting and number of fields of each row)
+// and computes its rows' boundaries, without materializing fields, using large sequential reads
+// and a quote state machine only, so it takes a fraction of a full parse.
+// The first structural error found is returned, as a [*csv.ParseError].
+// The returned map can be set as [CsvReader.BoundaryMap], for subsequent reads
+// to split data between goroutines in chunks of the same number of rows.
+// Compressed files are scanned decompressed. UTF-16 encoded files are not supported.
+func (cr *CsvReader) PreScan(ctx context.Context) (*BoundaryMap, error) {
+	if cr.Encoding.byteOrder() != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not pre-scan file (%w)", errUnsupportedWithUTF16)
+	}
+	if cr.ColumnsDelimiter >= utf8.RuneSelf || cr.quoteChar() >= utf8.RuneSelf || cr.EscapeChar >= utf8.RuneSelf {
+		return nil, fmt.Errorf("bigcsvreader: could not pre-scan file (%w)", errUnsupportedByPreScan)
+	}
+	cr, _, err := cr.withDecompressingFS()
+	if err != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not open file (%w)", err)
+	}
+	fileSize, err := cr.getFileSize()
+	if err != nil {
+		return nil, fmt.Errorf("bigcsvreader: file size error (%w)", err)
+	}
+	f, err := cr.FS.Open(cr.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not open file (%w)", err)
+	}
+	defer f.Close()
+
+	scanner := preScanner{
+		delimiter:    byte(cr.ColumnsDelimiter),
+		quote:        byte(cr.quoteChar()),
+		escape:       byte(cr.EscapeChar),
+		lazyQuotes:   cr.LazyQuotes,
+		crlfInQuotes: cr.CRLFInQuotedFields,
+		lfInQuotes:   cr.MultilineQuotedFields,
+		columnsCount: cr.ColumnsCount,
+		lineNo:       1,
+		boundaryMap:  &BoundaryMap{FileSize: int64(fileSize)},
+	}
+	if cr.FileHasHeader {
+		_, headerSize, err := cr.readHeader(f)
+		if err != nil {
+			return nil, err
+		}
+		scanner.offset, scanner.lineStart, scanner.lineNo = int64(headerSize), int64(headerSize), 2
+		if _, err := f.Seek(int64(headerSize), io.SeekStart); err != nil {
+			return nil, fmt.Errorf("bigcsvreader: could not seek file (%w)", err)
+		}
+	}
+
+	buf := make([]byte, preScanBufferSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("bigcsvreader: received context error (%w)", err)
+		}
+		n, readErr := f.Read(buf)
+		if err := scanner.scan(buf[:n]); err != nil {
+			return nil, fmt.Errorf("bigcsvreader: invalid file structure (%w)", err)
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("bigcsvreader: could not read file at offset %d (%w)", scanner.offset, readErr)
+		}
+	}
+	if err := scanner.finish(); err != nil {
+		return nil, fmt.Errorf("bigcsvreader: invalid file structure (%w)", err)
+	}
+	cr.Logger.Debug(
+		"msg", "pre-scanned file",
+		"file", cr.fileBaseName, "rowsCount", scanner.boundaryMap.RowsCount,
+	)
+
+	return scanner.boundaryMap, nil
+}
+
+// preScanner is the quote state machine [CsvReader.PreScan] feeds the file's bytes into.
+type preScanner struct {
+	delimiter, quote, escape byte
+	lazyQuotes               bool
+	crlfInQuotes             bool
+	lfInQuotes               bool
+	columnsCount             int
+	state                    int
+	escaped                  bool
+	// prev is the previous byte scanned.
+	prev byte
+	// fields is the number of the current row's fields ended so far.
+	fields int
+	// offset is the offset of the next byte to scan.
+	offset int64
+	// rowStart is the offset of the current row, rowLineNo its line number.
+	rowStart  int64
+	rowLineNo int
+	// lineStart is the offset of the current line, lineNo its number.
+	lineStart int64
+	lineNo    int
+	// inRow is a flag indicating bytes of the current row were scanned.
+	inRow bool
+	// blank is a flag indicating the current row holds only line delimiters so far (blank rows are skipped).
+	blank       bool
+	boundaryMap *BoundaryMap
+}
+
+// scan feeds given bytes into the state machine.
+func (s *preScanner) scan(data []byte) error {
+	for _, c := range data {
+		if !s.inRow {
+			s.inRow, s.blank, s.rowStart, s.rowLineNo = true, true, s.offset, s.lineNo
+		}
+		if c != '\r' && c != '\n' {
+			s.blank = false
+		}
+		if err := s.step(c); err != nil {
+			return err
+		}
+		s.prev = c
+		s.offset++
+		if c == '\n' {
+			s.lineNo++
+			s.lineStart = s.offset
+		}
+	}
+
+	return nil
+}
+
+// step moves the state machine for given byte.
+func (s *preScanner) step(c byte) error {
+	if s.escaped {
+		s.escaped = false
+
+		return nil
+	}
+	if s.escape != 0 && c == s.escape && s.state != scanClosed && s.state != scanQuoteInQuoted {
+		s.escaped = true
+		if s.state == scanFieldStart {
+			s.state = scanUnquoted
+		}
+
+		return nil
+	}
+	switch s.state {
+	case scanFieldStart, scanUnquoted, scanClosed:
+		switch {
+		case c == s.delimiter:
+			s.fields++
+			s.state = scanFieldStart
+		case c == '\n':
+			return s.endRow()
+		case s.state == scanFieldStart && s.quote != 0 && c == s.quote:
+			s.state = scanQuoted
+		case s.state == scanClosed && c != '\r' && !s.lazyQuotes:
+			return s.parseErr(csv.ErrQuote)
+		case s.state == scanUnquoted && s.quote != 0 && c == s.quote && !s.lazyQuotes:
+			return s.parseErr(csv.ErrBareQuote)
+		case s.state != scanClosed || c != '\r':
+			s.state = scanUnquoted
+		}
+	case scanQuoted:
+		switch {
+		case c == s.quote:
+			s.state = scanQuoteInQuoted
+		case c == '\n' && s.lazyQuotes:
+			return s.endRow() // as goroutines read line by line.
+		case c == '\n' && !s.lfInQuotes && !(s.crlfInQuotes && s.prev == '\r'):
+			return s.parseErr(csv.ErrQuote)
+		}
+	case scanQuoteInQuoted:
+		switch {
+		case c == s.quote:
+			s.state = scanQuoted // doubled quote.
+		case c == s.delimiter:
+			s.fields++
+			s.state = scanFieldStart
+		case c == '\n':
+			return s.endRow()
+		case c == '\r':
+			s.state = scanClosed
+		case s.lazyQuotes:
+			s.state = scanQuoted
+		default:
+			return s.parseErr(csv.ErrQuote)
+		}
+	}
+
+	return nil
+}
+
+// endRow accounts the row ended at current offset.
+func (s *preScanner) endRow() error {
+	if s.blank {
+		s.state, s.fields, s.inRow = scanFieldStart, 0, false
+
+		return nil
+	}
+	if s.columnsCount > 0 && s.fields+1 != s.columnsCount {
+		return s.parseErr(csv.ErrFieldCount)
+	}
+	if s.boundaryMap.RowsCount%indexStride == 0 {
+		s.boundaryMap.Offsets = append(s.boundaryMap.Offsets, s.rowStart)
+	}
+	s.boundaryMap.RowsCount++
+	s.state, s.fields, s.inRow = scanFieldStart, 0, false
+
+	return nil
+}
+
+// finish accounts the last row, if the file does not end with a line break.
+func (s *preScanner) finish() error {
+	if !s.inRow {
+		return nil
+	}
+	if s.state == scanQuoted && !s.lazyQuotes {
+		return s.parseErr(csv.ErrQuote)
+	}
+
+	return s.endRow()
+}
+
+// parseErr returns given error, located at current offset.
+func (s *preScanner) parseErr(err error) error {
+	return &csv.ParseError{
+		StartLine: s.rowLineNo,
+		Line:      s.lineNo,
+		Column:    int(s.offset-s.lineStart) + 1,
+		Err:       err,
+	}
+}
+
+// distribute returns the [start, end] intervals (relative to dataStart) goroutines should handle,
+// each starting right before a row's offset, holding the same number of rows (give or take
+// the offsets' stride), or nil if the map does not describe the data.
+func (bm *BoundaryMap) distribute(totalThreads, dataStart, dataEnd int) [][2]int {
+	if totalThreads <= 0 || len(bm.Offsets) == 0 || bm.Offsets[0] != int64(dataStart) ||
+		bm.RowsCount <= int64(len(bm.Offsets)-1)*indexStride || bm.RowsCount > int64(len(bm.Offsets))*indexStride {
+		return nil
+	}
+	totalThreads = min(totalThreads, len(bm.Offsets))
+	distribution := make([][2]int, 0, totalThreads)
+	start, prev := 0, 0
+	for thread := 1; thread < totalThreads; thread++ {
+		// index of the offset closest to the row the goroutine should start with.
+		idx := int((int64(thread)*bm.RowsCount/int64(totalThreads) + indexStride/2) / indexStride)
+		if idx <= prev || idx >= len(bm.Offsets) {
+			continue
+		}
+		prev = idx
+		// the goroutine starts at the previous row's line break, which it skips.
+		next := int(bm.Offsets[idx]) - 1 - dataStart
+		distribution = append(distribution, [2]int{start, next - 1})
+		start = next
+	}
+	distribution = append(distribution, [2]int{start, dataEnd - dataStart - 1})
+
+	return distribution
+}
@@ -0,0 +1,210 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_PreScan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("balanced chunks", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 20000
+		filePath, err := setUpTmpCsvFile(rowsCount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tearDownTmpCsvFile(filePath)
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 5
+		subject.MaxGoroutinesNo = 4
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		boundaryMap, err := subject.PreScan(ctx)
+		if !assertNil(t, err) {
+			return
+		}
+		subject.BoundaryMap = boundaryMap
+		rowsChans, errsChan := subject.Read(ctx)
+		counts := countRowsPerChan(rowsChans)
+		err = bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, int64(rowsCount), boundaryMap.RowsCount)
+		assertEqual(t, 20, len(boundaryMap.Offsets))
+		assertEqual(t, int64(0), boundaryMap.Offsets[0])
+		assertEqual(t, []int{5120, 5120, 5120, 4640}, counts)
+	})
+
+	t.Run("header and stale map", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.ColumnsDelimiter = ';'
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		boundaryMap, err := subject.PreScan(ctx)
+		if !assertNil(t, err) {
+			return
+		}
+		boundaryMap.FileSize++ // as if the file changed.
+		subject.BoundaryMap = boundaryMap
+		records, err := gatherRecords(subject.Read(ctx))
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, int64(5), boundaryMap.RowsCount)
+		assertEqual(t, 5, len(records))
+	})
+
+	t.Run("variable rows lengths with index file", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 8192
+		var content strings.Builder
+		for i := 0; i < rowsCount; i++ {
+			if i < rowsCount/2 {
+				fmt.Fprintf(&content, "%d,%s\n", i, strings.Repeat("x", 200))
+			} else {
+				fmt.Fprintf(&content, "%d,x\n", i)
+			}
+		}
+		tmpDir := t.TempDir()
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, tmpDir, "variable.csv", content.String()))
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 2
+		indexFilePath := filepath.Join(tmpDir, "variable.csv.index.json")
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		artifacts, err := subject.Analyze(ctx)
+		if !assertNil(t, err) {
+			return
+		}
+		err = artifacts.BoundaryMap().WriteFile(indexFilePath)
+		if !assertNil(t, err) {
+			return
+		}
+		err = subject.LoadBoundaryMap(indexFilePath)
+		if !assertNil(t, err) {
+			return
+		}
+		rowsChans, errsChan := subject.Read(ctx)
+		counts := countRowsPerChan(rowsChans)
+		err = bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, []int{rowsCount / 2, rowsCount / 2}, counts)
+	})
+
+	tests := [...]struct {
+		name          string
+		content       string
+		expectedErr   error
+		expectedLine  int
+		expectedStart int
+	}{
+		{
+			name:          "unterminated quote",
+			content:       "id,name\n1,John\n2,\"Jane\n3,Jim\n",
+			expectedErr:   csv.ErrQuote,
+			expectedLine:  3,
+			expectedStart: 3,
+		},
+		{
+			name:          "fields count",
+			content:       "1,\"John, Jr\"\n2,Jane,Doe\n",
+			expectedErr:   csv.ErrFieldCount,
+			expectedLine:  2,
+			expectedStart: 2,
+		},
+		{
+			name:          "bare quote",
+			content:       "1,John\n2,Ja\"ne\n",
+			expectedErr:   csv.ErrBareQuote,
+			expectedLine:  2,
+			expectedStart: 2,
+		},
+		{
+			name:          "extraneous quote",
+			content:       "1,John\n2,\"Jane\"x\n",
+			expectedErr:   csv.ErrQuote,
+			expectedLine:  2,
+			expectedStart: 2,
+		},
+	}
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(writeTmpFile(t, t.TempDir(), "invalid.csv", test.content))
+			subject.ColumnsCount = 2
+			var parseErr *csv.ParseError
+
+			// act
+			boundaryMap, err := subject.PreScan(context.Background())
+
+			// assert
+			assertNil(t, boundaryMap)
+			assertTrue(t, errors.Is(err, test.expectedErr))
+			if assertTrue(t, errors.As(err, &parseErr)) {
+				assertEqual(t, test.expectedLine, parseErr.Line)
+				assertEqual(t, test.expectedStart, parseErr.StartLine)
+			}
+		})
+	}
+}
+
+// countRowsPerChan consumes concurrently all the rows channels and returns the number of rows of each channel.
+func countRowsPerChan(rowsChans []bigcsvreader.RowsChan) []int {
+	var (
+		wg     sync.WaitGroup
+		counts = make([]int, len(rowsChans))
+	)
+	for i := range rowsChans {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for range rowsChans[i] {
+				counts[i]++
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	return counts
+}
@@ -0,0 +1,218 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// QuarantineOptions configures the dumping of the raw bytes which were not emitted, see [CsvReader.Quarantine].
+type QuarantineOptions struct {
+	// Dir is the directory sidecar files are written into.
+	// Defaults to the CSV file's directory.
+	Dir string
+}
+
+// QuarantinedRange describes a byte range of the CSV file dumped into a quarantine sidecar file.
+type QuarantinedRange struct {
+	// Thread is the number of the goroutine which read the range.
+	Thread int
+	// Path is the path of the sidecar file the range was dumped into.
+	Path string
+	// OffsetStart is the offset of the first byte of the range.
+	OffsetStart int
+	// OffsetEnd is the offset following the last byte of the range.
+	OffsetEnd int
+	// Reason is the message of the error the range was quarantined for.
+	Reason string
+}
+
+// quarantineLog holds the ranges quarantined during a run.
+type quarantineLog struct {
+	dir    string
+	mu     sync.Mutex
+	ranges []QuarantinedRange
+}
+
+// quarantineFile is a goroutine's quarantine sidecar file, opened at the first quarantined range.
+type quarantineFile struct {
+	path string
+	f    *os.File
+	bw   *bufio.Writer
+}
+
+// Quarantined returns the ranges dumped into quarantine sidecar files so far, sorted by offset,
+// if [CsvReader.Quarantine] is set.
+func (rn *Run) Quarantined() []QuarantinedRange {
+	if rn.quarantine == nil {
+		return nil
+	}
+	rn.quarantine.mu.Lock()
+	ranges := append([]QuarantinedRange(nil), rn.quarantine.ranges...)
+	rn.quarantine.mu.Unlock()
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].OffsetStart < ranges[j].OffsetStart })
+
+	return ranges
+}
+
+// newQuarantineLog instantiates the quarantine log, if [CsvReader.Quarantine] is set.
+func (cr *CsvReader) newQuarantineLog() *quarantineLog {
+	if cr.Quarantine == nil {
+		return nil
+	}
+	dir := cr.Quarantine.Dir
+	if dir == "" {
+		dir = filepath.Dir(cr.filePath)
+	}
+
+	return &quarantineLog{dir: dir}
+}
+
+// quarantineRow dumps the raw bytes of the row read at given offset, which was not emitted because of err.
+func (cr *CsvReader) quarantineRow(rn *Run, lh *lineHandler, raw []byte, offset int, err error) {
+	if rn.quarantine == nil {
+		return
+	}
+	cr.quarantineRange(rn, lh, bytes.NewReader(raw), offset, err)
+}
+
+// quarantineRest dumps the raw bytes the goroutine did not handle, from given offset to the end of its chunk
+// (the end of the line spanning over offsetEnd+1), as it stopped reading because of err.
+// The bytes are read through a new fd, as the goroutine's one may be failing.
+func (cr *CsvReader) quarantineRest(rn *Run, lh *lineHandler, from, offsetEnd int, err error) {
+	if rn.quarantine == nil || from >= rn.dataEnd {
+		return
+	}
+	f := cr.openFile(rn, lh.thread)
+	if f == nil {
+		return
+	}
+	defer f.Close()
+	r := bufio.NewReaderSize(io.NewSectionReader(f, int64(from), int64(rn.dataEnd-from)), cr.BufferSize)
+	rest := io.MultiReader(
+		io.LimitReader(r, int64(max(offsetEnd+1-from, 0))),
+		&lineRestReader{r: r},
+	)
+	cr.quarantineRange(rn, lh, rest, from, err)
+}
+
+// quarantineRange dumps the bytes of the range starting at given offset, read from r,
+// as a gzip member of the goroutine's sidecar file, whose comment holds the range's offset ("offset N").
+func (cr *CsvReader) quarantineRange(rn *Run, lh *lineHandler, r io.Reader, offset int, reason error) {
+	err := lh.openQuarantine(rn.quarantine.dir, cr.fileBaseName)
+	var written int64
+	if err == nil {
+		gz := gzip.NewWriter(lh.quarantine.bw)
+		gz.Name = cr.fileBaseName
+		gz.Comment = "offset " + strconv.Itoa(offset)
+		written, err = io.Copy(gz, r)
+		err = errors.Join(err, gz.Close())
+	}
+	if err != nil {
+		rn.fail()
+		if rn.sendErr(fmt.Errorf(
+			"bigcsvreader: thread #%d could not quarantine range at offset %d (%w)",
+			lh.thread, offset, err,
+		)) {
+			cr.Logger.Error(
+				"msg", "could not quarantine range", "err", err,
+				"file", cr.fileBaseName, "thread", lh.thread,
+				"offset", offset,
+			)
+		}
+
+		return
+	}
+
+	rn.quarantine.mu.Lock()
+	rn.quarantine.ranges = append(rn.quarantine.ranges, QuarantinedRange{
+		Thread:      lh.thread,
+		Path:        lh.quarantine.path,
+		OffsetStart: offset,
+		OffsetEnd:   offset + int(written),
+		Reason:      reason.Error(),
+	})
+	rn.quarantine.mu.Unlock()
+}
+
+// closeQuarantine flushes and closes the goroutine's sidecar file, if any range was quarantined.
+func (cr *CsvReader) closeQuarantine(rn *Run, lh *lineHandler) {
+	if lh.quarantine == nil {
+		return
+	}
+	err := lh.quarantine.bw.Flush()
+	if closeErr := lh.quarantine.f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		rn.fail()
+		if rn.sendErr(fmt.Errorf("bigcsvreader: thread #%d could not write quarantine file (%w)", lh.thread, err)) {
+			cr.Logger.Error(
+				"msg", "could not write quarantine file", "err", err,
+				"file", cr.fileBaseName, "thread", lh.thread,
+			)
+		}
+	}
+}
+
+// openQuarantine creates the goroutine's sidecar file, named after the CSV file and the goroutine, if not yet.
+func (lh *lineHandler) openQuarantine(dir, fileBaseName string) error {
+	if lh.quarantine != nil {
+		return nil
+	}
+	path := filepath.Join(dir, fileBaseName+".chunk-"+strconv.Itoa(lh.thread)+".quarantine.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	lh.quarantine = &quarantineFile{path: path, f: f, bw: bufio.NewWriter(f)}
+
+	return nil
+}
+
+// lineRestReader reads the rest of the current line, including its end line delimiter.
+type lineRestReader struct {
+	r    *bufio.Reader
+	done bool
+}
+
+// Read reads up to len(p) bytes of the rest of the line into p.
+func (lr *lineRestReader) Read(p []byte) (int, error) {
+	if lr.done || len(p) == 0 {
+		return 0, io.EOF
+	}
+	var n int
+	for n < len(p) {
+		b, err := lr.r.ReadByte()
+		if err != nil {
+			lr.done = true
+			if n > 0 && errors.Is(err, io.EOF) {
+				return n, nil
+			}
+
+			return n, err
+		}
+		p[n] = b
+		n++
+		if b == '\n' {
+			lr.done = true
+
+			break
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,209 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+	"github.com/actforgood/bigcsvreader/chaos"
+)
+
+func TestCsvReader_Quarantine(t *testing.T) {
+	t.Parallel()
+
+	t.Run("bad rows are quarantined", testCsvReaderQuarantineBadRows)
+	t.Run("abandoned chunk is quarantined", testCsvReaderQuarantineAbandonedChunk)
+	t.Run("chunks are quarantined once bad rows rate is exceeded", testCsvReaderQuarantineErrorRate)
+}
+
+func testCsvReaderQuarantineBadRows(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var content strings.Builder
+	for i := 1; i <= 600; i++ {
+		switch {
+		case i%100 == 0:
+			fmt.Fprintf(&content, "%d,\"bad \"quote\",x\n", i) // parse error.
+		case i%150 == 0:
+			fmt.Fprintf(&content, "%d,name %d,INVALID\n", i, i) // validation error.
+		default:
+			fmt.Fprintf(&content, "%d,name %d,x\n", i, i)
+		}
+	}
+	dir := t.TempDir()
+	filePath := writeTmpFile(t, dir, "bad.csv", content.String())
+	subject := bigcsvreader.New()
+	subject.SetFilePath(filePath)
+	subject.ColumnsCount = 3
+	subject.MaxGoroutinesNo = 3
+	subject.ColumnsOptions = map[int]bigcsvreader.ColumnOptions{2: {AllowedValues: []string{"x"}}}
+	subject.Quarantine = &bigcsvreader.QuarantineOptions{}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	run := subject.Start(ctx)
+	rowsCount := drainRows(run.RowsChans())
+	err := run.Err()
+
+	// assert
+	assertNotNil(t, err)
+	assertEqual(t, 600-6-2, rowsCount)
+	ranges := run.Quarantined()
+	if assertEqual(t, 8, len(ranges)) {
+		assertEqual(t, "100", strings.SplitN(rawRange(t, filePath, ranges[0]), ",", 2)[0])
+		assertEqual(t, "150,name 150,INVALID\n", rawRange(t, filePath, ranges[1]))
+		assertTrue(t, strings.Contains(ranges[1].Reason, "INVALID"))
+		assertEqual(t, dir, ranges[0].Path[:len(dir)])
+	}
+	assertEqual(t, quarantinedRanges(t, ranges, filePath), ranges)
+}
+
+func testCsvReaderQuarantineAbandonedChunk(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	const rowsCount = 900
+	filePath := writeTmpFile(t, t.TempDir(), "abandoned.csv", numberedRows(rowsCount))
+	subject := bigcsvreader.New()
+	subject.SetFilePath(filePath)
+	subject.ColumnsCount = 3
+	subject.MaxGoroutinesNo = 3
+	// the first fault is injected in the data head sampled for its longest line.
+	subject.FS = chaos.New(nil, chaos.Fault{Kind: chaos.TransientError, Offset: 1000, Times: 2})
+	subject.Quarantine = &bigcsvreader.QuarantineOptions{Dir: t.TempDir()}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	run := subject.Start(ctx)
+	emitted := drainRows(run.RowsChans())
+	err := run.Err()
+
+	// assert
+	assertTrue(t, errors.Is(err, bigcsvreader.ErrChunkIncomplete))
+	ranges := run.Quarantined()
+	if assertEqual(t, 1, len(ranges)) {
+		assertEqual(t, 1, ranges[0].Thread)
+		assertEqual(t, rowsCount, emitted+strings.Count(rawRange(t, filePath, ranges[0]), "\n"))
+	}
+	assertEqual(t, quarantinedRanges(t, ranges, filePath), ranges)
+}
+
+func testCsvReaderQuarantineErrorRate(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	const rowsCount = 900
+	content := numberedRows(rowsCount)
+	content = strings.Replace(content, "50,\"name 50\",x\n", "50,\"name 50\n", 1) // bad row.
+	filePath := writeTmpFile(t, t.TempDir(), "rate.csv", content)
+	subject := bigcsvreader.New()
+	subject.SetFilePath(filePath)
+	subject.ColumnsCount = 3
+	subject.MaxGoroutinesNo = 1
+	subject.AbortIfErrorRateExceeds(0.01, 1)
+	subject.Quarantine = &bigcsvreader.QuarantineOptions{Dir: t.TempDir()}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	run := subject.Start(ctx)
+	emitted := drainRows(run.RowsChans())
+	err := run.Err()
+
+	// assert
+	assertTrue(t, errors.Is(err, bigcsvreader.ErrErrorRateExceeded))
+	ranges := run.Quarantined()
+	if assertEqual(t, 2, len(ranges)) {
+		assertEqual(t, "50,\"name 50\n", rawRange(t, filePath, ranges[0]))
+		assertTrue(t, strings.Contains(ranges[1].Reason, bigcsvreader.ErrErrorRateExceeded.Error()))
+		assertEqual(t, len(content), ranges[1].OffsetEnd)
+		quarantinedRows := strings.Count(rawRange(t, filePath, ranges[1]), "\n")
+		assertEqual(t, rowsCount, emitted+1+quarantinedRows)
+	}
+	assertEqual(t, quarantinedRanges(t, ranges, filePath), ranges)
+}
+
+// numberedRows returns the content of a CSV file having given rows count.
+func numberedRows(rowsCount int) string {
+	var content strings.Builder
+	for i := 1; i <= rowsCount; i++ {
+		fmt.Fprintf(&content, "%d,\"name %d\",x\n", i, i)
+	}
+
+	return content.String()
+}
+
+// rawRange returns the CSV file's bytes of given range.
+func rawRange(t *testing.T, filePath string, r bigcsvreader.QuarantinedRange) string {
+	t.Helper()
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(content[r.OffsetStart:r.OffsetEnd])
+}
+
+// quarantinedRanges decompresses, member by member, the sidecar files of given ranges, checks each member's
+// bytes are the CSV file's bytes at the offset stated by its comment, and returns the ranges read, sorted by offset.
+func quarantinedRanges(t *testing.T, ranges []bigcsvreader.QuarantinedRange, filePath string) []bigcsvreader.QuarantinedRange {
+	t.Helper()
+	paths := make(map[string]int)
+	for _, r := range ranges {
+		paths[r.Path] = r.Thread
+	}
+	var read []bigcsvreader.QuarantinedRange
+	for path, thread := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		br := bufio.NewReader(f)
+		gz, err := gzip.NewReader(br)
+		for err == nil {
+			gz.Multistream(false)
+			var data []byte
+			if data, err = io.ReadAll(gz); err != nil {
+				break
+			}
+			offset, _ := strconv.Atoi(strings.TrimPrefix(gz.Comment, "offset "))
+			r := bigcsvreader.QuarantinedRange{Thread: thread, Path: path, OffsetStart: offset, OffsetEnd: offset + len(data)}
+			assertEqual(t, rawRange(t, filePath, r), string(data))
+			for _, quarantined := range ranges {
+				if quarantined.OffsetStart == offset {
+					r.Reason = quarantined.Reason
+				}
+			}
+			read = append(read, r)
+			err = gz.Reset(br)
+		}
+		_ = f.Close()
+		if !errors.Is(err, io.EOF) {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i < len(read); i++ { // insertion sort, by offset.
+		for j := i; j > 0 && read[j].OffsetStart < read[j-1].OffsetStart; j-- {
+			read[j], read[j-1] = read[j-1], read[j]
+		}
+	}
+
+	return read
+}
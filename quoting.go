@@ -0,0 +1,103 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// QuotePolicy tells which fields are enclosed in quotes when rows are re-serialized,
+// see [CsvReader.OutputQuotePolicy].
+type QuotePolicy int
+
+const (
+	// QuoteMinimal encloses in quotes only the fields which need it: the ones containing
+	// the output delimiter, quotes, \r or \n, or starting with a space. It is the default.
+	QuoteMinimal QuotePolicy = iota
+	// QuoteAlways encloses in quotes all fields, empty ones included.
+	QuoteAlways
+)
+
+// errInvalidOutputDelimiter is the error returned when rows cannot be re-serialized with given delimiter.
+var errInvalidOutputDelimiter = errors.New("invalid output delimiter")
+
+// recordWriter writes CSV records, the [csv.Writer] way.
+type recordWriter interface {
+	Write(record []string) error
+	Flush()
+	Error() error
+}
+
+// newRecordWriter returns a writer of records into w, delimited by given delimiter,
+// quoted according to given policy. Quotes inside fields are doubled.
+func newRecordWriter(w io.Writer, delimiter rune, policy QuotePolicy) recordWriter {
+	if policy == QuoteAlways {
+		return &quotingWriter{w: bufio.NewWriter(w), comma: delimiter}
+	}
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Comma = delimiter
+
+	return csvWriter
+}
+
+// quotingWriter is the [QuoteAlways] record writer.
+type quotingWriter struct {
+	w     *bufio.Writer
+	comma rune
+	err   error
+}
+
+// Write writes a record, each field enclosed in quotes.
+func (qw *quotingWriter) Write(record []string) error {
+	if !isValidOutputDelimiter(qw.comma) {
+		return errInvalidOutputDelimiter
+	}
+	for i, field := range record {
+		if i > 0 {
+			if _, err := qw.w.WriteRune(qw.comma); err != nil {
+				return err
+			}
+		}
+		if err := qw.w.WriteByte('"'); err != nil {
+			return err
+		}
+		if _, err := qw.w.WriteString(strings.ReplaceAll(field, `"`, `""`)); err != nil {
+			return err
+		}
+		if err := qw.w.WriteByte('"'); err != nil {
+			return err
+		}
+	}
+
+	return qw.w.WriteByte('\n')
+}
+
+// Flush writes any buffered data to the underlying writer.
+// To check if an error occurred during flush, call Error.
+func (qw *quotingWriter) Flush() {
+	qw.err = qw.w.Flush()
+}
+
+// Error reports any error that has occurred during a previous Write or Flush.
+func (qw *quotingWriter) Error() error {
+	_, err := qw.w.Write(nil)
+	if err == nil {
+		err = qw.err
+	}
+
+	return err
+}
+
+// isValidOutputDelimiter checks whether given delimiter can separate quoted fields.
+func isValidOutputDelimiter(delimiter rune) bool {
+	return delimiter != 0 && delimiter != '"' && delimiter != '\r' && delimiter != '\n' &&
+		utf8.ValidRune(delimiter) && delimiter != utf8.RuneError
+}
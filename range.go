@@ -0,0 +1,219 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+// defaultRangeBlockSize is the default number of bytes requested by a ranged read, see [CsvReader.SetRangeReader].
+const defaultRangeBlockSize = 4 << 20
+
+// RangeReader reads byte ranges of an object, like an S3, GCS or Azure Blob Storage one
+// (through a ranged GET request), see [CsvReader.SetRangeReader].
+// It is called concurrently.
+type RangeReader interface {
+	// ReadRange reads len(p) bytes of the object, starting at given offset, into p, with [io.ReaderAt] semantics:
+	// if less than len(p) bytes are read, an error is returned ([io.EOF] at the end of the object).
+	// The context is the one of the run reading the object.
+	ReadRange(ctx context.Context, p []byte, offset int64) (int, error)
+	// Size returns the object's size.
+	Size(ctx context.Context) (int64, error)
+}
+
+// SetRangeReader sets the CSV data to read from, as an object read by ranges through rr,
+// instead of a file path.
+// Goroutines read their chunks by requesting blockSize bytes ranges (0 means 4 MiB), each block being
+// requested once by the goroutine reading it sequentially, and calls are made with the context of the run.
+// It replaces [CsvReader.FS], serving the object under the "source" path, see [CsvReader.SetFilePath]
+// for compressed data, detected by magic bytes.
+func (cr *CsvReader) SetRangeReader(rr RangeReader, blockSize int) {
+	if blockSize <= 0 {
+		blockSize = defaultRangeBlockSize
+	}
+	cr.FS = &rangeFS{rr: rr, blockSize: blockSize, ctx: context.Background()}
+	cr.SetFilePath(sourceName)
+}
+
+// rangeFS is the file system serving the object set by [CsvReader.SetRangeReader].
+type rangeFS struct {
+	rr        RangeReader
+	blockSize int
+	// ctx is the context the object is read with.
+	ctx context.Context
+	// size is the object's size, requested once.
+	size    int64
+	sizeErr error
+	once    sync.Once
+}
+
+// withContext returns a file system reading the object with given context.
+func (fsys *rangeFS) withContext(ctx context.Context) *rangeFS {
+	return &rangeFS{rr: fsys.rr, blockSize: fsys.blockSize, ctx: ctx}
+}
+
+// contextFS is a file system making its (remote) requests with a context, see [CsvReader.FS].
+type contextFS interface {
+	// WithContext returns a copy of the file system making requests with given context.
+	WithContext(ctx context.Context) internal.FS
+}
+
+// withContextFS returns a copy of the reader whose file system makes its (remote) requests with given context,
+// or the reader itself, if its file system makes none.
+func (cr *CsvReader) withContextFS(ctx context.Context) *CsvReader {
+	var fsys internal.FS
+	switch crFS := cr.FS.(type) {
+	case *rangeFS:
+		fsys = crFS.withContext(ctx)
+	case contextFS:
+		fsys = crFS.WithContext(ctx)
+	default:
+		return cr
+	}
+	reader := *cr
+	reader.FS = fsys
+
+	return &reader
+}
+
+// objectSize returns the object's size, requesting it the first time.
+func (fsys *rangeFS) objectSize(op, name string) (int64, error) {
+	if name != sourceName {
+		return 0, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	fsys.once.Do(func() {
+		fsys.size, fsys.sizeErr = fsys.rr.Size(fsys.ctx)
+	})
+	if fsys.sizeErr != nil {
+		return 0, &fs.PathError{Op: op, Path: name, Err: fsys.sizeErr}
+	}
+
+	return fsys.size, nil
+}
+
+// Open opens the object, the only file the file system has.
+func (fsys *rangeFS) Open(name string) (internal.File, error) {
+	size, err := fsys.objectSize("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rangeFile{fsys: fsys, size: size}, nil
+}
+
+// Stat returns the object's info.
+func (fsys *rangeFS) Stat(name string) (os.FileInfo, error) {
+	size, err := fsys.objectSize("stat", name)
+	if err != nil {
+		return nil, err
+	}
+
+	return sourceInfo{size: size}, nil
+}
+
+// rangeFile is the object opened for reading. It keeps the last block read,
+// so sequential reads request each block once.
+type rangeFile struct {
+	fsys       *rangeFS
+	size       int64
+	mu         sync.Mutex
+	offset     int64
+	block      []byte
+	blockStart int64
+}
+
+// Read reads up to len(p) bytes into p, from the current offset.
+func (f *rangeFile) Read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.offset >= f.size {
+		return 0, io.EOF
+	}
+	n, err := f.readAt(p, f.offset)
+	f.offset += int64(n)
+	if n > 0 && errors.Is(err, io.EOF) {
+		err = nil
+	}
+
+	return n, err
+}
+
+// ReadAt reads len(p) bytes into p starting at given offset.
+func (f *rangeFile) ReadAt(p []byte, offset int64) (int, error) {
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "read", Path: sourceName, Err: fs.ErrInvalid}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.readAt(p, offset)
+}
+
+// readAt reads len(p) bytes into p starting at given offset, requesting blocks not read yet.
+func (f *rangeFile) readAt(p []byte, offset int64) (int, error) {
+	var n int
+	for n < len(p) {
+		pos := offset + int64(n)
+		if pos >= f.size {
+			return n, io.EOF
+		}
+		if pos < f.blockStart || pos >= f.blockStart+int64(len(f.block)) {
+			blockSize := int64(f.fsys.blockSize)
+			blockStart := pos / blockSize * blockSize
+			block := make([]byte, min(blockSize, f.size-blockStart))
+			if read, err := f.fsys.rr.ReadRange(f.fsys.ctx, block, blockStart); read < len(block) {
+				if err == nil || errors.Is(err, io.EOF) {
+					err = io.ErrUnexpectedEOF // the object is shorter than its size.
+				}
+
+				return n, &fs.PathError{Op: "read", Path: sourceName, Err: err}
+			}
+			f.block, f.blockStart = block, blockStart
+		}
+		n += copy(p[n:], f.block[pos-f.blockStart:])
+	}
+
+	return n, nil
+}
+
+// Seek sets the offset for the next Read.
+func (f *rangeFile) Seek(offset int64, whence int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.size
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: sourceName, Err: fs.ErrInvalid}
+	}
+	f.offset = offset
+
+	return offset, nil
+}
+
+// Close releases the block kept.
+func (f *rangeFile) Close() error {
+	f.mu.Lock()
+	f.block = nil
+	f.mu.Unlock()
+
+	return nil
+}
+
+// Stat returns the object's info.
+func (f *rangeFile) Stat() (os.FileInfo, error) {
+	return sourceInfo{size: f.size}, nil
+}
@@ -0,0 +1,145 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_SetRangeReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("chunks are read by ranges", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 5000
+		var (
+			content  strings.Builder
+			expected = make([][]string, 0, rowsCount)
+		)
+		content.WriteString("id,value\n")
+		for i := 1; i <= rowsCount; i++ {
+			expected = append(expected, []string{strconv.Itoa(i), "value " + strconv.Itoa(i)})
+			content.WriteString(strconv.Itoa(i) + ",value " + strconv.Itoa(i) + "\n")
+		}
+		rr := &stringRangeReader{content: content.String(), ctxValue: "run"}
+		subject := bigcsvreader.New()
+		subject.SetRangeReader(rr, 1<<12)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 4
+		ctx, cancelCtx := context.WithTimeout(context.WithValue(context.Background(), rangeCtxKey{}, "run"), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		rows := gatherRowsInOrder(run.RowsChans())
+		err := run.Err()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, []string{"id", "value"}, run.Header())
+		assertEqual(t, expected, rows)
+		assertEqual(t, int64(0), atomic.LoadInt64(&rr.foreignCtxCalls))
+		// each block is requested about once, by the goroutine reading it.
+		assertTrue(t, atomic.LoadInt64(&rr.rangeCalls) < int64(2*len(rr.content)/(1<<12)+10))
+	})
+
+	t.Run("synchronous operations request ranges with the caller's context", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		rr := &stringRangeReader{content: "id,value\n1,a\n2,b\n", ctxValue: "caller"}
+		subject := bigcsvreader.New()
+		subject.SetRangeReader(rr, 8)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+		ctx, cancelCtx := context.WithTimeout(context.WithValue(context.Background(), rangeCtxKey{}, "caller"), 15*time.Second)
+		defer cancelCtx()
+		var exported bytes.Buffer
+
+		// act
+		header, headerErr := subject.ReadHeader(ctx)
+		artifacts, analyzeErr := subject.Analyze(ctx)
+		exportErr := subject.Export(ctx, &exported, []string{"value"}, ',')
+
+		// assert
+		assertNil(t, headerErr)
+		assertNil(t, analyzeErr)
+		assertNil(t, exportErr)
+		assertEqual(t, []string{"id", "value"}, header)
+		assertEqual(t, 2, artifacts.RowsCount)
+		assertEqual(t, "value\na\nb\n", exported.String())
+		assertEqual(t, int64(0), atomic.LoadInt64(&rr.foreignCtxCalls))
+	})
+
+	t.Run("size error", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		rr := &stringRangeReader{sizeErr: errors.New("access denied")}
+		subject := bigcsvreader.New()
+		subject.SetRangeReader(rr, 0)
+
+		// act
+		run := subject.Start(context.Background())
+		err := run.Err()
+
+		// assert
+		if assertNotNil(t, err) {
+			assertTrue(t, strings.Contains(err.Error(), "access denied"))
+		}
+	})
+}
+
+// rangeCtxKey is the key of the context value a stringRangeReader checks.
+type rangeCtxKey struct{}
+
+// stringRangeReader is a bigcsvreader.RangeReader over a string, counting its calls.
+type stringRangeReader struct {
+	content         string
+	sizeErr         error
+	ctxValue        string
+	rangeCalls      int64
+	foreignCtxCalls int64
+}
+
+func (rr *stringRangeReader) ReadRange(ctx context.Context, p []byte, offset int64) (int, error) {
+	atomic.AddInt64(&rr.rangeCalls, 1)
+	rr.checkCtx(ctx)
+	if offset >= int64(len(rr.content)) {
+		return 0, io.EOF
+	}
+	n := copy(p, rr.content[offset:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+func (rr *stringRangeReader) Size(ctx context.Context) (int64, error) {
+	rr.checkCtx(ctx)
+
+	return int64(len(rr.content)), rr.sizeErr
+}
+
+func (rr *stringRangeReader) checkCtx(ctx context.Context) {
+	if rr.ctxValue != "" && ctx.Value(rangeCtxKey{}) != rr.ctxValue {
+		atomic.AddInt64(&rr.foreignCtxCalls, 1)
+	}
+}
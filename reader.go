@@ -9,20 +9,29 @@ import (
 	"bufio"
 	"bytes"
 	"context"
-	"encoding/csv"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
+	"io/fs"
+	"math"
 	"os"
 	"path"
-	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strconv"
 	"sync"
+	"time"
 
+	"github.com/actforgood/bigcsvreader/expr"
 	"github.com/actforgood/bigcsvreader/internal"
 )
 
 const (
-	chanSize                   = 256
+	defaultChanBufferSize      = 256
 	minBytesToReadByAGoroutine = 2048
 )
 
@@ -30,11 +39,13 @@ const (
 var ErrEmptyFile = errors.New("empty csv file")
 
 // RowsChan is the channel where read rows will be pushed into.
-// Has a buffer of 256 entries.
+// Has a buffer of [CsvReader.ChanBufferSize] entries.
+// Channels are closed once all goroutines finished, except the ones of empty chunks,
+// closed right away, see [Run.EmptyChunks].
 type RowsChan <-chan []string
 
 // ErrsChan is the channel where error(s) will be pushed in case
-// an error occurs during file read. Has a buffer of 256 entries.
+// an error occurs during file read. Has a buffer of [CsvReader.ChanBufferSize] entries.
 // Some errors can be fatal, like file does not exist, some errors like
 // rows parsing may occur for each affected row.
 type ErrsChan <-chan error
@@ -42,26 +53,77 @@ type ErrsChan <-chan error
 // CsvReader reads async rows from a CSV file.
 // It does that by initializing multiple goroutines, each of them handling
 // a chunk of data from the file.
+// The configuration is snapshotted when a read starts, so changing the exported fields
+// does not affect the reads in flight, and a CsvReader can be used for repeated
+// or concurrent reads. Fields must not be changed concurrently with starting a read, though.
 type CsvReader struct {
 	// MaxGoroutinesNo is the maximum goroutines to start parsing the CSV file.
-	// Minimum required bytes to start a new goroutine is 2048 bytes.
-	// Defaults to [runtime.NumCPU].
+	// Minimum required bytes to start a new goroutine is 2048 bytes, and at least [CsvReader.BufferSize].
+	// A value lower than 1 is replaced by 1, logging a warning.
+	// Defaults to [runtime.NumCPU], capped by the CPU quota of the container (cgroup v1 / v2), if any.
 	MaxGoroutinesNo int
 	// FileHasHeader is a flag indicating if file's first row is the header (columns names).
 	// If so, the header line is disregarded and not returned as a row.
 	// Defaults to false.
 	FileHasHeader bool
+	// EmitHeader is a flag indicating that, if [CsvReader.FileHasHeader] is enabled, the header is emitted too,
+	// once, as the first row of the first goroutine's channel (the last one for [CsvReader.ReadReverse]),
+	// flagged by [Row.Header] for [CsvReader.ReadWithMeta].
+	// The header is not counted as a row, nor passed through columns options, filter, checks or limit.
+	// Not applied by [CsvReader.ReadLazy], [CsvReader.ReadDecoded], nor to a data range (see [CsvReader.ReadTimeRange]).
+	// Defaults to false.
+	EmitHeader bool
 	// ColumnsCount is the number of columns the CSV file has.
+	// If not set (0), each goroutine infers it from the first record of its chunk, and chunks are
+	// checked to agree on it, a [*FieldsCountMismatchError] being sent through ErrsChan for each divergent chunk.
 	ColumnsCount int
+	// DefaultValues are the values rows shorter than [CsvReader.ColumnsCount] are padded with, instead of being
+	// rejected, for producers omitting trailing empty fields: a missing field takes the value at its column's index
+	// (empty string if DefaultValues is shorter). Padded rows are counted, see [Run.PaddedRows].
+	// Rows longer than [CsvReader.ColumnsCount] are still rejected.
+	// Requires [CsvReader.ColumnsCount] to be set, not applied by [CsvReader.ReadLazy].
+	// Defaults to nil (short rows are rejected).
+	DefaultValues []string
 	// ColumnsDelimiter is the delimiter char between columns. Defaults to comma.
 	ColumnsDelimiter rune
 	// BufferSize is used internally for [bufio.Reader] size. Has a default value of 4096.
-	// If you have lines bigger than this value, adjust it not to get "buffer full" error.
+	// If you have lines bigger than this value, adjust it not to get "buffer full" error,
+	// or enable [CsvReader.AutoGrowBuffer].
+	// Unless [CsvReader.AutoGrowBuffer] is enabled, it is grown (logging a warning) to fit the longest line
+	// of the first 64 KiB of data, and a value lower than 16 is replaced (the default one for a value lower than 1).
 	BufferSize int
+	// AutoGrowBuffer is a flag indicating that lines (and records, see [CsvReader.CRLFInQuotedFields])
+	// bigger than [CsvReader.BufferSize] are read anyway, by growing a buffer for them, instead of
+	// sending an error wrapping [ErrBufferFull] (or [ErrRecordTooLong]) and stopping the goroutine.
+	// The growth is capped by [CsvReader.MaxLineSize], if set.
+	// Not applied by [CsvReader.ReadReverse], nor with UTF-16 [CsvReader.Encoding].
+	// Defaults to false.
+	AutoGrowBuffer bool
+	// MaxLineSize is the safety cap on the size of a line (or record), with [CsvReader.AutoGrowBuffer] enabled,
+	// above which an error wrapping [ErrBufferFull] (or [ErrRecordTooLong]) is sent and the goroutine stops.
+	// Defaults to 0, meaning lines of any size are read.
+	MaxLineSize int
+	// WorkQueue is a flag indicating that data is split into small ranges (up to 1 MiB) the goroutines claim in turn,
+	// instead of each goroutine reading the chunk allocated to it up front, so a goroutine whose consumer drains
+	// its channel faster handles more ranges. While a goroutine handles a range, it reads its next claimed
+	// range's lines into memory, in the background, keeping the disk (or remote storage) busy while
+	// the channel drains. Rows of a goroutine's channel are in file order, but a range may follow
+	// another goroutine's one. It costs a goroutine and two ranges buffers per goroutine.
+	// Not supported by [CsvReader.ReadReverse], nor together with features relying on chunks allocated
+	// up front: plain gzip compression, UTF-16 [CsvReader.Encoding], [CsvReader.CRLFInQuotedFields],
+	// [CsvReader.MultilineQuotedFields], [CsvReader.NumberLines], [CsvReader.RealignChunks],
+	// [CsvReader.MaxErrorsPerChunk], [CsvReader.Checkpoint], [CsvReader.Manifest], [CsvReader.Quarantine]
+	// and [CsvReader.MaxRunDuration].
+	// Defaults to false.
+	WorkQueue bool
 	// Logger can be set to perform some debugging/error logging.
 	// Defaults to a no-operation logger (no log is performed).
 	// You can enable logging by passing a logger that implements [internal.Logger] contract.
 	Logger internal.Logger
+	// RunLabel can be set to a custom label logged (key "runLabel") by each run, next to the run's
+	// generated ID (key "run", see [Run.ID]), so interleaved logs of concurrent imports can be separated.
+	// Goroutines' logs also hold their number (key "thread"). Defaults to "" (no label is logged).
+	RunLabel string
 	// filePath is the CSV file path.
 	filePath string
 	// fileBaseName is the base name of the file extracted from filePath.
@@ -70,27 +132,393 @@ type CsvReader struct {
 	// LazyQuotes is a flag used to allow quotes in an unquoted field and non-doubled quotes
 	// in a quoted field
 	LazyQuotes bool
+	// PinFile is a flag indicating that the CSV file path should be resolved (symlinks evaluated)
+	// and the target pinned (opened once, fstat-ed) when reading starts.
+	// Each goroutine then checks the file it opened is the pinned one (same device and inode),
+	// so a symlink swap mid-read can't splice data from two different files into one result.
+	// If the file was replaced, [ErrFileChanged] is sent through ErrsChan.
+	// Defaults to false.
+	PinFile bool
+	// Cache can be set to store the artifacts derived by [CsvReader.Analyze],
+	// in order to skip redundant full scans of the same file.
+	// Defaults to nil (no caching is performed).
+	Cache Cache
+	// Watchdog can be set to monitor each goroutine's throughput
+	// and get alerted when it collapses.
+	// Defaults to nil (no monitoring is performed).
+	Watchdog *Watchdog
+	// ChanBufferSize is the buffer size of each RowsChan and of the ErrsChan.
+	// Defaults to 256.
+	ChanBufferSize int
+	// MemoryLimitAware is a flag indicating that the Go runtime soft memory limit
+	// (see [runtime/debug.SetMemoryLimit]) should be taken into consideration:
+	// channels buffers are shrunk at start if memory headroom is small,
+	// and goroutines pause reading while memory usage is above [CsvReader.MemoryLimitThreshold]
+	// of the limit (instead of growing allocations).
+	// Has no effect if no soft memory limit is set.
+	// Defaults to false.
+	MemoryLimitAware bool
+	// MemoryLimitThreshold is the fraction (0, 1] of the soft memory limit
+	// above which reading is paused, if [CsvReader.MemoryLimitAware] is enabled.
+	// Defaults to 0.9.
+	MemoryLimitThreshold float64
+	// TrimSpace is a flag indicating that leading and trailing white space
+	// should be removed from all columns values.
+	// Defaults to false.
+	TrimSpace bool
+	// NormalizeWindows1252 is a flag indicating that Windows-1252 punctuation bytes (smart quotes, dashes...)
+	// found inside nominally UTF-8 values should be translated into UTF-8, for all columns.
+	// See [ColumnOptions.NormalizeWindows1252].
+	// Defaults to false.
+	NormalizeWindows1252 bool
+	// ValidateUTF8 is a flag indicating that all column values must be valid UTF-8 (after columns options
+	// are applied, see [CsvReader.NormalizeWindows1252]), so invalid bytes don't flow silently into
+	// JSON marshalling or database drivers. A row having an invalid value is disregarded,
+	// and an [*InvalidUTF8Error], locating the first invalid byte, is sent through ErrsChan.
+	// Not applied by [CsvReader.ReadLazy].
+	// Defaults to false.
+	ValidateUTF8 bool
+	// ColumnsOptions holds options to apply to values of given columns (0-based indexes),
+	// like trimming white space, case normalization, and validation rules.
+	// Defaults to nil.
+	ColumnsOptions map[int]ColumnOptions
+	// Filter can be set to an expression (see [expr] package syntax) rows must match to be emitted,
+	// like `price > 100 && country == "US"`. Columns are referenced by header names, if the file has a header,
+	// or by 1-based positions ($1, $2...). It is compiled once per run, and evaluated inside the reading goroutines,
+	// after columns options were applied. Rows which do not match are silently disregarded;
+	// rows the expression could not be evaluated for are disregarded, and the error is sent through ErrsChan.
+	// Defaults to "" (no filtering is performed).
+	Filter string
+	// Unique can be set to assert a key (one or more columns) is unique across the whole file.
+	// Rows having a duplicate key are disregarded, and a [*DuplicateKeyError] is sent through ErrsChan.
+	// Defaults to nil (no uniqueness checking is performed).
+	Unique *UniqueConstraint
+	// ForeignKey can be set to look up a column's values (foreign keys) while reading.
+	// Rows referencing unknown keys are disregarded, and an [*UnknownForeignKeyError] is sent through ErrsChan.
+	// Defaults to nil (no foreign key checking is performed).
+	ForeignKey *ForeignKeyCheck
+	// Manifest can be set to write, after reading, a manifest file holding for each goroutine's
+	// chunk the byte range, the number of rows emitted and the checksum, optionally signed,
+	// so auditors can verify exactly which bytes were ingested by which job run.
+	// Defaults to nil (no manifest is written).
+	Manifest *ManifestOptions
+	// OnComplete can be set to be notified, with a [Summary] of the run,
+	// after all goroutines finished and all channels were closed.
+	// Defaults to nil.
+	OnComplete func(Summary)
+	// Decoder can be set to decode rows, inside the reading goroutines, for [CsvReader.ReadDecoded].
+	// It is a hand-written, reflection free alternative to [MapRows] / generated decoders.
+	// It is called concurrently.
+	// Defaults to nil.
+	Decoder func(row []string) (any, error)
+	// CompressShards is a flag indicating that shards written by [CsvReader.WriteShards]
+	// should be gzip compressed.
+	// Defaults to false.
+	CompressShards bool
+	// FS is the file system the CSV file is opened from.
+	// It can be replaced in tests with an in-memory or fault-injecting implementation.
+	// A file system having a WithContext(context.Context) method returning a file system (like the azblob one)
+	// is read, for each run, through the one returned for the run's context.
+	// Defaults to the operating system's file system.
+	FS internal.FS
+	// ZstdDecoder decompresses the frames of a zstd compressed file, which must be in the seekable format
+	// (see [CsvReader.SetFilePath]). It is required for reading zstd compressed files.
+	// Defaults to nil.
+	ZstdDecoder ZstdDecoder
+	// Clock is used for timestamps, durations, and monitors' polling.
+	// It can be replaced in tests with a fake, manually advanced, clock.
+	// Defaults to the system's clock.
+	Clock internal.Clock
+	// MergeUnionHeaders is a flag indicating that [CsvReader.Merge] should accept inputs having different headers,
+	// writing the union of their columns, instead of failing.
+	// Defaults to false.
+	MergeUnionHeaders bool
+	// NumberLines is a flag indicating that each row should be assigned its 1-based line number in the file.
+	// Lines preceding each goroutine's chunk are counted ahead of reading (one extra, parallel, pass).
+	// Line numbers are set on [Row]s and [LazyRow]s, and errors occurred on rows are wrapped into a [*LineError].
+	// Lines are not numbered by [CsvReader.ReadReverse].
+	// Defaults to false.
+	NumberLines bool
+	// MaxRunDuration is the maximum duration of a run (0 means no limit), distinct from the context's deadline:
+	// when it elapses, each goroutine finishes handling its current row and stops, rows already read
+	// are still delivered, and a [*RunTimeoutError] holding the progress made (rows read,
+	// offsets goroutines stopped at) is sent through ErrsChan, so a scheduled job can bail out
+	// before its next tick and resume later. See also [Summary.TimedOut].
+	// Not applied by [CsvReader.ReadReverse].
+	// Defaults to 0.
+	MaxRunDuration time.Duration
+	// ColumnsStatsInterval is the interval at which incremental per-column statistics (values, empty values,
+	// numeric values' min / max) of the rows read so far are pushed into [Run.ColumnsStatsChan],
+	// so data quality can be monitored while a long read is still running.
+	// A final snapshot is pushed after reading finished. Statistics are not computed for lazy rows.
+	// Defaults to 0 (no statistics are computed).
+	ColumnsStatsInterval time.Duration
+	// BoundaryMap holds the rows' boundaries computed by [CsvReader.PreScan] (or taken from [Artifacts.BoundaryMap],
+	// or loaded from an index file by [CsvReader.LoadBoundaryMap]). If set, and matching the file
+	// (same size), data is split between goroutines on rows' boundaries, each goroutine reading the same number
+	// of rows, instead of the same number of bytes.
+	// Not applied by [CsvReader.ReadReverse], nor to a data range (see [CsvReader.ReadTimeRange]).
+	// Defaults to nil.
+	BoundaryMap *BoundaryMap
+	// Checkpoint, if set, is the checkpoint reading resumes from (see [LoadCheckpoint]): the file is split into
+	// the checkpoint's chunks (disregarding [CsvReader.MaxGoroutinesNo] and [CsvReader.BoundaryMap]),
+	// each goroutine reading its chunk from the row following the last acknowledged one.
+	// An empty checkpoint (no chunks) gets the chunks of a run reading the whole file.
+	// The run's rows are acknowledged into it, see [Checkpoint.Ack].
+	// If the file's size differs from the checkpoint's one, an error wrapping [ErrCheckpointMismatch] is sent
+	// through ErrsChan. Not supported by [CsvReader.ReadReverse], nor with a data range
+	// (see [CsvReader.ReadTimeRange]), UTF-16 [CsvReader.Encoding] or plain gzip compression.
+	// Defaults to nil.
+	Checkpoint *Checkpoint
+	// LimitRows is the maximum number of rows to emit (0 means no limit). Once it is reached,
+	// all goroutines stop reading the rest of their chunks, and channels get closed.
+	// With several goroutines, rows emitted are not necessarily the file's first ones
+	// (set [CsvReader.MaxGoroutinesNo] to 1 for that).
+	// Defaults to 0.
+	LimitRows int64
+	// ProfilerLabels is a flag indicating that reading goroutines should be tagged with [pprof.Labels]
+	// "file" (the CSV file path) and "chunk" (the goroutine's number, as in logs and errors),
+	// so profiles of applications running several readers attribute time to specific files / chunks.
+	// Defaults to false.
+	ProfilerLabels bool
+	// Control can be set to validate the file against its companion control file (as found in SFTP drops),
+	// stating the expected rows count (header excluded) and / or the file's checksum.
+	// Rows (emitted or not) are counted while reading, while the checksum is computed meanwhile,
+	// in a separate goroutine. After the whole file was read, a [*ControlMismatchError] is sent
+	// through ErrsChan for each mismatch. Not applied to a data range (see [CsvReader.ReadTimeRange]).
+	// Defaults to nil (no control file is read).
+	Control *ControlOptions
+	// Quarantine can be set to dump the raw bytes which were not emitted into gzip compressed sidecar files,
+	// one for each goroutine's chunk, so nothing is lost and the exact ranges can be reprocessed later:
+	// rows which could not be parsed or failed checks (columns validations, unique and foreign key checks),
+	// and the rest of a chunk abandoned because of a read error, or because the bad rows rate was exceeded.
+	// Each range is a gzip member (so the file decompresses into the raw bytes) whose header comment
+	// holds the range's offset in the CSV file ("offset N"). See also [Run.Quarantined].
+	// Not supported with UTF-16, nor applied to the rest of chunks by [CsvReader.ReadReverse].
+	// Defaults to nil (nothing is quarantined).
+	Quarantine *QuarantineOptions
+	// Archive can be set to move, rename or delete the file, or to write a marker file next to it,
+	// after it was read successfully, so a drop directory is not reprocessed. The action is taken
+	// only if the whole file was read without fatal error (the context was not canceled, reading did not
+	// time out, nor was aborted, no read error, control mismatch...); rows which could not be parsed
+	// or failed checks are not fatal. It is taken on the operating system's file system, before
+	// ErrsChan is closed, an eventual failure being sent through it. Not applied to a data range
+	// (see [CsvReader.ReadTimeRange]). Defaults to nil (the file is left in place).
+	Archive *ArchiveOptions
+	// MaxErrorsPerChunk can be set to stop reading a goroutine's chunk after this number of its rows
+	// could not be parsed, assuming the chunk is misaligned, or the file is corrupted in that region,
+	// while the other goroutines carry on. An error wrapping [ErrTooManyErrorsInChunk] is sent through ErrsChan,
+	// and the skipped range is recorded (see [ThreadSummary.SkippedOffset], and [CsvReader.Quarantine]).
+	// Not applied by [CsvReader.ReadReverse], nor [CsvReader.ReadLazy]. Defaults to 0 (no limit).
+	MaxErrorsPerChunk int
+	// ErrorPolicy tells whether reading goes on after rows which could not be parsed, are invalid,
+	// hold an unknown foreign key or could not be decoded (bad rows),
+	// each sent as an error through ErrsChan, or is aborted by all goroutines after the first one ([FailFast]),
+	// or after [CsvReader.MaxBadRows] of them ([CollectUpToN]), an error wrapping [ErrTooManyBadRows] being sent.
+	// See also [CsvReader.AbortIfErrorRateExceeds]. Defaults to [SkipBadRows].
+	ErrorPolicy ErrorPolicy
+	// MaxBadRows is the number of bad rows tolerated with the [CollectUpToN] [CsvReader.ErrorPolicy].
+	// Defaults to 0 (reading is aborted after the first bad row, like with [FailFast]).
+	MaxBadRows int
+	// RealignChunks is a flag indicating that goroutines (but the first one) check records can be parsed
+	// right after the partial line they skip at their chunk's start, and, if not, start reading from the first
+	// next line, at most [CsvReader.BufferSize] bytes further, 3 consecutive records having
+	// [CsvReader.ColumnsCount] fields can be parsed from. This recovers from chunks boundaries landing inside
+	// quoted fields holding line breaks: the bytes skipped are the end of the previous chunk's last record.
+	// Requires [CsvReader.ColumnsCount] to be set, not supported with UTF-16, nor by [CsvReader.ReadReverse].
+	// Defaults to false.
+	RealignChunks bool
+	// WatchQuietPeriod is the period a file must not change during, to be considered completed
+	// by [CsvReader.WatchDirectory]. It is also the directory's polling interval.
+	// Defaults to 1s.
+	WatchQuietPeriod time.Duration
+	// Encoding is the character encoding of the file. UTF-16 files are read in 2-bytes code units:
+	// goroutines chunks are aligned to code units, so line ends are found without splitting characters,
+	// and lines are transcoded into UTF-8 before being parsed. An eventual byte order mark is skipped.
+	// Rows offsets are offsets in the file. Note: [CsvReader.ReadReverse], [CsvReader.ReadTimeRange],
+	// [CsvReader.NumberLines], [CsvReader.CRLFInQuotedFields] and [CsvReader.MultilineQuotedFields]
+	// are not supported with UTF-16,
+	// and the other operations on the file (shards, merge, estimations...) expect UTF-8.
+	// Defaults to [EncodingUTF8].
+	Encoding TextEncoding
+	// ErrorSampling can be set to sample errors sent through ErrsChan (and logged), per error class:
+	// the first ones are sent verbatim, then only 1 out of every few, and, at the end of the run,
+	// a [*SuppressedErrorsError] holding the final count is sent for each class errors were suppressed for.
+	// Suppressed errors are not recorded by [Run.Err] either.
+	// Defaults to nil (all errors are sent).
+	ErrorSampling *ErrorSampling
+	// Transport is the way rows are passed from each reading goroutine to its paired consumer,
+	// in [CsvReader.ReadPaired].
+	// Defaults to [TransportRing].
+	Transport Transport
+	// CRLFInQuotedFields is a flag indicating that \r\n found inside quoted fields should be treated as part of
+	// the field's value (as Excel exports cells holding line breaks), instead of ending the row.
+	// It is a lighter alternative to full multiline support: the whole row must fit into [CsvReader.BufferSize],
+	// quotes are expected to be escaped by doubling them, and a bare \n still ends the row.
+	// As with [encoding/csv], the line break is returned as \n in row's values (lazy rows keep the raw bytes).
+	// With several goroutines, the quotes preceding each goroutine's start offset are counted ahead of reading
+	// (like with [CsvReader.MultilineQuotedFields]), so a goroutine starting inside a quoted field skips
+	// the whole record, read by the previous goroutine.
+	// Not supported by [CsvReader.ReadReverse] and [CsvReader.ReadTimeRange].
+	// Defaults to false.
+	CRLFInQuotedFields bool
+	// MultilineQuotedFields is a flag indicating that any line break (\n or \r\n) found inside quoted fields
+	// should be treated as part of the field, as RFC 4180 allows, and not as the end of the row.
+	// Before goroutines start, the quotes preceding each goroutine's start offset are counted, concurrently,
+	// to tell whether it falls inside a quoted field, so records spanning two goroutines' chunks
+	// are read by the one they start in (this costs an extra pass over the file).
+	// Quotes must be balanced (escaped by doubling them, no bare quotes), as the quotes parity
+	// is what tells whether a line break is inside a quoted field.
+	// A record must fit into [CsvReader.BufferSize] (see also [CsvReader.AutoGrowBuffer]),
+	// otherwise [ErrRecordTooLong] is sent through ErrsChan.
+	// It takes precedence over [CsvReader.CRLFInQuotedFields].
+	// Not supported by [CsvReader.ReadReverse] and [CsvReader.ReadTimeRange].
+	// Defaults to false.
+	MultilineQuotedFields bool
+	// QuoteChar is the char fields are enclosed in. 0 stands for the double quote,
+	// see [CsvReader.NoQuoting] for files whose fields are never quoted.
+	// Defaults to double quote. See also [CsvReader.SetDialect].
+	QuoteChar rune
+	// NoQuoting is a flag indicating fields are never quoted, quote chars being part of values,
+	// whatever [CsvReader.QuoteChar] is. It is set by [CsvReader.SetDialect] for dialects without quoting.
+	// Defaults to false.
+	NoQuoting bool
+	// EscapeChar is the char escaping the next char inside fields (\n, \r, \t and \0 sequences
+	// stand for the control chars). 0 means there is no escaping, quotes are doubled inside quoted fields.
+	// Defaults to 0.
+	EscapeChar rune
+	// NullToken is the (unquoted) token the file holds for NULL values, which are returned as empty strings.
+	// Defaults to empty string (no token).
+	// A custom quote char, escape char or null token is not supported by [CsvReader.ReadLazy],
+	// nor, quote / escape char, with [CsvReader.CRLFInQuotedFields] or [CsvReader.MultilineQuotedFields].
+	NullToken string
+	// OutputQuotePolicy tells which fields are enclosed in (double) quotes when rows are re-serialized,
+	// by [CsvReader.Export], [CsvReader.Enrich], [CsvReader.Merge], [CsvReader.SplitColumns]
+	// and [CsvReader.Sample]. Whatever the policy, fields containing the output delimiter, quotes or line breaks
+	// are quoted, quotes being doubled, so written files remain valid.
+	// Defaults to [QuoteMinimal].
+	OutputQuotePolicy QuotePolicy
+	// maxErrorRate is the bad rows ratio above which reading is aborted, see [CsvReader.AbortIfErrorRateExceeds].
+	maxErrorRate float64
+	// errorRateMinSample is the number of rows to read before checking maxErrorRate.
+	errorRateMinSample int
+	// runID is the ID of the run the reader is a snapshot for, see [Run.ID].
+	runID string
+	// longestLine is the size of the longest line of the run's data head, see [CsvReader.fitBufferSize].
+	longestLine int
+	// inflight tracks the runs in flight, shared with the reader's copies, see [CsvReader.WaitDone].
+	inflight *runsTracker
 }
 
 // New instantiates a new CsvReader object with some default fields preset.
 func New() *CsvReader {
 	return &CsvReader{
-		MaxGoroutinesNo:  runtime.NumCPU(),
+		MaxGoroutinesNo:  internal.AvailableCPUs(),
 		ColumnsDelimiter: ',',
+		QuoteChar:        '"',
 		Logger:           internal.NopLogger{},
-		BufferSize:       4096,
+		BufferSize:       defaultBufferSize,
+		ChanBufferSize:   defaultChanBufferSize,
+		FS:               internal.OSFS{},
+		Clock:            internal.SystemClock{},
+		inflight:         &runsTracker{},
+		WatchQuietPeriod: time.Second,
 	}
 }
 
 // SetFilePath sets the CSV file path.
+// Compression is detected by the file's magic bytes, whatever its extension.
+// A gzip compressed file is decompressed on the fly.
+// A BGZF file (blocked gzip, as written by bgzip) is read in chunks by several goroutines, like a plain CSV file,
+// its blocks being located with its blocks index file (path followed by ".gzi"), if present, or by walking them.
+// A plain gzip file can only be decompressed sequentially, by a goroutine which fans out its lines to the
+// goroutines parsing them; reading it backwards, a data range (see [CsvReader.ReadTimeRange]),
+// with UTF-16 encoding, [CsvReader.Manifest] or [CsvReader.MaxErrorsPerChunk] is not supported.
+// A zstd compressed file must be in the seekable format
+// (independent frames, listed by a seek table ending the file), its frames being read in chunks
+// by several goroutines, decompressed with [CsvReader.ZstdDecoder].
+// A file compressed in another format (or a zstd one, without decoder) is rejected
+// with an [*UnsupportedCompressionError].
 func (cr *CsvReader) SetFilePath(csvFilePath string) {
 	cr.filePath = csvFilePath
 	cr.fileBaseName = path.Base(csvFilePath)
 }
 
+// SetFS sets the file system the CSV file is opened from, and the file's name in it (slash-separated, unrooted,
+// see [fs.ValidPath]), so files from an [embed.FS], a zip archive ([archive/zip.Reader]), or a test fake can be read.
+// Goroutines open the file through fsys, and read their chunks at given offsets: files not supporting random
+// access (like compressed zip archive entries) are read into memory once, and kept for the reader's lifetime.
+// A nil fsys means the operating system's file system. It replaces [CsvReader.FS].
+func (cr *CsvReader) SetFS(fsys fs.FS, name string) {
+	if fsys == nil {
+		cr.FS = internal.OSFS{}
+	} else {
+		cr.FS = internal.NewIOFS(fsys)
+	}
+	cr.SetFilePath(name)
+}
+
 // Read extracts asynchronously CSV rows, each started goroutine putting them into a RowsChan.
 // Error(s) occurred during parsing are sent through ErrsChan.
+// It can be called concurrently (like to read different time windows of the same file),
+// as runs share no mutable state.
 func (cr *CsvReader) Read(ctx context.Context) ([]RowsChan, ErrsChan) {
+	rn := cr.Start(ctx)
+
+	return rn.RowsChans(), rn.ErrsChan()
+}
+
+// ReadFile extracts asynchronously CSV rows from the file at given path, like [CsvReader.Read] does,
+// with this reader's configuration (its file path aside), so the same configuration can be used
+// to read different files, concurrently.
+func (cr *CsvReader) ReadFile(ctx context.Context, filePath string) ([]RowsChan, ErrsChan) {
+	reader := cr.snapshot()
+	reader.SetFilePath(filePath)
+
+	return reader.Read(ctx)
+}
+
+// Start starts extracting asynchronously CSV rows, like [CsvReader.Read] does,
+// and returns a handle to the run, which gives access to rows and errors channels,
+// and to the final outcome of the run.
+func (cr *CsvReader) Start(ctx context.Context) *Run {
+	return cr.start(ctx, modeRows, nil)
+}
+
+// readMode is the form read rows are pushed into channels.
+type readMode int
+
+const (
+	// modeRows pushes rows as []string.
+	modeRows readMode = iota
+	// modeLazy pushes rows as [LazyRow]s.
+	modeLazy
+	// modeDecoded pushes rows decoded with [CsvReader.Decoder].
+	modeDecoded
+	// modeMeta pushes rows as [Row]s.
+	modeMeta
+	// modePaired pushes rows as []string into ring buffers consumed by paired goroutines.
+	modePaired
+	// modeReverse pushes rows as []string, each goroutine reading its chunk backwards.
+	modeReverse
+)
+
+// locateFunc returns the [start, end) byte range, line aligned, to read out of the data
+// located in the [dataStart, fileSize) byte range (after eventual header).
+type locateFunc func(f io.ReaderAt, dataStart, fileSize int) (int, int, error)
+
+// start starts extracting asynchronously CSV rows, pushed in given mode.
+// If locate is not nil, only the data range it returns is read, otherwise the whole data is read.
+func (cr *CsvReader) start(ctx context.Context, mode readMode, locate locateFunc) *Run {
+	cr = cr.snapshot() // the run's goroutines use an immutable copy of the configuration.
+	cr.runID = newRunID()
+	cr = cr.withContextFS(ctx) // (remote) requests are made with the run's context.
+	if cr.RunLabel != "" {
+		cr.Logger = internal.WithKeyValues(cr.Logger, "run", cr.runID, "runLabel", cr.RunLabel)
+	} else {
+		cr.Logger = internal.WithKeyValues(cr.Logger, "run", cr.runID)
+	}
+	cr.normalizeSizes()
 	cr.Logger.Debug(
 		"msg", "starting file reading",
 		"filePath", cr.filePath,
@@ -99,24 +527,182 @@ func (cr *CsvReader) Read(ctx context.Context) ([]RowsChan, ErrsChan) {
 		"maxThreads", cr.MaxGoroutinesNo,
 	)
 
-	errsChan := make(chan error, chanSize)
-	fileSize, err := cr.getFileSize()
+	errsChan := make(chan error, cr.chanBufferSize(0, 0))
+	fsys, stream, err := cr.decompressingFS()
 	if err != nil {
-		errsChan <- fmt.Errorf(
-			"bigcsvreader: file size error (%w)",
-			err,
-		)
-		close(errsChan)
-		cr.Logger.Error(
-			"msg", "file size error",
-			"err", err,
-			"file", cr.fileBaseName,
-		)
+		return cr.failedRun(errsChan, "could not open file", err)
+	}
+	cr.FS = fsys
+	var (
+		pin      *pinnedFile
+		fileSize int
+	)
+	if cr.PinFile {
+		pin, err = pinFile(cr.FS, cr.filePath)
+		if err == nil {
+			fileSize, err = checkFileSize(pin.info)
+			if err != nil {
+				pin.close()
+			}
+		}
+	} else {
+		fileSize, err = cr.getFileSize()
+	}
+	if err != nil {
+		return cr.failedRun(errsChan, "file size error", err)
+	}
+	columns, validate, err := cr.compileColumnsOptions()
+	if err != nil {
+		pin.close()
+
+		return cr.failedRun(errsChan, "invalid columns options", err)
+	}
+	if cr.hasCustomDialect() &&
+		(mode == modeLazy || (cr.CRLFInQuotedFields || cr.MultilineQuotedFields) && (cr.quoteChar() != '"' || cr.EscapeChar != 0)) {
+		pin.close()
+
+		return cr.failedRun(errsChan, "invalid options", errUnsupportedWithDialect)
+	}
+	if (mode == modeReverse || locate != nil) && (cr.CRLFInQuotedFields || cr.MultilineQuotedFields) {
+		pin.close()
+
+		return cr.failedRun(errsChan, "invalid options", errUnsupportedWithQuotedLineBreaks)
+	}
+	unique, err := cr.newUniqueChecker(fileSize)
+	if err != nil {
+		pin.close()
+
+		return cr.failedRun(errsChan, "invalid unique constraint", err)
+	}
+	foreignKey, err := cr.newForeignKeyChecker()
+	if err != nil {
+		pin.close()
+
+		return cr.failedRun(errsChan, "invalid foreign key check", err)
+	}
+	if cr.Archive != nil {
+		if err = cr.validateArchive(); err != nil {
+			pin.close()
+
+			return cr.failedRun(errsChan, "invalid archive options", err)
+		}
+	}
+	var control *Control
+	if cr.Control != nil && locate == nil {
+		if control, err = cr.readControl(); err != nil {
+			pin.close()
+
+			return cr.failedRun(errsChan, "invalid control file", err)
+		}
+	}
+
+	// read the header once, up front, so workers split only the data following it.
+	var (
+		header     []string
+		headerSize int
+	)
+	if cr.FileHasHeader {
+		header, headerSize, err = cr.warmupHeader(pin)
+		if err != nil {
+			pin.close()
+
+			return cr.failedRun(errsChan, "header error", err)
+		}
+	}
+
+	dataStart, dataEnd := headerSize, fileSize
+	if order := cr.Encoding.byteOrder(); order != nil {
+		if mode == modeReverse || locate != nil || cr.NumberLines || cr.CRLFInQuotedFields ||
+			cr.MultilineQuotedFields || cr.Quarantine != nil {
+			pin.close()
+
+			return cr.failedRun(errsChan, "invalid options", errUnsupportedWithUTF16)
+		}
+		if !cr.FileHasHeader {
+			if dataStart, err = cr.warmupBOM(pin, order); err != nil {
+				pin.close()
+
+				return cr.failedRun(errsChan, "could not read byte order mark", err)
+			}
+		}
+	}
+	if stream {
+		if mode == modeReverse || locate != nil || cr.Encoding.byteOrder() != nil || cr.Manifest != nil ||
+			cr.MaxErrorsPerChunk > 0 {
+			pin.close()
+
+			return cr.failedRun(errsChan, "invalid options", errUnsupportedWithGzip)
+		}
+		dataEnd = math.MaxInt // the decompressed size is not known, data is read up to EOF.
+	}
+	if cr.Checkpoint != nil && (mode == modeReverse || locate != nil || cr.Encoding.byteOrder() != nil || stream) {
+		pin.close()
+
+		return cr.failedRun(errsChan, "invalid options", errUnsupportedWithCheckpoint)
+	}
+	if cr.WorkQueue && (mode == modeReverse || stream || cr.Encoding.byteOrder() != nil ||
+		cr.CRLFInQuotedFields || cr.MultilineQuotedFields || cr.NumberLines || cr.RealignChunks ||
+		cr.MaxErrorsPerChunk > 0 || cr.Checkpoint != nil || cr.Manifest != nil || cr.Quarantine != nil ||
+		cr.MaxRunDuration > 0) {
+		pin.close()
+
+		return cr.failedRun(errsChan, "invalid options", errUnsupportedWithWorkQueue)
+	}
+	if locate != nil {
+		dataStart, dataEnd, err = cr.locateData(pin, locate, headerSize, fileSize)
+		if err != nil {
+			pin.close()
 
-		return nil, errsChan
+			return cr.failedRun(errsChan, "could not locate data", err)
+		}
+	}
+
+	var filter *expr.Program
+	if cr.Filter != "" {
+		if filter, err = expr.Compile(cr.Filter, header); err != nil {
+			pin.close()
+
+			return cr.failedRun(errsChan, "invalid filter", err)
+		}
 	}
 
-	threadsInfo := internal.ComputeGoroutineOffsets(fileSize, cr.MaxGoroutinesNo, minBytesToReadByAGoroutine)
+	if !stream && cr.Encoding.byteOrder() == nil {
+		cr.fitBufferSize(pin, dataStart, dataEnd)
+	}
+
+	var threadsInfo [][2]int
+	switch {
+	case stream:
+		// goroutines handle the lines fanned out by the decompressing goroutine, they have no chunk,
+		// their number is computed out of the compressed size.
+		threadsInfo = make([][2]int, len(internal.ComputeGoroutineOffsets(fileSize, cr.MaxGoroutinesNo, minBytesToReadByAGoroutine)))
+	case cr.Encoding.byteOrder() != nil:
+		threadsInfo = cr.computeChunks(dataEnd - dataStart)
+		threadsInfo = internal.AlignGoroutineOffsets(threadsInfo, utf16CodeUnitSize)
+	case cr.Checkpoint != nil && cr.Checkpoint.resumes():
+		if threadsInfo, err = cr.Checkpoint.resumeOffsets(fileSize, dataStart, dataEnd); err != nil {
+			pin.close()
+
+			return cr.failedRun(errsChan, "could not resume from checkpoint", err)
+		}
+		for i := range threadsInfo { // offsets are shifted by the data start below.
+			threadsInfo[i][0] -= dataStart
+			threadsInfo[i][1] -= dataStart
+		}
+	default:
+		threadsInfo = cr.computeChunks(dataEnd - dataStart)
+		if cr.BoundaryMap != nil && mode != modeReverse && locate == nil {
+			if cr.BoundaryMap.FileSize != int64(fileSize) {
+				cr.Logger.Debug("msg", "boundary map does not match the file, disregarding it", "file", cr.fileBaseName)
+			} else if distribution := cr.BoundaryMap.distribute(len(threadsInfo), dataStart, dataEnd); distribution != nil {
+				threadsInfo = distribution
+			}
+		}
+	}
+	for i := range threadsInfo {
+		threadsInfo[i][0] += dataStart
+		threadsInfo[i][1] += dataStart
+	}
 	totalThreads := len(threadsInfo)
 	cr.Logger.Debug(
 		"msg", "stats",
@@ -124,49 +710,352 @@ func (cr *CsvReader) Read(ctx context.Context) ([]RowsChan, ErrsChan) {
 		"totalThreads", totalThreads, "initialOffsetsDistribution", threadsInfo,
 	)
 
-	rowsChans := make([]RowsChan, totalThreads)
-	rowsChs := make([]chan<- []string, totalThreads)
-	for i := 0; i < totalThreads; i++ {
-		rowsChan := make(chan []string, chanSize)
-		rowsChans[i] = rowsChan
-		rowsChs[i] = rowsChan
+	var firstLines []int64
+	if cr.NumberLines && mode != modeReverse && len(threadsInfo) > 0 {
+		firstLines, err = cr.countFirstLines(pin, threadsInfo)
+		if err != nil {
+			pin.close()
+
+			return cr.failedRun(errsChan, "could not number lines", err)
+		}
 	}
 
-	go cr.readAsync(ctx, threadsInfo, rowsChs, errsChan)
+	var inQuotes []bool
+	if (cr.MultilineQuotedFields || cr.CRLFInQuotedFields) && mode != modeReverse && !stream && len(threadsInfo) > 1 {
+		inQuotes, err = cr.scanQuotesParity(pin, threadsInfo)
+		if err != nil {
+			pin.close()
 
-	return rowsChans, errsChan
+			return cr.failedRun(errsChan, "could not scan quotes", err)
+		}
+	}
+
+	rn := newRun(threadsInfo, pin, errsChan)
+	rn.id = cr.runID
+	rn.firstLines = firstLines
+	rn.inQuotes = inQuotes
+	rn.header = header
+	if header != nil {
+		rn.headerIndex = NewHeaderIndex(header)
+	}
+	rn.emitHeader = cr.EmitHeader && header != nil && locate == nil && mode != modeLazy && mode != modeDecoded
+	rn.filter = filter
+	rn.utf16 = cr.Encoding.byteOrder()
+	rn.errSampler = cr.newErrorSampler()
+	rn.dataStart, rn.dataEnd = dataStart, dataEnd
+	rn.checkpoint = cr.Checkpoint
+	if rn.checkpoint == nil {
+		rn.checkpoint = &Checkpoint{}
+	}
+	if !rn.checkpoint.resumes() {
+		rn.checkpoint.init(cr.filePath, fileSize, threadsInfo)
+	}
+	rn.reverse = mode == modeReverse
+	if stream {
+		rn.streamLines = make(chan []streamLine, totalThreads)
+	}
+	if cr.WorkQueue {
+		rn.workQueue = newWorkQueue(dataStart, dataEnd, totalThreads)
+	}
+	rn.memThreshold = cr.memoryThreshold()
+	rn.columns, rn.validate = columns, validate
+	rn.unique = unique
+	rn.foreignKey = foreignKey
+	rn.control = control
+	rn.quarantine = cr.newQuarantineLog()
+	rn.archive = cr.Archive != nil && locate == nil
+	rn.fieldsCount = cr.newFieldsCountRef(totalThreads)
+	rn.clock = cr.Clock
+	rn.startedAt = cr.Clock.Now().UTC()
+	rn.errorRate = cr.newErrorRate()
+	if cr.ColumnsStatsInterval > 0 && mode != modeLazy {
+		rn.colStats = make([]columnsStatsAcc, totalThreads)
+		rn.colStatsChan = make(chan ColumnsStats, 1)
+	}
+	rn.limit = max(cr.LimitRows, 0)
+	rn.trimSpace = cr.TrimSpace
+	rn.normalizeWindows1252 = cr.NormalizeWindows1252
+	chanSize := cr.chanBufferSize(totalThreads, rn.memThreshold)
+	switch mode {
+	case modeLazy:
+		rn.rowsChans, rn.rowsChansOut = nil, nil
+		rn.lazyChans = make([]chan *LazyRow, totalThreads)
+		for i := 0; i < totalThreads; i++ {
+			rn.lazyChans[i] = make(chan *LazyRow, chanSize)
+		}
+	case modeDecoded:
+		rn.rowsChans, rn.rowsChansOut = nil, nil
+		rn.decodedChans = make([]chan any, totalThreads)
+		for i := 0; i < totalThreads; i++ {
+			rn.decodedChans[i] = make(chan any, chanSize)
+		}
+	case modePaired:
+		rn.rowsChans, rn.rowsChansOut = nil, nil
+		rn.rings = make([]internal.Transport[[]string], totalThreads)
+		for i := 0; i < totalThreads; i++ {
+			if cr.Transport == TransportChannel {
+				rn.rings[i] = internal.NewChanTransport[[]string](chanSize)
+			} else {
+				rn.rings[i] = internal.NewSPSCRing[[]string](max(chanSize, 1))
+			}
+		}
+	case modeMeta:
+		rn.rowsChans, rn.rowsChansOut = nil, nil
+		rn.metaChans = make([]chan Row, totalThreads)
+		for i := 0; i < totalThreads; i++ {
+			rn.metaChans[i] = make(chan Row, chanSize)
+		}
+	default:
+		for i := 0; i < totalThreads; i++ {
+			rowsChan := make(chan []string, chanSize)
+			rn.rowsChansOut[i] = rowsChan
+			rn.rowsChans[i] = rowsChan
+		}
+	}
+
+	cr.inflight.add()
+	go cr.readAsync(ctx, rn)
+
+	return rn
 }
 
-func (cr *CsvReader) readAsync(
-	ctx context.Context,
-	threadsInfo [][2]int,
-	rowsChans []chan<- []string,
-	errsChan chan<- error,
-) {
+// snapshot returns a copy of the reader, for a run to use.
+// The configuration held by pointers and slices is copied too (callbacks, logger, cache, watchdog... are shared).
+func (cr *CsvReader) snapshot() *CsvReader {
+	snapshot := *cr
+	if cr.Unique != nil {
+		unique := *cr.Unique
+		unique.Columns = slices.Clone(unique.Columns)
+		snapshot.Unique = &unique
+	}
+	if cr.ForeignKey != nil {
+		foreignKey := *cr.ForeignKey
+		snapshot.ForeignKey = &foreignKey
+	}
+	if cr.Manifest != nil {
+		manifest := *cr.Manifest
+		manifest.Key = slices.Clone(manifest.Key)
+		snapshot.Manifest = &manifest
+	}
+	if cr.ErrorSampling != nil {
+		sampling := *cr.ErrorSampling
+		snapshot.ErrorSampling = &sampling
+	}
+	if cr.BoundaryMap != nil {
+		boundaryMap := *cr.BoundaryMap
+		boundaryMap.Offsets = slices.Clone(boundaryMap.Offsets)
+		snapshot.BoundaryMap = &boundaryMap
+	}
+	if cr.Control != nil {
+		control := *cr.Control
+		snapshot.Control = &control
+	}
+	if cr.Quarantine != nil {
+		quarantine := *cr.Quarantine
+		snapshot.Quarantine = &quarantine
+	}
+	if cr.Archive != nil {
+		archive := *cr.Archive
+		snapshot.Archive = &archive
+	}
+	snapshot.DefaultValues = slices.Clone(cr.DefaultValues)
+
+	return &snapshot
+}
+
+// warmupHeader reads the header from the pinned file, if any, or from the file path.
+func (cr *CsvReader) warmupHeader(pin *pinnedFile) ([]string, int, error) {
+	if pin != nil {
+		return cr.readHeader(pin.f)
+	}
+	f, err := cr.FS.Open(cr.filePath)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	return cr.readHeader(f)
+}
+
+// warmupBOM returns the size of the byte order mark the pinned file, if any, or the file path starts with.
+func (cr *CsvReader) warmupBOM(pin *pinnedFile, order binary.ByteOrder) (int, error) {
+	if pin != nil {
+		return utf16BOMSize(pin.f, order)
+	}
+	f, err := cr.FS.Open(cr.filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return utf16BOMSize(f, order)
+}
+
+// locateData returns the data range to read, located in the pinned file, if any, or in the file path.
+func (cr *CsvReader) locateData(pin *pinnedFile, locate locateFunc, dataStart, fileSize int) (int, int, error) {
+	if pin != nil {
+		return locate(pin.f, dataStart, fileSize)
+	}
+	f, err := cr.FS.Open(cr.filePath)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	return locate(f, dataStart, fileSize)
+}
+
+// failedRun returns a Run which failed before any goroutine was started.
+func (cr *CsvReader) failedRun(errsChan chan error, msg string, err error) *Run {
+	rn := newRun(nil, nil, errsChan)
+	rn.id = cr.runID
+	rn.sendErr(fmt.Errorf(
+		"bigcsvreader: %s (%w)",
+		msg, err,
+	))
+	close(errsChan)
+	close(rn.done)
+	cr.Logger.Error(
+		"msg", msg,
+		"err", err,
+		"file", cr.fileBaseName,
+	)
+	if cr.OnComplete != nil {
+		cr.OnComplete(rn.summary(cr.filePath, cr.runConfig(), cr.Clock.Now().UTC()))
+	}
+
+	return rn
+}
+
+func (cr *CsvReader) readAsync(ctx context.Context, rn *Run) {
+	defer cr.inflight.done()
 	defer func() {
-		close(errsChan)
-		for i := 0; i < len(rowsChans); i++ {
-			close(rowsChans[i])
+		rn.pin.close()
+		if rn.archive {
+			cr.archive(ctx, rn)
+		}
+		close(rn.errsChan)
+		for i := range rn.threads {
+			if !rn.threads[i].isEmpty() { // empty chunks' channels were closed already.
+				rn.closeChans(i)
+			}
+		}
+		if rn.colStatsChan != nil {
+			rn.publishColumnsStats(cr.Clock.Now().UTC(), true)
+			close(rn.colStatsChan)
+		}
+		close(rn.done)
+		if cr.OnComplete != nil {
+			cr.OnComplete(rn.summary(cr.filePath, cr.runConfig(), cr.Clock.Now().UTC()))
 		}
 	}()
-	totalThreads := len(threadsInfo)
+	totalThreads := len(rn.threadsInfo)
+
+	// start monitoring goroutines, if any.
+	var (
+		monitorsDone = make(chan struct{})
+		monitorsWg   sync.WaitGroup
+	)
+	if cr.Watchdog != nil {
+		monitorsWg.Add(1)
+		go func() {
+			defer monitorsWg.Done()
+			cr.Watchdog.watch(rn, cr.Clock, monitorsDone)
+		}()
+	}
+	if cr.MaxRunDuration > 0 && !rn.reverse {
+		monitorsWg.Add(1)
+		go func() {
+			defer monitorsWg.Done()
+			cr.watchRunDuration(rn, monitorsDone)
+		}()
+	}
+	if rn.colStatsChan != nil {
+		monitorsWg.Add(1)
+		go func() {
+			defer monitorsWg.Done()
+			cr.streamColumnsStats(rn, monitorsDone)
+		}()
+	}
+	if rn.memThreshold > 0 {
+		cr.checkMemory(rn) // check once before any goroutine starts reading.
+		monitorsWg.Add(1)
+		go func() {
+			defer monitorsWg.Done()
+			cr.monitorMemory(rn, monitorsDone)
+		}()
+	}
+
+	var sums <-chan map[string]string
+	if rn.control != nil {
+		sums = cr.checksumAsync(rn)
+	}
 
 	// create a wait group pool as we need to wait for all goroutines to terminate.
 	var wg sync.WaitGroup
 	wg.Add(totalThreads)
 	worker := cr.readBetweenOffsetsAsync
+	switch {
+	case rn.streamLines != nil:
+		worker = cr.readStreamAsync
+		go cr.decompressAsync(ctx, rn)
+	case rn.reverse:
+		worker = cr.readBetweenOffsetsBackwardAsync
+	case rn.workQueue != nil:
+		worker = cr.readWorkQueueAsync
+	case cr.isSequential(rn):
+		worker = cr.readSequentiallyAsync
+	}
 	for thread := 0; thread < totalThreads; thread++ {
+		if cr.ProfilerLabels {
+			threadNo, offsets := thread+1, rn.threadsInfo[thread]
+			labels := pprof.Labels("file", cr.filePath, "chunk", strconv.Itoa(threadNo))
+			go pprof.Do(ctx, labels, func(ctx context.Context) {
+				worker(ctx, rn, threadNo, offsets[0], offsets[1], &wg)
+			})
+
+			continue
+		}
 		go worker(
 			ctx,
+			rn,
 			thread+1,
-			threadsInfo[thread][0], // start offset
-			threadsInfo[thread][1], // end offset
+			rn.threadsInfo[thread][0], // start offset
+			rn.threadsInfo[thread][1], // end offset
 			&wg,
-			rowsChans[thread],
-			errsChan,
 		)
 	}
 	wg.Wait()
+	close(monitorsDone)
+	monitorsWg.Wait()
+
+	if err := rn.timeoutErr(cr.MaxRunDuration); err != nil {
+		if rn.sendErr(fmt.Errorf("bigcsvreader: stopped reading (%w)", err)) {
+			cr.Logger.Error("msg", "stopped reading", "err", err, "file", cr.fileBaseName)
+		}
+	}
+
+	if rn.control != nil {
+		cr.checkControl(ctx, rn, <-sums)
+	}
+
+	if cr.Manifest != nil {
+		if err := cr.writeManifest(rn); err != nil {
+			rn.fail()
+			if rn.sendErr(fmt.Errorf("bigcsvreader: could not write manifest (%w)", err)) {
+				cr.Logger.Error("msg", "could not write manifest", "err", err, "file", cr.fileBaseName)
+			}
+		}
+	}
+
+	if rn.errSampler != nil {
+		suppressed := rn.errSampler.suppressed()
+		rn.errSampler = nil // summaries are not sampled.
+		for _, err := range suppressed {
+			rn.sendErr(fmt.Errorf("bigcsvreader: sampled errors (%w)", err))
+			cr.Logger.Error("msg", "sampled errors", "err", err, "file", cr.fileBaseName)
+		}
+	}
 
 	cr.Logger.Debug("msg", "finished file reading", "file", cr.fileBaseName)
 }
@@ -174,80 +1063,152 @@ func (cr *CsvReader) readAsync(
 // readBetweenOffsetsAsync reads the piece of file allocated to a given thread.
 func (cr *CsvReader) readBetweenOffsetsAsync(
 	ctx context.Context,
+	rn *Run,
 	currentThreadNo, offsetStart, offsetEnd int,
 	wg *sync.WaitGroup,
-	rowsChan chan<- []string,
-	errsChan chan<- error,
 ) {
 	defer wg.Done()
+	stats := &rn.threads[currentThreadNo-1]
+	defer stats.markDone()
 
-	f := cr.openFile(currentThreadNo, errsChan)
+	f := cr.openFile(rn, currentThreadNo)
 	if f == nil {
 		return
 	}
 	defer f.Close()
 
-	var line []byte
+	var (
+		line []byte
+		size int
+		lh   = cr.newLineHandler(rn, currentThreadNo, stats)
+	)
+	defer cr.closeQuarantine(rn, lh)
 
 	// move offset to startOffset and skip the whole line.
 	r := bufio.NewReaderSize(f, cr.BufferSize)
 	_, _ = f.Seek(int64(offsetStart), io.SeekStart)
-	if currentThreadNo != 1 || cr.FileHasHeader {
-		line = cr.readLine(r, currentThreadNo, offsetStart, errsChan)
+	// first goroutine starts right at the beginning of data (after eventual header), unless it resumes a chunk.
+	if currentThreadNo != 1 || offsetStart != rn.dataStart {
+		switch {
+		case rn.utf16 != nil:
+			line, size = cr.readUTF16Line(rn, lh, r, offsetStart)
+		case rn.inQuotes != nil:
+			var breaks int
+			line, breaks, _ = cr.readQuotedLine(rn, lh, r, offsetStart, rn.inQuotes[currentThreadNo-1])
+			size = len(line)
+			if lh.line > 0 {
+				lh.line += int64(breaks)
+			}
+		default:
+			line = cr.readLine(rn, r, currentThreadNo, offsetStart)
+			size = len(line)
+		}
 		if line == nil {
 			return
 		}
+	} else if rn.emitHeader {
+		cr.emitHeaderRow(rn)
+	}
+	realOffsetStart := offsetStart + size
+	if currentThreadNo != 1 && cr.realigns() && rn.utf16 == nil && cr.Checkpoint == nil {
+		if start := cr.realignChunk(rn, f, currentThreadNo, realOffsetStart, offsetEnd); start > realOffsetStart {
+			skipped, _ := r.Peek(start - realOffsetStart)
+			if lh.line > 0 {
+				lh.line += int64(bytes.Count(skipped, []byte{'\n'}))
+			}
+			_, _ = r.Discard(len(skipped))
+			realOffsetStart = start
+		}
 	}
-	realOffsetStart := offsetStart + len(line)
 	currentOffsetPos := realOffsetStart
+	if currentOffsetPos-1 > offsetEnd { // no line starts in the chunk, like a resumed one having all its rows acknowledged.
+		cr.finishLines(ctx, rn, lh, realOffsetStart, currentOffsetPos)
+		rn.closeIfEmpty(currentThreadNo)
 
-	bytesReader := bytes.NewReader(line)
-	csvReader := csv.NewReader(bytesReader)
-	csvReader.Comma = cr.ColumnsDelimiter
-	csvReader.FieldsPerRecord = cr.ColumnsCount
-	csvReader.LazyQuotes = cr.LazyQuotes
+		return
+	}
 
 ForLoop:
 	for {
 		select {
 		case <-ctx.Done():
 			if ctx.Err() != nil {
-				errsChan <- fmt.Errorf(
-					"bigcsvreader: thread #%d received context error (%w)",
-					currentThreadNo, ctx.Err(),
-				)
+				rn.sendErr(fmt.Errorf(
+					"bigcsvreader: thread #%d received context error (%w: %w)",
+					currentThreadNo, ErrCanceledWithPartialData, ctx.Err(),
+				))
 			}
 
 			return
 		default:
-			line = cr.readLine(r, currentThreadNo, currentOffsetPos, errsChan)
-			if line == nil {
+			if rn.errorRate.isAborted() {
+				cr.quarantineRest(rn, lh, currentOffsetPos, offsetEnd, rn.errorRate.abortErr())
+
+				return
+			}
+			if rn.memThreshold > 0 && !rn.waitMemory(ctx) {
+				continue // context is done, let the select handle it.
+			}
+			if currentOffsetPos >= rn.dataEnd {
+				break ForLoop // end of data to read.
+			}
+			if rn.isTimedOut() {
+				stats.stopAt(currentOffsetPos)
+
 				break ForLoop
 			}
+			if rn.isLimitReached() {
+				break ForLoop
+			}
+			breaks, lastBreak := 0, -1
+			switch {
+			case rn.utf16 != nil:
+				line, size = cr.readUTF16Line(rn, lh, r, currentOffsetPos)
+			case cr.CRLFInQuotedFields || cr.MultilineQuotedFields:
+				line, breaks, lastBreak = cr.readQuotedLine(rn, lh, r, currentOffsetPos, false)
+				size = len(line)
+			default:
+				line = cr.readLine(rn, r, currentThreadNo, currentOffsetPos)
+				size = len(line)
+			}
+			if line == nil {
+				if currentOffsetPos <= offsetEnd {
+					rn.fail()
+					rn.sendErr(fmt.Errorf(
+						"bigcsvreader: thread #%d stopped at offset %d, before chunk's end offset %d (%w)",
+						currentThreadNo, currentOffsetPos, offsetEnd, ErrChunkIncomplete,
+					))
+					cr.quarantineRest(rn, lh, currentOffsetPos, offsetEnd, ErrChunkIncomplete)
+				}
 
-			// pass read line through standard go CSV reader.
-			bytesReader.Reset(line)
-			record, err := csvReader.Read()
-			if err != nil {
-				errsChan <- fmt.Errorf(
-					"bigcsvreader: thread #%d could not parse row at offset %d (%w)",
-					currentThreadNo, currentOffsetPos, err,
-				)
-				cr.Logger.Error(
-					"msg", "could not parse row", "err", err,
-					"file", cr.fileBaseName, "thread", currentThreadNo,
-					"offset", currentOffsetPos, "row", string(line),
-				)
-			} else {
-				rowsChan <- record
+				break ForLoop
+			}
+			if lastBreak >= 0 && currentOffsetPos+lastBreak > offsetEnd && currentThreadNo < len(rn.threadsInfo) &&
+				!cr.realigns() && rn.inQuotes == nil {
+				rn.sendErr(fmt.Errorf(
+					"bigcsvreader: thread #%d read row at offset %d (%w)",
+					currentThreadNo, currentOffsetPos, ErrQuotedLineBreakAtChunkBoundary,
+				))
 			}
 
-			currentOffsetPos += len(line)
+			cr.handleLine(ctx, rn, lh, line, currentOffsetPos)
+			if lh.line > 0 {
+				lh.line += int64(breaks)
+			}
+			currentOffsetPos += size
+			stats.addBytes(size)
 			if currentOffsetPos-1 > offsetEnd {
 				break ForLoop // next thread will handle eventual next lines.
 			}
+			if cr.isCutOff(lh) {
+				cr.cutOff(rn, lh, currentOffsetPos, offsetEnd)
+
+				break ForLoop
+			}
 		}
 	}
+	cr.finishLines(ctx, rn, lh, realOffsetStart, currentOffsetPos)
+	rn.closeIfEmpty(currentThreadNo)
 
 	cr.Logger.Debug(
 		"msg", "done",
@@ -258,32 +1219,345 @@ ForLoop:
 	)
 }
 
+// lineHandler holds a goroutine's state for handling the lines it reads.
+type lineHandler struct {
+	thread int
+	stats  *threadStats
+	parser lineParser
+	// chunkHash is the checksum of the lines read, nil if no manifest is written.
+	chunkHash hash.Hash
+	// line is the line number of the line to handle next, 0 if lines are not numbered.
+	line int64
+	// fkBatch holds the records waiting for their foreign keys to be looked up, nil if there is no foreign key check.
+	fkBatch *foreignKeyBatch
+	// units holds the UTF-16 code units of the line being read, see [CsvReader.Encoding].
+	units []uint16
+	// decoded holds the UTF-8 transcoding of the UTF-16 line being read.
+	decoded []byte
+	// joined holds the lines of a record having \r\n inside quoted fields, see [CsvReader.CRLFInQuotedFields].
+	joined []byte
+	// raw holds the raw bytes of the line being handled.
+	raw []byte
+	// quarantine is the sidecar file rows and ranges not emitted are dumped into, see [CsvReader.Quarantine].
+	quarantine *quarantineFile
+	// fieldsChecked is a flag indicating the fields count of the chunk's first record was checked.
+	fieldsChecked bool
+	// parseErrs is the number of lines which could not be parsed.
+	parseErrs int
+}
+
+// newLineHandler instantiates a new lineHandler for given thread.
+func (cr *CsvReader) newLineHandler(rn *Run, thread int, stats *threadStats) *lineHandler {
+	lh := &lineHandler{
+		thread: thread,
+		stats:  stats,
+		parser: cr.newLineParser(cr.ColumnsCount),
+	}
+	countPadded(lh.parser, rn)
+	if cr.Manifest != nil {
+		lh.chunkHash = sha256.New()
+	}
+	if rn.foreignKey != nil {
+		lh.fkBatch = &foreignKeyBatch{}
+	}
+	if rn.firstLines != nil {
+		lh.line = rn.firstLines[thread-1]
+	}
+
+	return lh
+}
+
+// handleLine parses, transforms and checks the line read at given offset, and emits the resulting row.
+func (cr *CsvReader) handleLine(ctx context.Context, rn *Run, lh *lineHandler, line []byte, offset int) {
+	switch {
+	case isBlankLine(line):
+		// as standard go CSV reader does, blank lines (like a trailing \r at the end of file) are skipped.
+	case rn.lazyChans != nil:
+		lh.stats.addRecord()
+		cr.emitLazyRow(rn, line, lh.thread, offset, lh.line, lh.stats)
+	default:
+		lh.stats.addRecord()
+		lh.raw = line
+		// pass read line through standard go CSV reader (or the dialect's parser).
+		record, err := lh.parser.parse(line)
+		if err != nil {
+			lh.parseErrs++
+			cr.sendParseErr(rn, lh.thread, offset, lh.line, line, err)
+			cr.quarantineRow(rn, lh, line, offset, err)
+			cr.accountRow(rn, true, lh.thread)
+		} else {
+			if rn.fieldsCount != nil && !lh.fieldsChecked {
+				cr.checkFieldsCount(rn, lh, record, offset)
+			}
+			if rn.columns != nil {
+				rn.transformRecord(record)
+			}
+			if rn.filter == nil || cr.filterRecord(rn, record, lh.thread, offset, lh.line) {
+				cr.checkAndEmitRecord(ctx, rn, lh, record, offset)
+			}
+		}
+	}
+
+	if lh.chunkHash != nil {
+		_, _ = lh.chunkHash.Write(line)
+	}
+	if lh.line > 0 {
+		lh.line++
+	}
+}
+
+// sendParseErr sends the error occurred while parsing the line read at given offset.
+func (cr *CsvReader) sendParseErr(rn *Run, thread, offset int, lineNo int64, line []byte, err error) {
+	if rn.sendErr(fmt.Errorf(
+		"bigcsvreader: thread #%d could not parse row at offset %d (%w)",
+		thread, offset, wrapLineErr(err, lineNo),
+	)) {
+		cr.Logger.Error(
+			"msg", "could not parse row", "err", err,
+			"file", cr.fileBaseName, "thread", thread,
+			"offset", offset, "row", string(line),
+		)
+	}
+}
+
+// checkAndEmitRecord checks the record and emits it, if valid.
+func (cr *CsvReader) checkAndEmitRecord(ctx context.Context, rn *Run, lh *lineHandler, record []string, offset int) {
+	err := cr.checkRecord(rn, record, lh.thread, offset, lh.line)
+	if err != nil {
+		cr.accountRow(rn, true, lh.thread)
+		cr.quarantineRow(rn, lh, lh.raw, offset, err)
+
+		return
+	}
+	if lh.fkBatch == nil {
+		decoded := cr.emitRecord(rn, record, lh.thread, offset, lh.line, lh.stats)
+		cr.accountRow(rn, !decoded, lh.thread)
+
+		return
+	}
+	// the record is accounted once its foreign key was looked up.
+	var raw []byte
+	if rn.quarantine != nil {
+		raw = slices.Clone(lh.raw) // line's buffer is reused by next reads.
+	}
+	if rn.foreignKey.add(lh.fkBatch, record, raw, offset, lh.line) {
+		cr.flushForeignKeys(ctx, rn, lh)
+	}
+}
+
+// finishLines flushes the records waiting for their foreign keys to be looked up,
+// and records the [start, end) chunk read, if a manifest is written.
+func (cr *CsvReader) finishLines(ctx context.Context, rn *Run, lh *lineHandler, start, end int) {
+	if lh.fkBatch != nil {
+		cr.flushForeignKeys(ctx, rn, lh)
+	}
+	if lh.chunkHash != nil {
+		rn.addChunk(ManifestChunk{
+			Thread:      lh.thread,
+			OffsetStart: start,
+			OffsetEnd:   end,
+			RowsCount:   lh.stats.rows(),
+			SHA256:      hex.EncodeToString(lh.chunkHash.Sum(nil)),
+		})
+	}
+}
+
+// emitRecord pushes the record into the thread's rows channel, or, if the run decodes rows,
+// decodes it with [CsvReader.Decoder] and pushes the decoded value into the thread's decoded channel.
+// It returns false if the record could not be decoded (a bad row).
+func (cr *CsvReader) emitRecord(rn *Run, record []string, thread, offset int, line int64, stats *threadStats) bool {
+	var value any
+	if rn.decodedChans != nil {
+		var err error
+		if value, err = cr.Decoder(record); err != nil {
+			if rn.sendErr(fmt.Errorf(
+				"bigcsvreader: thread #%d could not decode row at offset %d (%w)",
+				thread, offset, wrapLineErr(err, line),
+			)) {
+				cr.Logger.Error(
+					"msg", "could not decode row", "err", err,
+					"file", cr.fileBaseName, "thread", thread,
+					"offset", offset,
+				)
+			}
+
+			return false
+		}
+	}
+	if !rn.takeRow() {
+		return true
+	}
+	if rn.colStats != nil {
+		rn.colStats[thread-1].add(record)
+	}
+	switch {
+	case rn.rings != nil:
+		rn.rings[thread-1].Push(record)
+	case rn.metaChans != nil:
+		rn.metaChans[thread-1] <- Row{
+			Fields:          record,
+			Offset:          offset,
+			Line:            line,
+			ChunkIndex:      thread - 1,
+			RowIndexInChunk: stats.rows(),
+		}
+		stats.observeChan(len(rn.metaChans[thread-1]))
+	case rn.decodedChans != nil:
+		rn.decodedChans[thread-1] <- value
+		stats.observeChan(len(rn.decodedChans[thread-1]))
+	default:
+		rn.rowsChans[thread-1] <- record
+		stats.observeChan(len(rn.rowsChans[thread-1]))
+	}
+	stats.addRow()
+
+	return true
+}
+
+// emitHeaderRow pushes a copy of the header into the first thread's channel, see [CsvReader.EmitHeader].
+func (cr *CsvReader) emitHeaderRow(rn *Run) {
+	header := slices.Clone(rn.header)
+	switch {
+	case rn.rings != nil:
+		rn.rings[0].Push(header)
+	case rn.metaChans != nil:
+		var line int64
+		if rn.firstLines != nil {
+			line = 1
+		}
+		rn.metaChans[0] <- Row{Fields: header, Line: line, RowIndexInChunk: -1, Header: true}
+	default:
+		rn.rowsChans[0] <- header
+	}
+}
+
+// accountRow accounts the read row into the bad rows rate, if it is tracked.
+func (cr *CsvReader) accountRow(rn *Run, bad bool, thread int) {
+	if rn.errorRate == nil {
+		return
+	}
+	if err := rn.errorRate.add(bad); err != nil {
+		if rn.sendErr(fmt.Errorf("bigcsvreader: thread #%d aborted reading (%w)", thread, err)) {
+			cr.Logger.Error(
+				"msg", "aborted reading", "err", err,
+				"file", cr.fileBaseName, "thread", thread,
+			)
+		}
+	}
+}
+
+// filterRecord returns whether the record matches [CsvReader.Filter].
+// An error occurred while evaluating the filter is sent through ErrsChan, and the record does not match.
+// Records which do not match are accounted here, matching ones are accounted after being checked.
+func (cr *CsvReader) filterRecord(rn *Run, record []string, thread, offset int, line int64) bool {
+	match, err := rn.filter.Match(record)
+	if err != nil {
+		if rn.sendErr(fmt.Errorf(
+			"bigcsvreader: thread #%d could not filter row at offset %d (%w)",
+			thread, offset, wrapLineErr(err, line),
+		)) {
+			cr.Logger.Error(
+				"msg", "could not filter row", "err", err,
+				"file", cr.fileBaseName, "thread", thread,
+				"offset", offset,
+			)
+		}
+	}
+	if !match {
+		cr.accountRow(rn, err != nil, thread)
+	}
+
+	return match
+}
+
+// checkRecord validates the record, if there are validation rules, and checks its key uniqueness,
+// if there is an unique constraint.
+// The eventual validation error is sent through ErrsChan and returned.
+func (cr *CsvReader) checkRecord(rn *Run, record []string, thread, offset int, line int64) error {
+	if !rn.validate && rn.unique == nil && !cr.ValidateUTF8 {
+		return nil
+	}
+	var err error
+	if cr.ValidateUTF8 {
+		err = validateUTF8(record, offset)
+	}
+	if err == nil && rn.validate {
+		err = rn.validateRecord(record, offset)
+	}
+	if err == nil && rn.unique != nil {
+		err = rn.unique.check(record, offset)
+	}
+	if err != nil {
+		err = wrapLineErr(err, line)
+		if rn.sendErr(fmt.Errorf(
+			"bigcsvreader: thread #%d invalid row at offset %d (%w)",
+			thread, offset, err,
+		)) {
+			cr.Logger.Error(
+				"msg", "invalid row", "err", err,
+				"file", cr.fileBaseName, "thread", thread,
+				"offset", offset,
+			)
+		}
+	}
+
+	return err
+}
+
 // openFile returns the fd of CSV file or nil if the file could not be opened.
-func (cr *CsvReader) openFile(thread int, errsChan chan<- error) *os.File {
-	f, err := os.Open(cr.filePath)
+// If the file is pinned, it also checks the opened file is the pinned one.
+func (cr *CsvReader) openFile(rn *Run, thread int) internal.File {
+	pin := rn.pin
+	filePath := cr.filePath
+	if pin != nil {
+		filePath = pin.path
+	}
+	f, err := cr.FS.Open(filePath)
 	if err == nil {
-		return f
+		if pin == nil {
+			return f
+		}
+		if err = pin.check(f); err == nil {
+			return f
+		}
+		_ = f.Close()
+		rn.fail()
+		if rn.sendErr(fmt.Errorf(
+			"bigcsvreader: thread #%d detected file change (%w)",
+			thread, err,
+		)) {
+			cr.Logger.Error(
+				"msg", "file changed", "err", err,
+				"file", cr.fileBaseName, "thread", thread,
+			)
+		}
+
+		return nil
 	}
 
-	errsChan <- fmt.Errorf(
+	rn.fail()
+	if rn.sendErr(fmt.Errorf(
 		"bigcsvreader: thread #%d could not open file (%w)",
 		thread, err,
-	)
-	cr.Logger.Error(
-		"msg", "could not open file", "err", err,
-		"file", cr.fileBaseName, "thread", thread,
-	)
+	)) {
+		cr.Logger.Error(
+			"msg", "could not open file", "err", err,
+			"file", cr.fileBaseName, "thread", thread,
+		)
+	}
 
 	return nil
 }
 
 // readLine reads returns a row from file, or nil if something bad happens or [io.EOF] is encountered.
-func (cr *CsvReader) readLine(r *bufio.Reader, thread, offsetPos int, errsChan chan<- error) []byte {
+// The file's last line is returned as it is, even if it lacks the end line delimiter, or ends with a lone \r
+// (old Mac style), which parsers drop, like a \r preceding \n.
+func (cr *CsvReader) readLine(rn *Run, r *bufio.Reader, thread, offsetPos int) []byte {
 	// did not use [bufio.Reader.ReadLine] as it disregards end line delimiter(s) (\n / \r\n)
 	// and we need the whole line length in advancing offset.
 	// [bufio.Reader.ReadSlice] also has the advantage of returning the subslice of buffered bytes,
 	// without allocating another slice.
-	line, err := r.ReadSlice('\n')
+	line, err := cr.readSlice(r)
 	if err == nil {
 		return line
 	}
@@ -292,27 +1566,77 @@ func (cr *CsvReader) readLine(r *bufio.Reader, thread, offsetPos int, errsChan c
 			return line
 		}
 	} else {
-		errsChan <- fmt.Errorf(
-			"bigcsvreader: thread #%d could not read line at offset %d (%w)",
-			thread, offsetPos, err,
-		)
-		cr.Logger.Error(
-			"msg", "could not read line", "err", err,
-			"file", cr.fileBaseName, "thread", thread,
-			"offset", offsetPos,
-		)
+		cr.sendReadErr(rn, thread, offsetPos, err)
 	}
 
 	return nil
 }
 
+// readSlice reads a line, end line delimiter included, from r's buffer, or, if it does not fit into it
+// and [CsvReader.AutoGrowBuffer] is enabled, from a newly allocated slice, see [CsvReader.readLongLine].
+func (cr *CsvReader) readSlice(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadSlice('\n')
+	if cr.AutoGrowBuffer && errors.Is(err, bufio.ErrBufferFull) {
+		return cr.readLongLine(r, line)
+	}
+
+	return line, err
+}
+
+// readLongLine reads the rest of a line which does not fit into r's buffer, see [CsvReader.AutoGrowBuffer].
+// The line is accumulated into a newly allocated slice, given its beginning (r's whole buffer).
+// [bufio.ErrBufferFull] is returned if the line exceeds [CsvReader.MaxLineSize].
+func (cr *CsvReader) readLongLine(r *bufio.Reader, start []byte) ([]byte, error) {
+	line := append(make([]byte, 0, 2*len(start)), start...)
+	for {
+		part, err := r.ReadSlice('\n')
+		if len(line)+len(part) > cr.maxRecordSize() {
+			return nil, bufio.ErrBufferFull
+		}
+		line = append(line, part...)
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return line, err
+		}
+	}
+}
+
+// maxRecordSize returns the maximum size of a line or record, see [CsvReader.AutoGrowBuffer].
+func (cr *CsvReader) maxRecordSize() int {
+	switch {
+	case !cr.AutoGrowBuffer:
+		return cr.BufferSize
+	case cr.MaxLineSize > 0:
+		return cr.MaxLineSize
+	default:
+		return math.MaxInt
+	}
+}
+
+// isBlankLine returns whether the line holds nothing but its end line delimiter (\n, \r\n, or a lone \r at EOF).
+func isBlankLine(line []byte) bool {
+	for _, c := range line {
+		if c != '\r' && c != '\n' {
+			return false
+		}
+	}
+
+	return true
+}
+
 // getFileSize returns file's size as each goroutine will
 // read approx. fileSize/totalGoroutines bytes.
 func (cr *CsvReader) getFileSize() (int, error) {
-	fileInfo, err := os.Stat(cr.filePath)
+	fileInfo, err := cr.FS.Stat(cr.filePath)
 	if err != nil {
 		return 0, err
 	}
+
+	return checkFileSize(fileInfo)
+}
+
+// checkFileSize returns the size of the file described by given info,
+// or [ErrEmptyFile] if the file is empty.
+func checkFileSize(fileInfo os.FileInfo) (int, error) {
 	fileSize := int(fileInfo.Size())
 	if fileSize < 1 {
 		return 0, ErrEmptyFile
@@ -11,6 +11,7 @@ import (
 	"errors"
 	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"strconv"
@@ -36,7 +37,10 @@ func TestCsvReader(t *testing.T) {
 	t.Run("context is canceled", testCsvReaderWithContextCanceled)
 	t.Run("invalid row", testCsvReaderWithInvalidRow)
 	t.Run("small buffer size", testCsvReaderWithSmallBufferSize)
+	t.Run("auto-growing buffer", testCsvReaderWithAutoGrowBuffer)
 	t.Run("quotes in unquoted field", testCsvReaderWithLazyQuotes)
+	t.Run("pinned file through symlink", testCsvReaderWithPinnedSymlink)
+	t.Run("concurrent reads", testCsvReaderWithConcurrentReads)
 }
 
 func testCsvReaderByHeader(withHeader bool) func(t *testing.T) {
@@ -224,6 +228,57 @@ func testCsvReaderWithLazyQuotes(t *testing.T) {
 	}
 }
 
+func testCsvReaderWithConcurrentReads(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	otherFilePath := filepath.Join(t.TempDir(), "other.csv")
+	if err := os.WriteFile(otherFilePath, []byte("1,Jim,41\n2,Joe,52\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/file_without_header.csv")
+	subject.ColumnsCount = 3
+	subject.MaxGoroutinesNo = 2
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+	filesPaths := []string{"", otherFilePath, "", otherFilePath, ""} // empty path means the reader's file.
+	var (
+		wg      sync.WaitGroup
+		results = make([][][]string, len(filesPaths))
+		errs    = make([]error, len(filesPaths))
+	)
+
+	// act
+	wg.Add(len(filesPaths))
+	for i, filePath := range filesPaths {
+		go func(i int, filePath string) {
+			defer wg.Done()
+			var (
+				rowsChans []bigcsvreader.RowsChan
+				errsChan  bigcsvreader.ErrsChan
+			)
+			if filePath == "" {
+				rowsChans, errsChan = subject.Read(ctx)
+			} else {
+				rowsChans, errsChan = subject.ReadFile(ctx, filePath)
+			}
+			results[i], errs[i] = gatherRecords(rowsChans, errsChan)
+		}(i, filePath)
+	}
+	wg.Wait()
+
+	// assert
+	for i, filePath := range filesPaths {
+		assertNil(t, errs[i])
+		if filePath == "" {
+			assertEqual(t, 5, len(results[i]))
+		} else {
+			assertEqual(t, 2, len(results[i]))
+		}
+	}
+}
+
 func testCsvReaderWithInvalidRow(t *testing.T) {
 	t.Parallel()
 
@@ -274,7 +329,7 @@ func testCsvReaderWithSmallBufferSize(t *testing.T) {
 	subject := bigcsvreader.New()
 	subject.SetFilePath("testdata/file_without_header.csv")
 	subject.ColumnsCount = 3
-	subject.BufferSize = 16 // min buffer size set by bufio - Ronaldinho line has len 17 and err should arise
+	subject.BufferSize = 16 // min buffer size set by bufio - Ronaldinho line has len 17, buffer is grown to fit it
 
 	ctx, cancelCtx := context.WithCancel(context.Background())
 	defer cancelCtx()
@@ -284,10 +339,93 @@ func testCsvReaderWithSmallBufferSize(t *testing.T) {
 	records, err := gatherRecords(rowsChans, errsChan)
 
 	// assert
-	if assertNotNil(t, err) {
-		assertTrue(t, strings.Contains(err.Error(), "buffer full"))
+	assertNil(t, err)
+	assertEqual(t, 5, len(records))
+}
+
+func testCsvReaderWithAutoGrowBuffer(t *testing.T) {
+	t.Parallel()
+
+	longName := strings.Repeat("Ronaldinho", 50) // many times the buffer size.
+	filePath := writeTmpFile(t, t.TempDir(), "long_lines.csv", "1,John,33\n2,"+longName+",23\n3,Mike,18\n")
+	tests := [...]struct {
+		name            string
+		maxLineSize     int
+		expectedRecords [][]string
+		expectedErr     error
+	}{
+		{
+			name:            "no cap",
+			expectedRecords: [][]string{{"1", "John", "33"}, {"2", longName, "23"}, {"3", "Mike", "18"}},
+		},
+		{
+			name:            "line fits into cap",
+			maxLineSize:     len(longName) + 6,
+			expectedRecords: [][]string{{"1", "John", "33"}, {"2", longName, "23"}, {"3", "Mike", "18"}},
+		},
+		{
+			name:        "line exceeds cap",
+			maxLineSize: len(longName),
+			expectedErr: bigcsvreader.ErrBufferFull,
+		},
 	}
-	assertNil(t, records)
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(filePath)
+			subject.ColumnsCount = 3
+			subject.MaxGoroutinesNo = 1
+			subject.BufferSize = 16
+			subject.AutoGrowBuffer = true
+			subject.MaxLineSize = test.maxLineSize
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			rowsChans, errsChan := subject.Read(ctx)
+			records, err := gatherRecords(rowsChans, errsChan)
+
+			// assert
+			if test.expectedErr != nil {
+				assertTrue(t, errors.Is(err, test.expectedErr))
+			} else {
+				assertNil(t, err)
+				assertEqual(t, test.expectedRecords, records)
+			}
+		})
+	}
+}
+
+func testCsvReaderWithPinnedSymlink(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	target, err := filepath.Abs("testdata/file_without_header.csv")
+	if err != nil {
+		t.Fatalf("prerequisite failed: %v", err)
+	}
+	link := filepath.Join(t.TempDir(), "link.csv")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	subject := bigcsvreader.New()
+	subject.SetFilePath(link)
+	subject.ColumnsCount = 3
+	subject.PinFile = true
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	rowsChans, errsChan := subject.Read(ctx)
+	records, err := gatherRecords(rowsChans, errsChan)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 5, len(records))
 }
 
 // gatherRecords returns the rows from big csv reader, or an error if something bad happened.
@@ -602,3 +740,42 @@ func Benchmark50000Rows_50Mb_withStdGoCsvReaderReadOneByOneAndReuseRecord(b *tes
 func Benchmark50000Rows_50Mb_withStdGoCsvReaderReadOneByOneProcessParalell(b *testing.B) {
 	benchmarkStdGoCsvReaderReadOneByOneProcessParalell(5e4)(b)
 }
+
+func Benchmark20Rows_withBigCsvReader(b *testing.B) {
+	benchmarkBigCsvReaderOneGoroutine(20)(b)
+}
+
+func Benchmark10000Rows_withBigCsvReaderOneGoroutine(b *testing.B) {
+	benchmarkBigCsvReaderOneGoroutine(1e4)(b)
+}
+
+// benchmarkBigCsvReaderOneGoroutine measures the per row overhead of reading with a single goroutine
+// (rows are not processed).
+func benchmarkBigCsvReaderOneGoroutine(rowsCount int64) func(b *testing.B) {
+	return func(b *testing.B) {
+		fName, err := setUpTmpCsvFile(rowsCount)
+		if err != nil {
+			b.Fatalf("prerequisite failed: could not generate CSV file: %v", err)
+		}
+		defer tearDownTmpCsvFile(fName)
+		subject := bigcsvreader.New()
+		subject.SetFilePath(fName)
+		subject.ColumnsCount = 5
+		subject.MaxGoroutinesNo = 1
+		ctx, cancelCtx := context.WithCancel(context.Background())
+		defer cancelCtx()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for n := 0; n < b.N; n++ {
+			rowsChans, errsChan := subject.Read(ctx)
+			count := int64(drainRows(rowsChans))
+			if count != rowsCount {
+				b.Errorf("expected %d, but got %d", rowsCount, count)
+			}
+			for range errsChan {
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"errors"
+	"io"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+// realignProbeRecords is the number of consecutive records which must be parsed successfully
+// from a position, for a goroutine to start reading its chunk from there.
+const realignProbeRecords = 3
+
+// realigns returns whether goroutines realign their chunk's start, see [CsvReader.RealignChunks].
+func (cr *CsvReader) realigns() bool {
+	return cr.RealignChunks && cr.ColumnsCount > 0
+}
+
+// realignChunk returns the offset the goroutine should start reading its chunk from, see [CsvReader.RealignChunks]:
+// given start offset (the one following the partial line skipped), if records can be parsed from there,
+// or the start of the first next line, at most [CsvReader.BufferSize] bytes further, records can be parsed from.
+func (cr *CsvReader) realignChunk(rn *Run, f internal.File, thread, start, offsetEnd int) int {
+	if cr.probeRecords(rn, f, start) {
+		return start
+	}
+
+	limit := min(offsetEnd+1, start+cr.BufferSize, rn.dataEnd)
+	r := bufio.NewReaderSize(io.NewSectionReader(f, int64(start), int64(limit-start)), cr.BufferSize)
+	pos := start
+	for {
+		line, err := r.ReadSlice('\n')
+		pos += len(line)
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+		if err != nil || pos >= limit {
+			return start // no alignment found, let the parser report the errors.
+		}
+		if cr.probeRecords(rn, f, pos) {
+			cr.Logger.Debug(
+				"msg", "realigned chunk", "file", cr.fileBaseName, "thread", thread,
+				"offset", start, "realignedOffset", pos,
+			)
+
+			return pos
+		}
+	}
+}
+
+// probeRecords checks whether [realignProbeRecords] records (or the ones left until the end of data)
+// can be parsed from given offset, each having [CsvReader.ColumnsCount] fields.
+func (cr *CsvReader) probeRecords(rn *Run, f internal.File, offset int) bool {
+	var (
+		r      = bufio.NewReaderSize(io.NewSectionReader(f, int64(offset), int64(rn.dataEnd-offset)), cr.BufferSize)
+		parser = cr.newLineParser(cr.ColumnsCount)
+		joined []byte
+	)
+	for parsed := 0; parsed < realignProbeRecords; {
+		line, err := r.ReadSlice('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return false
+		}
+		if cr.CRLFInQuotedFields || cr.MultilineQuotedFields {
+			joined = append(joined[:0], line...)
+			for err == nil && cr.continuesRecord(joined, false) {
+				line, err = r.ReadSlice('\n')
+				if err != nil && !errors.Is(err, io.EOF) {
+					return false
+				}
+				joined = append(joined, line...)
+			}
+			line = joined
+		}
+		if len(line) == 0 {
+			return parsed > 0 // end of data.
+		}
+		if !isBlankLine(line) {
+			if _, parseErr := parser.parse(line); parseErr != nil {
+				return false
+			}
+			parsed++
+		}
+		if err != nil {
+			return true // end of data.
+		}
+	}
+
+	return true
+}
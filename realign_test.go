@@ -0,0 +1,83 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_RealignChunks(t *testing.T) {
+	t.Parallel()
+
+	// a record holding a long multiline note spans over the middle of the file,
+	// so the second goroutine's chunk starts inside its quoted field.
+	var content strings.Builder
+	for i := 1; i <= 100; i++ {
+		fmt.Fprintf(&content, "%d,note %d,x\r\n", i, i)
+	}
+	content.WriteString(`101,"`)
+	for i := 1; i <= 200; i++ {
+		fmt.Fprintf(&content, "note line %d\r\n", i)
+	}
+	content.WriteString("end\",x\r\n")
+	for i := 102; i <= 201; i++ {
+		fmt.Fprintf(&content, "%d,note %d,x\r\n", i, i)
+	}
+	filePath := writeTmpFile(t, t.TempDir(), "notes.csv", content.String())
+
+	tests := [...]struct {
+		name           string
+		realignChunks  bool
+		expectedRowsNo int
+	}{
+		{
+			name:           "chunks are realigned",
+			realignChunks:  true,
+			expectedRowsNo: 201,
+		},
+		{
+			// the quotes before the second goroutine's start are counted ahead of reading.
+			name:           "chunks are not realigned",
+			expectedRowsNo: 201,
+		},
+	}
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(filePath)
+			subject.ColumnsCount = 3
+			subject.MaxGoroutinesNo = 2
+			subject.BufferSize = 8192
+			subject.CRLFInQuotedFields = true
+			subject.RealignChunks = test.realignChunks
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			run := subject.Start(ctx)
+			rows := gatherRowsInOrder(run.RowsChans())
+			err := run.Err()
+
+			// assert
+			assertNil(t, err)
+			if assertEqual(t, test.expectedRowsNo, len(rows)) {
+				assertEqual(t, "101", rows[100][0])
+				assertTrue(t, strings.HasSuffix(rows[100][1], "note line 200\nend"))
+				assertEqual(t, []string{"102", "note 102", "x"}, rows[101])
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+// HeaderIndex indexes the columns of a header by their names, so rows' values can be looked up by column name.
+// It is safe for concurrent use.
+type HeaderIndex struct {
+	header  []string
+	indexes map[string]int
+}
+
+// NewHeaderIndex returns the index of given header's columns.
+// If a name is found several times in the header, its first column is indexed.
+func NewHeaderIndex(header []string) *HeaderIndex {
+	indexes := make(map[string]int, len(header))
+	for col, name := range header {
+		if _, found := indexes[name]; !found {
+			indexes[name] = col
+		}
+	}
+
+	return &HeaderIndex{header: header, indexes: indexes}
+}
+
+// Header returns the indexed header.
+func (hi *HeaderIndex) Header() []string {
+	return hi.header
+}
+
+// Index returns the (0-based) index of the column having given name,
+// and whether the header has such a column.
+func (hi *HeaderIndex) Index(name string) (int, bool) {
+	col, found := hi.indexes[name]
+
+	return col, found
+}
+
+// Record wraps given row's fields into a [Record], whose values can be looked up by column name.
+func (hi *HeaderIndex) Record(fields []string) Record {
+	return Record{Fields: fields, index: hi}
+}
+
+// Record is a row whose values can be looked up by column name, see [Run.Record].
+type Record struct {
+	// Fields holds the row's values.
+	Fields []string
+	// index is the header index columns names are resolved against.
+	index *HeaderIndex
+}
+
+// ByName returns the value of the column having given name,
+// or empty string if there is no such column, see also [Record.Lookup].
+func (r Record) ByName(name string) string {
+	value, _ := r.Lookup(name)
+
+	return value
+}
+
+// Lookup returns the value of the column having given name, and whether the record has such a column.
+func (r Record) Lookup(name string) (string, bool) {
+	if r.index == nil {
+		return "", false
+	}
+	col, found := r.index.Index(name)
+	if !found || col >= len(r.Fields) {
+		return "", false
+	}
+
+	return r.Fields[col], true
+}
+
+// Record wraps given row (read by the run) into a [Record], whose values can be looked up
+// by the names of the file's header columns (like record.ByName("price")).
+// If the file has no header (see [CsvReader.FileHasHeader]), lookups find nothing.
+func (rn *Run) Record(fields []string) Record {
+	return Record{Fields: fields, index: rn.headerIndex}
+}
@@ -0,0 +1,100 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestRun_Record(t *testing.T) {
+	t.Parallel()
+
+	t.Run("values are looked up by header names", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.ColumnsDelimiter = ';'
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		var (
+			mu    sync.Mutex
+			wg    sync.WaitGroup
+			names = make(map[string]string)
+		)
+		for _, rowsChan := range run.RowsChans() {
+			wg.Add(1)
+			go func(rowsChan bigcsvreader.RowsChan) {
+				defer wg.Done()
+				for row := range rowsChan {
+					record := run.Record(row)
+					mu.Lock()
+					names[record.ByName("ID")] = record.ByName("Name")
+					mu.Unlock()
+				}
+			}(rowsChan)
+		}
+		wg.Wait()
+
+		// assert
+		assertNil(t, run.Err())
+		assertEqual(t, []string{"ID", "Name", "Age"}, run.Header())
+		assertEqual(t, 5, len(names))
+		assertEqual(t, "Ronaldinho", names["4"])
+	})
+
+	t.Run("file without header", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_without_header.csv")
+		subject.ColumnsCount = 3
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		drainRows(run.RowsChans())
+		value, found := run.Record([]string{"1", "John", "33"}).Lookup("Name")
+
+		// assert
+		assertNil(t, run.Err())
+		assertEqual(t, "", value)
+		assertEqual(t, false, found)
+	})
+}
+
+func TestRecord_Lookup(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	index := bigcsvreader.NewHeaderIndex([]string{"id", "price", "id", "note"})
+	record := index.Record([]string{"1", "9.99", "2"})
+
+	// act & assert
+	col, found := index.Index("id")
+	assertEqual(t, 0, col)
+	assertTrue(t, found)
+	assertEqual(t, "9.99", record.ByName("price"))
+	assertEqual(t, "1", record.ByName("id"))
+	value, found := record.Lookup("note") // short row.
+	assertEqual(t, "", value)
+	assertEqual(t, false, found)
+	_, found = record.Lookup("qty")
+	assertEqual(t, false, found)
+}
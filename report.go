@@ -0,0 +1,144 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// maxReportErrors is the maximum number of errors messages a run report holds.
+const maxReportErrors = 20
+
+// RunConfig holds the reader's main settings a run was started with, see [Summary.Config].
+type RunConfig struct {
+	// RunLabel is [CsvReader.RunLabel].
+	RunLabel string `json:"runLabel,omitempty"`
+	// MaxGoroutinesNo is [CsvReader.MaxGoroutinesNo].
+	MaxGoroutinesNo int `json:"maxGoroutinesNo"`
+	// FileHasHeader is [CsvReader.FileHasHeader].
+	FileHasHeader bool `json:"fileHasHeader"`
+	// ColumnsCount is [CsvReader.ColumnsCount].
+	ColumnsCount int `json:"columnsCount"`
+	// ColumnsDelimiter is [CsvReader.ColumnsDelimiter].
+	ColumnsDelimiter string `json:"columnsDelimiter"`
+	// BufferSize is [CsvReader.BufferSize].
+	BufferSize int `json:"bufferSize"`
+	// ChanBufferSize is [CsvReader.ChanBufferSize].
+	ChanBufferSize int `json:"chanBufferSize"`
+	// LazyQuotes is [CsvReader.LazyQuotes].
+	LazyQuotes bool `json:"lazyQuotes"`
+	// TrimSpace is [CsvReader.TrimSpace].
+	TrimSpace bool `json:"trimSpace"`
+	// Encoding is the name of [CsvReader.Encoding]: "utf-8", "utf-16le" or "utf-16be".
+	Encoding string `json:"encoding"`
+	// Filter is [CsvReader.Filter].
+	Filter string `json:"filter,omitempty"`
+	// LimitRows is [CsvReader.LimitRows].
+	LimitRows int64 `json:"limitRows,omitempty"`
+	// MaxRunDuration is [CsvReader.MaxRunDuration].
+	MaxRunDuration time.Duration `json:"maxRunDurationNs,omitempty"`
+}
+
+// runConfig returns the reader's main settings.
+func (cr *CsvReader) runConfig() RunConfig {
+	encodings := [...]string{EncodingUTF8: "utf-8", EncodingUTF16LE: "utf-16le", EncodingUTF16BE: "utf-16be"}
+	encoding := "unknown"
+	if cr.Encoding >= 0 && int(cr.Encoding) < len(encodings) {
+		encoding = encodings[cr.Encoding]
+	}
+
+	return RunConfig{
+		RunLabel:         cr.RunLabel,
+		MaxGoroutinesNo:  cr.MaxGoroutinesNo,
+		FileHasHeader:    cr.FileHasHeader,
+		ColumnsCount:     cr.ColumnsCount,
+		ColumnsDelimiter: string(cr.ColumnsDelimiter),
+		BufferSize:       cr.BufferSize,
+		ChanBufferSize:   cr.ChanBufferSize,
+		LazyQuotes:       cr.LazyQuotes,
+		TrimSpace:        cr.TrimSpace,
+		Encoding:         encoding,
+		Filter:           cr.Filter,
+		LimitRows:        cr.LimitRows,
+		MaxRunDuration:   cr.MaxRunDuration,
+	}
+}
+
+// report is the JSON representation of a [Summary].
+type report struct {
+	RunID      string          `json:"runId,omitempty"`
+	File       string          `json:"file"`
+	StartedAt  time.Time       `json:"startedAt"`
+	Duration   time.Duration   `json:"durationNs"`
+	Config     RunConfig       `json:"config"`
+	Totals     reportTotals    `json:"totals"`
+	Errors     []string        `json:"errors"`
+	Threads    []ThreadSummary `json:"threads"`
+	Successful bool            `json:"successful"`
+}
+
+// reportTotals holds the totals of a run report.
+type reportTotals struct {
+	RowsCount         int64         `json:"rowsCount"`
+	BytesCount        int64         `json:"bytesCount"`
+	DataSize          int64         `json:"dataSize"`
+	Coverage          float64       `json:"coverage"`
+	ErrorsCount       int           `json:"errorsCount"`
+	ErrsChanHighWater int           `json:"errsChanHighWater"`
+	TimedOut          bool          `json:"timedOut"`
+	PatternViolations map[int]int64 `json:"patternViolations,omitempty"`
+}
+
+// MarshalJSON returns the machine-readable run report: the reader's settings, the totals,
+// each goroutine's statistics, and a sample of the errors (the first ones, the "totals.errorsCount"
+// field holding their total number). Durations are expressed in nanoseconds.
+func (s Summary) MarshalJSON() ([]byte, error) {
+	rep := report{
+		RunID:     s.RunID,
+		File:      s.File,
+		StartedAt: s.StartedAt,
+		Duration:  s.Duration,
+		Config:    s.Config,
+		Totals: reportTotals{
+			RowsCount:         s.RowsCount,
+			BytesCount:        s.BytesCount,
+			DataSize:          s.DataSize,
+			Coverage:          s.Coverage(),
+			ErrorsCount:       s.ErrorsCount,
+			ErrsChanHighWater: s.ErrsChanHighWater,
+			TimedOut:          s.TimedOut,
+			PatternViolations: s.PatternViolations,
+		},
+		Errors:     make([]string, 0),
+		Threads:    s.Threads,
+		Successful: s.Err == nil && !s.TimedOut,
+	}
+	if rep.Threads == nil {
+		rep.Threads = make([]ThreadSummary, 0)
+	}
+	if s.Err != nil {
+		errs := []error{s.Err}
+		if joined, ok := s.Err.(interface{ Unwrap() []error }); ok {
+			errs = joined.Unwrap()
+		}
+		for _, err := range errs[:min(len(errs), maxReportErrors)] {
+			rep.Errors = append(rep.Errors, err.Error())
+		}
+	}
+
+	return json.Marshal(rep)
+}
+
+// WriteReport writes the machine-readable run report (see [Summary.MarshalJSON]) into w,
+// as indented JSON, suitable for attaching to job logs and audits.
+func (s Summary) WriteReport(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(s)
+}
@@ -0,0 +1,152 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ReadReverse extracts asynchronously CSV rows from the end of the file backwards,
+// like [CsvReader.Read] does otherwise.
+// Each started goroutine scans its chunk of the file backwards, and the returned channels
+// are ordered from the last chunk to the first, so consuming them in order yields
+// the rows in reverse file order ("most recent first" for append-only files).
+// Note: [CsvReader.ReadLazy] and [CsvReader.ReadDecoded] have no reverse counterpart.
+func (cr *CsvReader) ReadReverse(ctx context.Context) ([]RowsChan, ErrsChan) {
+	rn := cr.start(ctx, modeReverse, nil)
+	rowsChans := rn.RowsChans()
+	for i, j := 0, len(rowsChans)-1; i < j; i, j = i+1, j-1 {
+		rowsChans[i], rowsChans[j] = rowsChans[j], rowsChans[i]
+	}
+
+	return rowsChans, rn.ErrsChan()
+}
+
+// readBetweenOffsetsBackwardAsync reads backwards the piece of file allocated to a given thread.
+// It handles the same lines [CsvReader.readBetweenOffsetsAsync] would.
+func (cr *CsvReader) readBetweenOffsetsBackwardAsync(
+	ctx context.Context,
+	rn *Run,
+	currentThreadNo, offsetStart, offsetEnd int,
+	wg *sync.WaitGroup,
+) {
+	defer wg.Done()
+	stats := &rn.threads[currentThreadNo-1]
+	defer stats.markDone()
+
+	f := cr.openFile(rn, currentThreadNo)
+	if f == nil {
+		return
+	}
+	defer f.Close()
+
+	// the thread handles the lines starting in the (offsetStart, offsetEnd+1] interval,
+	// the first goroutine handling also the line starting right at the beginning of data.
+	realOffsetStart := offsetStart
+	if currentThreadNo != 1 {
+		line, err := readLineAt(f, offsetStart)
+		if err != nil {
+			cr.sendReadErr(rn, currentThreadNo, offsetStart, err)
+
+			return
+		}
+		realOffsetStart += len(line)
+	}
+	realOffsetEnd := rn.dataEnd
+	if currentThreadNo != len(rn.threadsInfo) {
+		line, err := readLineAt(f, offsetEnd+1)
+		if err != nil {
+			cr.sendReadErr(rn, currentThreadNo, offsetEnd+1, err)
+
+			return
+		}
+		realOffsetEnd = min(offsetEnd+1+len(line), rn.dataEnd)
+	}
+
+	lh := cr.newLineHandler(rn, currentThreadNo, stats)
+	defer cr.closeQuarantine(rn, lh)
+	var (
+		pos   = realOffsetEnd // offset of the data in buf.
+		buf   []byte          // data not handled yet, ending at end.
+		end   = realOffsetEnd // offset of the end of the last line not handled yet.
+		chunk = make([]byte, cr.BufferSize)
+	)
+	for end > realOffsetStart {
+		select {
+		case <-ctx.Done():
+			rn.sendErr(fmt.Errorf(
+				"bigcsvreader: thread #%d received context error (%w: %w)",
+				currentThreadNo, ErrCanceledWithPartialData, ctx.Err(),
+			))
+
+			return
+		default:
+		}
+		if rn.errorRate.isAborted() || rn.isLimitReached() {
+			return
+		}
+		if rn.memThreshold > 0 && !rn.waitMemory(ctx) {
+			continue // context is done, let the select handle it.
+		}
+
+		// look for the start of the last line in buf (after the previous end line delimiter).
+		lineEnd := end - pos
+		lineStart := bytes.LastIndexByte(buf[:max(lineEnd-1, 0)], '\n') + 1
+		if lineStart == 0 && pos > realOffsetStart {
+			// line starts before buf, read the previous piece of data.
+			n := min(len(chunk), pos-realOffsetStart)
+			if read, err := f.ReadAt(chunk[:n], int64(pos-n)); err != nil && (read < n || !errors.Is(err, io.EOF)) {
+				cr.sendReadErr(rn, currentThreadNo, pos-n, err)
+
+				return
+			}
+			buf = append(append(make([]byte, 0, n+lineEnd), chunk[:n]...), buf[:lineEnd]...)
+			pos -= n
+
+			continue
+		}
+		line := buf[lineStart:lineEnd]
+		cr.handleLine(ctx, rn, lh, line, pos+lineStart)
+		stats.addBytes(len(line))
+		end = pos + lineStart
+	}
+	if currentThreadNo == 1 && rn.emitHeader {
+		cr.emitHeaderRow(rn)
+	}
+	cr.finishLines(ctx, rn, lh, realOffsetStart, realOffsetEnd)
+
+	cr.Logger.Debug(
+		"msg", "done",
+		"file", cr.fileBaseName, "thread", currentThreadNo,
+		"offsetStart", offsetStart, "offsetEnd", offsetEnd,
+		"realOffsetStart", realOffsetStart, "realOffsetEnd", realOffsetEnd-1,
+		"bytesCount", realOffsetEnd-realOffsetStart,
+	)
+}
+
+// sendReadErr sends the error occurred while reading at given offset.
+func (cr *CsvReader) sendReadErr(rn *Run, thread, offset int, err error) {
+	rn.fail()
+	if errors.Is(err, bufio.ErrBufferFull) {
+		err = fmt.Errorf("%w: %w", ErrBufferFull, err)
+	}
+	if rn.sendErr(fmt.Errorf(
+		"bigcsvreader: thread #%d could not read line at offset %d (%w)",
+		thread, offset, err,
+	)) {
+		cr.Logger.Error(
+			"msg", "could not read line", "err", err,
+			"file", cr.fileBaseName, "thread", thread,
+			"offset", offset,
+		)
+	}
+}
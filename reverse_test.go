@@ -0,0 +1,132 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ReadReverse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rows are read backwards", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 3000
+		filePath, err := setUpTmpCsvFile(rowsCount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tearDownTmpCsvFile(filePath)
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 5
+		subject.MaxGoroutinesNo = 8
+		subject.BufferSize = 100 // smaller than a chunk, so each chunk is read in many pieces.
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rowsChans, errsChan := subject.ReadReverse(ctx)
+		rows := gatherRowsInOrder(rowsChans)
+		err = bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertNil(t, err)
+		assertTrue(t, len(rowsChans) > 1)
+		assertEqual(t, rowsCount, len(rows))
+		for i, row := range rows {
+			if row[0] != strconv.Itoa(rowsCount-i) {
+				t.Fatalf("expected row #%d to have id %d, got %s", i, rowsCount-i, row[0])
+			}
+		}
+	})
+
+	t.Run("header and missing end line delimiter", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.ColumnsDelimiter = ';'
+		subject.BufferSize = 8
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rowsChans, errsChan := subject.ReadReverse(ctx)
+		rows := gatherRowsInOrder(rowsChans)
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, 5, len(rows))
+		for i, row := range rows {
+			assertEqual(t, strconv.Itoa(5-i), row[0])
+		}
+	})
+
+	t.Run("line breaks in quoted fields are not supported", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		filePath := writeTmpFile(t, t.TempDir(), "quoted.csv", "1,\"a\nb\"\n2,c\n3,\"d\r\ne\"\n")
+		for _, multiline := range [...]bool{true, false} {
+			subject := bigcsvreader.New()
+			subject.SetFilePath(filePath)
+			subject.ColumnsCount = 2
+			subject.MultilineQuotedFields = multiline
+			subject.CRLFInQuotedFields = !multiline
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+
+			// act
+			rowsChans, errsChan := subject.ReadReverse(ctx)
+			err := bigcsvreader.CollectErrors(errsChan)
+			cancelCtx()
+
+			// assert
+			assertNil(t, rowsChans)
+			if assertNotNil(t, err) {
+				assertTrue(t, strings.Contains(err.Error(), "not supported with line breaks in quoted fields"))
+			}
+		}
+	})
+}
+
+// gatherRowsInOrder consumes concurrently all the rows channels and returns
+// the rows of all channels concatenated, in channels order.
+func gatherRowsInOrder(rowsChans []bigcsvreader.RowsChan) [][]string {
+	var (
+		wg        sync.WaitGroup
+		chansRows = make([][][]string, len(rowsChans))
+	)
+	for i := range rowsChans {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for row := range rowsChans[i] {
+				chansRows[i] = append(chansRows[i], row)
+			}
+		}(i)
+	}
+	wg.Wait()
+	var rows [][]string
+	for _, chanRows := range chansRows {
+		rows = append(rows, chanRows...)
+	}
+
+	return rows
+}
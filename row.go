@@ -0,0 +1,53 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import "context"
+
+// MetaRowsChan is the channel where rows with metadata will be pushed into.
+type MetaRowsChan <-chan Row
+
+// Row holds a CSV row's fields and its position metadata.
+// Rows can be put back in file order, after parallel processing,
+// by sorting them by ChunkIndex, then by RowIndexInChunk.
+type Row struct {
+	// Fields holds the row's values.
+	Fields []string
+	// Offset is the offset of the row in the file.
+	Offset int
+	// Line is the 1-based line number of the row in the file, if [CsvReader.NumberLines] is enabled, 0 otherwise.
+	Line int64
+	// ChunkIndex is the 0-based index of the chunk (read by a goroutine) the row belongs to.
+	// Chunks are indexed in file order.
+	ChunkIndex int
+	// RowIndexInChunk is the 0-based index of the row among the rows emitted from its chunk.
+	// It is -1 for the header, so it sorts first.
+	RowIndexInChunk int64
+	// Header is a flag indicating the row is the file's header, see [CsvReader.EmitHeader].
+	Header bool
+}
+
+// ReadWithMeta extracts asynchronously CSV rows, like [CsvReader.Read] does,
+// each started goroutine putting them, along with their position metadata, into a MetaRowsChan.
+func (cr *CsvReader) ReadWithMeta(ctx context.Context) ([]MetaRowsChan, ErrsChan) {
+	rn := cr.start(ctx, modeMeta, nil)
+
+	return rn.MetaRowsChans(), rn.ErrsChan()
+}
+
+// MetaRowsChans returns the channels where rows with metadata are pushed into, one for each started goroutine,
+// if the run was started by [CsvReader.ReadWithMeta].
+func (rn *Run) MetaRowsChans() []MetaRowsChan {
+	if len(rn.metaChans) == 0 {
+		return nil
+	}
+	metaChans := make([]MetaRowsChan, len(rn.metaChans))
+	for i, metaChan := range rn.metaChans {
+		metaChans[i] = metaChan
+	}
+
+	return metaChans
+}
@@ -0,0 +1,82 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ReadWithMeta(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	const rowsCount = 3000
+	filePath, err := setUpTmpCsvFile(rowsCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tearDownTmpCsvFile(filePath)
+	subject := bigcsvreader.New()
+	subject.SetFilePath(filePath)
+	subject.ColumnsCount = 5
+	subject.MaxGoroutinesNo = 4
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	metaChans, errsChan := subject.ReadWithMeta(ctx)
+	var (
+		rows []bigcsvreader.Row
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+	)
+	wg.Add(len(metaChans))
+	for _, metaChan := range metaChans {
+		go func(metaChan bigcsvreader.MetaRowsChan) {
+			defer wg.Done()
+			for row := range metaChan {
+				mu.Lock()
+				rows = append(rows, row)
+				mu.Unlock()
+			}
+		}(metaChan)
+	}
+	err = bigcsvreader.CollectErrors(errsChan)
+	wg.Wait()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 4, len(metaChans))
+	assertEqual(t, rowsCount, len(rows))
+	// stitch rows back in file order.
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].ChunkIndex != rows[j].ChunkIndex {
+			return rows[i].ChunkIndex < rows[j].ChunkIndex
+		}
+
+		return rows[i].RowIndexInChunk < rows[j].RowIndexInChunk
+	})
+	var prevChunk, prevOffset = -1, -1
+	var rowIndex int64
+	for i, row := range rows {
+		assertEqual(t, strconv.Itoa(i+1), row.Fields[0])
+		assertTrue(t, row.Offset > prevOffset)
+		if row.ChunkIndex != prevChunk {
+			assertEqual(t, prevChunk+1, row.ChunkIndex)
+			prevChunk, rowIndex = row.ChunkIndex, 0
+		}
+		assertEqual(t, rowIndex, row.RowIndexInChunk)
+		prevOffset = row.Offset
+		rowIndex++
+	}
+}
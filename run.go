@@ -0,0 +1,372 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/actforgood/bigcsvreader/expr"
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+// maxRunErrors is the maximum number of errors a run retains for [Run.Err], the other ones being only counted.
+const maxRunErrors = 1000
+
+// Run represents a reading of a CSV file, started with [CsvReader.Start].
+// It gives access to the rows and errors channels, and to the final outcome of the reading.
+type Run struct {
+	// id is the run's ID, logged with each log of the run.
+	id string
+	// header holds the parsed header, if the file has one.
+	header []string
+	// headerIndex indexes header's columns by name, nil if the file has no header, see [Run.Record].
+	headerIndex *HeaderIndex
+	// emitHeader is a flag indicating the header is emitted as a row, see [CsvReader.EmitHeader].
+	emitHeader bool
+	// control holds what the control file states, nil if the file is not validated against one.
+	control *Control
+	// quarantine holds the ranges dumped into quarantine sidecar files, nil if nothing is quarantined.
+	quarantine *quarantineLog
+	// fieldsCount is the reference fields count chunks are checked against, if it is inferred.
+	fieldsCount *fieldsCountRef
+	// archive is a flag indicating the file is archived after it was read successfully, see [CsvReader.Archive].
+	archive bool
+	// failed is set to 1 if a fatal error occurred, so the file is not archived.
+	failed int32
+	// dataStart is the offset data to read starts at (after eventual header).
+	dataStart int
+	// dataEnd is the offset data to read ends at (exclusive).
+	dataEnd int
+	// checkpoint is the checkpoint rows are acknowledged into, see [Run.Checkpoint].
+	checkpoint *Checkpoint
+	// firstLines holds the line number of the first line each goroutine handles, nil if lines are not numbered.
+	firstLines []int64
+	// inQuotes holds whether each goroutine's start offset lies inside a quoted field,
+	// nil if line breaks inside quoted fields are not looked for ahead of reading, see [CsvReader.MultilineQuotedFields]
+	// and [CsvReader.CRLFInQuotedFields].
+	inQuotes []bool
+	// reverse is a flag indicating goroutines read their chunks backwards.
+	reverse bool
+	// streamLines is the channel a plain gzip file's lines are fanned out through to the goroutines,
+	// nil if the file is not a plain gzip file.
+	streamLines chan []streamLine
+	// workQueue holds the data ranges the goroutines claim in turn, nil if [CsvReader.WorkQueue] is disabled.
+	workQueue *workQueue
+	// threadsInfo holds the [start, end] offsets each goroutine handles.
+	threadsInfo [][2]int
+	// pin is the pinned file, if [CsvReader.PinFile] is enabled.
+	pin *pinnedFile
+	// rowsChans holds the channels each goroutine pushes rows into.
+	rowsChans []chan<- []string
+	// rowsChansOut holds the receive-only version of rowsChans.
+	rowsChansOut []RowsChan
+	// lazyChans holds the channels each goroutine pushes lazy rows into, in lazy mode.
+	lazyChans []chan *LazyRow
+	// decodedChans holds the channels each goroutine pushes decoded rows into, in decoded mode.
+	decodedChans []chan any
+	// rings holds the transports (ring buffers, by default) each goroutine pushes rows into, in paired mode.
+	rings []internal.Transport[[]string]
+	// metaChans holds the channels each goroutine pushes rows with metadata into, in meta mode.
+	metaChans []chan Row
+	// errsChan is the channel errors are pushed into.
+	errsChan chan error
+	// done is closed after all goroutines finished and all channels are closed.
+	done chan struct{}
+	// errs holds the first [maxRunErrors] errors sent through errsChan.
+	errs []error
+	// errsCount is the number of errors sent through errsChan.
+	errsCount int
+	// errsMu guards errs and errsCount.
+	errsMu sync.Mutex
+	// errsHighWater is the maximum number of errors found waiting in errsChan, see [Summary.ErrsChanHighWater].
+	errsHighWater int64
+	// threads holds each goroutine's statistics.
+	threads []threadStats
+	// utf16 is the byte order of the UTF-16 encoded file, nil if the file is UTF-8 encoded.
+	utf16 binary.ByteOrder
+	// filter is the compiled [CsvReader.Filter], nil if rows are not filtered.
+	filter *expr.Program
+	// columns holds the columns options, indexed by column.
+	columns []compiledColumn
+	// validate is a flag indicating there are validation rules to check rows against.
+	validate bool
+	// unique checks keys uniqueness, nil if there is no unique constraint.
+	unique *uniqueChecker
+	// foreignKey checks foreign keys, nil if there is no foreign key check.
+	foreignKey *foreignKeyChecker
+	// errSampler samples errors, nil if errors are not sampled.
+	errSampler *errorSampler
+	// errorRate keeps track of the bad rows rate, nil if it is not checked.
+	errorRate *errorRate
+	// clock is the clock of the reader which started the run.
+	clock internal.Clock
+	// startedAt is the time reading started.
+	startedAt time.Time
+	// chunks holds the chunks read by goroutines, recorded only if a manifest is written.
+	chunks []ManifestChunk
+	// chunksMu guards chunks.
+	chunksMu sync.Mutex
+	// trimSpace is the global trim space flag, applied also to columns not present in columns.
+	trimSpace bool
+	// normalizeWindows1252 is the global Windows-1252 normalization flag, applied also to columns not present in columns.
+	normalizeWindows1252 bool
+	// windows1252Replacements is the number of Windows-1252 bytes translated into UTF-8, updated atomically.
+	windows1252Replacements int64
+	// paddedRows is the number of rows padded with [CsvReader.DefaultValues], updated atomically.
+	paddedRows int64
+	// memThreshold is the memory usage above which reading is paused, 0 if not memory limit aware.
+	memThreshold int64
+	// memPressure is a flag (updated atomically) indicating the memory usage
+	// is above the threshold and reading should be paused.
+	memPressure int32
+	// colStats holds each goroutine's columns statistics, nil if they are not computed.
+	colStats []columnsStatsAcc
+	// colStatsChan is the channel columns statistics snapshots are pushed into, nil if they are not computed.
+	colStatsChan chan ColumnsStats
+	// limit is the maximum number of rows to emit, 0 means no limit.
+	limit int64
+	// taken is the number of rows slots taken for emitting so far, updated atomically.
+	taken int64
+	// timedOut is a flag (updated atomically) indicating [CsvReader.MaxRunDuration] elapsed.
+	timedOut int32
+}
+
+// newRun instantiates a new Run for given goroutines offsets.
+func newRun(threadsInfo [][2]int, pin *pinnedFile, errsChan chan error) *Run {
+	rn := &Run{
+		threadsInfo:  threadsInfo,
+		pin:          pin,
+		rowsChans:    make([]chan<- []string, len(threadsInfo)),
+		rowsChansOut: make([]RowsChan, len(threadsInfo)),
+		errsChan:     errsChan,
+		done:         make(chan struct{}),
+		threads:      make([]threadStats, len(threadsInfo)),
+	}
+	for i := range rn.threads {
+		rn.threads[i].nextOffset = -1
+		rn.threads[i].skippedOffset = -1
+		rn.threads[i].doneChan = make(chan struct{})
+	}
+
+	return rn
+}
+
+// ID returns the run's ID, a random hex string logged (key "run") with each log of the run,
+// see [CsvReader.RunLabel].
+func (rn *Run) ID() string {
+	return rn.id
+}
+
+// newRunID generates a random run ID.
+func newRunID() string {
+	var id [8]byte
+	_, _ = rand.Read(id[:])
+
+	return hex.EncodeToString(id[:])
+}
+
+// RowsChans returns the channels where read rows are pushed into, one for each started goroutine.
+func (rn *Run) RowsChans() []RowsChan {
+	if len(rn.rowsChansOut) == 0 {
+		return nil
+	}
+
+	return rn.rowsChansOut
+}
+
+// Header returns the parsed header, if the file has one.
+// It is available as soon as the run started, before any row flows.
+func (rn *Run) Header() []string {
+	return rn.header
+}
+
+// ErrsChan returns the channel where error(s) are pushed into.
+func (rn *Run) ErrsChan() ErrsChan {
+	return rn.errsChan
+}
+
+// Done returns a channel which is closed after all goroutines finished and all channels were closed.
+func (rn *Run) Done() <-chan struct{} {
+	return rn.done
+}
+
+// Err blocks until the run is done, and returns the errors which were sent through ErrsChan,
+// joined with [errors.Join], or nil if no error occurred. Only the first 1000 errors are retained,
+// so a file full of bad rows does not grow memory unbounded, see [Summary.ErrorsCount] for their number.
+// Note: rows and errors channels must be consumed, otherwise Err blocks forever.
+func (rn *Run) Err() error {
+	<-rn.done
+	rn.errsMu.Lock()
+	defer rn.errsMu.Unlock()
+
+	return errors.Join(rn.errs...)
+}
+
+// RowsCount returns the number of rows pushed into rows channels so far, by all goroutines.
+// It is final after the run is done.
+func (rn *Run) RowsCount() int64 {
+	var rowsCount int64
+	for i := range rn.threads {
+		rowsCount += rn.threads[i].rows()
+	}
+
+	return rowsCount
+}
+
+// PatternViolations returns the number of values which did not match the column's pattern
+// (see [ColumnOptions.Pattern]), per column having a pattern.
+// Counters are final after the run is done.
+func (rn *Run) PatternViolations() map[int]int64 {
+	violations := make(map[int]int64)
+	for col := range rn.columns {
+		if rn.columns[col].pattern != nil {
+			violations[col] = atomic.LoadInt64(&rn.columns[col].violations)
+		}
+	}
+
+	return violations
+}
+
+// sendErr pushes the error into the errors channel, and records it.
+// It returns false if the error was suppressed by [CsvReader.ErrorSampling] (so it should not be logged either).
+func (rn *Run) sendErr(err error) bool {
+	if rn.errSampler != nil && !rn.errSampler.sample(err) {
+		return false
+	}
+	rn.errsMu.Lock()
+	if rn.errsCount < maxRunErrors {
+		rn.errs = append(rn.errs, err)
+	}
+	rn.errsCount++
+	rn.errsMu.Unlock()
+	rn.errsChan <- err
+	observeMax(&rn.errsHighWater, len(rn.errsChan))
+
+	return true
+}
+
+// takeRow takes a slot for a row to be emitted. It returns false if [CsvReader.LimitRows] was reached.
+func (rn *Run) takeRow() bool {
+	return rn.limit == 0 || atomic.AddInt64(&rn.taken, 1) <= rn.limit
+}
+
+// isLimitReached returns true if [CsvReader.LimitRows] rows were emitted.
+func (rn *Run) isLimitReached() bool {
+	return rn.limit > 0 && atomic.LoadInt64(&rn.taken) >= rn.limit
+}
+
+// CollectErrors drains the errors channel and returns all the errors received,
+// joined with [errors.Join], or nil if no error was received.
+// Note: rows channels must be consumed concurrently, otherwise CollectErrors may block forever.
+func CollectErrors(errsChan ErrsChan) error {
+	var errs []error
+	for err := range errsChan {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// threadStats holds the statistics of a goroutine, updated atomically.
+type threadStats struct {
+	rowsCount  int64
+	bytesCount int64
+	// recordsCount is the number of records read, emitted or not (filtered out, invalid...).
+	recordsCount int64
+	// nextOffset is the offset of the first line left unread, if the goroutine stopped early, -1 otherwise.
+	nextOffset int64
+	// skippedOffset is the offset of the first line skipped, if the goroutine was cut off, -1 otherwise.
+	skippedOffset int64
+	// chanHighWater is the maximum number of rows found waiting in the goroutine's channel.
+	chanHighWater int64
+	done          int32
+	// doneChan is closed once the goroutine finished, after it pushed all its rows.
+	doneChan chan struct{}
+	// empty is set to 1 if the goroutine's chunk held no record, its channel being closed right away.
+	empty int32
+}
+
+// addRow increments the number of rows pushed by the goroutine.
+func (ts *threadStats) addRow() {
+	atomic.AddInt64(&ts.rowsCount, 1)
+}
+
+// addRecord increments the number of records read by the goroutine.
+func (ts *threadStats) addRecord() {
+	atomic.AddInt64(&ts.recordsCount, 1)
+}
+
+// addBytes increments the number of bytes consumed by the goroutine.
+func (ts *threadStats) addBytes(n int) {
+	atomic.AddInt64(&ts.bytesCount, int64(n))
+}
+
+// observeChan records the number of rows waiting in the goroutine's channel, right after a push.
+func (ts *threadStats) observeChan(n int) {
+	observeMax(&ts.chanHighWater, n)
+}
+
+// highWater returns the maximum number of rows found waiting in the goroutine's channel so far.
+func (ts *threadStats) highWater() int {
+	return int(atomic.LoadInt64(&ts.chanHighWater))
+}
+
+// observeMax stores n into highWater, if greater.
+func observeMax(highWater *int64, n int) {
+	for {
+		current := atomic.LoadInt64(highWater)
+		if int64(n) <= current || atomic.CompareAndSwapInt64(highWater, current, int64(n)) {
+			return
+		}
+	}
+}
+
+// markDone marks the goroutine as finished.
+func (ts *threadStats) markDone() {
+	atomic.StoreInt32(&ts.done, 1)
+	close(ts.doneChan)
+}
+
+// stopAt marks the goroutine as stopped before the line starting at given offset.
+func (ts *threadStats) stopAt(offset int) {
+	atomic.StoreInt64(&ts.nextOffset, int64(offset))
+}
+
+// next returns the offset of the first line left unread, if the goroutine stopped early, -1 otherwise.
+func (ts *threadStats) next() int {
+	return int(atomic.LoadInt64(&ts.nextOffset))
+}
+
+// isEmpty returns whether the goroutine's chunk held no record, see [Run.EmptyChunks].
+func (ts *threadStats) isEmpty() bool {
+	return atomic.LoadInt32(&ts.empty) == 1
+}
+
+// isDone returns whether the goroutine finished.
+func (ts *threadStats) isDone() bool {
+	return atomic.LoadInt32(&ts.done) == 1
+}
+
+// records returns the number of records read by the goroutine so far.
+func (ts *threadStats) records() int64 {
+	return atomic.LoadInt64(&ts.recordsCount)
+}
+
+// rows returns the number of rows pushed by the goroutine so far.
+func (ts *threadStats) rows() int64 {
+	return atomic.LoadInt64(&ts.rowsCount)
+}
+
+// bytes returns the number of bytes consumed by the goroutine so far.
+func (ts *threadStats) bytes() int64 {
+	return atomic.LoadInt64(&ts.bytesCount)
+}
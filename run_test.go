@@ -0,0 +1,265 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful run", testRunSuccessful)
+	t.Run("run with errors", testRunWithErrors)
+	t.Run("run with fatal error", testRunWithFatalError)
+	t.Run("header is available before rows flow", testRunHeader)
+	t.Run("header only file", testRunHeaderOnly)
+	t.Run("configuration changes do not affect runs in flight", testRunConfigSnapshot)
+	t.Run("cleanup is awaited after cancellation", testRunWaitDone)
+}
+
+func testRunWaitDone(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath, err := setUpTmpCsvFile(5000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tearDownTmpCsvFile(filePath)
+	var completed atomic.Int32
+	subject := bigcsvreader.New()
+	subject.SetFilePath(filePath)
+	subject.ColumnsCount = 5
+	subject.MaxGoroutinesNo = 4
+	subject.ChanBufferSize = 0 // goroutines block until rows are consumed.
+	subject.OnComplete = func(bigcsvreader.Summary) {
+		time.Sleep(20 * time.Millisecond) // cleanup takes a while.
+		completed.Add(1)
+	}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+	subject.WaitDone() // no run in flight.
+	runs := []*bigcsvreader.Run{subject.Start(ctx)}
+	runs = append(runs, subject.Start(ctx))
+
+	// act
+	<-runs[0].RowsChans()[0]
+	cancelCtx()
+	for _, run := range runs {
+		go bigcsvreader.CollectErrors(run.ErrsChan())
+		drainRows(run.RowsChans())
+	}
+	subject.WaitDone()
+
+	// assert
+	assertEqual(t, int32(2), completed.Load())
+	assertTrue(t, errors.Is(runs[0].Err(), bigcsvreader.ErrCanceledWithPartialData))
+}
+
+func testRunSuccessful(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/file_without_header.csv")
+	subject.ColumnsCount = 3
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	run := subject.Start(ctx)
+	rowsCount := drainRows(run.RowsChans())
+	err := run.Err()
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 5, rowsCount)
+	select {
+	case <-run.Done():
+	default:
+		t.Error("run should be done")
+	}
+}
+
+func testRunConfigSnapshot(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	const rowsCount = 5000
+	filePath, err := setUpTmpCsvFile(rowsCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tearDownTmpCsvFile(filePath)
+	subject := bigcsvreader.New()
+	subject.SetFilePath(filePath)
+	subject.ColumnsCount = 5
+	subject.MaxGoroutinesNo = 4
+	subject.ChanBufferSize = 1
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	runs := []*bigcsvreader.Run{subject.Start(ctx)}
+	subject.ColumnsCount = 2 // would make all rows invalid.
+	subject.MaxGoroutinesNo = 1
+	subject.BufferSize = 16 // would make all lines too long.
+	runs = append(runs, subject.Start(ctx))
+	counts := make([]int, len(runs))
+	var wg sync.WaitGroup
+	wg.Add(len(runs))
+	for i, run := range runs {
+		go func(i int, run *bigcsvreader.Run) {
+			defer wg.Done()
+			go func() {
+				for range run.ErrsChan() { // errors are collected by Run.Err.
+				}
+			}()
+			counts[i] = drainRows(run.RowsChans())
+		}(i, run)
+	}
+	wg.Wait()
+
+	// assert
+	assertNil(t, runs[0].Err())
+	assertEqual(t, rowsCount, counts[0])
+	assertEqual(t, 4, len(runs[0].RowsChans()))
+	assertNotNil(t, runs[1].Err())
+	assertEqual(t, 0, counts[1])
+}
+
+func testRunWithErrors(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/invalid_row.csv")
+	subject.ColumnsCount = 3
+	subject.FileHasHeader = true
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+	var expectedErr *csv.ParseError
+
+	// act
+	run := subject.Start(ctx)
+	var collectedErr error
+	done := make(chan struct{})
+	go func() {
+		collectedErr = bigcsvreader.CollectErrors(run.ErrsChan())
+		close(done)
+	}()
+	rowsCount := drainRows(run.RowsChans())
+	<-done
+	err := run.Err()
+
+	// assert
+	assertEqual(t, 4, rowsCount)
+	assertTrue(t, errors.As(err, &expectedErr))
+	assertTrue(t, errors.As(collectedErr, &expectedErr))
+	assertEqual(t, collectedErr.Error(), err.Error())
+}
+
+func testRunWithFatalError(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/this_file_does_not_exist.csv")
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	run := subject.Start(ctx)
+	err := run.Err()
+
+	// assert
+	assertNil(t, run.RowsChans())
+	assertTrue(t, errors.Is(err, os.ErrNotExist))
+}
+
+func testRunHeader(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/file_with_header.csv")
+	subject.FileHasHeader = true
+	subject.ColumnsCount = 3
+	subject.ColumnsDelimiter = ';'
+	subject.ChanBufferSize = 1
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	run := subject.Start(ctx)
+	header := run.Header()
+	rowsCount := drainRows(run.RowsChans())
+	err := run.Err()
+
+	// assert
+	assertEqual(t, []string{"ID", "Name", "Age"}, header)
+	assertEqual(t, 5, rowsCount)
+	assertNil(t, err)
+}
+
+func testRunHeaderOnly(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath := filepath.Join(t.TempDir(), "header_only.csv")
+	if err := os.WriteFile(filePath, []byte("id,name\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	subject := bigcsvreader.New()
+	subject.SetFilePath(filePath)
+	subject.FileHasHeader = true
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	run := subject.Start(ctx)
+	rowsCount := drainRows(run.RowsChans())
+	err := run.Err()
+
+	// assert
+	assertEqual(t, []string{"id", "name"}, run.Header())
+	assertEqual(t, 0, rowsCount)
+	assertNil(t, err)
+}
+
+// drainRows consumes all the rows channels and returns the number of rows received.
+func drainRows(rowsChans []bigcsvreader.RowsChan) int {
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		count int
+	)
+	for i := 0; i < len(rowsChans); i++ {
+		wg.Add(1)
+		go func(rowsChan bigcsvreader.RowsChan) {
+			defer wg.Done()
+			for range rowsChan {
+				mu.Lock()
+				count++
+				mu.Unlock()
+			}
+		}(rowsChans[i])
+	}
+	wg.Wait()
+
+	return count
+}
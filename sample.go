@@ -0,0 +1,181 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"container/heap"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash/fnv"
+	"io"
+	"sort"
+	"sync"
+)
+
+// MaskFunc masks a column value.
+type MaskFunc func(value string) string
+
+// MaskHash returns a [MaskFunc] which replaces a value with a token derived from the salted SHA-256 of it.
+// Equal values get equal tokens, so relations between rows are preserved.
+// Empty values are kept empty.
+func MaskHash(salt string) MaskFunc {
+	return func(value string) string {
+		if value == "" {
+			return ""
+		}
+		sum := sha256.Sum256([]byte(salt + value))
+
+		return hex.EncodeToString(sum[:8])
+	}
+}
+
+// MaskRedact is a [MaskFunc] which replaces any non empty value with "***".
+func MaskRedact(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return "***"
+}
+
+// SampleOptions holds the options for [CsvReader.Sample].
+type SampleOptions struct {
+	// Size is the number of rows to extract.
+	Size int
+	// Seed drives the random selection; the same seed over the same file yields the same sample,
+	// no matter how many goroutines read the file.
+	Seed uint64
+	// Masks holds the masking functions to apply to given columns (0-based indexes) values.
+	Masks map[int]MaskFunc
+}
+
+// Sample writes into w a reproducible random sample of the CSV file's rows, with eventual masked columns,
+// delimited by [CsvReader.ColumnsDelimiter], preceded by the header, if the file has one.
+// Rows are selected by the lowest seeded hash of their content, so the sample does not depend on reading order.
+// Sampled rows are written in selection order.
+func (cr *CsvReader) Sample(ctx context.Context, w io.Writer, opts SampleOptions) error {
+	if opts.Size < 1 {
+		return errors.New("bigcsvreader: sample size must be positive")
+	}
+	var header []string
+	if cr.FileHasHeader {
+		var err error
+		if header, err = cr.Header(); err != nil {
+			return err
+		}
+	}
+
+	reader := cr.snapshot()
+	reader.EmitHeader = false // the header, if any, is not sampled.
+	rn := reader.Start(ctx)
+	var (
+		samples = make([]sampleHeap, len(rn.RowsChans()))
+		wg      sync.WaitGroup
+	)
+	wg.Add(len(samples))
+	for i, rowsChan := range rn.RowsChans() {
+		go func(sample *sampleHeap, rowsChan RowsChan) {
+			defer wg.Done()
+			for row := range rowsChan {
+				sample.offer(sampledRow{priority: samplePriority(opts.Seed, row), row: row}, opts.Size)
+			}
+		}(&samples[i], rowsChan)
+	}
+	wg.Wait()
+	if err := rn.Err(); err != nil {
+		return err
+	}
+
+	var merged []sampledRow
+	for _, sample := range samples {
+		merged = append(merged, sample...)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].less(merged[j]) })
+	if len(merged) > opts.Size {
+		merged = merged[:opts.Size]
+	}
+
+	csvWriter := newRecordWriter(w, cr.ColumnsDelimiter, cr.OutputQuotePolicy)
+	if header != nil {
+		_ = csvWriter.Write(header)
+	}
+	for _, sampled := range merged {
+		for col, mask := range opts.Masks {
+			if col < len(sampled.row) {
+				sampled.row[col] = mask(sampled.row[col])
+			}
+		}
+		_ = csvWriter.Write(sampled.row)
+	}
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// samplePriority returns the seeded hash of the row's content.
+func samplePriority(seed uint64, row []string) uint64 {
+	h := fnv.New64a()
+	var seedBytes [8]byte
+	binary.LittleEndian.PutUint64(seedBytes[:], seed)
+	_, _ = h.Write(seedBytes[:])
+	for _, value := range row {
+		_, _ = h.Write([]byte(value))
+		_, _ = h.Write([]byte{0})
+	}
+
+	return h.Sum64()
+}
+
+// sampledRow is a row candidate to the sample.
+type sampledRow struct {
+	priority uint64
+	row      []string
+}
+
+// less orders rows by priority, and, for equal priorities, by content, so selection is deterministic.
+func (sr sampledRow) less(other sampledRow) bool {
+	if sr.priority != other.priority {
+		return sr.priority < other.priority
+	}
+	for i := 0; i < len(sr.row) && i < len(other.row); i++ {
+		if sr.row[i] != other.row[i] {
+			return sr.row[i] < other.row[i]
+		}
+	}
+
+	return len(sr.row) < len(other.row)
+}
+
+// sampleHeap is a max-heap holding the rows with the lowest priorities.
+type sampleHeap []sampledRow
+
+func (h sampleHeap) Len() int           { return len(h) }
+func (h sampleHeap) Less(i, j int) bool { return h[j].less(h[i]) }
+func (h sampleHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *sampleHeap) Push(x any)        { *h = append(*h, x.(sampledRow)) }
+func (h *sampleHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return x
+}
+
+// offer adds the row to the heap if it is among the size lowest priorities seen.
+func (h *sampleHeap) offer(sr sampledRow, size int) {
+	if h.Len() < size {
+		heap.Push(h, sr)
+
+		return
+	}
+	if sr.less((*h)[0]) {
+		(*h)[0] = sr
+		heap.Fix(h, 0)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_Sample(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var input strings.Builder
+	input.WriteString("id,email\n")
+	for i := 1; i <= 5000; i++ {
+		fmt.Fprintf(&input, "%d,user%d@example.com\n", i, i)
+	}
+	filePath := filepath.Join(t.TempDir(), "users.csv")
+	if err := os.WriteFile(filePath, []byte(input.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sample := func(goroutinesNo int, seed uint64) string {
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = goroutinesNo
+		var w bytes.Buffer
+		err := subject.Sample(context.Background(), &w, bigcsvreader.SampleOptions{
+			Size:  10,
+			Seed:  seed,
+			Masks: map[int]bigcsvreader.MaskFunc{1: bigcsvreader.MaskHash("salt")},
+		})
+		assertNil(t, err)
+
+		return w.String()
+	}
+
+	// act
+	result1 := sample(1, 42)
+	result8 := sample(8, 42)
+	resultOtherSeed := sample(8, 43)
+
+	// assert
+	assertEqual(t, result1, result8)
+	assertTrue(t, result1 != resultOtherSeed)
+	lines := strings.Split(strings.TrimSuffix(result1, "\n"), "\n")
+	if assertEqual(t, 11, len(lines)) {
+		assertEqual(t, "id,email", lines[0])
+		for _, line := range lines[1:] {
+			fields := strings.Split(line, ",")
+			assertEqual(t, 2, len(fields))
+			assertTrue(t, !strings.Contains(fields[1], "@"))
+			assertEqual(t, 16, len(fields[1]))
+		}
+	}
+}
+
+func TestMaskRedact(t *testing.T) {
+	t.Parallel()
+
+	assertEqual(t, "***", bigcsvreader.MaskRedact("secret"))
+	assertEqual(t, "", bigcsvreader.MaskRedact(""))
+}
@@ -0,0 +1,50 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import "bytes"
+
+// recordBoundaryChars are the chars the records boundary scanner stops at.
+const recordBoundaryChars = "\"\n"
+
+// ScanRecord is a [bufio.SplitFunc] splitting CSV data into records, with the same boundaries
+// [CsvReader.MultilineQuotedFields] reads them by: a record ends at the first line feed which is not
+// inside a quoted field, quotes being balanced (escaped by doubling them), so line breaks inside
+// quoted fields are part of the record.
+// The token is the record without its line terminator (\n or \r\n), the advance counting it, so
+// record offsets can be tracked by summing advances. At EOF, the remaining data is the last record,
+// even if it ends inside a quoted field (the CSV parser reports it).
+// It never fails, so it is safe for any input.
+func ScanRecord(data []byte, atEOF bool) (int, []byte, error) {
+	inQuotes := false
+	for pos := 0; pos < len(data); {
+		idx := bytes.IndexAny(data[pos:], recordBoundaryChars)
+		if idx < 0 {
+			break
+		}
+		pos += idx
+		if data[pos] == '"' {
+			inQuotes = !inQuotes
+		} else if !inQuotes {
+			return pos + 1, dropCR(data[:pos]), nil
+		}
+		pos++
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+
+	return 0, nil, nil // request more data.
+}
+
+// dropCR drops a terminal \r from the data.
+func dropCR(data []byte) []byte {
+	if len(data) > 0 && data[len(data)-1] == '\r' {
+		return data[:len(data)-1]
+	}
+
+	return data
+}
@@ -0,0 +1,95 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestScanRecord(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name            string
+		input           string
+		expectedRecords []string
+	}{
+		{
+			name:            "single line records",
+			input:           "1,John\n2,Jane\r\n3,Mike",
+			expectedRecords: []string{"1,John", "2,Jane", "3,Mike"},
+		},
+		{
+			name:            "line breaks inside quoted fields",
+			input:           "1,\"multi\nline\"\n2,\"with \"\"escaped\"\"\r\nquotes\"\r\n",
+			expectedRecords: []string{"1,\"multi\nline\"", "2,\"with \"\"escaped\"\"\r\nquotes\""},
+		},
+		{
+			name:            "blank lines",
+			input:           "1,John\n\n2,Jane\n",
+			expectedRecords: []string{"1,John", "", "2,Jane"},
+		},
+		{
+			name:            "unterminated quoted field",
+			input:           "1,John\n2,\"Jane\n3,Mike\n",
+			expectedRecords: []string{"1,John", "2,\"Jane\n3,Mike\n"},
+		},
+		{
+			name:            "empty input",
+			input:           "",
+			expectedRecords: nil,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			scanner := bufio.NewScanner(strings.NewReader(test.input))
+			scanner.Buffer(make([]byte, 4), 1024) // small buffer, so more data is requested for records.
+			scanner.Split(bigcsvreader.ScanRecord)
+			var records []string
+
+			// act
+			for scanner.Scan() {
+				records = append(records, scanner.Text())
+			}
+
+			// assert
+			assertNil(t, scanner.Err())
+			assertEqual(t, test.expectedRecords, records)
+		})
+	}
+}
+
+func FuzzScanRecord(f *testing.F) {
+	f.Add([]byte("1,John\n2,\"Jane\r\nDoe\"\r\n3,\"Mike \"\"M\"\"\"\n"))
+	f.Add([]byte("\"\n\"\"\n"))
+	f.Add([]byte("\r\n\r\r\n"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for pos := 0; pos < len(data); {
+			advance, token, err := bigcsvreader.ScanRecord(data[pos:], true)
+			if err != nil || advance <= 0 || pos+advance > len(data) {
+				t.Fatalf("unexpected split at %d: advance %d, error %v", pos, advance, err)
+			}
+			if !bytes.HasPrefix(data[pos:], token) || len(token) > advance {
+				t.Fatalf("token %q at %d is not the split data %q", token, pos, data[pos:pos+advance])
+			}
+			if advance < len(data)-pos && bytes.Count(data[pos:pos+advance], []byte{'"'})%2 == 1 {
+				t.Fatalf("record %q at %d ends inside a quoted field", data[pos:pos+advance], pos)
+			}
+			pos += advance
+		}
+	})
+}
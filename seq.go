@@ -0,0 +1,40 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+//go:build go1.23
+
+package bigcsvreader
+
+import (
+	"context"
+	"io"
+	"iter"
+)
+
+// ReadSeq returns an iterator over the file's rows, in the file's order, to be ranged over:
+//
+//	for row, err := range reader.ReadSeq(ctx) {
+//		...
+//	}
+//
+// The file is read each time the iterator is ranged over, its chunks being read in parallel
+// underneath, like [CsvReader.AsSequentialReader] does.
+// Errors are yielded, with a nil row, as they are received, reading going on.
+// Breaking out of the loop stops reading, releasing the reading goroutines.
+func (cr *CsvReader) ReadSeq(ctx context.Context) iter.Seq2[[]string, error] {
+	return func(yield func([]string, error) bool) {
+		sr := cr.sequentialReader(ctx)
+		defer sr.Close()
+		for {
+			row, err := sr.Read()
+			if err == io.EOF {
+				return
+			}
+			if !yield(row, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+//go:build go1.23
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ReadSeq(t *testing.T) {
+	t.Parallel()
+
+	const rowsCount = 20000
+	var content strings.Builder
+	content.WriteString("id,value\n")
+	for i := 1; i <= rowsCount; i++ {
+		content.WriteString(strconv.Itoa(i) + ",value " + strconv.Itoa(i) + "\n")
+	}
+	filePath := writeTmpFile(t, t.TempDir(), "data.csv", content.String())
+	newReader := func() *bigcsvreader.CsvReader {
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 8
+		subject.ChanBufferSize = 16
+
+		return subject
+	}
+
+	t.Run("rows are yielded in the file's order", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := newReader()
+		want := make([]string, 0, rowsCount)
+		for i := 1; i <= rowsCount; i++ {
+			want = append(want, strconv.Itoa(i))
+		}
+
+		// act
+		var (
+			ids  = make([]string, 0, rowsCount)
+			errs []error
+		)
+		for row, err := range subject.ReadSeq(context.Background()) {
+			if err != nil {
+				errs = append(errs, err)
+
+				continue
+			}
+			ids = append(ids, row[0])
+		}
+
+		// assert
+		assertEqual(t, 0, len(errs))
+		assertEqual(t, want, ids)
+	})
+
+	t.Run("breaking stops reading", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := newReader()
+		ctx, cancelCtx := context.WithCancel(context.Background())
+		defer cancelCtx()
+
+		// act
+		var rows [][]string
+		for row, err := range subject.ReadSeq(ctx) {
+			if !assertNil(t, err) {
+				break
+			}
+			rows = append(rows, row)
+			if len(rows) == 3 {
+				break
+			}
+		}
+
+		// assert
+		assertEqual(t, [][]string{{"1", "value 1"}, {"2", "value 2"}, {"3", "value 3"}}, rows)
+		done := make(chan struct{})
+		go func() {
+			subject.WaitDone()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(15 * time.Second):
+			t.Error("reading goroutines were not released")
+		}
+	})
+
+	t.Run("errors are yielded", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/invalid_row.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+
+		// act
+		var (
+			rowsCount int
+			errs      []error
+		)
+		for row, err := range subject.ReadSeq(context.Background()) {
+			if err != nil {
+				assertTrue(t, row == nil)
+				errs = append(errs, err)
+
+				continue
+			}
+			rowsCount++
+		}
+
+		// assert
+		assertEqual(t, 1, len(errs))
+		assertEqual(t, 4, rowsCount)
+	})
+}
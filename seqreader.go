@@ -0,0 +1,140 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"io"
+)
+
+// SequentialReader reads rows one by one, in the file's order, like [csv.Reader] does,
+// while the file's chunks are read ahead, in parallel, by a [CsvReader], see [CsvReader.AsSequentialReader].
+// A sequential reader is not safe for concurrent use.
+type SequentialReader struct {
+	rowsChans  []RowsChan
+	chunksDone []<-chan struct{}
+	errsChan   ErrsChan
+	cancel     context.CancelFunc
+	// chunk is the index of the chunk rows are currently read from.
+	chunk int
+}
+
+// AsSequentialReader starts reading the file, like [CsvReader.Read] does, and returns a reader
+// handing its rows in the file's order, a drop-in replacement of [csv.Reader] for code reading
+// records one by one.
+// Chunks are read in parallel by [CsvReader.MaxGoroutinesNo] goroutines, rows of the following chunks
+// being buffered (up to [CsvReader.ChanBufferSize] rows each) while the current chunk is consumed.
+// Plain gzip compressed files are read by one goroutine.
+// The header is returned as the first record only if [CsvReader.EmitHeader] is enabled.
+// The reader must be read until [io.EOF], or closed.
+func (cr *CsvReader) AsSequentialReader() *SequentialReader {
+	return cr.sequentialReader(context.Background())
+}
+
+// sequentialReader starts reading the file, with given context, and returns a reader handing its rows in the file's order.
+func (cr *CsvReader) sequentialReader(ctx context.Context) *SequentialReader {
+	if _, stream, err := cr.decompressingFS(); err == nil && stream {
+		reader := cr.clone()
+		reader.Manifest = cr.Manifest
+		reader.MaxGoroutinesNo = 1 // decompressed lines are not dispatched in order.
+		cr = reader
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	rn := cr.start(ctx, modeRows, nil)
+	sr := &SequentialReader{
+		rowsChans:  rn.RowsChans(),
+		chunksDone: make([]<-chan struct{}, len(rn.threads)),
+		errsChan:   rn.ErrsChan(),
+		cancel:     cancel,
+	}
+	for i := range rn.threads {
+		sr.chunksDone[i] = rn.threads[i].doneChan
+	}
+
+	return sr
+}
+
+// Read returns the next row, or [io.EOF] when there are no more rows.
+// Errors are returned as they are received, reading going on, like [csv.Reader.Read] does
+// for parse errors.
+// The returned slice is not reused by following calls.
+func (sr *SequentialReader) Read() ([]string, error) {
+	for sr.chunk < len(sr.rowsChans) {
+		select {
+		case err, ok := <-sr.errsChan:
+			if !ok {
+				sr.errsChan = nil
+
+				continue
+			}
+
+			return nil, err
+		case row, ok := <-sr.rowsChans[sr.chunk]:
+			if ok {
+				return row, nil
+			}
+			sr.chunk++
+		case <-sr.chunksDone[sr.chunk]:
+			// the chunk's rows are all buffered, the channels of non-empty chunks being closed only after all chunks are read.
+			select {
+			case row, ok := <-sr.rowsChans[sr.chunk]:
+				if ok {
+					return row, nil
+				}
+			default:
+			}
+			sr.chunk++
+		}
+	}
+	if sr.errsChan != nil {
+		if err, ok := <-sr.errsChan; ok {
+			return nil, err
+		}
+		sr.errsChan = nil
+	}
+
+	return nil, io.EOF
+}
+
+// ReadAll reads all the remaining rows. Like [csv.Reader.ReadAll], it stops at the first error,
+// returning the rows read so far, and a successful call returns a nil error, not [io.EOF].
+// The reader is closed once it returned.
+func (sr *SequentialReader) ReadAll() ([][]string, error) {
+	defer sr.Close()
+	var rows [][]string
+	for {
+		row, err := sr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return rows, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+// Close stops reading, if not done yet, and releases the reader's resources.
+// Following reads return [io.EOF]. It is safe to call it several times.
+func (sr *SequentialReader) Close() error {
+	sr.cancel()
+	errsDone := make(chan struct{})
+	go func(errsChan ErrsChan) {
+		defer close(errsDone)
+		if errsChan != nil {
+			for range errsChan {
+			}
+		}
+	}(sr.errsChan)
+	if sr.chunk < len(sr.rowsChans) {
+		drainRowsChans(sr.rowsChans[sr.chunk:])
+		sr.chunk = len(sr.rowsChans)
+	}
+	<-errsDone
+	sr.errsChan = nil
+
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_AsSequentialReader(t *testing.T) {
+	t.Parallel()
+
+	const rowsCount = 20000
+	var content strings.Builder
+	content.WriteString("id,value\n")
+	for i := 1; i <= rowsCount; i++ {
+		content.WriteString(strconv.Itoa(i) + ",value " + strconv.Itoa(i) + "\n")
+	}
+	dir := t.TempDir()
+	tests := [...]struct {
+		name     string
+		filePath string
+	}{
+		{
+			name:     "not compressed file",
+			filePath: writeTmpFile(t, dir, "data.csv", content.String()),
+		},
+		{
+			name:     "plain gzip",
+			filePath: writeTmpFile(t, dir, "data.csv.gz", gzipContent(t, content.String())),
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run("rows are read in the file's order, "+test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(test.filePath)
+			subject.FileHasHeader = true
+			subject.EmitHeader = true
+			subject.ColumnsCount = 2
+			subject.MaxGoroutinesNo = 8
+			subject.ChanBufferSize = 16
+			reader := subject.AsSequentialReader()
+			defer reader.Close()
+
+			// act
+			header, errHeader := reader.Read()
+			var (
+				err  error
+				ids  = make([]string, 0, rowsCount)
+				row  []string
+				want = make([]string, 0, rowsCount)
+			)
+			for {
+				row, err = reader.Read()
+				if err != nil {
+					break
+				}
+				ids = append(ids, row[0])
+			}
+
+			// assert
+			assertNil(t, errHeader)
+			assertEqual(t, []string{"id", "value"}, header)
+			assertTrue(t, errors.Is(err, io.EOF))
+			for i := 1; i <= rowsCount; i++ {
+				want = append(want, strconv.Itoa(i))
+			}
+			assertEqual(t, want, ids)
+			_, err = reader.Read()
+			assertTrue(t, errors.Is(err, io.EOF))
+		})
+	}
+
+	t.Run("read all", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.ColumnsDelimiter = ';'
+		subject.MaxGoroutinesNo = 2
+
+		// act
+		rows, err := subject.AsSequentialReader().ReadAll()
+
+		// assert
+		assertNil(t, err)
+		if assertEqual(t, 5, len(rows)) {
+			assertEqual(t, []string{"1", "John", "33"}, rows[0])
+			assertEqual(t, []string{"5", "Elisabeth", "45"}, rows[4])
+		}
+	})
+
+	t.Run("errors are returned", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/not_found.csv")
+
+		// act
+		rows, err := subject.AsSequentialReader().ReadAll()
+
+		// assert
+		assertEqual(t, 0, len(rows))
+		assertNotNil(t, err)
+	})
+
+	t.Run("closing early stops reading", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(tests[0].filePath)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 4
+		subject.ChanBufferSize = 1
+		reader := subject.AsSequentialReader()
+
+		// act
+		row, errRead := reader.Read()
+		errClose := reader.Close()
+		_, errAfterClose := reader.Read()
+
+		// assert
+		assertNil(t, errRead)
+		assertEqual(t, []string{"1", "value 1"}, row)
+		assertNil(t, errClose)
+		assertTrue(t, errors.Is(errAfterClose, io.EOF))
+		assertNil(t, reader.Close())
+	})
+}
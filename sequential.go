@@ -0,0 +1,110 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// isSequential returns whether the run is read by a single goroutine pushing plain rows,
+// with no per row feature (columns options, filter, checks, limits...) enabled,
+// case in which the chunk machinery is skipped in favour of a tight sequential loop.
+func (cr *CsvReader) isSequential(rn *Run) bool {
+	return len(rn.threadsInfo) == 1 && len(rn.rowsChans) == 1 && !rn.reverse && rn.utf16 == nil &&
+		!cr.CRLFInQuotedFields && !cr.MultilineQuotedFields && cr.Manifest == nil &&
+		rn.columns == nil && rn.filter == nil && !rn.validate && rn.unique == nil && rn.foreignKey == nil &&
+		rn.errorRate == nil && rn.memThreshold == 0 && cr.MaxRunDuration == 0 && rn.limit == 0 && rn.colStats == nil &&
+		rn.control == nil && rn.quarantine == nil && cr.MaxErrorsPerChunk == 0 && !cr.ValidateUTF8 &&
+		cr.Checkpoint == nil
+}
+
+// readSequentiallyAsync reads the whole data range, line by line, and pushes the parsed rows into the
+// only rows channel. Errors and statistics are the same the chunks machinery sends and records,
+// reported as coming from thread #1.
+func (cr *CsvReader) readSequentiallyAsync(
+	ctx context.Context,
+	rn *Run,
+	currentThreadNo, offsetStart, offsetEnd int,
+	wg *sync.WaitGroup,
+) {
+	defer wg.Done()
+	stats := &rn.threads[currentThreadNo-1]
+	defer stats.markDone()
+
+	f := cr.openFile(rn, currentThreadNo)
+	if f == nil {
+		return
+	}
+	defer f.Close()
+	_, _ = f.Seek(int64(offsetStart), io.SeekStart)
+
+	var (
+		r        = bufio.NewReaderSize(f, cr.BufferSize)
+		parser   = cr.newLineParser(cr.ColumnsCount)
+		rowsChan = rn.rowsChans[currentThreadNo-1]
+		done     = ctx.Done()
+		offset   = offsetStart
+		lineNo   int64
+	)
+	if rn.firstLines != nil {
+		lineNo = rn.firstLines[currentThreadNo-1]
+	}
+	countPadded(parser, rn)
+	if rn.emitHeader {
+		cr.emitHeaderRow(rn)
+	}
+	for offset < rn.dataEnd {
+		select {
+		case <-done:
+			rn.sendErr(fmt.Errorf(
+				"bigcsvreader: thread #%d received context error (%w: %w)",
+				currentThreadNo, ErrCanceledWithPartialData, ctx.Err(),
+			))
+
+			return
+		default:
+		}
+		line := cr.readLine(rn, r, currentThreadNo, offset)
+		if line == nil {
+			if offset <= offsetEnd {
+				rn.fail()
+				rn.sendErr(fmt.Errorf(
+					"bigcsvreader: thread #%d stopped at offset %d, before chunk's end offset %d (%w)",
+					currentThreadNo, offset, offsetEnd, ErrChunkIncomplete,
+				))
+			}
+
+			break
+		}
+		if !isBlankLine(line) {
+			stats.addRecord()
+			if record, err := parser.parse(line); err != nil {
+				cr.sendParseErr(rn, currentThreadNo, offset, lineNo, line, err)
+			} else {
+				rowsChan <- record
+				stats.observeChan(len(rowsChan))
+				stats.addRow()
+			}
+		}
+		offset += len(line)
+		stats.addBytes(len(line))
+		if lineNo > 0 {
+			lineNo++
+		}
+	}
+	rn.closeIfEmpty(currentThreadNo)
+
+	cr.Logger.Debug(
+		"msg", "done",
+		"file", cr.fileBaseName, "thread", currentThreadNo,
+		"offsetStart", offsetStart, "offsetEnd", offsetEnd,
+		"bytesCount", offset-offsetStart,
+	)
+}
@@ -0,0 +1,129 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_singleGoroutineFastPath(t *testing.T) {
+	t.Parallel()
+
+	files := [...]string{
+		"testdata/invalid_row.csv",
+		"testdata/file_with_quote_in_unquoted_field.csv",
+		"testdata/file_without_header.csv",
+	}
+	for _, file := range files {
+		file := file // capture range variable
+		t.Run(file, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			newSubject := func() *bigcsvreader.CsvReader {
+				subject := bigcsvreader.New()
+				subject.SetFilePath(file)
+				subject.ColumnsCount = 3
+
+				return subject
+			}
+			fastPathSubject := newSubject()
+			chunkedSubject := newSubject()
+			chunkedSubject.LimitRows = 1 << 40 // any per row feature disables the fast path.
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			fastPathRows, fastPathErr := readRowsInOrder(ctx, fastPathSubject)
+			chunkedRows, chunkedErr := readRowsInOrder(ctx, chunkedSubject)
+
+			// assert
+			assertEqual(t, chunkedRows, fastPathRows)
+			if chunkedErr == nil {
+				assertNil(t, fastPathErr)
+			} else if assertNotNil(t, fastPathErr) {
+				assertEqual(t, chunkedErr.Error(), fastPathErr.Error())
+			}
+		})
+	}
+}
+
+func TestCsvReader_singleGoroutineFastPath_stats(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath := writeTmpFile(
+		t, t.TempDir(), "seq.csv",
+		"id,name\n1,John\n\n2,\"Jane\n3,Jim\n4,Jack\n\n",
+	)
+	newSubject := func(summaries chan bigcsvreader.Summary) *bigcsvreader.CsvReader {
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 2
+		subject.MaxGoroutinesNo = 1
+		subject.NumberLines = true
+		subject.OnComplete = func(summary bigcsvreader.Summary) {
+			summaries <- summary
+		}
+
+		return subject
+	}
+	run := func(subject *bigcsvreader.CsvReader, summaries chan bigcsvreader.Summary) (bigcsvreader.Summary, error) {
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+		rn := subject.Start(ctx)
+		drainRows(rn.RowsChans())
+		err := rn.Err()
+
+		return <-summaries, err
+	}
+	fastPathSummaries := make(chan bigcsvreader.Summary, 1)
+	fastPathSubject := newSubject(fastPathSummaries)
+	chunkedSummaries := make(chan bigcsvreader.Summary, 1)
+	chunkedSubject := newSubject(chunkedSummaries)
+	chunkedSubject.LimitRows = 1 << 40 // any per row feature disables the fast path.
+
+	// act
+	fastPathSummary, fastPathErr := run(fastPathSubject, fastPathSummaries)
+	chunkedSummary, chunkedErr := run(chunkedSubject, chunkedSummaries)
+
+	// assert
+	var fastPathLineErr, chunkedLineErr *bigcsvreader.LineError
+	if assertTrue(t, errors.As(fastPathErr, &fastPathLineErr)) && assertTrue(t, errors.As(chunkedErr, &chunkedLineErr)) {
+		assertEqual(t, int64(4), fastPathLineErr.Line)
+		assertEqual(t, chunkedLineErr.Line, fastPathLineErr.Line)
+	}
+	assertEqual(t, chunkedSummary.RowsCount, fastPathSummary.RowsCount)
+	assertEqual(t, chunkedSummary.BytesCount, fastPathSummary.BytesCount)
+	assertEqual(t, chunkedSummary.ErrorsCount, fastPathSummary.ErrorsCount)
+	if assertEqual(t, 1, len(fastPathSummary.Threads)) && assertEqual(t, 1, len(chunkedSummary.Threads)) {
+		assertEqual(t, int64(4), fastPathSummary.Threads[0].RecordsCount)
+		assertEqual(t, chunkedSummary.Threads[0].RecordsCount, fastPathSummary.Threads[0].RecordsCount)
+		assertEqual(t, chunkedSummary.Threads[0].RowsCount, fastPathSummary.Threads[0].RowsCount)
+		assertEqual(t, chunkedSummary.Threads[0].Empty, fastPathSummary.Threads[0].Empty)
+	}
+}
+
+// readRowsInOrder reads the file and returns the rows, in order, and the errors occurred.
+func readRowsInOrder(ctx context.Context, subject *bigcsvreader.CsvReader) ([][]string, error) {
+	run := subject.Start(ctx)
+	var err error
+	done := make(chan struct{})
+	go func() {
+		err = bigcsvreader.CollectErrors(run.ErrsChan())
+		close(done)
+	}()
+	rows := gatherRowsInOrder(run.RowsChans())
+	<-done
+
+	return rows, err
+}
@@ -0,0 +1,160 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// WriteShards rewrites the CSV file into record aligned shards of approx. shardSizeBytes each,
+// written in parallel into dir (created if it does not exist), named "shard-00001.csv", "shard-00002.csv"...
+// If [CsvReader.CompressShards] is enabled, shards are gzip compressed and named "shard-00001.csv.gz"...
+// If the file has a header, it is copied at the beginning of each shard.
+// Records are copied as they are, no parsing is performed (compressed files are decompressed,
+// plain gzip ones are not supported).
+// It returns the paths of the written shards, in file order.
+// On error, already written shards are removed.
+func (cr *CsvReader) WriteShards(ctx context.Context, dir string, shardSizeBytes int) ([]string, error) {
+	if shardSizeBytes < 1 {
+		return nil, errors.New("bigcsvreader: shard size must be positive")
+	}
+	cr, stream, err := cr.withDecompressingFS()
+	if err != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not open file (%w)", err)
+	}
+	if stream {
+		return nil, fmt.Errorf("bigcsvreader: could not write shards (%w)", errUnsupportedWithGzip)
+	}
+	fileSize, err := cr.getFileSize()
+	if err != nil {
+		return nil, fmt.Errorf("bigcsvreader: file size error (%w)", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not create shards directory (%w)", err)
+	}
+	f, err := cr.FS.Open(cr.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not open file (%w)", err)
+	}
+	defer f.Close()
+
+	// compute shards boundaries, aligned to lines starts.
+	var header []byte
+	offset := 0
+	if cr.FileHasHeader {
+		header, err = readLineAt(f, 0)
+		if err != nil {
+			return nil, fmt.Errorf("bigcsvreader: could not read header (%w: %w)", ErrBadHeader, err)
+		}
+		offset = len(header)
+	}
+	var boundaries [][2]int
+	for offset < fileSize {
+		end := offset + shardSizeBytes
+		if end < fileSize {
+			line, err := readLineAt(f, end-1)
+			if err != nil {
+				return nil, fmt.Errorf("bigcsvreader: could not read line at offset %d (%w)", end-1, err)
+			}
+			end += len(line) - 1
+		}
+		if end > fileSize {
+			end = fileSize
+		}
+		boundaries = append(boundaries, [2]int{offset, end})
+		offset = end
+	}
+
+	ext := ".csv"
+	if cr.CompressShards {
+		ext += ".gz"
+	}
+	var (
+		paths = make([]string, len(boundaries))
+		errs  = make([]error, len(boundaries))
+		sem   = make(chan struct{}, max(cr.MaxGoroutinesNo, 1))
+		wg    sync.WaitGroup
+	)
+	for i := range boundaries {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("shard-%05d%s", i+1, ext))
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := ctx.Err(); err != nil {
+				errs[i] = fmt.Errorf("bigcsvreader: received context error (%w)", err)
+
+				return
+			}
+			section := io.NewSectionReader(f, int64(boundaries[i][0]), int64(boundaries[i][1]-boundaries[i][0]))
+			if err := cr.writeShard(paths[i], header, section); err != nil {
+				errs[i] = fmt.Errorf("bigcsvreader: could not write shard %s (%w)", paths[i], err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		for _, path := range paths {
+			_ = os.Remove(path)
+		}
+		cr.Logger.Error("msg", "could not write shards", "err", err, "file", cr.fileBaseName)
+
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// writeShard writes header and given section of the file into a shard file.
+func (cr *CsvReader) writeShard(path string, header []byte, section io.Reader) error {
+	shard, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	var w io.Writer = shard
+	var gz *gzip.Writer
+	if cr.CompressShards {
+		gz = gzip.NewWriter(shard)
+		w = gz
+	}
+	_, err = w.Write(header)
+	if err == nil {
+		_, err = io.Copy(w, section)
+	}
+	if gz != nil {
+		if closeErr := gz.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := shard.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// readLineAt returns the line (end line delimiter included) starting at given offset.
+func readLineAt(f io.ReaderAt, offset int) ([]byte, error) {
+	r := bufio.NewReader(io.NewSectionReader(f, int64(offset), 1<<62))
+	line, err := r.ReadBytes('\n')
+	if err == io.EOF {
+		err = nil
+	}
+
+	return line, err
+}
@@ -0,0 +1,107 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_WriteShards(t *testing.T) {
+	t.Parallel()
+
+	t.Run("shards are record aligned", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		filePath, err := setUpTmpCsvFile(1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tearDownTmpCsvFile(filePath)
+		fileContent, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		dir := filepath.Join(t.TempDir(), "shards")
+
+		// act
+		paths, err := subject.WriteShards(context.Background(), dir, 100000)
+
+		// assert
+		assertNil(t, err)
+		assertTrue(t, len(paths) > 1)
+		var joined []byte
+		for i, path := range paths {
+			assertEqual(t, filepath.Join(dir, fmt.Sprintf("shard-%05d.csv", i+1)), path)
+			shard, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertTrue(t, bytes.HasSuffix(shard, []byte("\n")))
+			joined = append(joined, shard...)
+		}
+		assertEqual(t, fileContent, joined)
+	})
+
+	t.Run("compressed shards with header", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		subject.FileHasHeader = true
+		subject.CompressShards = true
+		dir := t.TempDir()
+
+		// act
+		paths, err := subject.WriteShards(context.Background(), dir, 20)
+
+		// assert
+		assertNil(t, err)
+		expected := []string{
+			"\"ID\";\"Name\";\"Age\"\n1;\"John\";33\n2;\"Jane\";30\n",
+			"\"ID\";\"Name\";\"Age\"\n3;\"Mike\";18\n4;\"Ronaldinho\";23\n",
+			"\"ID\";\"Name\";\"Age\"\n5;Elisabeth;45",
+		}
+		if assertEqual(t, len(expected), len(paths)) {
+			for i, path := range paths {
+				assertEqual(t, filepath.Join(dir, fmt.Sprintf("shard-%05d.csv.gz", i+1)), path)
+				assertEqual(t, expected[i], readGzipFile(t, path))
+			}
+		}
+	})
+}
+
+func readGzipFile(t *testing.T, path string) string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(content)
+}
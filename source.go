@@ -0,0 +1,91 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+// sourceName is the file path of the source set by [CsvReader.SetSource].
+const sourceName = "source"
+
+// SetSource sets the CSV data to read from, as size bytes readable by offset through r,
+// instead of a file path: a memory mapped region, an in-memory buffer ([bytes.Reader]),
+// a remote object read by ranges...
+// Goroutines read their chunks from r concurrently, as [io.ReaderAt] contract allows.
+// It replaces [CsvReader.FS], serving the source under the "source" path, see [CsvReader.SetFilePath]
+// for compressed data, detected by magic bytes.
+func (cr *CsvReader) SetSource(r io.ReaderAt, size int64) {
+	cr.FS = readerAtFS{r: r, size: size}
+	cr.SetFilePath(sourceName)
+}
+
+// readerAtFS is the file system serving the source set by [CsvReader.SetSource].
+type readerAtFS struct {
+	r    io.ReaderAt
+	size int64
+}
+
+// Open opens the source, the only file the file system has.
+func (fsys readerAtFS) Open(name string) (internal.File, error) {
+	if name != sourceName {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return readerAtFile{SectionReader: io.NewSectionReader(fsys.r, 0, fsys.size)}, nil
+}
+
+// Stat returns the source's info.
+func (fsys readerAtFS) Stat(name string) (os.FileInfo, error) {
+	if name != sourceName {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return sourceInfo{size: fsys.size}, nil
+}
+
+// readerAtFile reads the source set by [CsvReader.SetSource].
+type readerAtFile struct {
+	*io.SectionReader
+}
+
+// Close does nothing, the source being owned by the caller.
+func (readerAtFile) Close() error {
+	return nil
+}
+
+// Stat returns the source's info.
+func (f readerAtFile) Stat() (os.FileInfo, error) {
+	return sourceInfo{size: f.Size()}, nil
+}
+
+// sourceInfo is the info of the source set by [CsvReader.SetSource].
+type sourceInfo struct {
+	size int64
+}
+
+// Name returns the source's path.
+func (sourceInfo) Name() string { return sourceName }
+
+// Size returns the source's size.
+func (fi sourceInfo) Size() int64 { return fi.size }
+
+// Mode returns read only file mode bits.
+func (sourceInfo) Mode() os.FileMode { return 0o444 }
+
+// ModTime returns the zero time, the source's modification time is unknown.
+func (sourceInfo) ModTime() time.Time { return time.Time{} }
+
+// IsDir returns false, the source is a file.
+func (sourceInfo) IsDir() bool { return false }
+
+// Sys returns nil.
+func (sourceInfo) Sys() any { return nil }
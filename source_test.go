@@ -0,0 +1,74 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_SetSource(t *testing.T) {
+	t.Parallel()
+
+	const rowsCount = 5000
+	var (
+		content  strings.Builder
+		expected = make([][]string, 0, rowsCount)
+	)
+	content.WriteString("id,value\n")
+	for i := 1; i <= rowsCount; i++ {
+		expected = append(expected, []string{strconv.Itoa(i), "value " + strconv.Itoa(i)})
+		content.WriteString(strconv.Itoa(i) + ",value " + strconv.Itoa(i) + "\n")
+	}
+	tests := [...]struct {
+		name    string
+		content string
+	}{
+		{name: "plain data", content: content.String()},
+		{name: "gzip compressed data", content: gzipContent(t, content.String())},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetSource(strings.NewReader(test.content), int64(len(test.content)))
+			subject.FileHasHeader = true
+			subject.ColumnsCount = 2
+			subject.MaxGoroutinesNo = 4
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			header, errHeader := subject.ReadHeader(ctx)
+			rowsChans, errsChan := subject.Read(ctx)
+			rows := gatherRowsInOrder(rowsChans)
+			err := bigcsvreader.CollectErrors(errsChan)
+
+			// assert
+			sort.Slice(rows, func(i, j int) bool { // plain gzip decompressed lines are fanned out to goroutines.
+				id1, _ := strconv.Atoi(rows[i][0])
+				id2, _ := strconv.Atoi(rows[j][0])
+
+				return id1 < id2
+			})
+			assertNil(t, errHeader)
+			assertEqual(t, []string{"id", "value"}, header)
+			assertNil(t, err)
+			assertEqual(t, 4, len(rowsChans))
+			assertEqual(t, expected, rows)
+		})
+	}
+}
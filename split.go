@@ -0,0 +1,103 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// SplitColumns splits the wide CSV file into narrower files, one for each group of columns,
+// written into dir (created if it does not exist), named "split-001.csv", "split-002.csv"...
+// Each file holds the key columns followed by the group's columns, with a header.
+// Columns are identified by their header names, so the file must have a header.
+// The file is read once, in parallel, and rows are written in the file's order,
+// delimited by [CsvReader.ColumnsDelimiter].
+// It returns the paths of the written files, in groups order.
+// On error, already written files are removed.
+func (cr *CsvReader) SplitColumns(
+	ctx context.Context,
+	dir string,
+	keyColumns []string,
+	groups [][]string,
+) ([]string, error) {
+	if !cr.FileHasHeader {
+		return nil, errors.New("bigcsvreader: split requires a file with header")
+	}
+	if len(groups) == 0 {
+		return nil, errors.New("bigcsvreader: no columns groups to split into")
+	}
+	cr = cr.withContextFS(ctx)
+	header, err := cr.ReadHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		headers = make([][]string, len(groups))
+		indexes = make([][]int, len(groups))
+	)
+	for i, group := range groups {
+		headers[i] = append(append([]string(nil), keyColumns...), group...)
+		if indexes[i], err = columnsIndexes(header, headers[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not create split directory (%w)", err)
+	}
+
+	var (
+		paths = make([]string, len(groups))
+		files = make([]*os.File, 0, len(groups))
+		bws   = make([]*bufio.Writer, len(groups))
+		ws    = make([]io.Writer, len(groups))
+	)
+	removeAll := func() {
+		for _, file := range files {
+			_ = file.Close()
+			_ = os.Remove(file.Name())
+		}
+	}
+	for i := range groups {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("split-%03d.csv", i+1))
+		file, err := os.Create(paths[i])
+		if err != nil {
+			removeAll()
+
+			return nil, fmt.Errorf("bigcsvreader: could not create split file (%w)", err)
+		}
+		files = append(files, file)
+		bws[i] = bufio.NewWriter(file)
+		ws[i] = bws[i]
+	}
+
+	err = cr.writeOrderedMulti(ctx, ws, headers, cr.ColumnsDelimiter, func(row []string) ([][]string, error) {
+		outs := make([][]string, len(indexes))
+		for i := range indexes {
+			outs[i] = project(row, indexes[i])
+		}
+
+		return outs, nil
+	})
+	for i := 0; i < len(bws) && err == nil; i++ {
+		if err = bws[i].Flush(); err == nil {
+			err = files[i].Close()
+		}
+	}
+	if err != nil {
+		removeAll()
+		cr.Logger.Error("msg", "could not split columns", "err", err, "file", cr.fileBaseName)
+
+		return nil, err
+	}
+
+	return paths, nil
+}
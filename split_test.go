@@ -0,0 +1,81 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_SplitColumns(t *testing.T) {
+	t.Parallel()
+
+	t.Run("file is split into key and group columns", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 5000
+		var input, expected1, expected2 strings.Builder
+		input.WriteString("id,name,age,city\n")
+		expected1.WriteString("id,name\n")
+		expected2.WriteString("id,city,age\n")
+		for i := 1; i <= rowsCount; i++ {
+			fmt.Fprintf(&input, "%d,Name_%d,%d,City_%d\n", i, i, i%90, i%7)
+			fmt.Fprintf(&expected1, "%d,Name_%d\n", i, i)
+			fmt.Fprintf(&expected2, "%d,City_%d,%d\n", i, i%7, i%90)
+		}
+		tmpDir := t.TempDir()
+		filePath := filepath.Join(tmpDir, "people.csv")
+		if err := os.WriteFile(filePath, []byte(input.String()), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 4
+		subject.MaxGoroutinesNo = 8
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+		splitDir := filepath.Join(tmpDir, "split")
+
+		// act
+		paths, err := subject.SplitColumns(ctx, splitDir, []string{"id"}, [][]string{{"name"}, {"city", "age"}})
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, []string{filepath.Join(splitDir, "split-001.csv"), filepath.Join(splitDir, "split-002.csv")}, paths)
+		for i, expected := range []string{expected1.String(), expected2.String()} {
+			content, err := os.ReadFile(paths[i])
+			assertNil(t, err)
+			assertEqual(t, expected, string(content))
+		}
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_header.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsDelimiter = ';'
+		splitDir := filepath.Join(t.TempDir(), "split")
+
+		// act
+		paths, err := subject.SplitColumns(context.Background(), splitDir, []string{"ID"}, [][]string{{"Unknown"}})
+
+		// assert
+		assertNotNil(t, err)
+		assertNil(t, paths)
+	})
+}
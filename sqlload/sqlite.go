@@ -0,0 +1,185 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package sqlload
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+// SQLiteOptions holds the options for [LoadSQLite].
+type SQLiteOptions struct {
+	// Table is the table to create and load rows into.
+	Table string
+	// BatchSize is the number of rows inserted in a transaction.
+	// Defaults to 500.
+	BatchSize int
+}
+
+// LoadSQLite infers the schema of the CSV file (see [bigcsvreader.CsvReader.Analyze]), creates the table
+// into the SQLite database and loads the file into it in batches, giving instant SQL over the file.
+// Columns are named after the header, if the file has one, or "c1", "c2"... otherwise.
+// Values are converted to the inferred type (bool columns are stored as 0/1), empty values are stored as NULL,
+// so columns are type stable. Rows are parsed and converted in parallel, while writes are serialized,
+// as SQLite allows a single writer.
+// The db can be opened with any SQLite driver. It returns the number of loaded rows.
+func LoadSQLite(ctx context.Context, db *sql.DB, cr *bigcsvreader.CsvReader, opts SQLiteOptions) (int64, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	artifacts, err := cr.Analyze(ctx)
+	if err != nil {
+		return 0, err
+	}
+	columns := make([]string, artifacts.ColumnsCount)
+	if cr.FileHasHeader {
+		header, err := cr.Header()
+		if err != nil {
+			return 0, err
+		}
+		copy(columns, header)
+	}
+	for col := range columns {
+		if columns[col] == "" {
+			columns[col] = "c" + strconv.Itoa(col+1)
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, createTableSQL(opts.Table, columns, artifacts.ColumnsTypes)); err != nil {
+		return 0, fmt.Errorf("sqlload: could not create table (%w)", err)
+	}
+
+	var (
+		loaded  int64
+		writeMu sync.Mutex
+		insert  = insertSQL(opts.Table, columns)
+	)
+	rowsChans, errsChan := cr.Read(ctx)
+	err = bigcsvreader.Consume(
+		ctx,
+		rowsChans,
+		errsChan,
+		bigcsvreader.ConsumeOptions{BatchSize: opts.BatchSize, StopOnError: true},
+		func(ctx context.Context, rows [][]string) error {
+			args := make([][]any, len(rows))
+			for i, row := range rows {
+				args[i] = make([]any, len(columns))
+				for col := range columns {
+					if col < len(row) {
+						args[i][col] = sqliteValue(artifacts.ColumnsTypes[col], row[col])
+					}
+				}
+			}
+
+			writeMu.Lock()
+			defer writeMu.Unlock()
+			if err := insertBatch(ctx, db, insert, args); err != nil {
+				return fmt.Errorf("sqlload: could not insert rows (%w)", err)
+			}
+			atomic.AddInt64(&loaded, int64(len(rows)))
+
+			return nil
+		},
+	)
+
+	return loaded, err
+}
+
+// insertBatch inserts rows in a transaction.
+func insertBatch(ctx context.Context, db *sql.DB, insert string, rows [][]any) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, insert)
+	if err != nil {
+		_ = tx.Rollback()
+
+		return err
+	}
+	for _, args := range rows {
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			_ = stmt.Close()
+			_ = tx.Rollback()
+
+			return err
+		}
+	}
+	_ = stmt.Close()
+
+	return tx.Commit()
+}
+
+// createTableSQL returns the statement creating the table with given columns and types.
+func createTableSQL(table string, columns []string, types []bigcsvreader.ColumnType) string {
+	defs := make([]string, len(columns))
+	for col, column := range columns {
+		defs[col] = quoteIdentifier(column) + " " + sqliteType(types[col])
+	}
+
+	return "CREATE TABLE " + quoteIdentifier(table) + " (" + strings.Join(defs, ", ") + ")"
+}
+
+// insertSQL returns the statement inserting a row into the table.
+func insertSQL(table string, columns []string) string {
+	quoted := make([]string, len(columns))
+	for col, column := range columns {
+		quoted[col] = quoteIdentifier(column)
+	}
+
+	return "INSERT INTO " + quoteIdentifier(table) + " (" + strings.Join(quoted, ", ") + ")" +
+		" VALUES (" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+}
+
+// sqliteType returns the SQLite type for the inferred column type.
+func sqliteType(ct bigcsvreader.ColumnType) string {
+	switch ct {
+	case bigcsvreader.ColumnTypeBool, bigcsvreader.ColumnTypeInt:
+		return "INTEGER"
+	case bigcsvreader.ColumnTypeFloat:
+		return "REAL"
+	default:
+		return "TEXT"
+	}
+}
+
+// sqliteValue converts the value to the inferred column type.
+// Values were already checked by inference, so conversions do not fail.
+func sqliteValue(ct bigcsvreader.ColumnType, value string) any {
+	if value == "" {
+		return nil
+	}
+	switch ct {
+	case bigcsvreader.ColumnTypeBool:
+		if value == "true" {
+			return int64(1)
+		}
+
+		return int64(0)
+	case bigcsvreader.ColumnTypeInt:
+		n, _ := strconv.ParseInt(value, 10, 64)
+
+		return n
+	case bigcsvreader.ColumnTypeFloat:
+		f, _ := strconv.ParseFloat(value, 64)
+
+		return f
+	default:
+		return value
+	}
+}
+
+// quoteIdentifier quotes an SQLite identifier.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
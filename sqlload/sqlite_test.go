@@ -0,0 +1,61 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package sqlload_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/actforgood/bigcsvreader"
+	"github.com/actforgood/bigcsvreader/sqlload"
+)
+
+func TestLoadSQLite(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath := filepath.Join(t.TempDir(), "products.csv")
+	content := "id,name,price,active\n1,Pen,1.5,true\n2,,2,false\n3,Book,10.25,true\n"
+	if err := os.WriteFile(filePath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	db, fake := openFakeDB(t, "sqlite")
+	csvReader := bigcsvreader.New()
+	csvReader.SetFilePath(filePath)
+	csvReader.FileHasHeader = true
+	csvReader.ColumnsCount = 4
+
+	// act
+	rowsCount, err := sqlload.LoadSQLite(context.Background(), db, csvReader, sqlload.SQLiteOptions{Table: "products"})
+
+	// assert
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if rowsCount != 3 {
+		t.Errorf("expected 3 rows, got %d", rowsCount)
+	}
+	statements := fake.statements()
+	expectedCreate := `CREATE TABLE "products" ("id" INTEGER, "name" TEXT, "price" REAL, "active" INTEGER)`
+	if len(statements) == 0 || statements[0] != expectedCreate {
+		t.Errorf("expected first statement %q, got %q", expectedCreate, statements)
+	}
+	rows := fake.rows
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0].(int64) < rows[j][0].(int64) })
+	expectedRows := [][]driver.Value{
+		{int64(1), "Pen", 1.5, int64(1)},
+		{int64(2), nil, 2.0, int64(0)},
+		{int64(3), "Book", 10.25, int64(1)},
+	}
+	if !reflect.DeepEqual(expectedRows, rows) {
+		t.Errorf("expected rows %v, got %v", expectedRows, rows)
+	}
+}
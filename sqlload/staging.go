@@ -0,0 +1,216 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+// Package sqlload loads rows read by a [bigcsvreader.CsvReader] into a SQL database.
+package sqlload
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+// ErrCountMismatch is the error returned when the number of rows in the staging table
+// differs from the number of rows emitted by the reader.
+var ErrCountMismatch = errors.New("staging table rows count mismatch")
+
+// QuestionPlaceholder returns the "?" bind parameter placeholder (MySQL, SQLite, ...).
+func QuestionPlaceholder(int) string {
+	return "?"
+}
+
+// DollarPlaceholder returns the "$n" bind parameter placeholder (PostgreSQL, ...).
+func DollarPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// StagingOptions holds the options for [LoadStaged].
+// Table and columns names are used as provided, they are not quoted.
+type StagingOptions struct {
+	// Table is the target table.
+	Table string
+	// Columns holds the target table's columns, in the CSV columns order.
+	Columns []string
+	// StagingTable is the staging table, (re)created at the beginning of the load.
+	// Defaults to Table suffixed with "_staging".
+	StagingTable string
+	// CreateStagingSQL is the statement creating the staging table.
+	// Defaults to "CREATE TABLE <StagingTable> AS SELECT <Columns> FROM <Table> WHERE 1 = 0".
+	CreateStagingSQL string
+	// MergeSQL is the statement moving rows from the staging table into the target table,
+	// executed in the same transaction which drops the staging table.
+	// Defaults to "INSERT INTO <Table> (<Columns>) SELECT <Columns> FROM <StagingTable>".
+	MergeSQL string
+	// Placeholder returns the n-th (1-based) bind parameter placeholder.
+	// Defaults to [QuestionPlaceholder].
+	Placeholder func(n int) string
+	// WorkersNo is the number of goroutines loading rows into the staging table in parallel.
+	// Defaults to the number of rows channels.
+	WorkersNo int
+	// BatchSize is the number of rows inserted with a single statement.
+	// Defaults to 100.
+	BatchSize int
+}
+
+// LoadStaged loads the rows of given run into the target table using a staging table:
+// it creates the staging table, loads the rows into it in parallel, validates the staging table's
+// rows count against the reader's statistics, and then merges the rows into the target table
+// and drops the staging table in one transaction.
+// Any read error aborts the load before the merge, so the target table is either fully loaded or untouched.
+// It returns the number of merged rows.
+func LoadStaged(ctx context.Context, db *sql.DB, run *bigcsvreader.Run, opts StagingOptions) (int64, error) {
+	opts = opts.withDefaults()
+	if _, err := db.ExecContext(ctx, "DROP TABLE IF EXISTS "+opts.StagingTable); err != nil {
+		drainRun(run)
+
+		return 0, fmt.Errorf("sqlload: could not drop staging table (%w)", err)
+	}
+	if _, err := db.ExecContext(ctx, opts.CreateStagingSQL); err != nil {
+		drainRun(run)
+
+		return 0, fmt.Errorf("sqlload: could not create staging table (%w)", err)
+	}
+
+	var loaded int64
+	err := bigcsvreader.Consume(
+		ctx,
+		run.RowsChans(),
+		run.ErrsChan(),
+		bigcsvreader.ConsumeOptions{WorkersNo: opts.WorkersNo, BatchSize: opts.BatchSize, StopOnError: true},
+		func(ctx context.Context, rows [][]string) error {
+			query, args := opts.insertQuery(rows)
+			if _, err := db.ExecContext(ctx, query, args...); err != nil {
+				return fmt.Errorf("sqlload: could not insert rows into staging table (%w)", err)
+			}
+			atomic.AddInt64(&loaded, int64(len(rows)))
+
+			return nil
+		},
+	)
+	<-run.Done()
+	if err == nil {
+		err = opts.checkCount(ctx, db, run.RowsCount(), loaded)
+	}
+	if err == nil {
+		err = opts.merge(ctx, db)
+	}
+	if err != nil {
+		_, _ = db.ExecContext(context.Background(), "DROP TABLE IF EXISTS "+opts.StagingTable)
+
+		return 0, err
+	}
+
+	return loaded, nil
+}
+
+// withDefaults returns the options with defaults applied.
+func (opts StagingOptions) withDefaults() StagingOptions {
+	columns := strings.Join(opts.Columns, ", ")
+	if opts.StagingTable == "" {
+		opts.StagingTable = opts.Table + "_staging"
+	}
+	if opts.CreateStagingSQL == "" {
+		opts.CreateStagingSQL = "CREATE TABLE " + opts.StagingTable +
+			" AS SELECT " + columns + " FROM " + opts.Table + " WHERE 1 = 0"
+	}
+	if opts.MergeSQL == "" {
+		opts.MergeSQL = "INSERT INTO " + opts.Table + " (" + columns + ")" +
+			" SELECT " + columns + " FROM " + opts.StagingTable
+	}
+	if opts.Placeholder == nil {
+		opts.Placeholder = QuestionPlaceholder
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+
+	return opts
+}
+
+// insertQuery returns the multi-row insert statement into the staging table for given rows.
+func (opts StagingOptions) insertQuery(rows [][]string) (string, []any) {
+	var (
+		sb   strings.Builder
+		args = make([]any, 0, len(rows)*len(opts.Columns))
+	)
+	sb.WriteString("INSERT INTO " + opts.StagingTable + " (" + strings.Join(opts.Columns, ", ") + ") VALUES ")
+	for i, row := range rows {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('(')
+		for col := range opts.Columns {
+			if col > 0 {
+				sb.WriteString(", ")
+			}
+			var value string
+			if col < len(row) {
+				value = row[col]
+			}
+			args = append(args, value)
+			sb.WriteString(opts.Placeholder(len(args)))
+		}
+		sb.WriteByte(')')
+	}
+
+	return sb.String(), args
+}
+
+// checkCount validates the staging table's rows count against the reader's statistics.
+func (opts StagingOptions) checkCount(ctx context.Context, db *sql.DB, emitted, loaded int64) error {
+	var staged int64
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+opts.StagingTable).Scan(&staged); err != nil {
+		return fmt.Errorf("sqlload: could not count staging table rows (%w)", err)
+	}
+	if staged != emitted || staged != loaded {
+		return fmt.Errorf(
+			"sqlload: %d rows staged, %d rows emitted, %d rows loaded (%w)",
+			staged, emitted, loaded, ErrCountMismatch,
+		)
+	}
+
+	return nil
+}
+
+// merge moves the rows from the staging table into the target table, and drops the staging table,
+// in one transaction.
+func (opts StagingOptions) merge(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlload: could not begin transaction (%w)", err)
+	}
+	if _, err := tx.ExecContext(ctx, opts.MergeSQL); err != nil {
+		_ = tx.Rollback()
+
+		return fmt.Errorf("sqlload: could not merge staging table (%w)", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DROP TABLE "+opts.StagingTable); err != nil {
+		_ = tx.Rollback()
+
+		return fmt.Errorf("sqlload: could not drop staging table (%w)", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sqlload: could not commit transaction (%w)", err)
+	}
+
+	return nil
+}
+
+// drainRun discards the remaining rows and errors of the run, so its goroutines can terminate.
+func drainRun(run *bigcsvreader.Run) {
+	_ = bigcsvreader.Consume(
+		context.Background(),
+		run.RowsChans(),
+		run.ErrsChan(),
+		bigcsvreader.ConsumeOptions{},
+		func(context.Context, [][]string) error { return nil },
+	)
+}
@@ -0,0 +1,262 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package sqlload_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+	"github.com/actforgood/bigcsvreader/sqlload"
+)
+
+func TestLoadStaged(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rows are staged, counted and merged", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		db, fake := openFakeDB(t, "success")
+		run := startReading(t)
+
+		// act
+		rowsCount, err := sqlload.LoadStaged(context.Background(), db, run, sqlload.StagingOptions{
+			Table:       "users",
+			Columns:     []string{"id", "name", "age"},
+			Placeholder: sqlload.DollarPlaceholder,
+			BatchSize:   2,
+		})
+
+		// assert
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if rowsCount != 5 {
+			t.Errorf("expected 5 rows, got %d", rowsCount)
+		}
+		statements := fake.statements()
+		expected := []string{
+			"DROP TABLE IF EXISTS users_staging",
+			"CREATE TABLE users_staging AS SELECT id, name, age FROM users WHERE 1 = 0",
+			"SELECT COUNT(*) FROM users_staging",
+			"BEGIN",
+			"INSERT INTO users (id, name, age) SELECT id, name, age FROM users_staging",
+			"DROP TABLE users_staging",
+			"COMMIT",
+		}
+		if len(statements) != len(expected) {
+			t.Fatalf("expected statements %q, got %q", expected, statements)
+		}
+		for i := range expected {
+			if statements[i] != expected[i] {
+				t.Errorf("expected statement %q, got %q", expected[i], statements[i])
+			}
+		}
+		if fake.inserts != 3 {
+			t.Errorf("expected 3 insert batches, got %d", fake.inserts)
+		}
+	})
+
+	t.Run("count mismatch aborts the merge", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		db, fake := openFakeDB(t, "mismatch")
+		fake.countDelta = 1
+		run := startReading(t)
+
+		// act
+		rowsCount, err := sqlload.LoadStaged(context.Background(), db, run, sqlload.StagingOptions{
+			Table:   "users",
+			Columns: []string{"id", "name", "age"},
+		})
+
+		// assert
+		if !errors.Is(err, sqlload.ErrCountMismatch) {
+			t.Errorf("expected count mismatch error, got %v", err)
+		}
+		if rowsCount != 0 {
+			t.Errorf("expected 0 rows, got %d", rowsCount)
+		}
+		for _, statement := range fake.statements() {
+			if statement == "BEGIN" {
+				t.Error("expected no merge transaction")
+			}
+		}
+	})
+}
+
+func startReading(t *testing.T) *bigcsvreader.Run {
+	t.Helper()
+
+	csvReader := bigcsvreader.New()
+	csvReader.SetFilePath("../testdata/file_with_header.csv")
+	csvReader.ColumnsCount = 3
+	csvReader.FileHasHeader = true
+	csvReader.ColumnsDelimiter = ';'
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	t.Cleanup(cancelCtx)
+
+	return csvReader.Start(ctx)
+}
+
+var (
+	fakeDrivers   = make(map[string]*fakeDriver)
+	fakeDriversMu sync.Mutex
+)
+
+func init() {
+	sql.Register("sqlloadfake", fakeConnector{})
+}
+
+func openFakeDB(t *testing.T, name string) (*sql.DB, *fakeDriver) {
+	t.Helper()
+
+	fake := &fakeDriver{}
+	fakeDriversMu.Lock()
+	fakeDrivers[name] = fake
+	fakeDriversMu.Unlock()
+	db, err := sql.Open("sqlloadfake", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db, fake
+}
+
+// fakeConnector is a database/sql driver routing connections to the fakeDriver registered under the DSN.
+type fakeConnector struct{}
+
+func (fakeConnector) Open(name string) (driver.Conn, error) {
+	fakeDriversMu.Lock()
+	defer fakeDriversMu.Unlock()
+
+	return &fakeConn{fake: fakeDrivers[name]}, nil
+}
+
+// fakeDriver records executed statements (except inserts, which are counted / recorded).
+type fakeDriver struct {
+	mu         sync.Mutex
+	log        []string
+	staged     int64
+	inserts    int
+	countDelta int64
+	rows       [][]driver.Value
+}
+
+func (fd *fakeDriver) statements() []string {
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+
+	return append([]string(nil), fd.log...)
+}
+
+func (fd *fakeDriver) exec(query string, args []driver.Value) {
+	fd.mu.Lock()
+	defer fd.mu.Unlock()
+	if strings.HasPrefix(query, "INSERT INTO users_staging") {
+		fd.inserts++
+		fd.staged += int64(len(args) / 3)
+
+		return
+	}
+	if strings.HasPrefix(query, `INSERT INTO "`) {
+		fd.rows = append(fd.rows, args)
+
+		return
+	}
+	fd.log = append(fd.log, query)
+}
+
+type fakeConn struct {
+	fake *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{fake: c.fake, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.fake.exec("BEGIN", nil)
+
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.fake.exec("COMMIT", nil)
+
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.fake.exec("ROLLBACK", nil)
+
+	return nil
+}
+
+type fakeStmt struct {
+	fake  *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.fake.exec(s.query, args)
+
+	return driver.RowsAffected(0), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.fake.exec(s.query, args)
+	s.fake.mu.Lock()
+	count := s.fake.staged + s.fake.countDelta
+	s.fake.mu.Unlock()
+
+	return &fakeRows{value: count}, nil
+}
+
+type fakeRows struct {
+	value int64
+	read  bool
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"count"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	dest[0] = r.value
+
+	return nil
+}
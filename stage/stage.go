@@ -0,0 +1,245 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+// Package stage re-chunks rows read by a [bigcsvreader.CsvReader] into compressed, size-bounded
+// CSV parts, and uploads them concurrently to a cloud stage (GCS, S3, Azure Blob Storage...)
+// ready to be loaded by a warehouse (BigQuery LOAD, Snowflake COPY INTO...).
+package stage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+const (
+	defaultMaxPartSize  = 64 << 20 // 64 MiB
+	defaultConcurrency  = 4
+	defaultManifestName = "manifest.json"
+)
+
+// Uploader uploads content under given object name.
+// It is usually a thin wrapper around a cloud storage client.
+// Implementations must be safe for concurrent use.
+type Uploader interface {
+	Upload(ctx context.Context, name string, content []byte) error
+}
+
+// UploaderFunc is a function adapter for [Uploader].
+type UploaderFunc func(ctx context.Context, name string, content []byte) error
+
+// Upload calls f.
+func (f UploaderFunc) Upload(ctx context.Context, name string, content []byte) error {
+	return f(ctx, name, content)
+}
+
+// Options holds the options for [Upload].
+type Options struct {
+	// Prefix is prepended to each object name (for example "imports/2024-01-01/").
+	Prefix string
+	// MaxPartSize is the (approximate) maximum size, in bytes, of a compressed part.
+	// Defaults to 64 MiB.
+	MaxPartSize int
+	// Concurrency is the maximum number of concurrent uploads.
+	// Defaults to 4.
+	Concurrency int
+	// Delimiter is the CSV parts' columns delimiter.
+	// Defaults to ','.
+	Delimiter rune
+	// ManifestName is the name of the manifest object, uploaded after all parts.
+	// Defaults to "manifest.json".
+	ManifestName string
+}
+
+// Manifest lists the uploaded parts.
+type Manifest struct {
+	// Entries holds the uploaded parts, sorted by name.
+	Entries []Part `json:"entries"`
+	// RowsCount is the total number of rows.
+	RowsCount int64 `json:"rowsCount"`
+}
+
+// Part describes an uploaded part.
+type Part struct {
+	// Name is the object name.
+	Name string `json:"url"`
+	// Mandatory is always true (COPY fails if the part is missing).
+	Mandatory bool `json:"mandatory"`
+	// RowsCount is the number of rows in the part.
+	RowsCount int64 `json:"rowsCount"`
+	// Size is the compressed size of the part.
+	Size int64 `json:"size"`
+	// SHA256 is the hex encoded checksum of the compressed part.
+	SHA256 string `json:"sha256"`
+}
+
+// part is a part being built.
+type part struct {
+	name      string
+	buf       bytes.Buffer
+	gz        *gzip.Writer
+	w         *csv.Writer
+	rowsCount int64
+}
+
+// Upload re-chunks the rows read by a [bigcsvreader.CsvReader] into gzip compressed CSV parts,
+// each reading goroutine filling its own parts, and uploads the parts concurrently.
+// After all parts were uploaded successfully, the manifest is uploaded too.
+// It blocks until everything was consumed and returns the manifest,
+// and all the errors received through errsChan and returned by the uploader, joined with [errors.Join].
+// If there was any error, the manifest is not uploaded.
+func Upload(
+	ctx context.Context,
+	rowsChans []bigcsvreader.RowsChan,
+	errsChan bigcsvreader.ErrsChan,
+	uploader Uploader,
+	opts Options,
+) (Manifest, error) {
+	opts = opts.withDefaults()
+	var (
+		manifest  Manifest
+		errs      []error
+		mu        sync.Mutex
+		buildWg   sync.WaitGroup
+		uploadWg  sync.WaitGroup
+		partsChan = make(chan *part)
+	)
+	addErr := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+
+	uploadWg.Add(opts.Concurrency)
+	for i := 0; i < opts.Concurrency; i++ {
+		go func() {
+			defer uploadWg.Done()
+			for p := range partsChan {
+				content := p.buf.Bytes()
+				if err := uploader.Upload(ctx, p.name, content); err != nil {
+					addErr(fmt.Errorf("stage: could not upload part %s (%w)", p.name, err))
+
+					continue
+				}
+				checksum := sha256.Sum256(content)
+				mu.Lock()
+				manifest.Entries = append(manifest.Entries, Part{
+					Name:      p.name,
+					Mandatory: true,
+					RowsCount: p.rowsCount,
+					Size:      int64(len(content)),
+					SHA256:    hex.EncodeToString(checksum[:]),
+				})
+				manifest.RowsCount += p.rowsCount
+				mu.Unlock()
+			}
+		}()
+	}
+
+	buildWg.Add(len(rowsChans) + 1)
+	for i := 0; i < len(rowsChans); i++ {
+		go func(thread int, rowsChan bigcsvreader.RowsChan) {
+			defer buildWg.Done()
+			var (
+				p     *part
+				parts int
+			)
+			for row := range rowsChan {
+				if p == nil {
+					parts++
+					p = opts.newPart(thread, parts)
+				}
+				if err := p.w.Write(row); err != nil {
+					addErr(fmt.Errorf("stage: could not write part %s (%w)", p.name, err))
+
+					continue
+				}
+				p.rowsCount++
+				if p.buf.Len() >= opts.MaxPartSize {
+					opts.finishPart(p, partsChan, addErr)
+					p = nil
+				}
+			}
+			if p != nil {
+				opts.finishPart(p, partsChan, addErr)
+			}
+		}(i+1, rowsChans[i])
+	}
+	go func() {
+		defer buildWg.Done()
+		for err := range errsChan {
+			addErr(err)
+		}
+	}()
+	buildWg.Wait()
+	close(partsChan)
+	uploadWg.Wait()
+
+	sort.Slice(manifest.Entries, func(i, j int) bool {
+		return manifest.Entries[i].Name < manifest.Entries[j].Name
+	})
+	if len(errs) > 0 {
+		return manifest, errors.Join(errs...)
+	}
+	content, _ := json.MarshalIndent(manifest, "", "  ")
+	if err := uploader.Upload(ctx, opts.Prefix+opts.ManifestName, content); err != nil {
+		return manifest, fmt.Errorf("stage: could not upload manifest (%w)", err)
+	}
+
+	return manifest, nil
+}
+
+// withDefaults returns the options with defaults applied.
+func (opts Options) withDefaults() Options {
+	if opts.MaxPartSize <= 0 {
+		opts.MaxPartSize = defaultMaxPartSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	if opts.Delimiter == 0 {
+		opts.Delimiter = ','
+	}
+	if opts.ManifestName == "" {
+		opts.ManifestName = defaultManifestName
+	}
+
+	return opts
+}
+
+// newPart starts a new part, named after the reading goroutine and the part's sequence number.
+func (opts Options) newPart(thread, seq int) *part {
+	p := &part{name: fmt.Sprintf("%spart-%03d-%05d.csv.gz", opts.Prefix, thread, seq)}
+	p.gz = gzip.NewWriter(&p.buf)
+	p.w = csv.NewWriter(p.gz)
+	p.w.Comma = opts.Delimiter
+
+	return p
+}
+
+// finishPart flushes and closes the part, and hands it for upload.
+func (opts Options) finishPart(p *part, partsChan chan<- *part, addErr func(error)) {
+	p.w.Flush()
+	err := p.w.Error()
+	if closeErr := p.gz.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		addErr(fmt.Errorf("stage: could not write part %s (%w)", p.name, err))
+
+		return
+	}
+	partsChan <- p
+}
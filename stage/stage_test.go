@@ -0,0 +1,150 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package stage_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+	"github.com/actforgood/bigcsvreader/stage"
+)
+
+func TestUpload(t *testing.T) {
+	t.Parallel()
+
+	t.Run("parts and manifest are uploaded", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 20000
+		rowsChans, errsChan := startReading(t, rowsCount)
+		var (
+			objects   = make(map[string][]byte)
+			objectsMu sync.Mutex
+		)
+		uploader := stage.UploaderFunc(func(_ context.Context, name string, content []byte) error {
+			objectsMu.Lock()
+			objects[name] = content
+			objectsMu.Unlock()
+
+			return nil
+		})
+
+		// act
+		manifest, err := stage.Upload(context.Background(), rowsChans, errsChan, uploader, stage.Options{
+			Prefix:      "imports/",
+			MaxPartSize: 16 << 10,
+		})
+
+		// assert
+		if err != nil {
+			t.Fatalf("expected nil error, got %v", err)
+		}
+		if manifest.RowsCount != rowsCount {
+			t.Errorf("expected %d rows, got %d", rowsCount, manifest.RowsCount)
+		}
+		if len(manifest.Entries) < 2 {
+			t.Errorf("expected more parts, got %d", len(manifest.Entries))
+		}
+		var partsRowsCount int64
+		for _, part := range manifest.Entries {
+			if !strings.HasPrefix(part.Name, "imports/part-") {
+				t.Errorf("unexpected part name %q", part.Name)
+			}
+			records := readPart(t, objects[part.Name])
+			if int64(len(records)) != part.RowsCount {
+				t.Errorf("expected %d rows in part %q, got %d", part.RowsCount, part.Name, len(records))
+			}
+			partsRowsCount += part.RowsCount
+		}
+		if partsRowsCount != rowsCount {
+			t.Errorf("expected %d rows in parts, got %d", rowsCount, partsRowsCount)
+		}
+		var uploadedManifest stage.Manifest
+		if err := json.Unmarshal(objects["imports/manifest.json"], &uploadedManifest); err != nil {
+			t.Fatal(err)
+		}
+		if len(uploadedManifest.Entries) != len(manifest.Entries) {
+			t.Errorf("expected uploaded manifest to have %d entries, got %d", len(manifest.Entries), len(uploadedManifest.Entries))
+		}
+	})
+
+	t.Run("manifest is not uploaded on error", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		rowsChans, errsChan := startReading(t, 100)
+		uploadErr := errors.New("intentionally triggered upload error")
+		var manifestUploaded bool
+		uploader := stage.UploaderFunc(func(_ context.Context, name string, _ []byte) error {
+			if strings.HasSuffix(name, "manifest.json") {
+				manifestUploaded = true
+			}
+
+			return uploadErr
+		})
+
+		// act
+		_, err := stage.Upload(context.Background(), rowsChans, errsChan, uploader, stage.Options{})
+
+		// assert
+		if !errors.Is(err, uploadErr) {
+			t.Errorf("expected upload error, got %v", err)
+		}
+		if manifestUploaded {
+			t.Error("expected manifest not to be uploaded")
+		}
+	})
+}
+
+func startReading(t *testing.T, rowsCount int) ([]bigcsvreader.RowsChan, bigcsvreader.ErrsChan) {
+	t.Helper()
+
+	var sb strings.Builder
+	for i := 1; i <= rowsCount; i++ {
+		fmt.Fprintf(&sb, "%d,Product_%d,%d\n", i, i, i%100)
+	}
+	filePath := filepath.Join(t.TempDir(), "products.csv")
+	if err := os.WriteFile(filePath, []byte(sb.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	csvReader := bigcsvreader.New()
+	csvReader.SetFilePath(filePath)
+	csvReader.ColumnsCount = 3
+	csvReader.MaxGoroutinesNo = 4
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	t.Cleanup(cancelCtx)
+
+	return csvReader.Read(ctx)
+}
+
+func readPart(t *testing.T, content []byte) [][]string {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(gz).ReadAll()
+	if err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+
+	return records
+}
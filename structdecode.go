@@ -0,0 +1,330 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// structTag is the struct tag mapping a field to a column, see [StructDecoder].
+const structTag = "csv"
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// FieldDecodeError is the error returned when a column value could not be decoded into its struct field.
+type FieldDecodeError struct {
+	// Column is the column's header name (or position, like "$3").
+	Column string
+	// Field is the struct field's name.
+	Field string
+	// Value is the value which could not be decoded.
+	Value string
+	// Err is the decoding error.
+	Err error
+}
+
+// Error returns the error's message.
+func (e *FieldDecodeError) Error() string {
+	return fmt.Sprintf("could not decode column %q value %q into field %s: %v", e.Column, e.Value, e.Field, e.Err)
+}
+
+// Unwrap returns the decoding error.
+func (e *FieldDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// StructDecoder decodes rows into structs of type T, mapping columns to the struct's exported fields
+// through `csv` tags, like:
+//
+//	type Product struct {
+//		ID     int       `csv:"id"`
+//		Price  float64   `csv:"price,locale=de"`
+//		Active bool      `csv:"active,bool=yn"`
+//		Added  time.Time `csv:"added,layout=2006-01-02"`
+//		Note   string    `csv:"$5"`
+//	}
+//
+// A tag's name is the column's header name, or its 1-based position prefixed by "$" (like [expr] references).
+// Fields without tag (or with "-" tag) are left untouched.
+// Supported field types are string, bool, integers, floats, [time.Time] and [encoding.TextUnmarshaler]
+// implementations (through a pointer). Tag options, following the name, separated by commas:
+//   - locale=<tag> parses numbers formatted according to [LocaleNumberFormat], like "1.234,56" for "de";
+//   - bool=<name> parses booleans with the [LookupBoolDialect] dialect, [BoolDialectDefault] by default;
+//   - layout=<layout> parses times with given [time.Parse] layout (which must not hold commas),
+//     [time.RFC3339] by default.
+//
+// Empty values leave non string fields to their zero value.
+// A decoder is safe for concurrent use.
+type StructDecoder[T any] struct {
+	fields []fieldDecoder
+}
+
+// fieldDecoder decodes a column into a struct field.
+type fieldDecoder struct {
+	field  int
+	name   string
+	col    int
+	column string
+	set    func(v reflect.Value, value string) error
+}
+
+// NewStructDecoder returns a decoder of rows into structs of type T, resolving columns names
+// against given header (nil if the file has no header, case in which columns are referenced by position).
+func NewStructDecoder[T any](header []string) (*StructDecoder[T], error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("bigcsvreader: %s is not a struct", t)
+	}
+	var fields []fieldDecoder
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get(structTag)
+		if tag == "" || tag == "-" || !f.IsExported() {
+			continue
+		}
+		column, options, _ := strings.Cut(tag, ",")
+		col, err := columnIndex(header, column)
+		if err != nil {
+			return nil, fmt.Errorf("bigcsvreader: field %s (%w)", f.Name, err)
+		}
+		set, err := newFieldSetter(f.Type, options)
+		if err != nil {
+			return nil, fmt.Errorf("bigcsvreader: field %s (%w)", f.Name, err)
+		}
+		fields = append(fields, fieldDecoder{field: i, name: f.Name, col: col, column: column, set: set})
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("bigcsvreader: %s has no %q tagged fields", t, structTag)
+	}
+
+	return &StructDecoder[T]{fields: fields}, nil
+}
+
+// Decode decodes the row into a struct.
+// It returns a [*FieldDecodeError] if a value could not be decoded.
+func (d *StructDecoder[T]) Decode(row []string) (T, error) {
+	var decoded T
+	v := reflect.ValueOf(&decoded).Elem()
+	for _, fd := range d.fields {
+		var value string
+		if fd.col < len(row) {
+			value = row[fd.col]
+		}
+		if err := fd.set(v.Field(fd.field), value); err != nil {
+			var zero T
+
+			return zero, &FieldDecodeError{Column: fd.column, Field: fd.name, Value: value, Err: err}
+		}
+	}
+
+	return decoded, nil
+}
+
+// ReadInto extracts asynchronously CSV rows, like [CsvReader.Read] does, and decodes them
+// into structs of type T with a [StructDecoder] (columns names are resolved against the run's header,
+// if [CsvReader.FileHasHeader] is enabled). It returns a channel of structs for each started goroutine,
+// and an errors channel which carries both the reading errors and the decoding errors, see [MapRows].
+// Rows which could not be decoded are disregarded. [CsvReader.EmitHeader] is not applied.
+func ReadInto[T any](ctx context.Context, cr *CsvReader) ([]<-chan T, ErrsChan) {
+	if cr.EmitHeader {
+		reader := cr.clone()
+		reader.Manifest = cr.Manifest
+		reader.EmitHeader = false
+		cr = reader
+	}
+	ctx, cancelCtx := context.WithCancel(ctx)
+	rn := cr.Start(ctx)
+	if len(rn.RowsChans()) == 0 { // the run failed to start, its errors are forwarded.
+		cancelCtx()
+
+		return nil, rn.ErrsChan()
+	}
+	decoder, err := NewStructDecoder[T](rn.Header())
+	if err != nil {
+		cancelCtx()
+		go func() {
+			for range rn.ErrsChan() {
+			}
+		}()
+		drainRowsChans(rn.RowsChans())
+
+		return nil, cr.failedRun(make(chan error, 1), "invalid struct decoder", err).ErrsChan()
+	}
+	go func() {
+		<-rn.Done()
+		cancelCtx()
+	}()
+
+	return MapRows(rn.RowsChans(), rn.ErrsChan(), decoder.Decode)
+}
+
+// columnIndex returns the index of given column, referenced by header name, or by 1-based position ("$3").
+func columnIndex(header []string, column string) (int, error) {
+	if position, ok := strings.CutPrefix(column, "$"); ok {
+		if n, err := strconv.Atoi(position); err == nil && n > 0 {
+			return n - 1, nil
+		}
+
+		return 0, fmt.Errorf("invalid column position %q", column)
+	}
+	if col := slices.Index(header, column); col >= 0 {
+		return col, nil
+	}
+
+	return 0, fmt.Errorf("unknown column %q", column)
+}
+
+// newFieldSetter returns the function setting a field of given type from a column value, given the tag's options.
+func newFieldSetter(t reflect.Type, options string) (func(v reflect.Value, value string) error, error) {
+	var (
+		numberFormat *NumberFormat
+		boolDialect  = BoolDialectDefault
+		layout       = time.RFC3339
+	)
+	for _, option := range strings.Split(options, ",") {
+		if option == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "locale":
+			nf, ok := LocaleNumberFormat(value)
+			if !ok {
+				return nil, fmt.Errorf("unknown locale %q", value)
+			}
+			numberFormat = &nf
+		case "bool":
+			dialect, ok := LookupBoolDialect(value)
+			if !ok {
+				return nil, fmt.Errorf("unknown bool dialect %q", value)
+			}
+			boolDialect = dialect
+		case "layout":
+			layout = value
+		default:
+			return nil, fmt.Errorf("unknown tag option %q", option)
+		}
+	}
+
+	switch {
+	case t == timeType:
+		return skipEmpty(func(v reflect.Value, value string) error {
+			parsed, err := time.Parse(layout, value)
+			v.Set(reflect.ValueOf(parsed))
+
+			return err
+		}), nil
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return skipEmpty(func(v reflect.Value, value string) error {
+			return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+		}), nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return func(v reflect.Value, value string) error {
+			v.SetString(value)
+
+			return nil
+		}, nil
+	case reflect.Bool:
+		return skipEmpty(func(v reflect.Value, value string) error {
+			parsed, err := boolDialect.Parse(value)
+			v.SetBool(parsed)
+
+			return err
+		}), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return skipEmpty(func(v reflect.Value, value string) error {
+			parsed, err := parseInt(numberFormat, value, t.Bits())
+			if err == nil && v.OverflowInt(parsed) {
+				err = strconv.ErrRange
+			}
+			if err == nil {
+				v.SetInt(parsed)
+			}
+
+			return err
+		}), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return skipEmpty(func(v reflect.Value, value string) error {
+			parsed, err := parseUint(numberFormat, value, t.Bits())
+			if err == nil && v.OverflowUint(parsed) {
+				err = strconv.ErrRange
+			}
+			if err == nil {
+				v.SetUint(parsed)
+			}
+
+			return err
+		}), nil
+	case reflect.Float32, reflect.Float64:
+		return skipEmpty(func(v reflect.Value, value string) error {
+			var (
+				parsed float64
+				err    error
+			)
+			if numberFormat != nil {
+				parsed, err = numberFormat.ParseFloat(value)
+			} else {
+				parsed, err = strconv.ParseFloat(value, t.Bits())
+			}
+			if err == nil && v.OverflowFloat(parsed) {
+				err = strconv.ErrRange
+			}
+			if err == nil {
+				v.SetFloat(parsed)
+			}
+
+			return err
+		}), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// parseInt parses an integer, formatted according to given number format, if not nil.
+func parseInt(numberFormat *NumberFormat, value string, bitSize int) (int64, error) {
+	if numberFormat != nil {
+		return numberFormat.ParseInt(value)
+	}
+
+	return strconv.ParseInt(value, 10, bitSize)
+}
+
+// parseUint parses an unsigned integer, formatted according to given number format, if not nil.
+func parseUint(numberFormat *NumberFormat, value string, bitSize int) (uint64, error) {
+	if numberFormat != nil {
+		normalized, ok := numberFormat.normalize(value)
+		if !ok || strings.Contains(normalized, ".") {
+			return 0, &strconv.NumError{Func: "ParseUint", Num: value, Err: strconv.ErrSyntax}
+		}
+		value = normalized
+	}
+
+	return strconv.ParseUint(value, 10, bitSize)
+}
+
+// skipEmpty returns a setter which leaves the field untouched for empty values.
+func skipEmpty(set func(v reflect.Value, value string) error) func(v reflect.Value, value string) error {
+	return func(v reflect.Value, value string) error {
+		if value == "" {
+			return nil
+		}
+
+		return set(v, value)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+type decodedProduct struct {
+	ID     int        `csv:"id"`
+	Name   string     `csv:"name"`
+	Price  float64    `csv:"price,locale=de"`
+	Qty    uint16     `csv:"qty"`
+	Active bool       `csv:"active,bool=yn"`
+	Added  time.Time  `csv:"added,layout=2006-01-02"`
+	Addr   netip.Addr `csv:"$7"`
+	Note   string
+}
+
+func TestStructDecoder_Decode(t *testing.T) {
+	t.Parallel()
+
+	header := []string{"id", "name", "price", "qty", "active", "added", "ip"}
+	subject, err := bigcsvreader.NewStructDecoder[decodedProduct](header)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := [...]struct {
+		name        string
+		row         []string
+		expected    decodedProduct
+		expectedErr string
+	}{
+		{
+			name: "all fields",
+			row:  []string{"7", "Pen", "1.234,5", "12", "Y", "2024-03-01", "10.0.0.1"},
+			expected: decodedProduct{
+				ID: 7, Name: "Pen", Price: 1234.5, Qty: 12, Active: true,
+				Added: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Addr: netip.MustParseAddr("10.0.0.1"),
+			},
+		},
+		{
+			name:     "empty values leave zero values",
+			row:      []string{"8", "", "", "", "", "", ""},
+			expected: decodedProduct{ID: 8},
+		},
+		{
+			name:        "invalid bool",
+			row:         []string{"9", "Pen", "1", "1", "true", "", ""},
+			expectedErr: "active",
+		},
+		{
+			name:        "overflow",
+			row:         []string{"9", "Pen", "1", "70000", "N", "", ""},
+			expectedErr: "qty",
+		},
+	}
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			result, err := subject.Decode(test.row)
+
+			// assert
+			if test.expectedErr == "" {
+				assertNil(t, err)
+				assertEqual(t, test.expected, result)
+			} else {
+				var decodeErr *bigcsvreader.FieldDecodeError
+				if assertTrue(t, errors.As(err, &decodeErr)) {
+					assertEqual(t, test.expectedErr, decodeErr.Column)
+				}
+			}
+		})
+	}
+}
+
+func TestStructDecoder_Decode_unsigned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	type row struct {
+		Plain  uint64 `csv:"plain"`
+		Locale uint64 `csv:"locale,locale=de"`
+	}
+	subject, err := bigcsvreader.NewStructDecoder[row]([]string{"plain", "locale"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	result, err1 := subject.Decode([]string{"18446744073709551615", "18.446.744.073.709.551.615"})
+	_, err2 := subject.Decode([]string{"-1", ""})
+	_, err3 := subject.Decode([]string{"", "-1"})
+
+	// assert
+	assertNil(t, err1)
+	assertEqual(t, row{Plain: math.MaxUint64, Locale: math.MaxUint64}, result)
+	var decodeErr *bigcsvreader.FieldDecodeError
+	if assertTrue(t, errors.As(err2, &decodeErr)) {
+		assertEqual(t, "plain", decodeErr.Column)
+	}
+	if assertTrue(t, errors.As(err3, &decodeErr)) {
+		assertEqual(t, "locale", decodeErr.Column)
+	}
+}
+
+func TestNewStructDecoder_errors(t *testing.T) {
+	t.Parallel()
+
+	header := []string{"id", "name"}
+	_, err := bigcsvreader.NewStructDecoder[struct {
+		Email string `csv:"email"`
+	}](header)
+	assertNotNil(t, err)
+	_, err = bigcsvreader.NewStructDecoder[struct {
+		ID int `csv:"id,locale=xx"`
+	}](header)
+	assertNotNil(t, err)
+	_, err = bigcsvreader.NewStructDecoder[struct {
+		ID []int `csv:"id"`
+	}](header)
+	assertNotNil(t, err)
+	_, err = bigcsvreader.NewStructDecoder[[]string](header)
+	assertNotNil(t, err)
+}
+
+func TestReadInto(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		ID   int    `csv:"id"`
+		Name string `csv:"name"`
+	}
+	const rowsCount = 2000
+	var content strings.Builder
+	content.WriteString("name,id\n")
+	for i := 1; i <= rowsCount; i++ {
+		content.WriteString("name " + strconv.Itoa(i) + "," + strconv.Itoa(i) + "\n")
+	}
+	content.WriteString("bad,x\n")
+	filePath := writeTmpFile(t, t.TempDir(), "rows.csv", content.String())
+	subject := bigcsvreader.New()
+	subject.SetFilePath(filePath)
+	subject.FileHasHeader = true
+	subject.EmitHeader = true
+	subject.ColumnsCount = 2
+	subject.MaxGoroutinesNo = 4
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	chans, errsChan := bigcsvreader.ReadInto[row](ctx, subject)
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		rows []row
+	)
+	for _, ch := range chans {
+		wg.Add(1)
+		go func(ch <-chan row) {
+			defer wg.Done()
+			for r := range ch {
+				mu.Lock()
+				rows = append(rows, r)
+				mu.Unlock()
+			}
+		}(ch)
+	}
+	err := bigcsvreader.CollectErrors(errsChan)
+	wg.Wait()
+
+	// assert
+	var decodeErr *bigcsvreader.FieldDecodeError
+	if assertTrue(t, errors.As(err, &decodeErr)) {
+		assertEqual(t, "id", decodeErr.Column)
+		assertEqual(t, "x", decodeErr.Value)
+	}
+	if assertEqual(t, rowsCount, len(rows)) {
+		sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+		assertEqual(t, row{ID: 1, Name: "name 1"}, rows[0])
+		assertEqual(t, row{ID: rowsCount, Name: "name " + strconv.Itoa(rowsCount)}, rows[rowsCount-1])
+	}
+}
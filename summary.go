@@ -0,0 +1,146 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Summary holds the outcome of a run, see [CsvReader.OnComplete].
+type Summary struct {
+	// File is the CSV file path.
+	File string
+	// RunID is the run's ID, see [Run.ID].
+	RunID string
+	// Config holds the reader's main settings the run was started with.
+	Config RunConfig
+	// StartedAt is the time reading started.
+	StartedAt time.Time
+	// Duration is the time elapsed from start until all channels were closed.
+	Duration time.Duration
+	// RowsCount is the number of rows pushed into channels.
+	RowsCount int64
+	// BytesCount is the number of bytes read (header excluded).
+	BytesCount int64
+	// DataSize is the number of bytes to read (file size, header excluded).
+	DataSize int64
+	// ErrorsCount is the number of errors sent through ErrsChan.
+	ErrorsCount int
+	// Err holds the errors sent through ErrsChan (the first 1000 ones, see [Run.Err]), joined,
+	// or nil if no error occurred.
+	Err error
+	// ErrsChanHighWater is the maximum number of errors found waiting in ErrsChan
+	// (whose capacity is [CsvReader.ChanBufferSize]), along the run.
+	ErrsChanHighWater int
+	// PatternViolations holds the number of values which did not match the column's pattern,
+	// per column having a pattern, see [Run.PatternViolations]. It is nil if no column has a pattern.
+	PatternViolations map[int]int64
+	// TimedOut is a flag indicating reading was stopped because [CsvReader.MaxRunDuration] elapsed.
+	TimedOut bool
+	// Threads holds each goroutine's summary.
+	Threads []ThreadSummary
+}
+
+// ThreadSummary holds the outcome of a goroutine.
+type ThreadSummary struct {
+	// Thread is the goroutine's number.
+	Thread int `json:"thread"`
+	// OffsetStart is the initial start offset allocated to the goroutine.
+	OffsetStart int `json:"offsetStart"`
+	// OffsetEnd is the initial end offset allocated to the goroutine.
+	OffsetEnd int `json:"offsetEnd"`
+	// RowsCount is the number of rows pushed into the goroutine's channel.
+	RowsCount int64 `json:"rowsCount"`
+	// RecordsCount is the number of records (blank lines aside) read by the goroutine,
+	// pushed into its channel or not (filtered out, invalid...).
+	RecordsCount int64 `json:"recordsCount"`
+	// BytesCount is the number of bytes read by the goroutine.
+	BytesCount int64 `json:"bytesCount"`
+	// NextOffset is the offset of the first line the goroutine did not read, if it stopped
+	// because [CsvReader.MaxRunDuration] elapsed, or -1 otherwise.
+	// Lines starting from NextOffset up to OffsetEnd+1 (inclusive) were left unread.
+	NextOffset int `json:"nextOffset"`
+	// SkippedOffset is the offset of the first line the goroutine skipped, if it stopped
+	// because [CsvReader.MaxErrorsPerChunk] was reached, or -1 otherwise.
+	// Lines starting from SkippedOffset up to OffsetEnd+1 (inclusive) were skipped.
+	SkippedOffset int `json:"skippedOffset"`
+	// ChanHighWater is the maximum number of rows found waiting in the goroutine's channel, along the run.
+	// Compared with [CsvReader.ChanBufferSize], it helps sizing it: a high-water mark reaching it means
+	// the goroutine waited for rows to be consumed, a low one means the buffer is oversized.
+	// It is not tracked by [CsvReader.ReadPaired].
+	ChanHighWater int `json:"chanHighWater"`
+	// Empty is a flag indicating the goroutine's chunk held no record, see [Run.EmptyChunks].
+	Empty bool `json:"empty"`
+}
+
+// Coverage returns the fraction [0, 1] of data bytes which were read.
+// A value lower than 1 means reading stopped early (context cancellation, fatal error...).
+func (s Summary) Coverage() float64 {
+	if s.DataSize <= 0 {
+		return 1
+	}
+
+	return float64(s.BytesCount) / float64(s.DataSize)
+}
+
+// summary returns the summary of the run, finished at given time.
+func (rn *Run) summary(filePath string, config RunConfig, now time.Time) Summary {
+	summary := Summary{
+		File:      filePath,
+		RunID:     rn.id,
+		Config:    config,
+		StartedAt: rn.startedAt,
+	}
+	if !rn.startedAt.IsZero() {
+		summary.Duration = now.Sub(rn.startedAt)
+	}
+	if len(rn.threadsInfo) > 0 {
+		summary.DataSize = int64(rn.threadsInfo[len(rn.threadsInfo)-1][1] + 1 - rn.threadsInfo[0][0])
+	}
+	summary.Threads, summary.RowsCount, summary.BytesCount = rn.threadsSummaries()
+	for _, thread := range summary.Threads {
+		if thread.NextOffset >= 0 {
+			summary.TimedOut = true
+		}
+	}
+	rn.errsMu.Lock()
+	summary.ErrorsCount = rn.errsCount
+	rn.errsMu.Unlock()
+	summary.ErrsChanHighWater = int(atomic.LoadInt64(&rn.errsHighWater))
+	if violations := rn.PatternViolations(); len(violations) > 0 {
+		summary.PatternViolations = violations
+	}
+	summary.Err = rn.Err()
+
+	return summary
+}
+
+// threadsSummaries returns each goroutine's summary, and the total rows and bytes counts.
+func (rn *Run) threadsSummaries() ([]ThreadSummary, int64, int64) {
+	var (
+		threads               = make([]ThreadSummary, len(rn.threadsInfo))
+		rowsCount, bytesCount int64
+	)
+	for i, info := range rn.threadsInfo {
+		threads[i] = ThreadSummary{
+			Thread:        i + 1,
+			OffsetStart:   info[0],
+			OffsetEnd:     info[1],
+			RowsCount:     rn.threads[i].rows(),
+			RecordsCount:  rn.threads[i].records(),
+			BytesCount:    rn.threads[i].bytes(),
+			NextOffset:    rn.threads[i].next(),
+			SkippedOffset: rn.threads[i].skipped(),
+			ChanHighWater: rn.threads[i].highWater(),
+			Empty:         rn.threads[i].isEmpty(),
+		}
+		rowsCount += threads[i].RowsCount
+		bytesCount += threads[i].BytesCount
+	}
+
+	return threads, rowsCount, bytesCount
+}
@@ -0,0 +1,229 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_OnComplete(t *testing.T) {
+	t.Parallel()
+
+	t.Run("successful run", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		filePath, err := setUpTmpCsvFile(2000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tearDownTmpCsvFile(filePath)
+		fileInfo, err := os.Stat(filePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		summaries := make(chan bigcsvreader.Summary, 1)
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 5
+		subject.MaxGoroutinesNo = 4
+		subject.OnComplete = func(summary bigcsvreader.Summary) {
+			summaries <- summary
+		}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		drainRows(run.RowsChans())
+		assertNil(t, run.Err())
+		summary := <-summaries
+
+		// assert
+		assertEqual(t, filePath, summary.File)
+		assertEqual(t, int64(2000), summary.RowsCount)
+		assertEqual(t, fileInfo.Size(), summary.BytesCount)
+		assertEqual(t, fileInfo.Size(), summary.DataSize)
+		assertEqual(t, 1.0, summary.Coverage())
+		assertEqual(t, 0, summary.ErrorsCount)
+		assertNil(t, summary.Err)
+		assertTrue(t, summary.Duration > 0)
+		assertEqual(t, 4, len(summary.Threads))
+		var threadsRows int64
+		for i, thread := range summary.Threads {
+			assertEqual(t, i+1, thread.Thread)
+			threadsRows += thread.RowsCount
+		}
+		assertEqual(t, summary.RowsCount, threadsRows)
+	})
+
+	t.Run("run with errors", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		summaries := make(chan bigcsvreader.Summary, 1)
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/invalid_row.csv")
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.OnComplete = func(summary bigcsvreader.Summary) {
+			summaries <- summary
+		}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		drainRows(run.RowsChans())
+		_ = run.Err()
+		summary := <-summaries
+
+		// assert
+		assertEqual(t, int64(4), summary.RowsCount)
+		assertEqual(t, 1, summary.ErrorsCount)
+		assertNotNil(t, summary.Err)
+		assertEqual(t, 1.0, summary.Coverage())
+	})
+
+	t.Run("run with more errors than retained", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		const rowsCount = 1500
+		summaries := make(chan bigcsvreader.Summary, 1)
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, t.TempDir(), "bad_rows.csv", strings.Repeat("a\n", rowsCount)))
+		subject.ColumnsCount = 2
+		subject.OnComplete = func(summary bigcsvreader.Summary) {
+			summaries <- summary
+		}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		go func() {
+			for range run.ErrsChan() {
+			}
+		}()
+		drainRows(run.RowsChans())
+		err := run.Err()
+		summary := <-summaries
+
+		// assert
+		assertEqual(t, rowsCount, summary.ErrorsCount)
+		if joined, ok := err.(interface{ Unwrap() []error }); assertTrue(t, ok) {
+			assertEqual(t, 1000, len(joined.Unwrap()))
+		}
+	})
+}
+
+func TestCsvReader_OnComplete_chanHighWater(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	filePath, err := setUpTmpCsvFile(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tearDownTmpCsvFile(filePath)
+	summaries := make(chan bigcsvreader.Summary, 1)
+	subject := bigcsvreader.New()
+	subject.SetFilePath(filePath)
+	subject.ColumnsCount = 5
+	subject.MaxGoroutinesNo = 1
+	subject.ChanBufferSize = 8
+	subject.OnComplete = func(summary bigcsvreader.Summary) {
+		summaries <- summary
+	}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	run := subject.Start(ctx)
+	rowsChan := run.RowsChans()[0]
+	for len(rowsChan) < cap(rowsChan) { // let the goroutine fill the channel.
+		time.Sleep(time.Millisecond)
+	}
+	drainRows(run.RowsChans())
+	assertNil(t, run.Err())
+	summary := <-summaries
+
+	// assert
+	if assertEqual(t, 1, len(summary.Threads)) {
+		assertEqual(t, 8, summary.Threads[0].ChanHighWater)
+	}
+	assertEqual(t, 0, summary.ErrsChanHighWater)
+}
+
+func TestSummary_WriteReport(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	summaries := make(chan bigcsvreader.Summary, 1)
+	subject := bigcsvreader.New()
+	subject.SetFilePath("testdata/invalid_row.csv")
+	subject.FileHasHeader = true
+	subject.ColumnsCount = 3
+	subject.MaxGoroutinesNo = 2
+	subject.OnComplete = func(summary bigcsvreader.Summary) {
+		summaries <- summary
+	}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+	run := subject.Start(ctx)
+	drainRows(run.RowsChans())
+	_ = run.Err()
+	summary := <-summaries
+	var buf bytes.Buffer
+
+	// act
+	err := summary.WriteReport(&buf)
+
+	// assert
+	assertNil(t, err)
+	var report struct {
+		File   string `json:"file"`
+		Config struct {
+			ColumnsCount     int    `json:"columnsCount"`
+			ColumnsDelimiter string `json:"columnsDelimiter"`
+			FileHasHeader    bool   `json:"fileHasHeader"`
+			Encoding         string `json:"encoding"`
+		} `json:"config"`
+		Totals struct {
+			RowsCount   int64   `json:"rowsCount"`
+			Coverage    float64 `json:"coverage"`
+			ErrorsCount int     `json:"errorsCount"`
+		} `json:"totals"`
+		Errors     []string                     `json:"errors"`
+		Threads    []bigcsvreader.ThreadSummary `json:"threads"`
+		Successful bool                         `json:"successful"`
+	}
+	if assertNil(t, json.Unmarshal(buf.Bytes(), &report)) {
+		assertEqual(t, "testdata/invalid_row.csv", report.File)
+		assertEqual(t, 3, report.Config.ColumnsCount)
+		assertEqual(t, ",", report.Config.ColumnsDelimiter)
+		assertTrue(t, report.Config.FileHasHeader)
+		assertEqual(t, "utf-8", report.Config.Encoding)
+		assertEqual(t, int64(4), report.Totals.RowsCount)
+		assertEqual(t, 1.0, report.Totals.Coverage)
+		assertEqual(t, 1, report.Totals.ErrorsCount)
+		if assertEqual(t, 1, len(report.Errors)) {
+			assertEqual(t, summary.Err.Error(), report.Errors[0])
+		}
+		assertEqual(t, summary.Threads, report.Threads)
+		assertEqual(t, false, report.Successful)
+	}
+	assertTrue(t, strings.HasPrefix(buf.String(), "{\n  \"runId\": "))
+}
@@ -0,0 +1,142 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ReadTimeRange extracts asynchronously the CSV rows having the time in given column (0-based index)
+// in the [from, to) interval, like [CsvReader.Read] does.
+// The file must be sorted ascending by the time column; the first and last rows of the interval
+// are located with a binary search on byte offsets, and only the region between them is read.
+// Column's values are parsed with [time.Parse] and given layout.
+// If a row probed by the binary search can't be parsed, the error is sent through ErrsChan
+// and no row is read.
+func (cr *CsvReader) ReadTimeRange(
+	ctx context.Context,
+	col int,
+	from, to time.Time,
+	layout string,
+) ([]RowsChan, ErrsChan) {
+	locate := func(f io.ReaderAt, dataStart, fileSize int) (int, int, error) {
+		tl := timeLocator{cr: cr, f: f, col: col, layout: layout, dataStart: dataStart, fileSize: fileSize}
+		start, err := tl.search(from)
+		if err != nil {
+			return 0, 0, err
+		}
+		end, err := tl.search(to)
+		if err != nil {
+			return 0, 0, err
+		}
+		if end < start {
+			end = start
+		}
+
+		return start, end, nil
+	}
+	rn := cr.start(ctx, modeRows, locate)
+
+	return rn.RowsChans(), rn.ErrsChan()
+}
+
+// timeLocator locates rows by time, in a file sorted by a time column.
+type timeLocator struct {
+	cr        *CsvReader
+	f         io.ReaderAt
+	col       int
+	layout    string
+	dataStart int
+	fileSize  int
+}
+
+// search returns the offset of the first row having the time not before given time,
+// or the file size if there is no such row.
+func (tl timeLocator) search(t time.Time) (int, error) {
+	// find the lowest offset whose next line start's row has the time not before t.
+	lo, hi := tl.dataStart, tl.fileSize
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		lineStart, line, err := tl.lineFrom(mid)
+		if err != nil {
+			return 0, err
+		}
+		if lineStart >= tl.fileSize {
+			hi = mid
+
+			continue
+		}
+		rowTime, err := tl.parseTime(line, lineStart)
+		if err != nil {
+			return 0, err
+		}
+		if rowTime.Before(t) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	lineStart, _, err := tl.lineFrom(lo)
+
+	return lineStart, err
+}
+
+// lineFrom returns the first non blank line starting at or after given offset, and its start offset.
+func (tl timeLocator) lineFrom(offset int) (int, []byte, error) {
+	if offset >= tl.fileSize {
+		return tl.fileSize, nil, nil
+	}
+	if offset > tl.dataStart {
+		// skip the rest of the line containing offset-1.
+		partial, err := readLineAt(tl.f, offset-1)
+		if err != nil {
+			return 0, nil, err
+		}
+		offset += len(partial) - 1
+		if offset >= tl.fileSize {
+			return tl.fileSize, nil, nil
+		}
+	}
+	for {
+		line, err := readLineAt(tl.f, offset)
+		if err != nil || !isBlankLine(line) {
+			return offset, line, err
+		}
+		offset += len(line)
+		if len(line) == 0 || offset >= tl.fileSize {
+			return tl.fileSize, nil, nil
+		}
+	}
+}
+
+// parseTime parses the time column of given line.
+func (tl timeLocator) parseTime(line []byte, offset int) (time.Time, error) {
+	record, err := tl.cr.newLineParser(-1).parse(line)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bigcsvreader: could not parse row at offset %d (%w)", offset, err)
+	}
+	if tl.col < 0 || tl.col >= len(record) {
+		return time.Time{}, fmt.Errorf(
+			"bigcsvreader: row at offset %d has no column %d (%w)",
+			offset, tl.col, csv.ErrFieldCount,
+		)
+	}
+	value := record[tl.col]
+	if tl.cr.TrimSpace {
+		value = strings.TrimSpace(value)
+	}
+	rowTime, err := time.Parse(tl.layout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bigcsvreader: could not parse time at offset %d (%w)", offset, err)
+	}
+
+	return rowTime, nil
+}
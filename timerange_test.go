@@ -0,0 +1,200 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ReadTimeRange(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	const rowsCount = 5000
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var sb strings.Builder
+	sb.WriteString("id,time,message\n")
+	for i := 0; i < rowsCount; i++ {
+		// 2 rows per second, so equal times are spread across chunks too.
+		sb.WriteString(strconv.Itoa(i) + "," + base.Add(time.Duration(i/2)*time.Second).Format(time.RFC3339) + ",message " + strconv.Itoa(i) + "\n")
+	}
+	filePath := filepath.Join(t.TempDir(), "log.csv")
+	if err := os.WriteFile(filePath, []byte(sb.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := [...]struct {
+		name        string
+		from, to    time.Time
+		expectedIDs []int
+	}{
+		{
+			name:        "range inside file",
+			from:        base.Add(1000 * time.Second),
+			to:          base.Add(1003 * time.Second),
+			expectedIDs: []int{2000, 2001, 2002, 2003, 2004, 2005},
+		},
+		{
+			name:        "range starting before file",
+			from:        base.Add(-time.Hour),
+			to:          base.Add(time.Second),
+			expectedIDs: []int{0, 1},
+		},
+		{
+			name:        "range ending after file",
+			from:        base.Add(2499 * time.Second),
+			to:          base.Add(time.Hour),
+			expectedIDs: []int{4998, 4999},
+		},
+		{
+			name:        "range outside file",
+			from:        base.Add(time.Hour),
+			to:          base.Add(2 * time.Hour),
+			expectedIDs: nil,
+		},
+		{
+			name:        "empty range",
+			from:        base.Add(10 * time.Second),
+			to:          base.Add(10 * time.Second),
+			expectedIDs: nil,
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(filePath)
+			subject.FileHasHeader = true
+			subject.ColumnsCount = 3
+			subject.MaxGoroutinesNo = 4
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			rowsChans, errsChan := subject.ReadTimeRange(ctx, 1, test.from, test.to, time.RFC3339)
+			ids := gatherIDs(t, rowsChans)
+			err := bigcsvreader.CollectErrors(errsChan)
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, test.expectedIDs, ids)
+		})
+	}
+
+	t.Run("invalid time", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rowsChans, errsChan := subject.ReadTimeRange(ctx, 2, base, base.Add(time.Hour), time.RFC3339)
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertNil(t, rowsChans)
+		assertNotNil(t, err)
+	})
+
+	t.Run("blank lines", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		var sb strings.Builder
+		sb.WriteString("id,time,message\n\n")
+		for i := 0; i < 100; i++ {
+			sb.WriteString(strconv.Itoa(i) + "," + base.Add(time.Duration(i)*time.Second).Format(time.RFC3339) + ",message\n\n\r\n\n")
+		}
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, t.TempDir(), "blank.csv", sb.String()))
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.MaxGoroutinesNo = 4
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rowsChans, errsChan := subject.ReadTimeRange(ctx, 1, base.Add(40*time.Second), base.Add(43*time.Second), time.RFC3339)
+		ids := gatherIDs(t, rowsChans)
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, []int{40, 41, 42}, ids)
+	})
+
+	t.Run("line breaks in quoted fields are not supported", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.MultilineQuotedFields = true
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rowsChans, errsChan := subject.ReadTimeRange(ctx, 1, base, base.Add(time.Hour), time.RFC3339)
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertNil(t, rowsChans)
+		if assertNotNil(t, err) {
+			assertTrue(t, strings.Contains(err.Error(), "not supported with line breaks in quoted fields"))
+		}
+	})
+}
+
+// gatherIDs consumes all the rows channels and returns the sorted ids (first column) of the rows.
+func gatherIDs(t *testing.T, rowsChans []bigcsvreader.RowsChan) []int {
+	t.Helper()
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		ids []int
+	)
+	for _, rowsChan := range rowsChans {
+		wg.Add(1)
+		go func(rowsChan bigcsvreader.RowsChan) {
+			defer wg.Done()
+			for row := range rowsChan {
+				id, err := strconv.Atoi(row[0])
+				if err != nil {
+					t.Error(err)
+
+					continue
+				}
+				mu.Lock()
+				ids = append(ids, id)
+				mu.Unlock()
+			}
+		}(rowsChan)
+	}
+	wg.Wait()
+	sort.Ints(ids)
+
+	return ids
+}
@@ -0,0 +1,117 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+// keySeparator joins multiple key columns' values (ASCII unit separator).
+const keySeparator = "\x1f"
+
+// UniqueConstraint asserts that the key, made of one or more columns' values, is unique across the whole file.
+type UniqueConstraint struct {
+	// Columns holds the indexes (0-based) of the columns forming the key.
+	Columns []int
+	// FalsePositiveRate, if set (0 < rate < 1), enables memory bounded checking, through a bloom filter.
+	// In this mode a duplicate may be falsely reported, with the configured probability,
+	// and the offset of the first occurrence is not known.
+	// If not set, keys are checked exactly, through a hash set holding all keys in memory.
+	FalsePositiveRate float64
+	// ExpectedKeys is the number of keys the bloom filter is sized for.
+	// If not set, it is estimated from the file size.
+	ExpectedKeys int
+}
+
+// DuplicateKeyError is the error returned when a row's key was already seen.
+// As rows are read concurrently, the first occurrence is not necessarily the one
+// which comes first in file.
+type DuplicateKeyError struct {
+	// Key holds the key columns' values.
+	Key []string
+	// Offset is the offset of the row having the duplicate key.
+	Offset int
+	// FirstOffset is the offset of the row the key was first seen in,
+	// or -1 if unknown (bloom filter checking).
+	FirstOffset int
+}
+
+// Error returns the error's message.
+func (e *DuplicateKeyError) Error() string {
+	if e.FirstOffset < 0 {
+		return fmt.Sprintf("duplicate key %q at offset %d", e.Key, e.Offset)
+	}
+
+	return fmt.Sprintf("duplicate key %q at offset %d, first seen at offset %d", e.Key, e.Offset, e.FirstOffset)
+}
+
+// uniqueChecker checks keys uniqueness during a run.
+type uniqueChecker struct {
+	columns []int
+	set     *internal.ShardedKeySet
+	bloom   *internal.BloomFilter
+}
+
+// newUniqueChecker instantiates the checker for the configured [UniqueConstraint], if any.
+func (cr *CsvReader) newUniqueChecker(fileSize int) (*uniqueChecker, error) {
+	if cr.Unique == nil {
+		return nil, nil
+	}
+	if len(cr.Unique.Columns) == 0 {
+		return nil, errors.New("unique constraint has no columns")
+	}
+	for _, col := range cr.Unique.Columns {
+		if col < 0 || (cr.ColumnsCount > 0 && col >= cr.ColumnsCount) {
+			return nil, fmt.Errorf("unique constraint column #%d is out of range", col)
+		}
+	}
+	checker := &uniqueChecker{columns: cr.Unique.Columns}
+	rate := cr.Unique.FalsePositiveRate
+	switch {
+	case rate == 0:
+		checker.set = internal.NewShardedKeySet()
+	case rate > 0 && rate < 1:
+		expectedKeys := cr.Unique.ExpectedKeys
+		if expectedKeys <= 0 {
+			// assume rows of at least 16 bytes.
+			expectedKeys = fileSize / 16
+		}
+		checker.bloom = internal.NewBloomFilter(expectedKeys, rate)
+	default:
+		return nil, fmt.Errorf("unique constraint false positive rate %v is not in (0, 1)", rate)
+	}
+
+	return checker, nil
+}
+
+// check returns a [*DuplicateKeyError] if record's key was already seen.
+func (uc *uniqueChecker) check(record []string, offset int) error {
+	key := make([]string, 0, len(uc.columns))
+	for _, col := range uc.columns {
+		if col < len(record) {
+			key = append(key, record[col])
+		} else {
+			key = append(key, "")
+		}
+	}
+	joinedKey := strings.Join(key, keySeparator)
+	if uc.bloom != nil {
+		if !uc.bloom.Add(joinedKey) {
+			return &DuplicateKeyError{Key: key, Offset: offset, FirstOffset: -1}
+		}
+
+		return nil
+	}
+	if firstOffset, added := uc.set.Add(joinedKey, offset); !added {
+		return &DuplicateKeyError{Key: key, Offset: offset, FirstOffset: firstOffset}
+	}
+
+	return nil
+}
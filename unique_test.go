@@ -0,0 +1,103 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_Unique(t *testing.T) {
+	t.Parallel()
+
+	tests := [...]struct {
+		name     string
+		unique   *bigcsvreader.UniqueConstraint
+		expected []bigcsvreader.DuplicateKeyError
+	}{
+		{
+			name:   "exact checking",
+			unique: &bigcsvreader.UniqueConstraint{Columns: []int{0}},
+			expected: []bigcsvreader.DuplicateKeyError{
+				{Key: []string{"1"}, Offset: 8, FirstOffset: 0},
+				{Key: []string{"2"}, Offset: 16, FirstOffset: 4},
+			},
+		},
+		{
+			name:   "bloom filter checking",
+			unique: &bigcsvreader.UniqueConstraint{Columns: []int{0}, FalsePositiveRate: 0.0001, ExpectedKeys: 100},
+			expected: []bigcsvreader.DuplicateKeyError{
+				{Key: []string{"1"}, Offset: 8, FirstOffset: -1},
+				{Key: []string{"2"}, Offset: 16, FirstOffset: -1},
+			},
+		},
+		{
+			name:   "composite key",
+			unique: &bigcsvreader.UniqueConstraint{Columns: []int{0, 1}},
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath("testdata/file_with_duplicates.csv")
+			subject.ColumnsCount = 2
+			subject.Unique = test.unique
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			run := subject.Start(ctx)
+			rowsCount := drainRows(run.RowsChans())
+			err := run.Err()
+
+			// assert
+			assertEqual(t, 5-len(test.expected), rowsCount)
+			if len(test.expected) == 0 {
+				assertNil(t, err)
+
+				return
+			}
+			joinedErr, ok := err.(interface{ Unwrap() []error })
+			if assertTrue(t, ok) && assertEqual(t, len(test.expected), len(joinedErr.Unwrap())) {
+				for i, expected := range test.expected {
+					var dupErr *bigcsvreader.DuplicateKeyError
+					if assertTrue(t, errors.As(joinedErr.Unwrap()[i], &dupErr)) {
+						assertEqual(t, expected, *dupErr)
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("invalid constraint fails the run", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_with_duplicates.csv")
+		subject.ColumnsCount = 2
+		subject.Unique = &bigcsvreader.UniqueConstraint{Columns: []int{2}}
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		err := run.Err()
+
+		// assert
+		assertNotNil(t, err)
+		assertEqual(t, 0, len(run.RowsChans()))
+	})
+}
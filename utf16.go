@@ -0,0 +1,154 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// TextEncoding is the character encoding of a CSV file, see [CsvReader.Encoding].
+type TextEncoding int
+
+const (
+	// EncodingUTF8 is the UTF-8 encoding (ASCII included).
+	EncodingUTF8 TextEncoding = iota
+	// EncodingUTF16LE is the little endian UTF-16 encoding.
+	EncodingUTF16LE
+	// EncodingUTF16BE is the big endian UTF-16 encoding.
+	EncodingUTF16BE
+)
+
+// utf16CodeUnitSize is the size, in bytes, of an UTF-16 code unit.
+const utf16CodeUnitSize = 2
+
+// errUnsupportedWithUTF16 is the error returned when a feature which splits data on bytes is used on an UTF-16 file.
+var errUnsupportedWithUTF16 = errors.New("not supported with UTF-16 encoding")
+
+// byteOrder returns the byte order of the UTF-16 encoding, or nil for UTF-8.
+func (enc TextEncoding) byteOrder() binary.ByteOrder {
+	switch enc {
+	case EncodingUTF16LE:
+		return binary.LittleEndian
+	case EncodingUTF16BE:
+		return binary.BigEndian
+	default:
+		return nil
+	}
+}
+
+// readUTF16Line reads a line of UTF-16 code units, up to and including the \n code unit,
+// and returns it transcoded into UTF-8 (into lh's buffer), and the number of bytes it occupies in the file.
+// A \n code unit can't be part of a surrogate pair, so, reading from an aligned offset,
+// line ends are found without splitting characters.
+// It returns nil if there is no more data to read or if an error occurred (which is sent through ErrsChan).
+func (cr *CsvReader) readUTF16Line(rn *Run, lh *lineHandler, r *bufio.Reader, offsetPos int) ([]byte, int) {
+	var (
+		unit  [utf16CodeUnitSize]byte
+		size  int
+		units = lh.units[:0]
+	)
+	for {
+		if _, err := io.ReadFull(r, unit[:]); err != nil {
+			if errors.Is(err, io.EOF) && size > 0 {
+				break // last line has no end line delimiter.
+			}
+			if !errors.Is(err, io.EOF) {
+				cr.sendReadErr(rn, lh.thread, offsetPos, err)
+			}
+
+			return nil, 0
+		}
+		size += utf16CodeUnitSize
+		if size > cr.BufferSize {
+			cr.sendReadErr(rn, lh.thread, offsetPos, bufio.ErrBufferFull)
+
+			return nil, 0
+		}
+		units = append(units, rn.utf16.Uint16(unit[:]))
+		if units[len(units)-1] == '\n' {
+			break
+		}
+	}
+	lh.units = units
+	lh.decoded = appendUTF16(lh.decoded[:0], units)
+
+	return lh.decoded, size
+}
+
+// appendUTF16 appends to dst the UTF-8 encoding of given UTF-16 code units.
+// Unpaired surrogates are replaced with [utf8.RuneError].
+func appendUTF16(dst []byte, units []uint16) []byte {
+	for i := 0; i < len(units); i++ {
+		r := rune(units[i])
+		if utf16.IsSurrogate(r) {
+			if i+1 < len(units) {
+				r = utf16.DecodeRune(r, rune(units[i+1]))
+			} else {
+				r = utf8.RuneError
+			}
+			if r != utf8.RuneError {
+				i++
+			}
+		}
+		dst = utf8.AppendRune(dst, r)
+	}
+
+	return dst
+}
+
+// readUTF16Header reads and parses the header (first line, after the eventual byte order mark) of given UTF-16 file.
+// It returns also the header's size in the file, byte order mark and end line delimiter included.
+func (cr *CsvReader) readUTF16Header(f io.ReaderAt) ([]string, int, error) {
+	order := cr.Encoding.byteOrder()
+	bomSize, err := utf16BOMSize(f, order)
+	if err != nil {
+		return nil, 0, fmt.Errorf("bigcsvreader: could not read header (%w: %w)", ErrBadHeader, err)
+	}
+	r := bufio.NewReader(io.NewSectionReader(f, int64(bomSize), 1<<62))
+	var (
+		units []uint16
+		unit  [utf16CodeUnitSize]byte
+	)
+	for len(units) == 0 || units[len(units)-1] != '\n' {
+		if _, err := io.ReadFull(r, unit[:]); err != nil {
+			if errors.Is(err, io.EOF) && len(units) > 0 {
+				break
+			}
+
+			return nil, 0, fmt.Errorf("bigcsvreader: could not read header (%w: %w)", ErrBadHeader, err)
+		}
+		units = append(units, order.Uint16(unit[:]))
+	}
+	header, err := cr.newLineParser(-1).parse(appendUTF16(nil, units))
+	if err != nil {
+		return nil, 0, fmt.Errorf("bigcsvreader: could not parse header (%w: %w)", ErrBadHeader, err)
+	}
+
+	return header, bomSize + len(units)*utf16CodeUnitSize, nil
+}
+
+// utf16BOMSize returns the size of the byte order mark the file starts with, 0 if it has none.
+func utf16BOMSize(f io.ReaderAt, order binary.ByteOrder) (int, error) {
+	var bom [utf16CodeUnitSize]byte
+	if n, err := f.ReadAt(bom[:], 0); n < len(bom) {
+		if err == nil || errors.Is(err, io.EOF) {
+			return 0, nil // too short to hold a byte order mark.
+		}
+
+		return 0, err
+	}
+	if order.Uint16(bom[:]) == 0xFEFF {
+		return utf16CodeUnitSize, nil
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,99 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_Encoding(t *testing.T) {
+	t.Parallel()
+
+	// values hold characters having code units with \n (0x0A) bytes, and surrogate pairs.
+	const rowsCount = 2000
+	var sb strings.Builder
+	sb.WriteString("\uFEFFid,name\n")
+	for i := 1; i <= rowsCount; i++ {
+		sb.WriteString(strconv.Itoa(i) + ",Ċਅ name " + strconv.Itoa(i) + " \U0001F600\n")
+	}
+	tests := [...]struct {
+		name     string
+		encoding bigcsvreader.TextEncoding
+		order    binary.ByteOrder
+	}{
+		{name: "UTF-16LE", encoding: bigcsvreader.EncodingUTF16LE, order: binary.LittleEndian},
+		{name: "UTF-16BE", encoding: bigcsvreader.EncodingUTF16BE, order: binary.BigEndian},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name+" file is read by multiple goroutines", func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			units := utf16.Encode([]rune(sb.String()))
+			content := make([]byte, 2*len(units))
+			for i, unit := range units {
+				test.order.PutUint16(content[2*i:], unit)
+			}
+			filePath := filepath.Join(t.TempDir(), "utf16.csv")
+			if err := os.WriteFile(filePath, content, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			subject := bigcsvreader.New()
+			subject.SetFilePath(filePath)
+			subject.FileHasHeader = true
+			subject.ColumnsCount = 2
+			subject.MaxGoroutinesNo = 7
+			subject.Encoding = test.encoding
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			rn := subject.Start(ctx)
+			rows := gatherRowsInOrder(rn.RowsChans())
+
+			// assert
+			assertNil(t, rn.Err())
+			assertEqual(t, []string{"id", "name"}, rn.Header())
+			if assertEqual(t, rowsCount, len(rows)) {
+				for i, row := range rows {
+					id := strconv.Itoa(i + 1)
+					assertEqual(t, []string{id, "Ċਅ name " + id + " \U0001F600"}, row)
+				}
+			}
+		})
+	}
+
+	t.Run("byte splitting features are not supported with UTF-16", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath("testdata/file_without_header.csv")
+		subject.ColumnsCount = 3
+		subject.Encoding = bigcsvreader.EncodingUTF16LE
+		subject.NumberLines = true
+
+		// act
+		rn := subject.Start(context.Background())
+		rows := gatherRowsInOrder(rn.RowsChans())
+
+		// assert
+		assertNotNil(t, rn.Err())
+		assertEqual(t, 0, len(rows))
+	})
+}
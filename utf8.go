@@ -0,0 +1,50 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// InvalidUTF8Error is the error returned, if [CsvReader.ValidateUTF8] is enabled,
+// when a column value is not valid UTF-8.
+type InvalidUTF8Error struct {
+	// Column is the column's index (0-based).
+	Column int
+	// Value is the invalid value.
+	Value string
+	// ByteOffset is the offset, in the value, of the first byte which is not part of a valid UTF-8 sequence.
+	ByteOffset int
+	// Offset is the offset of the row in the file.
+	Offset int
+}
+
+// Error returns the error's message.
+func (e *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf(
+		"invalid UTF-8 byte %#x at byte %d of column #%d value %q at offset %d",
+		e.Value[e.ByteOffset], e.ByteOffset, e.Column, e.Value, e.Offset,
+	)
+}
+
+// validateUTF8 checks the record's values are valid UTF-8.
+func validateUTF8(record []string, offset int) error {
+	for col, value := range record {
+		if utf8.ValidString(value) {
+			continue
+		}
+		for pos := 0; pos < len(value); {
+			r, size := utf8.DecodeRuneInString(value[pos:])
+			if r == utf8.RuneError && size == 1 {
+				return &InvalidUTF8Error{Column: col, Value: value, ByteOffset: pos, Offset: offset}
+			}
+			pos += size
+		}
+	}
+
+	return nil
+}
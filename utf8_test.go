@@ -0,0 +1,79 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ValidateUTF8(t *testing.T) {
+	t.Parallel()
+
+	content := "1,café,ok\n" +
+		"2,caf\xe9,ok\n" +
+		"3,\x93smart quotes\x94,ok\n"
+	filePath := writeTmpFile(t, t.TempDir(), "latin1.csv", content)
+	tests := [...]struct {
+		name                 string
+		normalizeWindows1252 bool
+		expectedRows         [][]string
+		expectedErrs         []bigcsvreader.InvalidUTF8Error
+	}{
+		{
+			name:         "invalid values are rejected",
+			expectedRows: [][]string{{"1", "café", "ok"}},
+			expectedErrs: []bigcsvreader.InvalidUTF8Error{
+				{Column: 1, Value: "caf\xe9", ByteOffset: 3, Offset: 11},
+				{Column: 1, Value: "\x93smart quotes\x94", ByteOffset: 0, Offset: 21},
+			},
+		},
+		{
+			name:                 "values are validated after normalization",
+			normalizeWindows1252: true,
+			expectedRows:         [][]string{{"1", "café", "ok"}, {"3", "“smart quotes”", "ok"}},
+			expectedErrs: []bigcsvreader.InvalidUTF8Error{
+				{Column: 1, Value: "caf\xe9", ByteOffset: 3, Offset: 11},
+			},
+		},
+	}
+
+	for _, testData := range tests {
+		test := testData // capture range variable
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(filePath)
+			subject.ColumnsCount = 3
+			subject.MaxGoroutinesNo = 1
+			subject.ValidateUTF8 = true
+			subject.NormalizeWindows1252 = test.normalizeWindows1252
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			rowsChans, errsChan := subject.Read(ctx)
+			rows := gatherRowsInOrder(rowsChans)
+			var errs []bigcsvreader.InvalidUTF8Error
+			for err := range errsChan {
+				var utf8Err *bigcsvreader.InvalidUTF8Error
+				if assertTrue(t, errors.As(err, &utf8Err)) {
+					errs = append(errs, *utf8Err)
+				}
+			}
+
+			// assert
+			assertEqual(t, test.expectedRows, rows)
+			assertEqual(t, test.expectedErrs, errs)
+		})
+	}
+}
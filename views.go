@@ -0,0 +1,229 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/actforgood/bigcsvreader/expr"
+)
+
+// View is a named projection and / or selection of a file's rows, see [CsvReader.ReadViews].
+type View struct {
+	// Name identifies the view, it must be unique among the views read together.
+	Name string
+	// Columns are the columns the view's rows hold, in this order, identified by their header names
+	// (so the file must have a header). Empty means all the columns, in the file's order.
+	Columns []string
+	// Filter is an expression (see package [expr]) the rows must match to be part of the view,
+	// evaluated against the file's columns. Empty means all the rows.
+	Filter string
+}
+
+// compiledView is a [View] ready to be applied to rows.
+type compiledView struct {
+	name string
+	// indexes holds the file's column index of each of the view's columns, nil for all the columns.
+	indexes []int
+	filter  *expr.Program
+}
+
+// ReadViews reads the file once and feeds the rows to all given views, each view getting
+// its own rows channels (one for each started goroutine, like [CsvReader.Read] returns), by view name.
+// So one physical scan serves several consumers needing different columns subsets or rows.
+// Each view's row is its own slice, consumers can modify it.
+// With [CsvReader.EmitHeader], each view's first row is its (projected) header, which is not filtered.
+// The returned errors channel carries both the reading errors and the views' filtering errors.
+// Note: all views' channels must be consumed, as a goroutine waits for each view to accept a row
+// before handling the next row.
+func (cr *CsvReader) ReadViews(ctx context.Context, views []View) (map[string][]RowsChan, ErrsChan) {
+	compiled, err := cr.compileViews(ctx, views)
+	if err != nil {
+		return nil, cr.failedRun(make(chan error, 1), "invalid views", err).ErrsChan()
+	}
+
+	rowsChans, errsChan := cr.Read(ctx)
+	var (
+		viewsChans = make(map[string][]RowsChan, len(compiled))
+		outChans   = make([][]chan []string, len(compiled))
+		outErrs    = make(chan error, cr.chanBufferSize(0, 0))
+		wg         sync.WaitGroup
+	)
+	for i, view := range compiled {
+		outChans[i] = make([]chan []string, len(rowsChans))
+		viewsChans[view.name] = make([]RowsChan, len(rowsChans))
+		for j := range rowsChans {
+			outChans[i][j] = make(chan []string, cr.chanBufferSize(0, 0))
+			viewsChans[view.name][j] = outChans[i][j]
+		}
+	}
+
+	wg.Add(len(rowsChans) + 1)
+	for j := range rowsChans {
+		go func(j int) {
+			defer wg.Done()
+			defer func() {
+				for i := range compiled {
+					close(outChans[i][j])
+				}
+			}()
+			isHeader := j == 0 && cr.EmitHeader && cr.FileHasHeader
+			for row := range rowsChans[j] {
+				for i, view := range compiled {
+					if view.filter != nil && !isHeader {
+						match, err := view.filter.Match(row)
+						if err != nil {
+							outErrs <- fmt.Errorf("bigcsvreader: view %q could not filter row (%w)", view.name, err)
+						}
+						if !match {
+							continue
+						}
+					}
+					if view.indexes == nil {
+						outChans[i][j] <- slices.Clone(row)
+					} else {
+						outChans[i][j] <- project(row, view.indexes)
+					}
+				}
+				isHeader = false
+			}
+		}(j)
+	}
+	go func() {
+		defer wg.Done()
+		for err := range errsChan {
+			outErrs <- err
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(outErrs)
+	}()
+
+	return viewsChans, outErrs
+}
+
+// compileViews checks given views and compiles them against the file's header.
+func (cr *CsvReader) compileViews(ctx context.Context, views []View) ([]compiledView, error) {
+	if len(views) == 0 {
+		return nil, errors.New("no views to read")
+	}
+	var (
+		header []string
+		err    error
+	)
+	if cr.FileHasHeader {
+		if header, err = cr.ReadHeader(ctx); err != nil {
+			return nil, err
+		}
+	}
+	compiled := make([]compiledView, len(views))
+	for i, view := range views {
+		if slices.ContainsFunc(views[:i], func(other View) bool { return other.Name == view.Name }) {
+			return nil, fmt.Errorf("duplicate view name %q", view.Name)
+		}
+		compiled[i].name = view.Name
+		if len(view.Columns) > 0 {
+			if header == nil {
+				return nil, fmt.Errorf("view %q columns require a file with header", view.Name)
+			}
+			if compiled[i].indexes, err = columnsIndexes(header, view.Columns); err != nil {
+				return nil, err
+			}
+		}
+		if view.Filter != "" {
+			if compiled[i].filter, err = expr.Compile(view.Filter, header); err != nil {
+				return nil, fmt.Errorf("view %q filter: %w", view.Name, err)
+			}
+		}
+	}
+
+	return compiled, nil
+}
+
+// Sink consumes the rows of a view, like [Consume] does, blocking until all of them were consumed,
+// see [CsvReader.RouteViews]. Through errsChan it receives no error (it is closed after the view's rows
+// channels), reading and filtering errors being returned by [CsvReader.RouteViews].
+type Sink func(ctx context.Context, rowsChans []RowsChan, errsChan ErrsChan) error
+
+// Route attaches a sink to a view, see [CsvReader.RouteViews].
+type Route struct {
+	// View selects the rows the sink receives.
+	View View
+	// Sink consumes the view's rows.
+	Sink Sink
+}
+
+// RouteViews reads the file once, feeding all the routes' views (see [CsvReader.ReadViews]),
+// and runs the routes' sinks (a database loader, a message publisher, a file writer...) concurrently,
+// each consuming its own view, blocking until all sinks returned.
+// So one physical scan replaces a full read for each sink.
+// If a sink returns before consuming all its view's rows, the remaining rows are drained,
+// so the other sinks are not blocked.
+// It returns the reading and filtering errors, and the errors returned by sinks, joined with [errors.Join],
+// or nil if there was no error.
+func (cr *CsvReader) RouteViews(ctx context.Context, routes []Route) error {
+	views := make([]View, len(routes))
+	for i, route := range routes {
+		if route.Sink == nil {
+			return fmt.Errorf("bigcsvreader: view %q has no sink", route.View.Name)
+		}
+		views[i] = route.View
+	}
+	viewsChans, errsChan := cr.ReadViews(ctx, views)
+	noErrs := make(chan error)
+	close(noErrs)
+
+	var (
+		errs []error
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+	)
+	addErr := func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+	for _, route := range routes {
+		rowsChans, ok := viewsChans[route.View.Name]
+		if !ok {
+			continue // views are invalid, error is received through errsChan.
+		}
+		wg.Add(1)
+		go func(route Route, rowsChans []RowsChan) {
+			defer wg.Done()
+			if err := route.Sink(ctx, rowsChans, noErrs); err != nil {
+				addErr(fmt.Errorf("bigcsvreader: view %q sink failed (%w)", route.View.Name, err))
+			}
+			drainRowsChans(rowsChans) // rows left unconsumed.
+		}(route, rowsChans)
+	}
+	for err := range errsChan {
+		addErr(err)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// drainRowsChans consumes, concurrently, all the rows channels, disregarding rows.
+// Channels are closed all together, after all reading goroutines finished, so they must be drained concurrently.
+func drainRowsChans(rowsChans []RowsChan) {
+	var wg sync.WaitGroup
+	wg.Add(len(rowsChans))
+	for _, rowsChan := range rowsChans {
+		go func(rowsChan RowsChan) {
+			defer wg.Done()
+			for range rowsChan {
+			}
+		}(rowsChan)
+	}
+	wg.Wait()
+}
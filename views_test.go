@@ -0,0 +1,237 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_ReadViews(t *testing.T) {
+	t.Parallel()
+
+	const rowsCount = 3000
+	var content strings.Builder
+	content.WriteString("id,name,country,price\n")
+	for i := 1; i <= rowsCount; i++ {
+		country := "US"
+		if i%3 == 0 {
+			country = "FR"
+		}
+		fmt.Fprintf(&content, "%d,name %d,%s,%d\n", i, i, country, i%200)
+	}
+	filePath := writeTmpFile(t, t.TempDir(), "products.csv", content.String())
+
+	t.Run("one pass feeds all views", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 4
+		subject.MaxGoroutinesNo = 4
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+		views := []bigcsvreader.View{
+			{Name: "all"},
+			{Name: "names", Columns: []string{"name", "id"}},
+			{Name: "expensive FR", Columns: []string{"id"}, Filter: `country == "FR" && price >= 100`},
+		}
+
+		// act
+		viewsChans, errsChan := subject.ReadViews(ctx, views)
+		var (
+			mu   sync.Mutex
+			wg   sync.WaitGroup
+			rows = make(map[string][][]string)
+		)
+		for name, rowsChans := range viewsChans {
+			for _, rowsChan := range rowsChans {
+				wg.Add(1)
+				go func(name string, rowsChan bigcsvreader.RowsChan) {
+					defer wg.Done()
+					for row := range rowsChan {
+						mu.Lock()
+						rows[name] = append(rows[name], row)
+						mu.Unlock()
+					}
+				}(name, rowsChan)
+			}
+		}
+		err := bigcsvreader.CollectErrors(errsChan)
+		wg.Wait()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, 3, len(viewsChans))
+		assertEqual(t, 4, len(viewsChans["names"]))
+		assertEqual(t, rowsCount, len(rows["all"]))
+		if assertEqual(t, rowsCount, len(rows["names"])) {
+			sortRowsByColumn(rows["names"], 1)
+			assertEqual(t, []string{"name 7", "7"}, rows["names"][6])
+		}
+		var expectedIDs [][]string
+		for i := 3; i <= rowsCount; i += 3 {
+			if i%200 >= 100 {
+				expectedIDs = append(expectedIDs, []string{strconv.Itoa(i)})
+			}
+		}
+		sortRowsByColumn(rows["expensive FR"], 0)
+		assertEqual(t, expectedIDs, rows["expensive FR"])
+	})
+
+	t.Run("invalid views", func(t *testing.T) {
+		t.Parallel()
+
+		tests := [...]struct {
+			name  string
+			views []bigcsvreader.View
+		}{
+			{name: "no views"},
+			{name: "duplicate names", views: []bigcsvreader.View{{Name: "a"}, {Name: "a"}}},
+			{name: "unknown column", views: []bigcsvreader.View{{Name: "a", Columns: []string{"email"}}}},
+			{name: "invalid filter", views: []bigcsvreader.View{{Name: "a", Filter: "price >"}}},
+		}
+		for _, testData := range tests {
+			test := testData // capture range variable
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+
+				// arrange
+				subject := bigcsvreader.New()
+				subject.SetFilePath(filePath)
+				subject.FileHasHeader = true
+				subject.ColumnsCount = 4
+
+				// act
+				viewsChans, errsChan := subject.ReadViews(context.Background(), test.views)
+				err := bigcsvreader.CollectErrors(errsChan)
+
+				// assert
+				assertNil(t, viewsChans)
+				if assertNotNil(t, err) {
+					assertTrue(t, strings.Contains(err.Error(), "invalid views"))
+				}
+			})
+		}
+	})
+}
+
+func TestCsvReader_RouteViews(t *testing.T) {
+	t.Parallel()
+
+	const rowsCount = 3000
+	var content strings.Builder
+	content.WriteString("id,name,country\n")
+	for i := 1; i <= rowsCount; i++ {
+		country := "US"
+		if i%4 == 0 {
+			country = "FR"
+		}
+		fmt.Fprintf(&content, "%d,name %d,%s\n", i, i, country)
+	}
+	filePath := writeTmpFile(t, t.TempDir(), "customers.csv", content.String())
+
+	t.Run("each sink consumes its view", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+		subject.ColumnsCount = 3
+		subject.MaxGoroutinesNo = 4
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+		var allCount, frCount atomic.Int64
+		var names strings.Builder
+		routes := []bigcsvreader.Route{
+			{
+				View: bigcsvreader.View{Name: "all"},
+				Sink: func(ctx context.Context, rowsChans []bigcsvreader.RowsChan, errsChan bigcsvreader.ErrsChan) error {
+					return bigcsvreader.Consume(ctx, rowsChans, errsChan, bigcsvreader.ConsumeOptions{BatchSize: 100},
+						func(_ context.Context, rows [][]string) error {
+							allCount.Add(int64(len(rows)))
+
+							return nil
+						})
+				},
+			},
+			{
+				View: bigcsvreader.View{Name: "fr", Columns: []string{"name"}, Filter: `country == "FR"`},
+				Sink: func(ctx context.Context, rowsChans []bigcsvreader.RowsChan, errsChan bigcsvreader.ErrsChan) error {
+					return bigcsvreader.Consume(ctx, rowsChans, errsChan, bigcsvreader.ConsumeOptions{WorkersNo: 1},
+						func(_ context.Context, rows [][]string) error {
+							frCount.Add(int64(len(rows)))
+							names.WriteString(rows[0][0] + ";")
+
+							return nil
+						})
+				},
+			},
+			{
+				View: bigcsvreader.View{Name: "failing", Columns: []string{"id"}},
+				Sink: func(context.Context, []bigcsvreader.RowsChan, bigcsvreader.ErrsChan) error {
+					return errors.New("broker unavailable") // rows are left unconsumed.
+				},
+			},
+		}
+
+		// act
+		err := subject.RouteViews(ctx, routes)
+
+		// assert
+		if assertNotNil(t, err) {
+			assertTrue(t, strings.Contains(err.Error(), `view "failing" sink failed (broker unavailable)`))
+		}
+		assertEqual(t, int64(rowsCount), allCount.Load())
+		assertEqual(t, int64(rowsCount/4), frCount.Load())
+		assertTrue(t, strings.Contains(names.String(), "name 4;"))
+	})
+
+	t.Run("invalid views", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.FileHasHeader = true
+		sink := func(context.Context, []bigcsvreader.RowsChan, bigcsvreader.ErrsChan) error { return nil }
+		routes := []bigcsvreader.Route{
+			{View: bigcsvreader.View{Name: "a", Columns: []string{"email"}}, Sink: sink},
+			{View: bigcsvreader.View{Name: "b"}, Sink: sink},
+		}
+
+		// act
+		err := subject.RouteViews(context.Background(), routes)
+
+		// assert
+		if assertNotNil(t, err) {
+			assertTrue(t, strings.Contains(err.Error(), "invalid views"))
+		}
+	})
+}
+
+// sortRowsByColumn sorts rows by the numeric value of given column.
+func sortRowsByColumn(rows [][]string, col int) {
+	sort.Slice(rows, func(i, j int) bool {
+		a, _ := strconv.Atoi(rows[i][col])
+		b, _ := strconv.Atoi(rows[j][col])
+
+		return a < b
+	})
+}
@@ -0,0 +1,231 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WatchHandler handles a file completed in a watched directory, being read by given run
+// (started like [CsvReader.Start] does), see [CsvReader.WatchDirectory].
+// Rows left unconsumed when the handler returns are drained.
+type WatchHandler func(ctx context.Context, filePath string, rn *Run) error
+
+// WatchDirectory watches the drop directory dir, and reads each newly completed file whose name matches
+// pattern (see [filepath.Match]), with this reader's configuration (file path aside), passing the run to handler.
+// The directory is on the operating system's file system.
+// Files are handled one at a time, in completion order, each being read in parallel.
+// Files present when watching starts are disregarded (unless they are modified afterwards).
+//
+// A file is considered completed when its size and modification time did not change during
+// [CsvReader.WatchQuietPeriod], and, on Linux, when it was also closed after writing or moved into
+// the directory (reported by inotify), so files written slowly are not read prematurely.
+// On other systems, the directory is polled every quiet period.
+//
+// Handler errors are logged, and watching goes on. WatchDirectory returns when ctx is done,
+// with ctx's error, or if the directory could not be watched.
+func (cr *CsvReader) WatchDirectory(ctx context.Context, dir, pattern string, handler WatchHandler) error {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("bigcsvreader: invalid pattern (%w)", err)
+	}
+	watcher, err := newDirWatcher(dir)
+	if err != nil {
+		return fmt.Errorf("bigcsvreader: could not watch directory (%w)", err)
+	}
+	defer watcher.close()
+
+	dw := &dropWatch{
+		cr:      cr,
+		dir:     dir,
+		pattern: pattern,
+		events:  watcher.events != nil,
+		pending: make(map[string]*pendingFile),
+		seen:    make(map[string]fileState),
+	}
+	files, err := dw.scan()
+	if err != nil {
+		return fmt.Errorf("bigcsvreader: could not list directory (%w)", err)
+	}
+	for name, state := range files {
+		dw.seen[name] = state // disregard files present before watching.
+	}
+
+	tick, stopTicker := cr.Clock.NewTicker(cr.WatchQuietPeriod)
+	defer stopTicker()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-watcher.events:
+			if !ok {
+				return fmt.Errorf("bigcsvreader: could not watch directory (%w)", watcher.err)
+			}
+			dw.notify(event)
+		case now := <-tick:
+			for _, filePath := range dw.completed(now) {
+				dw.handle(ctx, filePath, handler)
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+			}
+		}
+	}
+}
+
+// dirEvent is a directory change reported by the system.
+type dirEvent struct {
+	// name is the name of the file changed, empty if events were lost.
+	name string
+	// written is a flag indicating the file was closed after writing, or moved into the directory.
+	written bool
+}
+
+// fileState holds what tells a file changed.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// pendingFile is a file being written into a watched directory.
+type pendingFile struct {
+	state fileState
+	// since is the time the file was first observed in its current state.
+	since time.Time
+	// written is a flag indicating the system reported the file was closed after writing.
+	written bool
+}
+
+// dropWatch holds the state of a watched directory.
+type dropWatch struct {
+	cr      *CsvReader
+	dir     string
+	pattern string
+	// events is a flag indicating the system reports directory changes.
+	events bool
+	// pending holds the files being written, by name.
+	pending map[string]*pendingFile
+	// seen holds the files already handled (or present before watching), by name, in the state they were handled.
+	seen map[string]fileState
+}
+
+// notify records the change reported by the system.
+func (dw *dropWatch) notify(event dirEvent) {
+	if event.name == "" { // events were lost, rely on files' stability.
+		dw.cr.Logger.Debug("msg", "directory events were lost", "dir", dw.dir)
+		for _, pending := range dw.pending {
+			pending.written = true
+		}
+
+		return
+	}
+	if matched, _ := filepath.Match(dw.pattern, event.name); !matched {
+		return
+	}
+	pending, found := dw.pending[event.name]
+	if !found {
+		pending = &pendingFile{}
+		dw.pending[event.name] = pending
+	}
+	pending.written = pending.written || event.written
+}
+
+// completed returns, sorted by name, the paths of the files completed at given time.
+func (dw *dropWatch) completed(now time.Time) []string {
+	files, err := dw.scan()
+	if err != nil {
+		dw.cr.Logger.Error("msg", "could not list directory", "err", err, "dir", dw.dir)
+
+		return nil
+	}
+	for name := range dw.pending {
+		if _, found := files[name]; !found {
+			delete(dw.pending, name) // removed meanwhile.
+		}
+	}
+
+	var completed []string
+	for name, state := range files {
+		if seenState, seen := dw.seen[name]; seen && seenState == state {
+			delete(dw.pending, name) // events caused by handling the file, if any.
+
+			continue
+		}
+		pending, found := dw.pending[name]
+		if !found {
+			pending = &pendingFile{}
+			dw.pending[name] = pending
+		}
+		if pending.since.IsZero() || pending.state != state {
+			pending.state, pending.since = state, now
+
+			continue
+		}
+		if now.Sub(pending.since) < dw.cr.WatchQuietPeriod || (dw.events && !pending.written) {
+			continue
+		}
+		delete(dw.pending, name)
+		dw.seen[name] = state
+		completed = append(completed, filepath.Join(dw.dir, name))
+	}
+	sort.Strings(completed)
+
+	return completed
+}
+
+// scan returns the state of the regular files matching the pattern, by name.
+func (dw *dropWatch) scan() (map[string]fileState, error) {
+	entries, err := os.ReadDir(dw.dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]fileState, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		if matched, _ := filepath.Match(dw.pattern, entry.Name()); !matched {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // removed meanwhile.
+		}
+		files[entry.Name()] = fileState{size: info.Size(), modTime: info.ModTime()}
+	}
+
+	return files, nil
+}
+
+// handle reads the file, passing the run to handler, and drains what handler left unconsumed.
+func (dw *dropWatch) handle(ctx context.Context, filePath string, handler WatchHandler) {
+	reader := dw.cr.snapshot()
+	reader.SetFilePath(filePath)
+	dw.cr.Logger.Debug("msg", "handling completed file", "file", filePath)
+	rn := reader.Start(ctx)
+	if err := handler(ctx, filePath, rn); err != nil {
+		dw.cr.Logger.Error("msg", "could not handle file", "err", err, "file", filePath)
+	}
+
+	var wg sync.WaitGroup
+	for _, rowsChan := range rn.RowsChans() {
+		wg.Add(1)
+		go func(rowsChan RowsChan) {
+			defer wg.Done()
+			for range rowsChan {
+			}
+		}(rowsChan)
+	}
+	for range rn.ErrsChan() {
+	}
+	wg.Wait()
+	<-rn.Done()
+}
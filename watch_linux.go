@@ -0,0 +1,92 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// dirWatcher reports the changes of a directory, with inotify.
+type dirWatcher struct {
+	// events delivers the changes, it is closed if reading them failed.
+	events chan dirEvent
+	// err is the error reading changes failed with.
+	err  error
+	f    *os.File
+	done chan struct{}
+}
+
+// newDirWatcher starts watching the directory.
+func newDirWatcher(dir string) (*dirWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+	mask := uint32(syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE | syscall.IN_MOVED_TO)
+	if _, err = syscall.InotifyAddWatch(fd, dir, mask); err != nil {
+		_ = syscall.Close(fd)
+
+		return nil, &os.PathError{Op: "inotify_add_watch", Path: dir, Err: err}
+	}
+	// the non blocking fd is handled by the runtime poller, so closing it interrupts a pending read.
+	dw := &dirWatcher{
+		events: make(chan dirEvent),
+		f:      os.NewFile(uintptr(fd), "inotify"),
+		done:   make(chan struct{}),
+	}
+	go dw.read()
+
+	return dw, nil
+}
+
+// read reads the inotify events, until the watcher is closed.
+func (dw *dirWatcher) read() {
+	defer close(dw.events)
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := dw.f.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				dw.err = err
+			}
+
+			return
+		}
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			name := buf[nameStart : nameStart+int(raw.Len)]
+			offset = nameStart + int(raw.Len)
+
+			var event dirEvent
+			switch {
+			case raw.Mask&syscall.IN_Q_OVERFLOW != 0:
+			case raw.Mask&syscall.IN_IGNORED != 0: // directory was removed.
+				dw.err = errors.New("watched directory was removed")
+
+				return
+			default:
+				event.name = string(bytes.TrimRight(name, "\x00"))
+				event.written = raw.Mask&(syscall.IN_CLOSE_WRITE|syscall.IN_MOVED_TO) != 0
+			}
+			select {
+			case dw.events <- event:
+			case <-dw.done:
+				return
+			}
+		}
+	}
+}
+
+// close stops watching the directory.
+func (dw *dirWatcher) close() {
+	close(dw.done)
+	_ = dw.f.Close()
+}
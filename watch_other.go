@@ -0,0 +1,25 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+//go:build !linux
+
+package bigcsvreader
+
+// dirWatcher reports the changes of a directory. Changes are not reported on this system,
+// the directory is polled.
+type dirWatcher struct {
+	// events is nil, as changes are not reported.
+	events chan dirEvent
+	// err is always nil.
+	err error
+}
+
+// newDirWatcher returns a watcher reporting no change.
+func newDirWatcher(string) (*dirWatcher, error) {
+	return &dirWatcher{}, nil
+}
+
+// close does nothing.
+func (*dirWatcher) close() {}
@@ -0,0 +1,98 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_WatchDirectory(t *testing.T) {
+	t.Parallel()
+
+	t.Run("completed files are read", testCsvReaderWatchDirectoryCompletedFiles)
+	t.Run("invalid pattern", testCsvReaderWatchDirectoryInvalidPattern)
+}
+
+func testCsvReaderWatchDirectoryCompletedFiles(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	var (
+		dropDir    = t.TempDir()
+		stagingDir = t.TempDir()
+		content    = numberedRows(700)
+	)
+	writeTmpFile(t, dropDir, "before.csv", content)
+	subject := bigcsvreader.New()
+	subject.ColumnsCount = 3
+	subject.MaxGoroutinesNo = 2
+	subject.WatchQuietPeriod = 30 * time.Millisecond
+	type handledFile struct {
+		name      string
+		rowsCount int
+		err       error
+	}
+	handled := make(chan handledFile, 10)
+	handler := func(_ context.Context, filePath string, rn *bigcsvreader.Run) error {
+		rowsCount := drainRows(rn.RowsChans())
+		handled <- handledFile{name: filepath.Base(filePath), rowsCount: rowsCount, err: rn.Err()}
+
+		return nil
+	}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+	watchErr := make(chan error, 1)
+
+	// act
+	go func() {
+		watchErr <- subject.WatchDirectory(ctx, dropDir, "*.csv", handler)
+	}()
+	time.Sleep(100 * time.Millisecond) // let watching start.
+	f, err := os.Create(filepath.Join(dropDir, "slow.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(content[:len(content)/2])
+	if runtime.GOOS == "linux" { // the file is not closed yet, it is not completed, no matter it does not change.
+		time.Sleep(10 * subject.WatchQuietPeriod)
+	}
+	_, _ = f.WriteString(content[len(content)/2:])
+	_ = f.Close()
+	slow := <-handled
+	_ = os.Rename(writeTmpFile(t, stagingDir, "moved.csv", content), filepath.Join(dropDir, "moved.csv"))
+	writeTmpFile(t, dropDir, "ignored.txt", content)
+	moved := <-handled
+	cancelCtx()
+	err = <-watchErr
+
+	// assert
+	assertTrue(t, errors.Is(err, context.Canceled))
+	assertEqual(t, handledFile{name: "slow.csv", rowsCount: 700}, slow)
+	assertEqual(t, handledFile{name: "moved.csv", rowsCount: 700}, moved)
+	assertEqual(t, 0, len(handled))
+}
+
+func testCsvReaderWatchDirectoryInvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	subject := bigcsvreader.New()
+	handler := func(context.Context, string, *bigcsvreader.Run) error { return nil }
+
+	// act
+	err := subject.WatchDirectory(context.Background(), t.TempDir(), "[", handler)
+
+	// assert
+	assertTrue(t, errors.Is(err, filepath.ErrBadPattern))
+}
@@ -0,0 +1,106 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"time"
+
+	"github.com/actforgood/bigcsvreader/internal"
+)
+
+// defaultWatchdogInterval is the default period a [Watchdog] measures throughput at.
+const defaultWatchdogInterval = 30 * time.Second
+
+// ThreadThroughput holds the throughput of a goroutine reading a chunk of the file.
+type ThreadThroughput struct {
+	// Thread is the goroutine's number (1-based).
+	Thread int
+	// RowsCount is the total number of rows pushed so far.
+	RowsCount int64
+	// BytesCount is the total number of bytes consumed so far.
+	BytesCount int64
+	// RowsPerSecond is the rows throughput measured over the last interval.
+	RowsPerSecond float64
+	// BytesPerSecond is the bytes throughput measured over the last interval.
+	BytesPerSecond float64
+}
+
+// Watchdog monitors the throughput of each goroutine reading the file,
+// so long imports can alert operations when a goroutine's throughput collapses
+// (disk failing, network mount stalls), instead of hanging silently for hours.
+// Note: a goroutine's throughput also collapses if its RowsChan is not drained fast enough.
+type Watchdog struct {
+	// Interval is the period the throughput is measured at.
+	// Defaults to 30s.
+	Interval time.Duration
+	// MinRowsPerSecond is the rows throughput under which OnAlert is triggered.
+	// Zero value disables the check.
+	MinRowsPerSecond float64
+	// MaxRowsPerSecond is the rows throughput above which OnAlert is triggered.
+	// Zero value disables the check.
+	MaxRowsPerSecond float64
+	// OnAlert is called with the throughput of a goroutine which is out of bounds.
+	OnAlert func(ThreadThroughput)
+	// OnTick is optional, and is called every interval with the throughput
+	// of all goroutines still running.
+	OnTick func([]ThreadThroughput)
+}
+
+// watch measures periodically the throughput of run's goroutines until done is closed.
+func (w *Watchdog) watch(rn *Run, clock internal.Clock, done <-chan struct{}) {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = defaultWatchdogInterval
+	}
+	ticks, stop := clock.NewTicker(interval)
+	defer stop()
+
+	totalThreads := len(rn.threads)
+	prevRows := make([]int64, totalThreads)
+	prevBytes := make([]int64, totalThreads)
+	prevTime := clock.Now()
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticks:
+			elapsed := now.Sub(prevTime).Seconds()
+			prevTime = now
+			throughputs := make([]ThreadThroughput, 0, totalThreads)
+			for i := 0; i < totalThreads; i++ {
+				stats := &rn.threads[i]
+				if stats.isDone() {
+					continue
+				}
+				rows, bytes := stats.rows(), stats.bytes()
+				throughput := ThreadThroughput{
+					Thread:         i + 1,
+					RowsCount:      rows,
+					BytesCount:     bytes,
+					RowsPerSecond:  float64(rows-prevRows[i]) / elapsed,
+					BytesPerSecond: float64(bytes-prevBytes[i]) / elapsed,
+				}
+				prevRows[i], prevBytes[i] = rows, bytes
+				throughputs = append(throughputs, throughput)
+				if w.isOutOfBounds(throughput) && w.OnAlert != nil {
+					w.OnAlert(throughput)
+				}
+			}
+			if w.OnTick != nil && len(throughputs) > 0 {
+				w.OnTick(throughputs)
+			}
+		}
+	}
+}
+
+// isOutOfBounds checks if given throughput is outside configured bounds.
+func (w *Watchdog) isOutOfBounds(throughput ThreadThroughput) bool {
+	if w.MinRowsPerSecond > 0 && throughput.RowsPerSecond < w.MinRowsPerSecond {
+		return true
+	}
+
+	return w.MaxRowsPerSecond > 0 && throughput.RowsPerSecond > w.MaxRowsPerSecond
+}
@@ -0,0 +1,57 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestWatchdog(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	fName, err := setUpTmpCsvFile(1e4)
+	if err != nil {
+		t.Fatalf("prerequisite failed: could not generate CSV file: %v", err)
+	}
+	defer tearDownTmpCsvFile(fName)
+	var alertsCount, ticksCount int64
+	subject := bigcsvreader.New()
+	subject.SetFilePath(fName)
+	subject.ColumnsCount = 5
+	subject.MaxGoroutinesNo = 2
+	subject.Watchdog = &bigcsvreader.Watchdog{
+		Interval:         10 * time.Millisecond,
+		MinRowsPerSecond: 1e9, // unreachable, as the consumer is slow.
+		OnAlert: func(throughput bigcsvreader.ThreadThroughput) {
+			assertTrue(t, throughput.Thread == 1 || throughput.Thread == 2)
+			assertTrue(t, throughput.RowsPerSecond < 1e9)
+			atomic.AddInt64(&alertsCount, 1)
+		},
+		OnTick: func(throughputs []bigcsvreader.ThreadThroughput) {
+			assertTrue(t, len(throughputs) > 0)
+			atomic.AddInt64(&ticksCount, 1)
+		},
+	}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	rowsChans, errsChan := subject.Read(ctx)
+	time.Sleep(50 * time.Millisecond) // simulate a stalled consumer
+	records, err := gatherRecords(rowsChans, errsChan)
+
+	// assert
+	assertNil(t, err)
+	assertEqual(t, 10000, len(records))
+	assertTrue(t, atomic.LoadInt64(&alertsCount) > 0)
+	assertTrue(t, atomic.LoadInt64(&ticksCount) > 0)
+}
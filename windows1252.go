@@ -0,0 +1,67 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// windows1252Punctuation maps the Windows-1252 punctuation bytes (0x80-0x9F range)
+// commonly found in nominally UTF-8 exports to their characters. Zero means the byte is not mapped.
+var windows1252Punctuation = [32]rune{
+	0x80 - 0x80: '€',
+	0x82 - 0x80: '‚',
+	0x84 - 0x80: '„',
+	0x85 - 0x80: '…',
+	0x8B - 0x80: '‹',
+	0x91 - 0x80: '‘',
+	0x92 - 0x80: '’',
+	0x93 - 0x80: '“',
+	0x94 - 0x80: '”',
+	0x95 - 0x80: '•',
+	0x96 - 0x80: '–',
+	0x97 - 0x80: '—',
+	0x99 - 0x80: '™',
+	0x9B - 0x80: '›',
+}
+
+// normalizeWindows1252 translates the Windows-1252 punctuation bytes which are not part
+// of valid UTF-8 sequences into UTF-8. It returns the normalized value and the number of replacements.
+// Other invalid bytes are left as they are.
+func normalizeWindows1252(value string) (string, int) {
+	if utf8.ValidString(value) {
+		return value, 0
+	}
+	var (
+		sb           strings.Builder
+		replacements int
+	)
+	sb.Grow(len(value) + 2)
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		if r == utf8.RuneError && size == 1 && value[i] >= 0x80 && value[i] <= 0x9F {
+			if mapped := windows1252Punctuation[value[i]-0x80]; mapped != 0 {
+				sb.WriteRune(mapped)
+				replacements++
+				i++
+
+				continue
+			}
+		}
+		sb.WriteString(value[i : i+size])
+		i += size
+	}
+
+	return sb.String(), replacements
+}
+
+// Windows1252Replacements returns the number of Windows-1252 punctuation bytes translated into UTF-8
+// (see [ColumnOptions.NormalizeWindows1252]) so far. It is final after the run is done.
+func (rn *Run) Windows1252Replacements() int64 {
+	return atomic.LoadInt64(&rn.windows1252Replacements)
+}
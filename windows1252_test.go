@@ -0,0 +1,48 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_NormalizeWindows1252(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	content := "1,\x93quoted\x94 \x96 dash\n" +
+		"2,\"already \xe2\x80\x9cUTF-8\xe2\x80\x9d, caf\xc3\xa9\"\n" +
+		"3,it\x92s unknown \xff\x81\n"
+	filePath := writeTmpFile(t, t.TempDir(), "windows1252.csv", content)
+	subject := bigcsvreader.New()
+	subject.SetFilePath(filePath)
+	subject.ColumnsCount = 2
+	subject.ColumnsOptions = map[int]bigcsvreader.ColumnOptions{
+		1: {NormalizeWindows1252: true},
+	}
+	ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancelCtx()
+
+	// act
+	run := subject.Start(ctx)
+	rows := gatherRowsInOrder(run.RowsChans())
+	err := run.Err()
+
+	// assert
+	assertNil(t, err)
+	sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+	assertEqual(t, "“quoted” – dash", rows[0][1])
+	assertEqual(t, "already “UTF-8”, café", rows[1][1])
+	assertEqual(t, "it’s unknown \xff\x81", rows[2][1]) // bytes not mapped are left as they are.
+	assertTrue(t, utf8.ValidString(rows[0][1]))
+	assertEqual(t, int64(4), run.Windows1252Replacements())
+}
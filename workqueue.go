@@ -0,0 +1,201 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// workQueueRangesPerGoroutine is the number of ranges data is split into, per goroutine, see [CsvReader.WorkQueue].
+	workQueueRangesPerGoroutine = 8
+	// workQueueMaxRangeSize is the maximum size of a range, see [CsvReader.WorkQueue].
+	workQueueMaxRangeSize = 1 << 20
+)
+
+// errUnsupportedWithWorkQueue is the error returned when [CsvReader.WorkQueue] is combined with a feature
+// relying on the goroutines' chunks being allocated up front.
+var errUnsupportedWithWorkQueue = errors.New("not supported with work queue")
+
+// workQueue holds the data ranges the goroutines claim in turn, see [CsvReader.WorkQueue].
+type workQueue struct {
+	// ranges holds the [start, end] offsets of each range.
+	ranges [][2]int
+	// claimed is the number of ranges claimed so far.
+	claimed int64
+}
+
+// newWorkQueue splits the data located in the [dataStart, dataEnd) byte range into ranges,
+// for given number of goroutines.
+func newWorkQueue(dataStart, dataEnd, totalThreads int) *workQueue {
+	rangeSize := (dataEnd - dataStart) / (workQueueRangesPerGoroutine * max(totalThreads, 1))
+	rangeSize = max(min(rangeSize, workQueueMaxRangeSize), minBytesToReadByAGoroutine)
+	wq := &workQueue{}
+	for start := dataStart; start < dataEnd; start += rangeSize {
+		wq.ranges = append(wq.ranges, [2]int{start, min(start+rangeSize, dataEnd) - 1})
+	}
+
+	return wq
+}
+
+// claim returns the next unclaimed range, or false if all ranges were claimed.
+func (wq *workQueue) claim() ([2]int, bool) {
+	i := atomic.AddInt64(&wq.claimed, 1) - 1
+	if i >= int64(len(wq.ranges)) {
+		return [2]int{}, false
+	}
+
+	return wq.ranges[i], true
+}
+
+// loadedRange holds the lines of a range, read into memory.
+type loadedRange struct {
+	// offsets are the range's [start, end] offsets.
+	offsets [2]int
+	// start is the offset of the range's first line.
+	start int
+	// data holds the range's lines.
+	data []byte
+	// complete is a flag indicating all the range's lines were read (no error occurred).
+	complete bool
+}
+
+// prefetchRanges claims ranges and reads their lines into memory, in the background, pushing them into
+// the returned channel. The channel being unbuffered, a range is read while the previous one is handled,
+// alternately into two buffers, the one read into being no longer referenced by the handling goroutine.
+// Prefetching stops after all ranges were claimed, or after done is closed, the channel being closed then.
+func (cr *CsvReader) prefetchRanges(rn *Run, f io.ReaderAt, thread int, done <-chan struct{}) <-chan loadedRange {
+	ranges := make(chan loadedRange)
+	go func() {
+		defer close(ranges)
+		var (
+			buffers [2][]byte
+			r       = bufio.NewReaderSize(nil, cr.BufferSize)
+		)
+		for i := 0; ; i ^= 1 {
+			offsets, ok := rn.workQueue.claim()
+			if !ok {
+				return
+			}
+			r.Reset(io.NewSectionReader(f, int64(offsets[0]), int64(rn.dataEnd-offsets[0])))
+			loaded := cr.loadRange(rn, r, thread, offsets, buffers[i][:0])
+			buffers[i] = loaded.data
+			select {
+			case ranges <- loaded:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return ranges
+}
+
+// loadRange reads into buf the lines of given range, from r, positioned at the range's start.
+// Like with goroutines' chunks, the line the range's start falls into is skipped (it belongs to the previous range),
+// unless the range starts the data, and the lines starting up to the range's end offset + 1 are read.
+func (cr *CsvReader) loadRange(rn *Run, r *bufio.Reader, thread int, offsets [2]int, buf []byte) loadedRange {
+	loaded := loadedRange{offsets: offsets, start: offsets[0], data: buf}
+	if offsets[0] != rn.dataStart {
+		line := cr.readLine(rn, r, thread, offsets[0])
+		if line == nil {
+			return loaded
+		}
+		loaded.start += len(line)
+	}
+	for pos := loaded.start; pos-1 <= offsets[1] && pos < rn.dataEnd; {
+		line := cr.readLine(rn, r, thread, pos)
+		if line == nil {
+			return loaded
+		}
+		loaded.data = append(loaded.data, line...)
+		pos += len(line)
+	}
+	loaded.complete = true
+
+	return loaded
+}
+
+// readWorkQueueAsync handles the ranges the goroutine claims in turn (see [CsvReader.WorkQueue]),
+// emitting the rows into the thread's channel. The goroutine's initial chunk offsets are disregarded.
+func (cr *CsvReader) readWorkQueueAsync(
+	ctx context.Context,
+	rn *Run,
+	currentThreadNo, _, _ int,
+	wg *sync.WaitGroup,
+) {
+	defer wg.Done()
+	stats := &rn.threads[currentThreadNo-1]
+	defer stats.markDone()
+
+	f := cr.openFile(rn, currentThreadNo)
+	if f == nil {
+		return
+	}
+	defer f.Close()
+
+	lh := cr.newLineHandler(rn, currentThreadNo, stats)
+	if currentThreadNo == 1 && rn.emitHeader {
+		cr.emitHeaderRow(rn)
+	}
+	done := make(chan struct{})
+	ranges := cr.prefetchRanges(rn, f, currentThreadNo, done)
+	defer func() {
+		close(done)
+		for range ranges { // wait for the prefetching goroutine to stop, before the file is closed.
+		}
+	}()
+
+RangesLoop:
+	for loaded := range ranges {
+		pos := loaded.start
+		for data := loaded.data; len(data) > 0; {
+			select {
+			case <-ctx.Done():
+				rn.sendErr(fmt.Errorf(
+					"bigcsvreader: thread #%d received context error (%w: %w)",
+					currentThreadNo, ErrCanceledWithPartialData, ctx.Err(),
+				))
+
+				return
+			default:
+			}
+			if rn.errorRate.isAborted() {
+				return
+			}
+			if rn.isLimitReached() {
+				break RangesLoop
+			}
+			if rn.memThreshold > 0 && !rn.waitMemory(ctx) {
+				continue // context is done, let the select handle it.
+			}
+			line := data
+			if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+				line = data[:idx+1]
+			}
+			cr.handleLine(ctx, rn, lh, line, pos)
+			pos += len(line)
+			stats.addBytes(len(line))
+			data = data[len(line):]
+		}
+		if !loaded.complete {
+			rn.fail()
+			rn.sendErr(fmt.Errorf(
+				"bigcsvreader: thread #%d stopped at offset %d, before range's end offset %d (%w)",
+				currentThreadNo, pos, loaded.offsets[1], ErrChunkIncomplete,
+			))
+		}
+	}
+	cr.finishLines(ctx, rn, lh, 0, 0)
+	rn.closeIfEmpty(currentThreadNo)
+}
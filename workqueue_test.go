@@ -0,0 +1,137 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_WorkQueue(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	const rowsCount = 3000
+	filePath, err := setUpTmpCsvFile(rowsCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tearDownTmpCsvFile(filePath) })
+	newSubject := func(maxGoroutines int) *bigcsvreader.CsvReader {
+		subject := bigcsvreader.New()
+		subject.SetFilePath(filePath)
+		subject.ColumnsCount = 5
+		subject.MaxGoroutinesNo = maxGoroutines
+		subject.WorkQueue = true
+
+		return subject
+	}
+
+	t.Run("ranges are claimed by goroutines", func(t *testing.T) {
+		t.Parallel()
+
+		for _, maxGoroutines := range [...]int{1, 4} {
+			// arrange
+			subject := newSubject(maxGoroutines)
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+
+			// act
+			rows, err := readRowsInOrder(ctx, subject)
+			cancelCtx()
+
+			// assert
+			assertNil(t, err)
+			if assertEqual(t, rowsCount, len(rows)) {
+				ids := make([]int, len(rows))
+				for i, row := range rows {
+					ids[i], _ = strconv.Atoi(row[0])
+				}
+				sort.Ints(ids)
+				for i, id := range ids {
+					if !assertEqual(t, i+1, id) {
+						break
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("a goroutine whose channel drains claims more ranges", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := newSubject(2)
+		subject.ChanBufferSize = 1
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		run := subject.Start(ctx)
+		rowsChans := run.RowsChans()
+		secondChanRows := 0
+		timeout := time.After(10 * time.Second)
+	ConsumeLoop:
+		for secondChanRows < rowsCount*3/4 { // the first goroutine's channel is not consumed meanwhile.
+			select {
+			case <-rowsChans[1]:
+				secondChanRows++
+			case <-timeout:
+				break ConsumeLoop
+			}
+		}
+		drained := secondChanRows + drainRows(rowsChans)
+		err := run.Err()
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, 2, len(rowsChans))
+		assertEqual(t, rowsCount*3/4, secondChanRows)
+		assertEqual(t, rowsCount, drained)
+	})
+
+	t.Run("reading stops at limit", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := newSubject(4)
+		subject.LimitRows = 10
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rows, err := readRowsInOrder(ctx, subject)
+
+		// assert
+		assertNil(t, err)
+		assertEqual(t, 10, len(rows))
+	})
+
+	t.Run("chunks based features are not supported", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := newSubject(4)
+		subject.NumberLines = true
+		ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancelCtx()
+
+		// act
+		rowsChans, errsChan := subject.Read(ctx)
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertNil(t, rowsChans)
+		if assertNotNil(t, err) {
+			assertTrue(t, strings.Contains(err.Error(), "not supported with work queue"))
+		}
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ZstdDecoder decompresses zstd frames, see [CsvReader.ZstdDecoder].
+// It is satisfied by the github.com/klauspost/compress/zstd package's *zstd.Decoder,
+// created with zstd.NewReader(nil).
+type ZstdDecoder interface {
+	// DecodeAll decompresses all the frames of input, appending the decompressed data to dst.
+	// It is called concurrently.
+	DecodeAll(input, dst []byte) ([]byte, error)
+}
+
+// errNotSeekableZstd is the error returned when a zstd compressed file is not in the seekable format.
+var errNotSeekableZstd = errors.New("zstd compressed file is not in the seekable format")
+
+// zstdMagic are the bytes zstd frames start with.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+const (
+	// zstdSeekTableMagic is the magic number of the skippable frame holding the seek table.
+	zstdSeekTableMagic = 0x184d2a5e
+	// zstdSeekableMagic is the magic number ending the seek table.
+	zstdSeekableMagic = 0x8f92eab1
+	// zstdSeekTableFooterSize is the size of the seek table's footer.
+	zstdSeekTableFooterSize = 9
+	// zstdSkippableHeaderSize is the size of the skippable frame's header (magic number and frame size).
+	zstdSkippableHeaderSize = 8
+)
+
+// readZstdSeekTable returns the blocks index of the zstd file in the seekable format, out of its seek table,
+// which ends the file, in a skippable frame, listing the compressed and decompressed sizes of each frame.
+func readZstdSeekTable(f io.ReaderAt, size int64) (*blocksIndex, error) {
+	footer := make([]byte, zstdSeekTableFooterSize)
+	if size < zstdSkippableHeaderSize+zstdSeekTableFooterSize {
+		return nil, errNotSeekableZstd
+	}
+	if _, err := f.ReadAt(footer, size-zstdSeekTableFooterSize); err != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not read zstd seek table (%w)", err)
+	}
+	if binary.LittleEndian.Uint32(footer[5:]) != zstdSeekableMagic {
+		return nil, errNotSeekableZstd
+	}
+	var (
+		framesCount = int64(binary.LittleEndian.Uint32(footer))
+		descriptor  = footer[4]
+		entrySize   = int64(8)
+	)
+	if descriptor&0x7c != 0 { // reserved bits.
+		return nil, fmt.Errorf("bigcsvreader: invalid zstd seek table descriptor %#x", descriptor)
+	}
+	if descriptor&0x80 != 0 { // entries hold frames' checksums too.
+		entrySize += 4
+	}
+	tableSize := framesCount*entrySize + zstdSeekTableFooterSize
+	tableStart := size - tableSize - zstdSkippableHeaderSize
+	if tableStart < 0 {
+		return nil, fmt.Errorf("bigcsvreader: invalid zstd seek table (%d frames)", framesCount)
+	}
+	table := make([]byte, zstdSkippableHeaderSize+framesCount*entrySize)
+	if _, err := f.ReadAt(table, tableStart); err != nil {
+		return nil, fmt.Errorf("bigcsvreader: could not read zstd seek table (%w)", err)
+	}
+	if binary.LittleEndian.Uint32(table) != zstdSeekTableMagic ||
+		int64(binary.LittleEndian.Uint32(table[4:])) != tableSize {
+		return nil, errNotSeekableZstd
+	}
+
+	blocks := make([]compressedBlock, 0, framesCount+1)
+	var last compressedBlock
+	for entry := table[zstdSkippableHeaderSize:]; len(entry) > 0; entry = entry[entrySize:] {
+		blocks = append(blocks, last)
+		last.coffset += int64(binary.LittleEndian.Uint32(entry))
+		last.uoffset += int64(binary.LittleEndian.Uint32(entry[4:]))
+	}
+	if last.coffset != tableStart {
+		return nil, fmt.Errorf(
+			"bigcsvreader: invalid zstd seek table (frames end at offset %d, instead of %d)",
+			last.coffset, tableStart,
+		)
+	}
+
+	return &blocksIndex{blocks: append(blocks, last)}, nil
+}
+
+// zstdFrameDecoder decompresses zstd frames.
+type zstdFrameDecoder struct {
+	zstd ZstdDecoder
+	// frame holds the compressed frame being decompressed.
+	frame []byte
+}
+
+// decode decompresses the zstd frame read by r, appending the decompressed data to dst.
+func (d *zstdFrameDecoder) decode(r *io.SectionReader, dst []byte) ([]byte, error) {
+	if int64(cap(d.frame)) < r.Size() {
+		d.frame = make([]byte, r.Size())
+	}
+	d.frame = d.frame[:r.Size()]
+	if _, err := io.ReadFull(r, d.frame); err != nil {
+		return dst, err
+	}
+
+	return d.zstd.DecodeAll(d.frame, dst)
+}
@@ -0,0 +1,195 @@
+// Copyright The ActForGood Authors.
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file or at
+// https://github.com/actforgood/bigcsvreader/blob/main/LICENSE.
+
+package bigcsvreader_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actforgood/bigcsvreader"
+)
+
+func TestCsvReader_zstd(t *testing.T) {
+	t.Parallel()
+
+	const rowsCount = 2000
+	var (
+		content  strings.Builder
+		expected = make(map[string]string, rowsCount)
+	)
+	content.WriteString("id,value\n")
+	for i := 1; i <= rowsCount; i++ {
+		value := "value " + strconv.Itoa(i*7)
+		expected[strconv.Itoa(i)] = value
+		content.WriteString(strconv.Itoa(i) + "," + value + "\n")
+	}
+	dir := t.TempDir()
+
+	for _, withChecksums := range [...]bool{false, true} {
+		withChecksums := withChecksums // capture range variable
+		t.Run("seekable format, checksums "+strconv.FormatBool(withChecksums), func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			subject := bigcsvreader.New()
+			subject.SetFilePath(writeSeekableZstdFile(
+				t, dir, "checksums_"+strconv.FormatBool(withChecksums)+".csv.zst", content.String(), 200, withChecksums,
+			))
+			subject.FileHasHeader = true
+			subject.ColumnsCount = 2
+			subject.MaxGoroutinesNo = 4
+			subject.NumberLines = true
+			subject.ZstdDecoder = rawBlocksZstdDecoder{}
+			ctx, cancelCtx := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancelCtx()
+
+			// act
+			rowsChans, errsChan := subject.ReadWithMeta(ctx)
+			var (
+				mu    sync.Mutex
+				wg    sync.WaitGroup
+				rows  = make(map[string]string, rowsCount)
+				lines = make(map[string]int64, rowsCount)
+			)
+			for _, rowsChan := range rowsChans {
+				wg.Add(1)
+				go func(rowsChan bigcsvreader.MetaRowsChan) {
+					defer wg.Done()
+					for row := range rowsChan {
+						mu.Lock()
+						rows[row.Fields[0]] = row.Fields[1]
+						lines[row.Fields[0]] = row.Line
+						mu.Unlock()
+					}
+				}(rowsChan)
+			}
+			err := bigcsvreader.CollectErrors(errsChan)
+			wg.Wait()
+
+			// assert
+			assertNil(t, err)
+			assertEqual(t, 4, len(rowsChans))
+			assertEqual(t, expected, rows)
+			assertEqual(t, int64(2), lines["1"])
+			assertEqual(t, int64(rowsCount+1), lines[strconv.Itoa(rowsCount)])
+		})
+	}
+
+	t.Run("no decoder", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeSeekableZstdFile(t, t.TempDir(), "data.csv.zst", content.String(), 200, false))
+
+		// act
+		rowsChans, errsChan := subject.Read(context.Background())
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertEqual(t, 0, len(rowsChans))
+		var compressionErr *bigcsvreader.UnsupportedCompressionError
+		if assertTrue(t, errors.As(err, &compressionErr)) {
+			assertEqual(t, "zstd", compressionErr.Format)
+		}
+	})
+
+	t.Run("not seekable format", func(t *testing.T) {
+		t.Parallel()
+
+		// arrange
+		subject := bigcsvreader.New()
+		subject.SetFilePath(writeTmpFile(t, t.TempDir(), "data.csv", string(zstdRawFrame([]byte("1,a\n2,b\n")))))
+		subject.ZstdDecoder = rawBlocksZstdDecoder{}
+
+		// act
+		rowsChans, errsChan := subject.Read(context.Background())
+		err := bigcsvreader.CollectErrors(errsChan)
+
+		// assert
+		assertEqual(t, 0, len(rowsChans))
+		if assertNotNil(t, err) {
+			assertTrue(t, strings.Contains(err.Error(), "not in the seekable format"))
+		}
+	})
+}
+
+// rawBlocksZstdDecoder decompresses zstd frames holding raw (not compressed) blocks,
+// as written by [zstdRawFrame].
+type rawBlocksZstdDecoder struct{}
+
+func (rawBlocksZstdDecoder) DecodeAll(input, dst []byte) ([]byte, error) {
+	for len(input) > 0 {
+		if len(input) < 6 || !bytes.HasPrefix(input, []byte{0x28, 0xb5, 0x2f, 0xfd}) || input[4] != 0x20 {
+			return dst, errors.New("unsupported frame")
+		}
+		input = input[6:]
+		for last := false; !last; {
+			if len(input) < 3 {
+				return dst, errors.New("truncated block")
+			}
+			header := uint32(input[0]) | uint32(input[1])<<8 | uint32(input[2])<<16
+			last = header&1 == 1
+			size := int(header >> 3)
+			if (header>>1)&3 != 0 || len(input) < 3+size {
+				return dst, errors.New("unsupported block")
+			}
+			dst = append(dst, input[3:3+size]...)
+			input = input[3+size:]
+		}
+	}
+
+	return dst, nil
+}
+
+// zstdRawFrame returns a zstd frame holding the data (up to 255 bytes) in a raw block.
+func zstdRawFrame(data []byte) []byte {
+	frame := []byte{0x28, 0xb5, 0x2f, 0xfd, 0x20, byte(len(data))} // single segment, 1 byte content size.
+	blockHeader := uint32(len(data))<<3 | 1                        // last raw block.
+	frame = append(frame, byte(blockHeader), byte(blockHeader>>8), byte(blockHeader>>16))
+
+	return append(frame, data...)
+}
+
+// writeSeekableZstdFile writes the content, as zstd frames holding frameSize bytes each, followed by
+// the seek table, into the named file from given directory, and returns its path.
+func writeSeekableZstdFile(t *testing.T, dir, name, content string, frameSize int, withChecksums bool) string {
+	t.Helper()
+	var (
+		buf         bytes.Buffer
+		table       bytes.Buffer
+		framesCount uint32
+	)
+	for start := 0; start < len(content); start += frameSize {
+		data := content[start:min(start+frameSize, len(content))]
+		frame := zstdRawFrame([]byte(data))
+		buf.Write(frame)
+		_ = binary.Write(&table, binary.LittleEndian, [2]uint32{uint32(len(frame)), uint32(len(data))})
+		if withChecksums {
+			_ = binary.Write(&table, binary.LittleEndian, uint32(0))
+		}
+		framesCount++
+	}
+	descriptor := byte(0)
+	if withChecksums {
+		descriptor = 0x80
+	}
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0x184d2a5e))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(table.Len()+9))
+	buf.Write(table.Bytes())
+	_ = binary.Write(&buf, binary.LittleEndian, framesCount)
+	buf.WriteByte(descriptor)
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(0x8f92eab1))
+
+	return writeTmpFile(t, dir, name, buf.String())
+}